@@ -3,20 +3,30 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/config"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/handlers"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/middleware"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services/ai"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/worker"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/cqai"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/lyrics"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/metrics"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/storage"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func main() {
@@ -28,13 +38,43 @@ func main() {
 	log.Printf("Environment: %s", cfg.Environment)
 	log.Printf("Server port: %d", cfg.ServerPort)
 	log.Printf("Data path: %s", cfg.DBPath)
+	if cfg.APIAuthEnabled {
+		log.Printf("API authentication enabled (%d keys)", len(cfg.APIKeys))
+	}
 
 	// Ensure data directories exist
-	if err := utils.EnsureDataDirectories(); err != nil {
+	if err := utils.EnsureDataDirectories(cfg.BrandingPath); err != nil {
 		log.Fatalf("Failed to create data directories: %v", err)
 	}
 	log.Printf("Data directories verified")
 
+	// Resolve overlay fonts now so a missing font shows up at startup, not mid-render
+	fonts, err := video.ConfigureSystemFonts(cfg.FontBoldPath, cfg.FontRegularPath)
+	if err != nil {
+		log.Printf("WARNING: %v; videos with text overlays will fail to render", err)
+	} else {
+		log.Printf("Overlay fonts: bold=%s regular=%s", fonts.Bold, fonts.Regular)
+	}
+
+	// Check FFmpeg's version and filters now; renders swap in fallbacks for missing optional filters
+	ffmpegCaps, err := video.DetectCapabilities()
+	if err != nil {
+		if cfg.FFmpegStrict {
+			log.Fatalf("FFmpeg capability check failed: %v", err)
+		}
+		log.Printf("WARNING: FFmpeg capability check failed: %v; videos will fail to render", err)
+	} else {
+		ffmpegCaps.Log()
+		if cfg.FFmpegStrict && !ffmpegCaps.Ready() {
+			log.Fatalf("FFmpeg is missing required filters: %s (FFMPEG_STRICT=true)", strings.Join(ffmpegCaps.MissingRequired, ", "))
+		}
+	}
+
+	// Report Python environment problems now rather than as subprocess errors mid-render
+	audio.NewAnalyzer(cfg).CheckEnvironment().Log()
+	lyrics.CheckEnvironment(cfg).Log()
+	audio.NewSeparator(cfg).CheckEnvironment().Log()
+
 	// Initialize database
 	if err := database.InitDB(cfg.DBPath); err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
@@ -54,30 +94,57 @@ func main() {
 	queueRepo := database.NewQueueRepository(database.DB)
 	videoRepo := database.NewVideoRepository(database.DB)
 	settingsRepo := database.NewSettingsRepository(database.DB)
+	statsRepo := database.NewStatsRepository(database.DB)
+	youtubeRepo := database.NewYoutubeUploadRepository(database.DB)
+
+	// Register Prometheus collectors
+	metrics.Register()
+
+	// Limit concurrent CQAI/Ollama requests shared by all jobs
+	cqai.SetConcurrencyLimit(cfg.ConcurrencyLimit)
+	log.Printf("CQAI concurrency limit: %d", cfg.ConcurrencyLimit)
+
+	// Create artifact storage (local disk or S3)
+	store, err := storage.New(cfg, utils.GetDataPath())
+	if err != nil {
+		log.Fatalf("Failed to initialize storage: %v", err)
+	}
+	log.Printf("Storage backend: %s", cfg.StorageBackend)
 
 	// Create progress broadcaster for live updates
 	broadcaster := services.NewProgressBroadcaster()
 
 	// Create AI client for metadata enrichment
-	aiClient := ai.NewClient()
+	aiClient := ai.NewClient(cfg, settingsRepo)
 	log.Println("AI client initialized")
 
+	// Create queue worker (handlers wake it when work is enqueued)
+	queueWorker := worker.NewWorker(queueRepo, songRepo, broadcaster, cfg.WorkerPollInterval, cfg, store)
+
 	// Create handlers
-	songHandler := handlers.NewSongHandler(songRepo, cfg)
-	queueHandler := handlers.NewQueueHandler(queueRepo, broadcaster)
+	songHandler := handlers.NewSongHandler(songRepo, cfg, queueWorker, queueWorker)
+	queueHandler := handlers.NewQueueHandler(queueRepo, broadcaster, queueWorker)
 	progressHandler := handlers.NewProgressHandler(broadcaster, queueRepo)
-	imageHandler := handlers.NewImageHandler(settingsRepo)
-	audioHandler := handlers.NewAudioHandler(songRepo, aiClient)
-	uploadHandler := handlers.NewUploadHandler(songRepo)
+	imageHandler := handlers.NewImageHandler(settingsRepo, queueRepo, songRepo, queueWorker, cfg, store)
+	stemSeparations := services.NewStemSeparations(audio.NewSeparator(cfg), store, broadcaster)
+	audioHandler := handlers.NewAudioHandler(songRepo, settingsRepo, aiClient, audio.NewAnalyzer(cfg), stemSeparations)
+	uploadHandler := handlers.NewUploadHandler(songRepo, store)
 	dashboardHandler := handlers.NewDashboardHandler(database.DB)
-	videoHandler := handlers.NewVideoHandler(videoRepo)
+	statsHandler := handlers.NewStatsHandler(statsRepo)
+	videoHandler := handlers.NewVideoHandler(videoRepo, store)
 	settingsHandler := handlers.NewSettingsHandler(settingsRepo)
-	enrichmentHandler := handlers.NewEnrichmentHandler(songRepo, aiClient)
-
-	// Create and start queue worker
-	queueWorker := worker.NewWorker(queueRepo, songRepo, broadcaster, 5*time.Second, cfg)
+	brandingHandler := handlers.NewBrandingHandler(settingsRepo, cfg)
+	fontHandler := handlers.NewFontHandler(video.NewFontRegistry(utils.GetFontsPath()))
+	renderHandler := handlers.NewRenderHandler()
+	enrichmentHandler := handlers.NewEnrichmentHandler(songRepo, settingsRepo, aiClient,
+		services.NewEnrichmentJobs(songRepo, settingsRepo, aiClient, broadcaster))
+	youtubeHandler := handlers.NewYouTubeHandler(songRepo, youtubeRepo, settingsRepo, aiClient)
+	healthHandler := handlers.NewHealthHandler(database.DB, cfg)
+	maintenanceHandler := handlers.NewMaintenanceHandler(songRepo, cfg)
+
+	// Start queue worker
 	go queueWorker.Start()
-	log.Println("Queue worker started (polling every 5 seconds)")
+	log.Printf("Queue worker started (polling every %s)", cfg.WorkerPollInterval)
 
 	// Create Gin router
 	if cfg.Environment == "production" {
@@ -86,21 +153,11 @@ func main() {
 
 	router := gin.Default()
 
-	// CORS middleware - MUST be first
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Add("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Add("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Add("Access-Control-Allow-Headers", "Content-Type, Authorization, Cache-Control, Accept")
-		c.Writer.Header().Add("Access-Control-Expose-Headers", "Content-Type, Cache-Control, Connection")
-		c.Writer.Header().Add("Access-Control-Max-Age", "86400")
+	// Tag every request with an ID that follows it into queued work
+	router.Use(middleware.RequestID())
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(200)
-			return
-		}
-
-		c.Next()
-	})
+	// CORS middleware - MUST be before any routes (origins from CORS_ALLOWED_ORIGINS)
+	router.Use(middleware.CORS(cfg))
 
 	// Health check endpoint
 	router.GET("/health", func(c *gin.Context) {
@@ -110,82 +167,144 @@ func main() {
 		})
 	})
 
+	// Readiness check - probes downstream dependencies
+	router.GET("/health/ready", healthHandler.Ready)
+
+	// Prometheus metrics
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	// Serve static files from new data directory
 	videosPath := utils.GetVideosPath()
-	router.Static("/videos", videosPath)
+	serveArtifacts(router, store, "videos", videosPath)
 	log.Printf("Serving videos from: %s", videosPath)
 
 	// Serve static image files
 	imagesPath := utils.GetImagesPath()
-	router.Static("/images", imagesPath)
+	serveArtifacts(router, store, "images", imagesPath)
 	log.Printf("Serving images from: %s", imagesPath)
 
 	// Serve static audio files
 	audioPath := utils.GetAudioPath()
-	router.Static("/audio", audioPath)
+	serveArtifacts(router, store, "audio", audioPath)
 	log.Printf("Serving audio from: %s", audioPath)
 
 	// Serve branding files (logos, etc.)
-	brandingPath := filepath.Join(utils.GetDataPath(), "branding")
-	router.Static("/branding", brandingPath)
-	log.Printf("Serving branding from: %s", brandingPath)
+	router.Static("/branding", cfg.BrandingPath)
+	log.Printf("Serving branding from: %s", cfg.BrandingPath)
+
+	// Stricter per-client limit for endpoints that start renders, analysis or CQAI work
+	expensive := middleware.ExpensiveRateLimit(cfg)
 
-	// API v1 group
-	v1 := router.Group("/api/v1")
+	// API v1 group (requires an API key when API_AUTH_ENABLED=true, rate
+	// limited per client when RATE_LIMIT_ENABLED=true)
+	v1 := router.Group("/api/v1", middleware.APIAuth(cfg), middleware.RateLimit(cfg))
 	{
 		// Dashboard endpoint
 		v1.GET("/dashboard", dashboardHandler.GetDashboard)
 
+		// Historical queue statistics for charts
+		v1.GET("/stats/timeseries", statsHandler.GetTimeSeries)
+
+		// Check CQAI image/LLM connectivity and that the configured models are loaded
+		v1.GET("/diagnostics/cqai", healthHandler.CQAIDiagnostics)
+
 		// Songs endpoints
 		songs := v1.Group("/songs")
 		{
 			songs.GET("", songHandler.GetAll)
 			songs.GET("/:id", songHandler.GetByID)
 			songs.POST("", songHandler.Create)
+			songs.POST("/import", songHandler.ImportSong)
 			songs.PUT("/:id", songHandler.Update)
 			songs.DELETE("/:id", songHandler.Delete)
+			songs.POST("/:id/copy-settings", songHandler.CopySettings)
 
 			// Validation endpoint
 			songs.GET("/:id/validate-paths", songHandler.ValidateAudioPaths)
 
 			// Render log endpoint
 			songs.GET("/:id/render-log", songHandler.GetRenderLog)
+			songs.POST("/:id/overlay-preview", songHandler.OverlayPreview)
+
+			// Queue a low-resolution preview render
+			songs.POST("/:id/draft-render", expensive, queueHandler.DraftRender)
+
+			// Export all artifacts for a song as a zip
+			songs.GET("/:id/export", songHandler.ExportSong)
+
+			// Lyrics endpoints
+			songs.GET("/:id/lyrics", songHandler.GetLyrics)
+			songs.POST("/:id/reprocess-lyrics", songHandler.ReprocessLyrics)
+			songs.POST("/:id/reset-processing", songHandler.ResetProcessing)
+			songs.PUT("/:id/lyric-timing", songHandler.UpdateLyricTiming)
+			songs.POST("/:id/karaoke/rebuild", songHandler.RebuildKaraoke)
 
 			// Image endpoints for songs
 			songs.GET("/:id/images", imageHandler.GetImagesBySong)
 			songs.POST("/:id/images", imageHandler.CreateImagePrompt)
 			songs.DELETE("/:id/images", imageHandler.DeleteImagesBySong)
+			songs.POST("/:id/regenerate-all-images", expensive, imageHandler.RegenerateAllImages)
+			songs.POST("/:id/regenerate-image", expensive, imageHandler.RegenerateSectionImage)
+			songs.POST("/:id/approve-images", imageHandler.ApproveSongImages)
+			songs.GET("/:id/image-similarity", imageHandler.GetImageSimilarity)
 
 			// Audio analysis endpoint
-			songs.POST("/:id/analyze", audioHandler.AnalyzeSong) // Audio upload endpoint
+			songs.POST("/:id/analyze", expensive, audioHandler.AnalyzeSong) // Audio upload endpoint
 			songs.POST("/:id/upload-audio", uploadHandler.UploadAudio)
+			songs.POST("/:id/separate-stems", expensive, audioHandler.SeparateStems)
+			songs.GET("/:id/separate-stems", audioHandler.GetStemSeparation)
+			songs.POST("/:id/upload-audio/init", uploadHandler.InitAudioUpload)
+			songs.GET("/:id/upload-audio/:uploadId", uploadHandler.GetAudioUpload)
+			songs.HEAD("/:id/upload-audio/:uploadId", uploadHandler.GetAudioUpload)
+			songs.PATCH("/:id/upload-audio/:uploadId", uploadHandler.PatchAudioUpload)
+			songs.POST("/:id/upload-audio/:uploadId/finalize", uploadHandler.FinalizeAudioUpload)
+			songs.DELETE("/:id/upload-audio/:uploadId", uploadHandler.AbortAudioUpload)
 
 			// Metadata enrichment endpoints
-			songs.POST("/:id/enrich-metadata", enrichmentHandler.EnrichSongMetadata)
+			songs.POST("/:id/enrich-metadata", expensive, enrichmentHandler.EnrichSongMetadata)
+			songs.POST("/:id/enrich", expensive, enrichmentHandler.ProposeMetadata)
+			songs.GET("/:id/enrich", enrichmentHandler.GetProposedMetadata)
+			songs.PUT("/:id/metadata", enrichmentHandler.UpdateMetadata)
+
+			// YouTube metadata (generated, then reviewed before upload)
+			songs.GET("/:id/youtube-metadata", youtubeHandler.GetMetadata)
+			songs.POST("/:id/youtube-metadata", youtubeHandler.GenerateMetadata)
+			songs.PUT("/:id/youtube-metadata", youtubeHandler.UpdateMetadata)
 		}
 
 		// Enrichment endpoints
 		enrichment := v1.Group("/enrichment")
 		{
-			enrichment.POST("/batch", enrichmentHandler.EnrichBatch)
+			enrichment.POST("/batch", expensive, enrichmentHandler.EnrichBatch)
 			enrichment.GET("/status", enrichmentHandler.GetEnrichmentStatus)
 		}
+		v1.GET("/enrich/jobs/:id", enrichmentHandler.GetJob)
 
 		// Images endpoints
 		images := v1.Group("/images")
 		{
 			images.POST("/generate-prompt", imageHandler.GeneratePromptFromLyrics)
+			images.POST("/preview-prompt", imageHandler.PreviewPrompt)
 			images.PUT("/:id/prompt", imageHandler.UpdateImagePrompt)
-			images.POST("/:id/regenerate", imageHandler.RegenerateImage)
+			images.GET("/:id/file", imageHandler.GetImageFile)
+			images.POST("/:id/regenerate", expensive, imageHandler.RegenerateImage)
+			images.GET("/:id/variants", imageHandler.GetVariants)
+			images.POST("/:id/variants", expensive, imageHandler.GenerateVariants)
+			images.POST("/:id/select-variant/:variantId", imageHandler.SelectVariant)
+			images.POST("/:id/describe", imageHandler.DescribeImage)
+			images.POST("/:id/approve", imageHandler.ApproveImage)
 		}
 
 		// Queue endpoints
 		queue := v1.Group("/queue")
 		{
 			queue.GET("", queueHandler.GetAll)
-			queue.POST("", queueHandler.Create)
+			queue.POST("", expensive, queueHandler.Create)
+			queue.DELETE("", queueHandler.Clear)
 			queue.GET("/next", queueHandler.GetNext)
 			queue.GET("/:id", queueHandler.GetByID)
+			queue.GET("/:id/history", queueHandler.History)
+			queue.GET("/:id/logs", queueHandler.Logs)
 			queue.PUT("/:id", queueHandler.Update)
 			queue.DELETE("/:id", queueHandler.Delete)
 			queue.PUT("/:id/flag", queueHandler.UpdateFlag)
@@ -197,6 +316,8 @@ func main() {
 			progress.GET("/stream", progressHandler.StreamProgress)
 			progress.GET("/stream/:id", progressHandler.StreamQueueProgress)
 			progress.GET("/stats", progressHandler.GetStats)
+			progress.GET("/snapshot", progressHandler.GetSnapshot)
+			progress.GET("/snapshot/:id", progressHandler.GetQueueSnapshot)
 		}
 
 		// Videos endpoints
@@ -204,13 +325,32 @@ func main() {
 		{
 			videos.GET("", videoHandler.GetAll)
 			videos.GET("/song/:songId", videoHandler.GetBySongID)
+			videos.GET("/:id/download", videoHandler.Download)
 			videos.DELETE("/:id", videoHandler.Delete)
 		}
 
+		// Maintenance
+		v1.POST("/maintenance/gc", maintenanceHandler.GarbageCollect)
+		v1.POST("/maintenance/purge-temp", maintenanceHandler.PurgeTempFiles)
+		v1.POST("/maintenance/reconcile-images", maintenanceHandler.ReconcileImages)
+
 		// Settings endpoints
 		v1.GET("/settings", settingsHandler.Get)
 		v1.POST("/settings", settingsHandler.Update)
-		v1.POST("/settings/upload-logo", settingsHandler.UploadLogo)
+		v1.GET("/genres", settingsHandler.Genres)
+		v1.POST("/settings/upload-logo", brandingHandler.UploadLogo) // Kept for older frontends
+
+		// Branding assets
+		v1.GET("/branding", brandingHandler.Get)
+		v1.POST("/branding/logo", brandingHandler.UploadLogo)
+
+		// Uploaded fonts for karaoke and overlays
+		v1.GET("/fonts", fontHandler.List)
+		v1.POST("/fonts", fontHandler.Upload)
+		v1.GET("/fonts/:name", fontHandler.GetFile)
+
+		// Spectrum styles, colors, transitions and quality presets for render settings
+		v1.GET("/render/options", renderHandler.Options)
 
 		// Albums endpoints (placeholder)
 		albums := v1.Group("/albums")
@@ -254,3 +394,22 @@ func main() {
 
 	log.Println("Shutdown complete")
 }
+
+// serveArtifacts serves a data directory from disk, or redirects to presigned
+// URLs when artifacts live in remote storage
+func serveArtifacts(router *gin.Engine, store storage.Storage, prefix, localPath string) {
+	if !storage.IsRemote(store) {
+		router.Static("/"+prefix, localPath)
+		return
+	}
+
+	router.GET("/"+prefix+"/*filepath", func(c *gin.Context) {
+		key := prefix + "/" + strings.TrimPrefix(c.Param("filepath"), "/")
+		url, err := store.URL(c.Request.Context(), key, time.Hour)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+			return
+		}
+		c.Redirect(http.StatusFound, url)
+	})
+}
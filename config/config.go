@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all application configuration
@@ -12,6 +15,27 @@ type Config struct {
 	ServerPort  int
 	DBPath      string
 
+	// CORS: allowed origins ("*" for any), methods and request headers.
+	// Credentialed requests echo the request's origin, since browsers reject
+	// "*" with credentials.
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSAllowCredentials bool
+
+	// API key authentication for /api/v1, off by default for local use.
+	// APIKeys maps each accepted key to a label identifying its holder.
+	APIAuthEnabled bool
+	APIKeys        map[string]string
+
+	// Per-client (API key or IP) rate limits in requests per minute, off by
+	// default. Expensive endpoints (queueing, analysis, enrichment, image
+	// generation) are limited on top of the write limit.
+	RateLimitEnabled   bool
+	RateLimitReads     int
+	RateLimitWrites    int
+	RateLimitExpensive int
+
 	// Storage paths
 	StoragePath   string
 	SongsPath     string
@@ -19,16 +43,94 @@ type Config struct {
 	TempPath      string
 	LogsPath      string
 	PythonScripts string
+	BrandingPath  string // Logos and other branding overlays
+
+	// Audio analyzer; empty values fall back to python3 and script discovery
+	AnalyzerPython  string
+	AnalyzerScript  string
+	AnalyzerTimeout time.Duration
+
+	// Stem separation (Demucs or Spleeter) for songs uploaded as one mixed
+	// track; empty values fall back to the analyzer's interpreter and script
+	// discovery. AutoSeparateStems runs it in the pipeline when available.
+	SeparatorPython   string
+	SeparatorScript   string
+	SeparatorModel    string
+	SeparatorTimeout  time.Duration
+	AutoSeparateStems bool
+
+	// Detected vocal onsets later than this fraction of the song are treated
+	// as detection errors and ignored (songs can override the onset instead)
+	MaxVocalOnsetFraction float64
 
 	// CQAI settings
-	CQAIURL    string
-	LLMModel   string
-	ImageModel string
+	CQAIURL     string // z-image API
+	CQAILLMURL  string // Ollama API for LLM
+	LLMModel    string
+	ImageModel  string
+	VisionModel string
+
+	// ConcurrencyLimit caps in-flight CQAI/Ollama requests across all jobs
+	ConcurrencyLimit int
+
+	// WhisperX settings
+	WhisperXURL string
 
 	// Image generation settings
 	ImageWidth  int
 	ImageHeight int
 	ImageSteps  int
+
+	// Near-duplicate background detection: images whose perceptual hashes
+	// differ by at most this many bits (of 64) are flagged; negative disables
+	ImageSimilarityThreshold int
+	RegenerateSimilarImages  bool // Regenerate the later image of each flagged pair
+
+	// Render sections whose AI image is missing over a gradient derived from
+	// the song's mood and genre instead of skipping them
+	FallbackBackgrounds bool
+
+	// Storage backend: "local" (default) or "s3"
+	StorageBackend string
+	S3Endpoint     string
+	S3Bucket       string
+	S3Region       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3Prefix       string
+	S3UseSSL       bool
+
+	// Rendering settings
+	StrictDrawtext   bool   // Strip overlay text to Latin characters for fonts with limited glyphs
+	FontBoldPath     string // Fallback bold font file for overlays; empty auto-detects
+	FontRegularPath  string // Fallback regular font file for overlays; empty auto-detects
+	KeepTempFiles    bool   // Preserve intermediate render files per job for debugging
+	DebugRenderSteps bool   // Copy each render step's output to debug/song_<id>/ as a named sequence
+	FFmpegStrict     bool   // Refuse to start when FFmpeg is missing or lacks required filters
+
+	// MaxVideoDuration is the longest song the worker will render; longer
+	// durations almost always come from a corrupt analysis or upload
+	MaxVideoDuration time.Duration
+
+	// PhaseWeights maps each pipeline phase (separation, analysis, lyrics,
+	// images, render, upload) to its relative share of overall job progress
+	PhaseWeights map[string]int
+
+	// WorkerPollInterval is how often the queue worker checks for new work
+	// when it hasn't been woken by an enqueue
+	WorkerPollInterval time.Duration
+}
+
+// DefaultPhaseWeights returns the default share of overall progress per pipeline phase
+func DefaultPhaseWeights() map[string]int {
+	return map[string]int{
+		"separation": 15,
+		"analysis":   20,
+		"lyrics":     10,
+		"images":     20,
+		"render":     40,
+		"upload":     10,
+	}
 }
 
 // LoadConfig loads configuration based on environment
@@ -55,23 +157,200 @@ func LoadConfig() *Config {
 		cfg.StoragePath = dataPath
 	}
 
+	// Resolve the storage root so derived paths don't depend on the working directory
+	if absPath, err := filepath.Abs(cfg.StoragePath); err == nil {
+		cfg.StoragePath = absPath
+	}
+
 	// Derived storage paths
 	cfg.SongsPath = filepath.Join(cfg.StoragePath, "songs")
 	cfg.VideosPath = filepath.Join(cfg.StoragePath, "videos")
 	cfg.TempPath = filepath.Join(cfg.StoragePath, "temp")
 	cfg.LogsPath = filepath.Join(cfg.StoragePath, "logs")
 	cfg.PythonScripts = filepath.Join(cfg.StoragePath, "python-scripts")
+	cfg.BrandingPath = filepath.Join(cfg.StoragePath, "branding")
+
+	// Audio analyzer, e.g. AUDIO_ANALYZER_PYTHON=/opt/trackstudio/.venv/bin/python
+	cfg.AnalyzerPython = os.Getenv("AUDIO_ANALYZER_PYTHON")
+	cfg.AnalyzerScript = os.Getenv("AUDIO_ANALYZER_SCRIPT")
+	cfg.AnalyzerTimeout = getEnvDuration("AUDIO_ANALYZER_TIMEOUT", 5*time.Minute)
+
+	// Stem separation, e.g. STEM_SEPARATOR_MODEL=htdemucs_ft; AUTO_SEPARATE_STEMS=false
+	// leaves mixed-only songs unsplit unless separation is requested
+	cfg.SeparatorPython = os.Getenv("STEM_SEPARATOR_PYTHON")
+	cfg.SeparatorScript = os.Getenv("STEM_SEPARATOR_SCRIPT")
+	cfg.SeparatorModel = os.Getenv("STEM_SEPARATOR_MODEL")
+	cfg.SeparatorTimeout = getEnvDuration("STEM_SEPARATOR_TIMEOUT", 30*time.Minute)
+	cfg.AutoSeparateStems = getEnv("AUTO_SEPARATE_STEMS", "true") == "true"
 
-	// CQAI configuration
-	cfg.CQAIURL = "http://cqai.nlaakstudios"
-	cfg.LLMModel = "qwen2.5:7b"
-	cfg.ImageModel = "z-image-nsfw"
+	// Vocal onset sanity limit, e.g. MAX_VOCAL_ONSET_FRACTION=0.3
+	cfg.MaxVocalOnsetFraction = getEnvFloat("MAX_VOCAL_ONSET_FRACTION", 0.3)
+
+	// CQAI configuration (CQAI_URL is kept as a fallback for the LLM endpoint)
+	cfg.CQAIURL = getEnv("CQAI_IMAGE_URL", "http://cqai.nlaakstudios")
+	cfg.CQAILLMURL = getEnv("CQAI_LLM_URL", getEnv("CQAI_URL", "http://cqai.nlaakstudios:11434"))
+	cfg.LLMModel = getEnv("CQAI_LLM_MODEL", "qwen2.5:7b")
+	cfg.ImageModel = getEnv("CQAI_IMAGE_MODEL", "z-image-nsfw")
+	cfg.VisionModel = getEnv("CQAI_VISION_MODEL", "llama3.2-vision:11b")
+	cfg.ConcurrencyLimit = getEnvInt("CQAI_CONCURRENCY_LIMIT", 2)
+
+	// WhisperX configuration
+	cfg.WhisperXURL = getEnv("WHISPERX_URL", "http://192.168.1.76:8181")
 
 	// Image generation settings (verified working)
 	cfg.ImageWidth = 1920
 	cfg.ImageHeight = 1024
 	cfg.ImageSteps = 25
+	cfg.ImageSimilarityThreshold = 10
+	if threshold, err := strconv.Atoi(os.Getenv("IMAGE_SIMILARITY_THRESHOLD")); err == nil {
+		cfg.ImageSimilarityThreshold = threshold // Zero and negative are meaningful here
+	}
+	cfg.RegenerateSimilarImages = os.Getenv("REGENERATE_SIMILAR_IMAGES") == "true"
+	cfg.FallbackBackgrounds = getEnv("FALLBACK_BACKGROUNDS", "true") == "true"
+
+	// Storage backend (videos/images/audio are mirrored to S3 when "s3")
+	cfg.StorageBackend = getEnv("STORAGE_BACKEND", "local")
+	cfg.S3Endpoint = os.Getenv("S3_ENDPOINT")
+	cfg.S3Bucket = os.Getenv("S3_BUCKET")
+	cfg.S3Region = os.Getenv("S3_REGION")
+	cfg.S3AccessKey = os.Getenv("S3_ACCESS_KEY")
+	cfg.S3SecretKey = os.Getenv("S3_SECRET_KEY")
+	cfg.S3Prefix = os.Getenv("S3_PREFIX")
+	cfg.S3UseSSL = getEnv("S3_USE_SSL", "true") == "true"
+
+	// Rendering settings
+	cfg.StrictDrawtext = os.Getenv("STRICT_DRAWTEXT") == "true"
+	cfg.FontBoldPath = os.Getenv("FONT_BOLD_PATH")
+	cfg.FontRegularPath = os.Getenv("FONT_REGULAR_PATH")
+	cfg.KeepTempFiles = os.Getenv("KEEP_TEMP_FILES") == "true"
+	cfg.DebugRenderSteps = os.Getenv("DEBUG_RENDER_STEPS") == "true"
+	cfg.FFmpegStrict = os.Getenv("FFMPEG_STRICT") == "true"
+	cfg.MaxVideoDuration = getEnvDuration("MAX_VIDEO_DURATION", 15*time.Minute)
+
+	// Progress weighting, e.g. PHASE_WEIGHTS="separation=15,analysis=10,lyrics=5,images=30,render=50,upload=5"
+	cfg.PhaseWeights = parsePhaseWeights(os.Getenv("PHASE_WEIGHTS"))
+
+	// Queue worker polling, e.g. WORKER_POLL_INTERVAL="10s"
+	cfg.WorkerPollInterval = getEnvDuration("WORKER_POLL_INTERVAL", 5*time.Second)
+
+	// CORS, e.g. CORS_ALLOWED_ORIGINS="https://studio.example.com,http://localhost:3000"
+	cfg.CORSAllowedOrigins = getEnvList("CORS_ALLOWED_ORIGINS", "*")
+	cfg.CORSAllowedMethods = getEnvList("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS")
+	cfg.CORSAllowedHeaders = getEnvList("CORS_ALLOWED_HEADERS", "Content-Type,Authorization,Cache-Control,Accept,X-Request-ID,Upload-Offset,Last-Event-ID,X-API-Key")
+	cfg.CORSAllowCredentials = os.Getenv("CORS_ALLOW_CREDENTIALS") == "true"
+
+	// API authentication, e.g. API_AUTH_ENABLED=true API_KEYS="studio-ui:k3y1,ci:k3y2"
+	cfg.APIAuthEnabled = os.Getenv("API_AUTH_ENABLED") == "true"
+	cfg.APIKeys = parseAPIKeys(os.Getenv("API_KEYS"))
+
+	// Rate limiting, e.g. RATE_LIMIT_ENABLED=true RATE_LIMIT_EXPENSIVE=5 (0 leaves a tier unlimited)
+	cfg.RateLimitEnabled = os.Getenv("RATE_LIMIT_ENABLED") == "true"
+	cfg.RateLimitReads = getEnvRateLimit("RATE_LIMIT_READS", 600)
+	cfg.RateLimitWrites = getEnvRateLimit("RATE_LIMIT_WRITES", 120)
+	cfg.RateLimitExpensive = getEnvRateLimit("RATE_LIMIT_EXPENSIVE", 10)
 
 	fmt.Printf("Loaded configuration for environment: %s\n", env)
 	return &cfg
 }
+
+// parsePhaseWeights overrides the default phase weights with "phase=weight" pairs.
+// Unknown phases and invalid weights are ignored.
+func parsePhaseWeights(raw string) map[string]int {
+	weights := DefaultPhaseWeights()
+	for _, pair := range strings.Split(raw, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if _, known := weights[name]; !known || err != nil || weight < 0 {
+			fmt.Printf("Ignoring invalid PHASE_WEIGHTS entry: %q\n", pair)
+			continue
+		}
+		weights[name] = weight
+	}
+	return weights
+}
+
+// parseAPIKeys parses comma-separated "label:key" pairs into a key -> label
+// map. A bare key is labeled by its position. Keys are never printed.
+func parseAPIKeys(raw string) map[string]string {
+	keys := make(map[string]string)
+	for i, pair := range strings.Split(raw, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		label, key, ok := strings.Cut(pair, ":")
+		if !ok {
+			label, key = fmt.Sprintf("key-%d", i+1), pair
+		}
+		label, key = strings.TrimSpace(label), strings.TrimSpace(key)
+		if key == "" {
+			fmt.Printf("Ignoring API_KEYS entry %q with an empty key\n", label)
+			continue
+		}
+		keys[key] = label
+	}
+	return keys
+}
+
+// getEnvInt returns an integer environment variable or a default if unset or invalid
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvRateLimit returns a requests-per-minute limit from the environment,
+// where 0 means unlimited, or a default if unset, negative or invalid
+func getEnvRateLimit(key string, defaultValue int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < 0 {
+		fmt.Printf("Ignoring invalid %s: %q\n", key, raw)
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat returns a positive float environment variable or a default if unset or invalid
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvDuration returns a duration environment variable (e.g. "5s") or a default if unset or invalid
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return defaultValue
+}
+
+// getEnvList returns a comma-separated environment variable (or the default
+// when unset) as a list of trimmed, non-empty values
+func getEnvList(key, defaultValue string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnv returns the value of an environment variable or a default
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -11,12 +11,14 @@ func CreateGeneratedImage(img *models.GeneratedImage) error {
 	query := `
 		INSERT INTO generated_images (
 			song_id, queue_id, image_path, prompt, negative_prompt,
-			image_type, sequence_number, width, height, model
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			image_type, sequence_number, width, height, model, approved, description, seed,
+			parent_image_id, is_variant, phash
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 	result, err := DB.Exec(query,
 		img.SongID, img.QueueID, img.ImagePath, img.Prompt, img.NegativePrompt,
-		img.ImageType, img.SequenceNumber, img.Width, img.Height, img.Model,
+		img.ImageType, img.SequenceNumber, img.Width, img.Height, img.Model, img.Approved, img.Description, img.Seed,
+		img.ParentImageID, img.IsVariant, img.PHash,
 	)
 	if err != nil {
 		return err
@@ -40,16 +42,35 @@ func CreateImagePrompt(img *models.GeneratedImage) (int, error) {
 	return img.ID, nil
 }
 
-// GetImagesBySongID retrieves all images for a song
+// GetImagesBySongID retrieves the active (non-variant) images for a song
 func GetImagesBySongID(songID int) ([]models.GeneratedImage, error) {
-	query := `
+	return queryImages(`
 		SELECT id, song_id, queue_id, image_path, prompt, negative_prompt,
-		       image_type, sequence_number, width, height, model, created_at
+		       image_type, sequence_number, width, height, model,
+		       COALESCE(approved, 0) as approved, description, seed,
+		       parent_image_id, COALESCE(is_variant, 0) as is_variant, phash, created_at
 		FROM generated_images
-		WHERE song_id = ?
+		WHERE song_id = ? AND COALESCE(is_variant, 0) = 0
 		ORDER BY image_type, sequence_number
-	`
-	rows, err := DB.Query(query, songID)
+	`, songID)
+}
+
+// GetImageVariants retrieves the variant candidates generated for an image
+func GetImageVariants(parentID int) ([]models.GeneratedImage, error) {
+	return queryImages(`
+		SELECT id, song_id, queue_id, image_path, prompt, negative_prompt,
+		       image_type, sequence_number, width, height, model,
+		       COALESCE(approved, 0) as approved, description, seed,
+		       parent_image_id, COALESCE(is_variant, 0) as is_variant, phash, created_at
+		FROM generated_images
+		WHERE parent_image_id = ? AND COALESCE(is_variant, 0) = 1
+		ORDER BY id
+	`, parentID)
+}
+
+// queryImages runs a generated_images SELECT and scans the rows
+func queryImages(query string, args ...interface{}) ([]models.GeneratedImage, error) {
+	rows, err := DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -60,7 +81,9 @@ func GetImagesBySongID(songID int) ([]models.GeneratedImage, error) {
 		var img models.GeneratedImage
 		err := rows.Scan(
 			&img.ID, &img.SongID, &img.QueueID, &img.ImagePath, &img.Prompt, &img.NegativePrompt,
-			&img.ImageType, &img.SequenceNumber, &img.Width, &img.Height, &img.Model, &img.CreatedAt,
+			&img.ImageType, &img.SequenceNumber, &img.Width, &img.Height, &img.Model,
+			&img.Approved, &img.Description, &img.Seed,
+			&img.ParentImageID, &img.IsVariant, &img.PHash, &img.CreatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -74,14 +97,18 @@ func GetImagesBySongID(songID int) ([]models.GeneratedImage, error) {
 func GetImageByID(id int) (*models.GeneratedImage, error) {
 	query := `
 		SELECT id, song_id, queue_id, image_path, prompt, negative_prompt,
-		       image_type, sequence_number, width, height, model, created_at
+		       image_type, sequence_number, width, height, model,
+		       COALESCE(approved, 0) as approved, description, seed,
+		       parent_image_id, COALESCE(is_variant, 0) as is_variant, phash, created_at
 		FROM generated_images
 		WHERE id = ?
 	`
 	var img models.GeneratedImage
 	err := DB.QueryRow(query, id).Scan(
 		&img.ID, &img.SongID, &img.QueueID, &img.ImagePath, &img.Prompt, &img.NegativePrompt,
-		&img.ImageType, &img.SequenceNumber, &img.Width, &img.Height, &img.Model, &img.CreatedAt,
+		&img.ImageType, &img.SequenceNumber, &img.Width, &img.Height, &img.Model,
+		&img.Approved, &img.Description, &img.Seed,
+		&img.ParentImageID, &img.IsVariant, &img.PHash, &img.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -103,6 +130,13 @@ func UpdateImagePrompt(id int, prompt, negativePrompt string) error {
 	return err
 }
 
+// UpdateImageSeed sets the seed used to (re)generate an image; nil clears it
+func UpdateImageSeed(id int, seed *int64) error {
+	query := `UPDATE generated_images SET seed = ? WHERE id = ?`
+	_, err := DB.Exec(query, seed, id)
+	return err
+}
+
 // UpdateImagePath updates the image_path for a generated image
 func UpdateImagePath(id int, imagePath string) error {
 	query := `
@@ -114,6 +148,46 @@ func UpdateImagePath(id int, imagePath string) error {
 	return err
 }
 
+// UpdateImageDescription stores the vision-model description for a generated image
+func UpdateImageDescription(id int, description string) error {
+	query := `UPDATE generated_images SET description = ? WHERE id = ?`
+	_, err := DB.Exec(query, description, id)
+	return err
+}
+
+// UpdateImageHash stores the perceptual hash (hex) of an image's current file
+func UpdateImageHash(id int, phash string) error {
+	query := `UPDATE generated_images SET phash = ? WHERE id = ?`
+	_, err := DB.Exec(query, phash, id)
+	return err
+}
+
+// SetImageApproval sets the approved flag for a generated image
+func SetImageApproval(id int, approved bool) error {
+	query := `UPDATE generated_images SET approved = ? WHERE id = ?`
+	_, err := DB.Exec(query, approved, id)
+	return err
+}
+
+// ApproveImagesBySongID approves all images for a song
+func ApproveImagesBySongID(songID int) (int64, error) {
+	query := `UPDATE generated_images SET approved = 1 WHERE song_id = ?`
+	result, err := DB.Exec(query, songID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CountUnapprovedImages returns how many images for a song still need approval
+func CountUnapprovedImages(songID int) (int, error) {
+	query := `SELECT COUNT(*) FROM generated_images
+		WHERE song_id = ? AND COALESCE(approved, 0) = 0 AND COALESCE(is_variant, 0) = 0`
+	var count int
+	err := DB.QueryRow(query, songID).Scan(&count)
+	return count, err
+}
+
 // DeleteImagesBySongID deletes all images for a song
 func DeleteImagesBySongID(songID int) error {
 	query := `DELETE FROM generated_images WHERE song_id = ?`
@@ -121,6 +195,41 @@ func DeleteImagesBySongID(songID int) error {
 	return err
 }
 
+// DeleteImageByID deletes a single image record
+func DeleteImageByID(id int) error {
+	_, err := DB.Exec(`DELETE FROM generated_images WHERE id = ?`, id)
+	return err
+}
+
+// SwapImageVariant promotes a variant to the active image by exchanging the
+// generated content (prompt, negative prompt, seed, description, hash) of the two
+// records. Callers swap the image files so each record keeps its own path.
+// The active image needs approval again afterwards.
+func SwapImageVariant(active, variant *models.GeneratedImage) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE generated_images
+		SET prompt = ?, negative_prompt = ?, seed = ?, description = ?, phash = ?
+		WHERE id = ?
+	`
+	if _, err := tx.Exec(query, variant.Prompt, variant.NegativePrompt, variant.Seed, variant.Description, variant.PHash, active.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(query, active.Prompt, active.NegativePrompt, active.Seed, active.Description, active.PHash, variant.ID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE generated_images SET approved = 0 WHERE id = ?`, active.ID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // DeleteImagesByQueueID deletes all images for a queue item
 func DeleteImagesByQueueID(queueID int) error {
 	query := `DELETE FROM generated_images WHERE queue_id = ?`
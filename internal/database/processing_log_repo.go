@@ -0,0 +1,66 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+)
+
+// ProcessingLogRepository handles the structured per-phase processing logs
+type ProcessingLogRepository struct {
+	db *sql.DB
+}
+
+// NewProcessingLogRepository creates a new processing log repository
+func NewProcessingLogRepository(db *sql.DB) *ProcessingLogRepository {
+	return &ProcessingLogRepository{db: db}
+}
+
+// Create records a processing log entry
+func (r *ProcessingLogRepository) Create(entry *models.ProcessingLog) error {
+	entry.CreatedAt = time.Now().UTC()
+	query := `INSERT INTO processing_logs (queue_id, step, status, message, duration_seconds, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	result, err := r.db.Exec(query, entry.QueueID, entry.Step, entry.Status, entry.Message, entry.DurationSeconds, entry.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	entry.ID = int(id)
+	return nil
+}
+
+// GetByQueueID returns a queue item's processing logs, oldest first
+func (r *ProcessingLogRepository) GetByQueueID(queueID int) ([]models.ProcessingLog, error) {
+	query := `SELECT id, queue_id, step, status,
+		COALESCE(message, '') as message,
+		COALESCE(duration_seconds, 0) as duration_seconds,
+		created_at
+		FROM processing_logs
+		WHERE queue_id = ?
+		ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.db.Query(query, queueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.ProcessingLog{}
+	for rows.Next() {
+		var entry models.ProcessingLog
+		if err := rows.Scan(
+			&entry.ID, &entry.QueueID, &entry.Step, &entry.Status,
+			&entry.Message, &entry.DurationSeconds, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
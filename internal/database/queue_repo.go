@@ -2,6 +2,10 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
 )
@@ -27,7 +31,10 @@ func (r *QueueRepository) GetAll() ([]models.QueueItem, error) {
 		COALESCE(video_file_size, 0) as video_file_size, 
 		COALESCE(thumbnail_path, '') as thumbnail_path,
 		flag,
-		queued_at, started_at, completed_at
+		COALESCE(last_phase, '') as last_phase,
+		COALESCE(draft, 0) as draft,
+		COALESCE(request_id, '') as request_id,
+		queued_at, scheduled_at, started_at, completed_at, last_heartbeat
 		FROM queue ORDER BY priority DESC, queued_at ASC`
 
 	rows, err := r.db.Query(query)
@@ -44,7 +51,10 @@ func (r *QueueRepository) GetAll() ([]models.QueueItem, error) {
 			&item.CurrentStep, &item.Progress, &item.ErrorMessage, &item.RetryCount,
 			&item.VideoFilePath, &item.VideoFileSize, &item.ThumbnailPath,
 			&item.Flag,
-			&item.QueuedAt, &item.StartedAt, &item.CompletedAt,
+			&item.LastPhase,
+			&item.Draft,
+			&item.RequestID,
+			&item.QueuedAt, &item.ScheduledAt, &item.StartedAt, &item.CompletedAt, &item.LastHeartbeat,
 		)
 		if err != nil {
 			return nil, err
@@ -66,7 +76,10 @@ func (r *QueueRepository) GetByID(id int) (*models.QueueItem, error) {
 		COALESCE(video_file_size, 0) as video_file_size, 
 		COALESCE(thumbnail_path, '') as thumbnail_path,
 		flag,
-		queued_at, started_at, completed_at
+		COALESCE(last_phase, '') as last_phase,
+		COALESCE(draft, 0) as draft,
+		COALESCE(request_id, '') as request_id,
+		queued_at, scheduled_at, started_at, completed_at, last_heartbeat
 		FROM queue WHERE id = ?`
 
 	var item models.QueueItem
@@ -75,7 +88,10 @@ func (r *QueueRepository) GetByID(id int) (*models.QueueItem, error) {
 		&item.CurrentStep, &item.Progress, &item.ErrorMessage, &item.RetryCount,
 		&item.VideoFilePath, &item.VideoFileSize, &item.ThumbnailPath,
 		&item.Flag,
-		&item.QueuedAt, &item.StartedAt, &item.CompletedAt,
+		&item.LastPhase,
+		&item.Draft,
+		&item.RequestID,
+		&item.QueuedAt, &item.ScheduledAt, &item.StartedAt, &item.CompletedAt, &item.LastHeartbeat,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -89,10 +105,10 @@ func (r *QueueRepository) GetByID(id int) (*models.QueueItem, error) {
 
 // Create creates a new queue item
 func (r *QueueRepository) Create(item *models.QueueItem) error {
-	query := `INSERT INTO queue (song_id, status, priority)
-		VALUES (?, ?, ?)`
+	query := `INSERT INTO queue (song_id, status, priority, draft, request_id, scheduled_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
 
-	result, err := r.db.Exec(query, item.SongID, item.Status, item.Priority)
+	result, err := r.db.Exec(query, item.SongID, item.Status, item.Priority, item.Draft, item.RequestID, item.ScheduledAt)
 	if err != nil {
 		return err
 	}
@@ -103,34 +119,93 @@ func (r *QueueRepository) Create(item *models.QueueItem) error {
 	}
 
 	item.ID = int(id)
+	if err := r.AddEvent(item, "Queued"); err != nil {
+		log.Printf("Warning: failed to record queue event for item %d: %v", item.ID, err)
+	}
 	return nil
 }
 
-// Update updates an existing queue item
+// Update updates an existing queue item. last_heartbeat is left alone: only
+// Heartbeat writes it, so a stale in-memory item can't roll it back.
 func (r *QueueRepository) Update(item *models.QueueItem) error {
 	query := `UPDATE queue SET status=?, priority=?,
 		current_step=?, progress=?, error_message=?, retry_count=?,
 		video_file_path=?, video_file_size=?, thumbnail_path=?,
-		started_at=?, completed_at=?
+		last_phase=?,
+		scheduled_at=?, started_at=?, completed_at=?
 		WHERE id=?`
 
 	_, err := r.db.Exec(query,
 		item.Status, item.Priority,
 		item.CurrentStep, item.Progress, item.ErrorMessage, item.RetryCount,
 		item.VideoFilePath, item.VideoFileSize, item.ThumbnailPath,
-		item.StartedAt, item.CompletedAt,
+		item.LastPhase,
+		item.ScheduledAt, item.StartedAt, item.CompletedAt,
 		item.ID,
 	)
 	return err
 }
 
-// Delete removes a queue item
+// Delete removes a queue item and its history
 func (r *QueueRepository) Delete(id int) error {
+	if _, err := r.db.Exec("DELETE FROM queue_events WHERE queue_id=?", id); err != nil {
+		return err
+	}
 	_, err := r.db.Exec("DELETE FROM queue WHERE id=?", id)
 	return err
 }
 
-// GetNextPending returns the next pending queue item
+// DeleteByStatus removes every queue item in any of the given statuses in a
+// single transaction and returns the IDs removed
+func (r *QueueRepository) DeleteByStatus(statuses []string) ([]int, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+
+	placeholders := make([]string, len(statuses))
+	args := make([]interface{}, len(statuses))
+	for i, status := range statuses {
+		placeholders[i] = "?"
+		args[i] = status
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	inStatuses := "(" + strings.Join(placeholders, ", ") + ")"
+	rows, err := tx.Query("SELECT id FROM queue WHERE status IN "+inStatuses, args...)
+	if err != nil {
+		return nil, err
+	}
+	var deleted []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		deleted = append(deleted, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM queue_events WHERE queue_id IN (SELECT id FROM queue WHERE status IN "+inStatuses+")", args...); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM queue WHERE status IN "+inStatuses, args...); err != nil {
+		return nil, err
+	}
+
+	return deleted, tx.Commit()
+}
+
+// GetNextPending returns the next pending queue item, skipping items
+// scheduled to start in the future
 func (r *QueueRepository) GetNextPending() (*models.QueueItem, error) {
 	query := `SELECT id, song_id, status, priority,
 		COALESCE(current_step, '') as current_step, 
@@ -141,19 +216,26 @@ func (r *QueueRepository) GetNextPending() (*models.QueueItem, error) {
 		COALESCE(video_file_size, 0) as video_file_size, 
 		COALESCE(thumbnail_path, '') as thumbnail_path,
 		flag,
-		queued_at, started_at, completed_at
+		COALESCE(last_phase, '') as last_phase,
+		COALESCE(draft, 0) as draft,
+		COALESCE(request_id, '') as request_id,
+		queued_at, scheduled_at, started_at, completed_at, last_heartbeat
 		FROM queue 
 		WHERE status = ?
+		AND (scheduled_at IS NULL OR scheduled_at <= ?)
 		ORDER BY priority DESC, queued_at ASC
 		LIMIT 1`
 
 	var item models.QueueItem
-	err := r.db.QueryRow(query, models.StatusQueued).Scan(
+	err := r.db.QueryRow(query, models.StatusQueued, time.Now().UTC()).Scan(
 		&item.ID, &item.SongID, &item.Status, &item.Priority,
 		&item.CurrentStep, &item.Progress, &item.ErrorMessage, &item.RetryCount,
 		&item.VideoFilePath, &item.VideoFileSize, &item.ThumbnailPath,
 		&item.Flag,
-		&item.QueuedAt, &item.StartedAt, &item.CompletedAt,
+		&item.LastPhase,
+		&item.Draft,
+		&item.RequestID,
+		&item.QueuedAt, &item.ScheduledAt, &item.StartedAt, &item.CompletedAt, &item.LastHeartbeat,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -171,3 +253,176 @@ func (r *QueueRepository) UpdateFlag(id int, flag *string) error {
 	_, err := r.db.Exec(query, flag, id)
 	return err
 }
+
+// ResumeAwaitingApproval re-queues any items for a song that are waiting on image approval
+func (r *QueueRepository) ResumeAwaitingApproval(songID int) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	step := "Images approved"
+	events := `INSERT INTO queue_events (queue_id, status, step, message, retry_count, created_at)
+		SELECT id, ?, ?, ?, COALESCE(retry_count, 0), ? FROM queue WHERE song_id = ? AND status = ?`
+	if _, err := tx.Exec(events, models.StatusQueued, step, "Resumed after image approval", time.Now().UTC(), songID, models.StatusAwaitingApproval); err != nil {
+		return 0, err
+	}
+
+	query := `UPDATE queue SET status = ?, current_step = ? WHERE song_id = ? AND status = ?`
+	result, err := tx.Exec(query, models.StatusQueued, step, songID, models.StatusAwaitingApproval)
+	if err != nil {
+		return 0, err
+	}
+	resumed, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return resumed, tx.Commit()
+}
+
+// IsSongProcessing reports whether a queue item for the song is being processed
+func (r *QueueRepository) IsSongProcessing(songID int) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM queue WHERE song_id = ? AND status = ?`, songID, models.StatusProcessing).Scan(&count)
+	return count > 0, err
+}
+
+// Heartbeat records that a processing queue item is still alive
+func (r *QueueRepository) Heartbeat(id int) error {
+	_, err := r.db.Exec(`UPDATE queue SET last_heartbeat = ? WHERE id = ?`, time.Now().UTC(), id)
+	return err
+}
+
+// ReclaimStale recovers processing items whose heartbeat is older than
+// threshold (or that never sent one), e.g. after a crash mid-render. Items
+// are re-queued until they have been reclaimed maxRetries times; after that
+// they are marked failed so a job that keeps crashing the server stops being
+// retried. Returns the re-queued and failed counts.
+func (r *QueueRepository) ReclaimStale(threshold time.Duration, maxRetries int) (int64, int64, error) {
+	now := time.Now().UTC()
+	cutoff := now.Add(-threshold)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	// Items out of retries fail; the rest go back in the queue
+	outcomes := []struct {
+		status, step, message, retries string
+	}{
+		{models.StatusFailed, "Failed after interruption",
+			fmt.Sprintf("Processing was interrupted %d times (stale heartbeat); not retrying", maxRetries),
+			"COALESCE(retry_count, 0) + 1 >= ?"},
+		{models.StatusQueued, "Reclaimed after interruption",
+			"Processing was interrupted (stale heartbeat)",
+			"COALESCE(retry_count, 0) + 1 < ?"},
+	}
+
+	var counts [2]int64
+	for i, o := range outcomes {
+		stale := `WHERE status = ?
+			AND COALESCE(last_heartbeat, started_at, queued_at) < ?
+			AND ` + o.retries
+
+		events := `INSERT INTO queue_events (queue_id, status, step, message, retry_count, created_at)
+			SELECT id, ?, ?, ?, COALESCE(retry_count, 0) + 1, ? FROM queue ` + stale
+		if _, err := tx.Exec(events, o.status, o.step, o.message, now, models.StatusProcessing, cutoff, maxRetries); err != nil {
+			return 0, 0, err
+		}
+
+		var completedAt *time.Time
+		if o.status == models.StatusFailed {
+			completedAt = &now
+		}
+		query := `UPDATE queue
+			SET status = ?, current_step = ?, error_message = ?, retry_count = COALESCE(retry_count, 0) + 1,
+			completed_at = COALESCE(?, completed_at)
+			` + stale
+		result, err := tx.Exec(query,
+			o.status, o.step, o.message, completedAt,
+			models.StatusProcessing, cutoff, maxRetries,
+		)
+		if err != nil {
+			return 0, 0, err
+		}
+		if counts[i], err = result.RowsAffected(); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	failed, requeued := counts[0], counts[1]
+	return requeued, failed, tx.Commit()
+}
+
+// CountByStatus returns the number of queue items for each status
+func (r *QueueRepository) CountByStatus() (map[string]int, error) {
+	rows, err := r.db.Query(`SELECT status, COUNT(*) FROM queue GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+	return counts, rows.Err()
+}
+
+// AddEvent records the item's current status, step and retry count in its
+// history with a message
+func (r *QueueRepository) AddEvent(item *models.QueueItem, message string) error {
+	query := `INSERT INTO queue_events (queue_id, status, step, message, retry_count, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+	_, err := r.db.Exec(query, item.ID, item.Status, item.CurrentStep, message, item.RetryCount, time.Now().UTC())
+	return err
+}
+
+// GetEvents returns a queue item's history, oldest first
+func (r *QueueRepository) GetEvents(queueID int) ([]models.QueueEvent, error) {
+	query := `SELECT id, queue_id, status,
+		COALESCE(step, '') as step,
+		COALESCE(message, '') as message,
+		COALESCE(retry_count, 0) as retry_count,
+		created_at
+		FROM queue_events
+		WHERE queue_id = ?
+		ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.db.Query(query, queueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.QueueEvent{}
+	for rows.Next() {
+		var event models.QueueEvent
+		if err := rows.Scan(
+			&event.ID, &event.QueueID, &event.Status,
+			&event.Step, &event.Message, &event.RetryCount,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Each event lasts until the next one
+	for i := 0; i+1 < len(events); i++ {
+		duration := events[i+1].CreatedAt.Sub(events[i].CreatedAt).Seconds()
+		events[i].DurationSeconds = &duration
+	}
+	return events, nil
+}
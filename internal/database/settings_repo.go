@@ -2,6 +2,8 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -22,18 +24,56 @@ func NewSettingsRepository(db *sql.DB) *SettingsRepository {
 // Get retrieves the application settings (always ID = 1)
 func (r *SettingsRepository) Get() (*models.Settings, error) {
 	query := `
-		SELECT id, master_prompt, master_negative_prompt, brand_logo_path, data_storage_path, created_at, updated_at
+		SELECT id, master_prompt, master_negative_prompt, brand_logo_path, data_storage_path,
+		       COALESCE(video_filename_template, '{title}') as video_filename_template,
+		       COALESCE(default_quality, 'standard') as default_quality,
+		       COALESCE(youtube_description_template, '') as youtube_description_template,
+		       COALESCE(overlay_font, '') as overlay_font,
+		       COALESCE(logo_size, 0) as logo_size,
+		       COALESCE(logo_opacity, 0) as logo_opacity,
+		       COALESCE(logo_corner, '') as logo_corner,
+		       COALESCE(logo_margin, 0) as logo_margin,
+		       COALESCE(image_prompt_template, '') as image_prompt_template,
+		       COALESCE(image_prompt_section_templates, '') as image_prompt_section_templates,
+		       COALESCE(allowed_genres, '') as allowed_genres,
+		       COALESCE(auto_apply_enrichment, 0) as auto_apply_enrichment,
+		       COALESCE(default_background_style, '') as default_background_style,
+		       COALESCE(default_spectrum_color, '') as default_spectrum_color,
+		       COALESCE(default_spectrum_opacity, 0) as default_spectrum_opacity,
+		       COALESCE(default_target_resolution, '') as default_target_resolution,
+		       COALESCE(default_lyric_theme, '') as default_lyric_theme,
+		       COALESCE(default_copyright_text, '') as default_copyright_text,
+		       created_at, updated_at
 		FROM settings
 		WHERE id = 1
 	`
 
 	var settings models.Settings
+	var sectionTemplates, allowedGenres string
 	err := r.db.QueryRow(query).Scan(
 		&settings.ID,
 		&settings.MasterPrompt,
 		&settings.MasterNegativePrompt,
 		&settings.BrandLogoPath,
 		&settings.DataStoragePath,
+		&settings.VideoFilenameTemplate,
+		&settings.DefaultQuality,
+		&settings.YouTubeDescriptionTemplate,
+		&settings.OverlayFont,
+		&settings.LogoSize,
+		&settings.LogoOpacity,
+		&settings.LogoCorner,
+		&settings.LogoMargin,
+		&settings.ImagePromptTemplate,
+		&sectionTemplates,
+		&allowedGenres,
+		&settings.AutoApplyEnrichment,
+		&settings.DefaultBackgroundStyle,
+		&settings.DefaultSpectrumColor,
+		&settings.DefaultSpectrumOpacity,
+		&settings.DefaultTargetResolution,
+		&settings.DefaultLyricTheme,
+		&settings.DefaultCopyrightText,
 		&settings.CreatedAt,
 		&settings.UpdatedAt,
 	)
@@ -47,6 +87,17 @@ func (r *SettingsRepository) Get() (*models.Settings, error) {
 		return nil, err
 	}
 
+	if sectionTemplates != "" {
+		if err := json.Unmarshal([]byte(sectionTemplates), &settings.ImagePromptSectionTemplates); err != nil {
+			return nil, fmt.Errorf("invalid image prompt section templates: %w", err)
+		}
+	}
+	if allowedGenres != "" {
+		if err := json.Unmarshal([]byte(allowedGenres), &settings.AllowedGenres); err != nil {
+			return nil, fmt.Errorf("invalid allowed genres: %w", err)
+		}
+	}
+
 	return &settings, nil
 }
 
@@ -61,12 +112,48 @@ func (r *SettingsRepository) Update(settings *models.Settings) error {
 		}
 	}
 
+	sectionTemplates := ""
+	if len(settings.ImagePromptSectionTemplates) > 0 {
+		data, err := json.Marshal(settings.ImagePromptSectionTemplates)
+		if err != nil {
+			return err
+		}
+		sectionTemplates = string(data)
+	}
+
+	allowedGenres := ""
+	if genres := models.NormalizeGenres(settings.AllowedGenres); len(genres) > 0 {
+		data, err := json.Marshal(genres)
+		if err != nil {
+			return err
+		}
+		allowedGenres = string(data)
+	}
+
 	query := `
 		UPDATE settings
 		SET master_prompt = ?,
 		    master_negative_prompt = ?,
 		    brand_logo_path = ?,
 		    data_storage_path = ?,
+		    video_filename_template = ?,
+		    default_quality = ?,
+		    youtube_description_template = ?,
+		    overlay_font = ?,
+		    logo_size = ?,
+		    logo_opacity = ?,
+		    logo_corner = ?,
+		    logo_margin = ?,
+		    image_prompt_template = ?,
+		    image_prompt_section_templates = ?,
+		    allowed_genres = ?,
+		    auto_apply_enrichment = ?,
+		    default_background_style = ?,
+		    default_spectrum_color = ?,
+		    default_spectrum_opacity = ?,
+		    default_target_resolution = ?,
+		    default_lyric_theme = ?,
+		    default_copyright_text = ?,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = 1
 	`
@@ -76,6 +163,24 @@ func (r *SettingsRepository) Update(settings *models.Settings) error {
 		settings.MasterNegativePrompt,
 		settings.BrandLogoPath,
 		dataPath,
+		settings.VideoFilenameTemplate,
+		settings.DefaultQuality,
+		settings.YouTubeDescriptionTemplate,
+		settings.OverlayFont,
+		settings.LogoSize,
+		settings.LogoOpacity,
+		settings.LogoCorner,
+		settings.LogoMargin,
+		settings.ImagePromptTemplate,
+		sectionTemplates,
+		allowedGenres,
+		settings.AutoApplyEnrichment,
+		settings.DefaultBackgroundStyle,
+		settings.DefaultSpectrumColor,
+		settings.DefaultSpectrumOpacity,
+		settings.DefaultTargetResolution,
+		settings.DefaultLyricTheme,
+		settings.DefaultCopyrightText,
 	)
 
 	return err
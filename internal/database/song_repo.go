@@ -3,6 +3,8 @@ package database
 import (
 	"database/sql"
 	"encoding/json"
+	"fmt"
+	"log"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
 )
@@ -17,9 +19,9 @@ func NewSongRepository(db *sql.DB) *SongRepository {
 	return &SongRepository{db: db}
 }
 
-// GetAll returns all songs
-func (r *SongRepository) GetAll() ([]models.Song, error) {
-	query := `SELECT id, album_id, title, artist_name, genre, 
+// songColumns is the column list shared by all song SELECT queries.
+// The order must match the Scan order in scanSong.
+const songColumns = `id, album_id, title, artist_name, genre,
 		vocals_stem_path, music_stem_path, 
 		COALESCE(mixed_audio_path, '') as mixed_audio_path, 
 		COALESCE(metadata_file_path, '') as metadata_file_path,
@@ -33,8 +35,9 @@ func (r *SongRepository) GetAll() ([]models.Song, error) {
 		COALESCE(tempo, '') as tempo, 
 		COALESCE(duration_seconds, 0) as duration_seconds, 
 		COALESCE(vocal_timing, '') as vocal_timing,
+		COALESCE(beat_times, '') as beat_times,
 		COALESCE(brand_logo_path, '') as brand_logo_path, 
-		COALESCE(copyright_text, '') as copyright_text,
+		copyright_text,
 		COALESCE(background_style, 'cinematic') as background_style, 
 		COALESCE(spectrum_color, 'rainbow') as spectrum_color, 
 		COALESCE(spectrum_opacity, 0.25) as spectrum_opacity, 
@@ -58,7 +61,119 @@ func (r *SongRepository) GetAll() ([]models.Song, error) {
 		COALESCE(target_audience, '') as target_audience,
 		COALESCE(energy_level, '') as energy_level,
 		COALESCE(vocal_style, '') as vocal_style,
-		created_at, updated_at
+		COALESCE(require_image_approval, 0) as require_image_approval,
+		COALESCE(instrumental, 0) as instrumental,
+		COALESCE(karaoke_whisper_model, 'base') as karaoke_whisper_model,
+		COALESCE(language, 'en') as language,
+		COALESCE(lyric_theme, 'scroll') as lyric_theme,
+		COALESCE(lyric_render_mode, 'drawtext') as lyric_render_mode,
+		COALESCE(soft_subtitles, 0) as soft_subtitles,
+		COALESCE(soft_subtitles_only, 0) as soft_subtitles_only,
+		COALESCE(quality, '') as quality,
+		COALESCE(master_prompt_override, '') as master_prompt_override,
+		COALESCE(master_negative_override, '') as master_negative_override,
+		COALESCE(hide_countdown, 0) as hide_countdown,
+		COALESCE(countdown_threshold, 0) as countdown_threshold,
+		COALESCE(countdown_bar_width, 0) as countdown_bar_width,
+		COALESCE(countdown_color, '') as countdown_color,
+		COALESCE(countdown_text, '') as countdown_text,
+		COALESCE(enable_ken_burns, 0) as enable_ken_burns,
+		COALESCE(ken_burns_zoom_rate, 0) as ken_burns_zoom_rate,
+		COALESCE(ken_burns_direction, '') as ken_burns_direction,
+		COALESCE(manual_timing, 0) as manual_timing,
+		COALESCE(karaoke_timing_offset, 0) as karaoke_timing_offset,
+		vocal_onset_override,
+		COALESCE(image_fit, 'letterbox') as image_fit,
+		COALESCE(output_variants, '') as output_variants,
+		COALESCE(reference_image_path, '') as reference_image_path,
+		COALESCE(reference_strength, 0) as reference_strength,
+		COALESCE(outro_card_path, '') as outro_card_path,
+		COALESCE(outro_duration, 0) as outro_duration,
+		COALESCE(intro_card_enabled, 0) as intro_card_enabled,
+		COALESCE(intro_duration, 0) as intro_duration,
+		COALESCE(intro_background_color, '') as intro_background_color,
+		COALESCE(intro_text_color, '') as intro_text_color,
+		COALESCE(spectrum_position, '') as spectrum_position,
+		COALESCE(spectrum_height, 0) as spectrum_height,
+		COALESCE(genre_source, '') as genre_source,
+		COALESCE(audio_fade_in, 0) as audio_fade_in,
+		COALESCE(audio_fade_out, 0) as audio_fade_out,
+		COALESCE(video_fade_in, 0) as video_fade_in,
+		COALESCE(video_fade_out, 0) as video_fade_out,
+		created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSong scans a row selected with songColumns into a song
+func scanSong(row rowScanner, s *models.Song) error {
+	var outputVariants string
+	err := row.Scan(
+		&s.ID, &s.AlbumID, &s.Title, &s.ArtistName, &s.Genre,
+		&s.VocalsStemPath, &s.MusicStemPath, &s.MixedAudioPath, &s.MetadataPath,
+		&s.Lyrics, &s.LyricsKaraoke, &s.LyricsDisplay, &s.LyricsSections, &s.WhisperEngine,
+		&s.BPM, &s.Key, &s.Tempo, &s.DurationSeconds, &s.VocalTiming, &s.BeatTimes,
+		&s.BrandLogoPath, &s.CopyrightText,
+		&s.BackgroundStyle, &s.SpectrumColor, &s.SpectrumOpacity, &s.TargetResolution,
+		&s.KaraokeFontFamily, &s.KaraokeFontSize, &s.KaraokePrimaryColor, &s.KaraokePrimaryBorderColor,
+		&s.KaraokeHighlightColor, &s.KaraokeHighlightBorderColor, &s.KaraokeAlignment, &s.KaraokeMarginBottom,
+		&s.GenrePrimary, &s.GenreSecondary, &s.Tags, &s.StyleDescriptors, &s.Mood, &s.Themes,
+		&s.SimilarArtists, &s.Summary, &s.TargetAudience, &s.EnergyLevel, &s.VocalStyle,
+		&s.RequireImageApproval,
+		&s.Instrumental,
+		&s.KaraokeWhisperModel,
+		&s.Language,
+		&s.LyricTheme,
+		&s.LyricRenderMode,
+		&s.EmbedSoftSubtitles,
+		&s.SoftSubtitlesOnly,
+		&s.Quality,
+		&s.MasterPromptOverride, &s.MasterNegativeOverride,
+		&s.HideCountdown, &s.CountdownThreshold, &s.CountdownBarWidth, &s.CountdownColor, &s.CountdownText,
+		&s.EnableKenBurns, &s.KenBurnsZoomRate, &s.KenBurnsDirection,
+		&s.ManualTiming,
+		&s.KaraokeTimingOffset,
+		&s.VocalOnsetOverride,
+		&s.ImageFit,
+		&outputVariants,
+		&s.ReferenceImagePath, &s.ReferenceStrength,
+		&s.OutroCardPath, &s.OutroDuration,
+		&s.IntroCardEnabled, &s.IntroDuration, &s.IntroBackgroundColor, &s.IntroTextColor,
+		&s.SpectrumPosition, &s.SpectrumHeight,
+		&s.GenreSource,
+		&s.AudioFadeIn, &s.AudioFadeOut, &s.VideoFadeIn, &s.VideoFadeOut,
+		&s.CreatedAt, &s.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if outputVariants != "" {
+		if err := json.Unmarshal([]byte(outputVariants), &s.OutputVariants); err != nil {
+			return fmt.Errorf("invalid output variants for song %d: %w", s.ID, err)
+		}
+	}
+	return nil
+}
+
+// encodeOutputVariants stores a song's output variants as a JSON array, or
+// NULL when there are none
+func encodeOutputVariants(variants []string) (interface{}, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(variants)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// GetAll returns all songs
+func (r *SongRepository) GetAll() ([]models.Song, error) {
+	query := `SELECT ` + songColumns + `
 		FROM songs ORDER BY created_at DESC`
 
 	rows, err := r.db.Query(query)
@@ -70,20 +185,7 @@ func (r *SongRepository) GetAll() ([]models.Song, error) {
 	var songs []models.Song
 	for rows.Next() {
 		var s models.Song
-		err := rows.Scan(
-			&s.ID, &s.AlbumID, &s.Title, &s.ArtistName, &s.Genre,
-			&s.VocalsStemPath, &s.MusicStemPath, &s.MixedAudioPath, &s.MetadataPath,
-			&s.Lyrics, &s.LyricsKaraoke, &s.LyricsDisplay, &s.LyricsSections, &s.WhisperEngine,
-			&s.BPM, &s.Key, &s.Tempo, &s.DurationSeconds, &s.VocalTiming,
-			&s.BrandLogoPath, &s.CopyrightText,
-			&s.BackgroundStyle, &s.SpectrumColor, &s.SpectrumOpacity, &s.TargetResolution,
-			&s.KaraokeFontFamily, &s.KaraokeFontSize, &s.KaraokePrimaryColor, &s.KaraokePrimaryBorderColor,
-			&s.KaraokeHighlightColor, &s.KaraokeHighlightBorderColor, &s.KaraokeAlignment, &s.KaraokeMarginBottom,
-			&s.GenrePrimary, &s.GenreSecondary, &s.Tags, &s.StyleDescriptors, &s.Mood, &s.Themes,
-			&s.SimilarArtists, &s.Summary, &s.TargetAudience, &s.EnergyLevel, &s.VocalStyle,
-			&s.CreatedAt, &s.UpdatedAt,
-		)
-		if err != nil {
+		if err := scanSong(rows, &s); err != nil {
 			return nil, err
 		}
 		songs = append(songs, s)
@@ -94,62 +196,11 @@ func (r *SongRepository) GetAll() ([]models.Song, error) {
 
 // GetByID returns a song by ID
 func (r *SongRepository) GetByID(id int) (*models.Song, error) {
-	query := `SELECT id, album_id, title, artist_name, genre,
-		vocals_stem_path, music_stem_path, 
-		COALESCE(mixed_audio_path, '') as mixed_audio_path, 
-		COALESCE(metadata_file_path, '') as metadata_file_path,
-		lyrics, 
-		COALESCE(lyrics_karaoke, '') as lyrics_karaoke,
-		COALESCE(lyrics_display, '') as lyrics_display, 
-		COALESCE(lyrics_sections, '') as lyrics_sections,
-		COALESCE(whisper_engine, '') as whisper_engine,
-		COALESCE(bpm, 0) as bpm, 
-		COALESCE(key, '') as key, 
-		COALESCE(tempo, '') as tempo, 
-		COALESCE(duration_seconds, 0) as duration_seconds, 
-		COALESCE(vocal_timing, '') as vocal_timing,
-		COALESCE(brand_logo_path, '') as brand_logo_path, 
-		COALESCE(copyright_text, '') as copyright_text,
-		COALESCE(background_style, 'cinematic') as background_style, 
-		COALESCE(spectrum_color, 'rainbow') as spectrum_color, 
-		COALESCE(spectrum_opacity, 0.25) as spectrum_opacity, 
-		COALESCE(target_resolution, '4k') as target_resolution,
-		COALESCE(karaoke_font_family, 'Arial') as karaoke_font_family,
-		COALESCE(karaoke_font_size, 96) as karaoke_font_size,
-		COALESCE(karaoke_primary_color, '4169E1') as karaoke_primary_color,
-		COALESCE(karaoke_primary_border_color, 'FFFFFF') as karaoke_primary_border_color,
-		COALESCE(karaoke_highlight_color, 'FFD700') as karaoke_highlight_color,
-		COALESCE(karaoke_highlight_border_color, 'FFFFFF') as karaoke_highlight_border_color,
-		COALESCE(karaoke_alignment, 5) as karaoke_alignment,
-		COALESCE(karaoke_margin_bottom, 0) as karaoke_margin_bottom,
-		COALESCE(genre_primary, '') as genre_primary,
-		COALESCE(genre_secondary, '') as genre_secondary,
-		COALESCE(tags, '') as tags,
-		COALESCE(style_descriptors, '') as style_descriptors,
-		COALESCE(mood, '') as mood,
-		COALESCE(themes, '') as themes,
-		COALESCE(similar_artists, '') as similar_artists,
-		COALESCE(summary, '') as summary,
-		COALESCE(target_audience, '') as target_audience,
-		COALESCE(energy_level, '') as energy_level,
-		COALESCE(vocal_style, '') as vocal_style,
-		created_at, updated_at
+	query := `SELECT ` + songColumns + `
 		FROM songs WHERE id = ?`
 
 	var s models.Song
-	err := r.db.QueryRow(query, id).Scan(
-		&s.ID, &s.AlbumID, &s.Title, &s.ArtistName, &s.Genre,
-		&s.VocalsStemPath, &s.MusicStemPath, &s.MixedAudioPath, &s.MetadataPath,
-		&s.Lyrics, &s.LyricsKaraoke, &s.LyricsDisplay, &s.LyricsSections, &s.WhisperEngine,
-		&s.BPM, &s.Key, &s.Tempo, &s.DurationSeconds, &s.VocalTiming,
-		&s.BrandLogoPath, &s.CopyrightText,
-		&s.BackgroundStyle, &s.SpectrumColor, &s.SpectrumOpacity, &s.TargetResolution,
-		&s.KaraokeFontFamily, &s.KaraokeFontSize, &s.KaraokePrimaryColor, &s.KaraokePrimaryBorderColor,
-		&s.KaraokeHighlightColor, &s.KaraokeHighlightBorderColor, &s.KaraokeAlignment, &s.KaraokeMarginBottom,
-		&s.GenrePrimary, &s.GenreSecondary, &s.Tags, &s.StyleDescriptors, &s.Mood, &s.Themes,
-		&s.SimilarArtists, &s.Summary, &s.TargetAudience, &s.EnergyLevel, &s.VocalStyle,
-		&s.CreatedAt, &s.UpdatedAt,
-	)
+	err := scanSong(r.db.QueryRow(query, id), &s)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -162,25 +213,76 @@ func (r *SongRepository) GetByID(id int) (*models.Song, error) {
 
 // Create creates a new song
 func (r *SongRepository) Create(song *models.Song) error {
+	// Unset render preferences take the house style from settings
+	if settings, err := NewSettingsRepository(r.db).Get(); err != nil {
+		log.Printf("Warning: failed to load settings for song defaults: %v", err)
+	} else {
+		settings.ApplySongDefaults(song)
+	}
+
 	query := `INSERT INTO songs (album_id, title, artist_name, genre,
 		vocals_stem_path, music_stem_path, mixed_audio_path, metadata_file_path,
 		lyrics, lyrics_karaoke, lyrics_display, lyrics_sections, whisper_engine,
-		bpm, key, tempo, duration_seconds, vocal_timing,
+		bpm, key, tempo, duration_seconds, vocal_timing, beat_times,
 		brand_logo_path, copyright_text,
 		background_style, spectrum_color, spectrum_opacity, target_resolution,
 		karaoke_font_family, karaoke_font_size, karaoke_primary_color, karaoke_primary_border_color,
-		karaoke_highlight_color, karaoke_highlight_border_color, karaoke_alignment, karaoke_margin_bottom)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+		karaoke_highlight_color, karaoke_highlight_border_color, karaoke_alignment, karaoke_margin_bottom,
+		require_image_approval,
+		instrumental,
+		karaoke_whisper_model,
+		language,
+		lyric_theme,
+		lyric_render_mode,
+		soft_subtitles,
+		soft_subtitles_only,
+		quality,
+		master_prompt_override, master_negative_override,
+		hide_countdown, countdown_threshold, countdown_bar_width, countdown_color, countdown_text,
+		enable_ken_burns, ken_burns_zoom_rate, ken_burns_direction,
+		manual_timing, karaoke_timing_offset, vocal_onset_override,
+		image_fit, output_variants,
+		reference_image_path, reference_strength,
+		outro_card_path, outro_duration,
+		intro_card_enabled, intro_duration, intro_background_color, intro_text_color,
+		spectrum_position, spectrum_height,
+		genre_source, audio_fade_in, audio_fade_out, video_fade_in, video_fade_out)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	outputVariants, err := encodeOutputVariants(song.OutputVariants)
+	if err != nil {
+		return err
+	}
 
 	result, err := r.db.Exec(query,
 		song.AlbumID, song.Title, song.ArtistName, song.Genre,
 		song.VocalsStemPath, song.MusicStemPath, song.MixedAudioPath, song.MetadataPath,
-		song.Lyrics, song.LyricsKaraoke, song.LyricsDisplay, song.LyricsSections,
-		song.BPM, song.Key, song.Tempo, song.DurationSeconds, song.VocalTiming,
+		song.Lyrics, song.LyricsKaraoke, song.LyricsDisplay, song.LyricsSections, song.WhisperEngine,
+		song.BPM, song.Key, song.Tempo, song.DurationSeconds, song.VocalTiming, song.BeatTimes,
 		song.BrandLogoPath, song.CopyrightText,
 		song.BackgroundStyle, song.SpectrumColor, song.SpectrumOpacity, song.TargetResolution,
 		song.KaraokeFontFamily, song.KaraokeFontSize, song.KaraokePrimaryColor, song.KaraokePrimaryBorderColor,
 		song.KaraokeHighlightColor, song.KaraokeHighlightBorderColor, song.KaraokeAlignment, song.KaraokeMarginBottom,
+		song.RequireImageApproval,
+		song.Instrumental,
+		song.KaraokeWhisperModel,
+		song.Language,
+		song.LyricTheme,
+		song.LyricRenderMode,
+		song.EmbedSoftSubtitles,
+		song.SoftSubtitlesOnly,
+		song.Quality,
+		song.MasterPromptOverride, song.MasterNegativeOverride,
+		song.HideCountdown, song.CountdownThreshold, song.CountdownBarWidth, song.CountdownColor, song.CountdownText,
+		song.EnableKenBurns, song.KenBurnsZoomRate, song.KenBurnsDirection,
+		song.ManualTiming, song.KaraokeTimingOffset, song.VocalOnsetOverride,
+		song.ImageFit, outputVariants,
+		song.ReferenceImagePath, song.ReferenceStrength,
+		song.OutroCardPath, song.OutroDuration,
+		song.IntroCardEnabled, song.IntroDuration, song.IntroBackgroundColor, song.IntroTextColor,
+		song.SpectrumPosition, song.SpectrumHeight,
+		song.GenreSource,
+		song.AudioFadeIn, song.AudioFadeOut, song.VideoFadeIn, song.VideoFadeOut,
 	)
 	if err != nil {
 		return err
@@ -200,35 +302,119 @@ func (r *SongRepository) Update(song *models.Song) error {
 	query := `UPDATE songs SET album_id=?, title=?, artist_name=?, genre=?,
 		vocals_stem_path=?, music_stem_path=?, mixed_audio_path=?, metadata_file_path=?,
 		lyrics=?, lyrics_karaoke=?, lyrics_display=?, lyrics_sections=?, whisper_engine=?,
-		bpm=?, key=?, tempo=?, duration_seconds=?, vocal_timing=?,
+		bpm=?, key=?, tempo=?, duration_seconds=?, vocal_timing=?, beat_times=?,
 		brand_logo_path=?, copyright_text=?,
 		background_style=?, spectrum_color=?, spectrum_opacity=?, target_resolution=?,
 		karaoke_font_family=?, karaoke_font_size=?, karaoke_primary_color=?, karaoke_primary_border_color=?,
 		karaoke_highlight_color=?, karaoke_highlight_border_color=?, karaoke_alignment=?, karaoke_margin_bottom=?,
+		require_image_approval=?,
+		instrumental=?,
+		karaoke_whisper_model=?,
+		language=?,
+		lyric_theme=?,
+		lyric_render_mode=?,
+		soft_subtitles=?,
+		soft_subtitles_only=?,
+		quality=?,
+		master_prompt_override=?, master_negative_override=?,
+		hide_countdown=?, countdown_threshold=?, countdown_bar_width=?, countdown_color=?, countdown_text=?,
+		enable_ken_burns=?, ken_burns_zoom_rate=?, ken_burns_direction=?,
+		manual_timing=?, karaoke_timing_offset=?, vocal_onset_override=?,
+		image_fit=?, output_variants=?,
+		reference_image_path=?, reference_strength=?,
+		outro_card_path=?, outro_duration=?,
+		intro_card_enabled=?, intro_duration=?, intro_background_color=?, intro_text_color=?,
+		spectrum_position=?, spectrum_height=?,
+		genre_source=?, audio_fade_in=?, audio_fade_out=?, video_fade_in=?, video_fade_out=?,
 		updated_at=CURRENT_TIMESTAMP
 		WHERE id=?`
 
-	_, err := r.db.Exec(query,
+	outputVariants, err := encodeOutputVariants(song.OutputVariants)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(query,
 		song.AlbumID, song.Title, song.ArtistName, song.Genre,
 		song.VocalsStemPath, song.MusicStemPath, song.MixedAudioPath, song.MetadataPath,
 		song.Lyrics, song.LyricsKaraoke, song.LyricsDisplay, song.LyricsSections, song.WhisperEngine,
-		song.BPM, song.Key, song.Tempo, song.DurationSeconds, song.VocalTiming,
+		song.BPM, song.Key, song.Tempo, song.DurationSeconds, song.VocalTiming, song.BeatTimes,
 		song.BrandLogoPath, song.CopyrightText,
 		song.BackgroundStyle, song.SpectrumColor, song.SpectrumOpacity, song.TargetResolution,
 		song.KaraokeFontFamily, song.KaraokeFontSize, song.KaraokePrimaryColor, song.KaraokePrimaryBorderColor,
 		song.KaraokeHighlightColor, song.KaraokeHighlightBorderColor, song.KaraokeAlignment, song.KaraokeMarginBottom,
+		song.RequireImageApproval,
+		song.Instrumental,
+		song.KaraokeWhisperModel,
+		song.Language,
+		song.LyricTheme,
+		song.LyricRenderMode,
+		song.EmbedSoftSubtitles,
+		song.SoftSubtitlesOnly,
+		song.Quality,
+		song.MasterPromptOverride, song.MasterNegativeOverride,
+		song.HideCountdown, song.CountdownThreshold, song.CountdownBarWidth, song.CountdownColor, song.CountdownText,
+		song.EnableKenBurns, song.KenBurnsZoomRate, song.KenBurnsDirection,
+		song.ManualTiming, song.KaraokeTimingOffset, song.VocalOnsetOverride,
+		song.ImageFit, outputVariants,
+		song.ReferenceImagePath, song.ReferenceStrength,
+		song.OutroCardPath, song.OutroDuration,
+		song.IntroCardEnabled, song.IntroDuration, song.IntroBackgroundColor, song.IntroTextColor,
+		song.SpectrumPosition, song.SpectrumHeight,
+		song.GenreSource,
+		song.AudioFadeIn, song.AudioFadeOut, song.VideoFadeIn, song.VideoFadeOut,
 		song.ID,
 	)
 	return err
 }
 
+// GetAllIDs returns the set of existing song IDs
+func (r *SongRepository) GetAllIDs() (map[int]bool, error) {
+	rows, err := r.db.Query("SELECT id FROM songs")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
 // Delete deletes a song
 func (r *SongRepository) Delete(id int) error {
 	_, err := r.db.Exec("DELETE FROM songs WHERE id=?", id)
 	return err
 }
 
-// UpdateMetadataEnrichment updates only the AI-generated metadata fields
+// ResetProcessing clears the fields computed by audio analysis and lyrics
+// processing so the next render recomputes them. A genre that analysis
+// detected or the LLM inferred goes too; one the user entered is kept.
+func (r *SongRepository) ResetProcessing(id int) error {
+	query := `UPDATE songs SET
+		bpm=NULL, key=NULL, tempo=NULL, duration_seconds=NULL,
+		vocal_timing=NULL, beat_times=NULL,
+		lyrics_display=NULL, lyrics_sections=NULL, whisper_engine=NULL,
+		genre=CASE WHEN genre_source IN (?, ?) THEN '' ELSE genre END,
+		genre_source=CASE WHEN genre_source IN (?, ?) THEN NULL ELSE genre_source END,
+		updated_at=CURRENT_TIMESTAMP
+		WHERE id=?`
+	_, err := r.db.Exec(query,
+		models.GenreSourceAnalysis, models.GenreSourceAI,
+		models.GenreSourceAnalysis, models.GenreSourceAI,
+		id,
+	)
+	return err
+}
+
+// UpdateMetadataEnrichment updates only the AI-generated metadata fields and
+// clears any proposal awaiting review
 func (r *SongRepository) UpdateMetadataEnrichment(songID int, enrichment *models.SongMetadataEnrichment) error {
 	// Convert arrays to JSON strings
 	genreSecondary, _ := json.Marshal(enrichment.GenreSecondary)
@@ -251,7 +437,8 @@ func (r *SongRepository) UpdateMetadataEnrichment(songID int, enrichment *models
 		energy_level=?,
 		vocal_style=?,
 		metadata_enriched_at=CURRENT_TIMESTAMP,
-		metadata_version=1
+		metadata_version=1,
+		pending_enrichment=''
 		WHERE id=?`
 
 	_, err := r.db.Exec(query,
@@ -270,3 +457,55 @@ func (r *SongRepository) UpdateMetadataEnrichment(songID int, enrichment *models
 	)
 	return err
 }
+
+// GetAlbumTitle returns the title of an album, or "" if it doesn't exist
+func (r *SongRepository) GetAlbumTitle(albumID int) (string, error) {
+	var title string
+	err := r.db.QueryRow("SELECT title FROM albums WHERE id = ?", albumID).Scan(&title)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return title, err
+}
+
+// GetAlbumCoverArt returns an album's cover art path, or "" if it has none
+func (r *SongRepository) GetAlbumCoverArt(albumID int) (string, error) {
+	var path sql.NullString
+	err := r.db.QueryRow("SELECT cover_art_path FROM albums WHERE id = ?", albumID).Scan(&path)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return path.String, err
+}
+
+// SetPendingEnrichment stores proposed AI metadata for review without
+// touching the song's current metadata
+func (r *SongRepository) SetPendingEnrichment(songID int, enrichment *models.SongMetadataEnrichment) error {
+	data, err := json.Marshal(enrichment)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec("UPDATE songs SET pending_enrichment=? WHERE id=?", string(data), songID)
+	return err
+}
+
+// GetPendingEnrichment returns the proposed AI metadata awaiting review, or nil if none
+func (r *SongRepository) GetPendingEnrichment(songID int) (*models.SongMetadataEnrichment, error) {
+	var data string
+	err := r.db.QueryRow("SELECT COALESCE(pending_enrichment, '') FROM songs WHERE id=?", songID).Scan(&data)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if data == "" {
+		return nil, nil
+	}
+
+	var enrichment models.SongMetadataEnrichment
+	if err := json.Unmarshal([]byte(data), &enrichment); err != nil {
+		return nil, err
+	}
+	return &enrichment, nil
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Time-series metrics computed from the queue table
+const (
+	MetricCompleted     = "completed"       // Jobs completed per bucket
+	MetricFailed        = "failed"          // Jobs failed per bucket
+	MetricFailureRate   = "failure_rate"    // Failed / (completed + failed), 0-1
+	MetricAvgRenderTime = "avg_render_time" // Mean seconds from start to completion
+	MetricQueued        = "queued"          // Jobs submitted per bucket
+)
+
+// Time-series bucket sizes
+const (
+	BucketHour  = "hour"
+	BucketDay   = "day"
+	BucketWeek  = "week"
+	BucketMonth = "month"
+)
+
+// TimeSeriesPoint is one bucket of a time series
+type TimeSeriesPoint struct {
+	Bucket string  `json:"bucket"` // Bucket start, formatted per bucket size
+	Value  float64 `json:"value"`
+	Count  int     `json:"count"` // Jobs that contributed to the value
+}
+
+type StatsRepository struct {
+	db *sql.DB
+}
+
+func NewStatsRepository(db *sql.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// bucketSQL returns the SQLite expression grouping column into a bucket label
+// and the matching Go layout used to fill in empty buckets
+func bucketSQL(bucket, column string) (string, string, error) {
+	switch bucket {
+	case BucketHour:
+		return fmt.Sprintf("strftime('%%Y-%%m-%%d %%H:00', %s)", column), "2006-01-02 15:00", nil
+	case BucketDay:
+		return fmt.Sprintf("date(%s)", column), "2006-01-02", nil
+	case BucketWeek:
+		// Label weeks by their Monday
+		return fmt.Sprintf("date(%s, 'weekday 0', '-6 days')", column), "2006-01-02", nil
+	case BucketMonth:
+		return fmt.Sprintf("strftime('%%Y-%%m', %s)", column), "2006-01", nil
+	default:
+		return "", "", fmt.Errorf("unknown bucket %q", bucket)
+	}
+}
+
+// metricSQL returns the aggregate expression, timestamp column and filter for a metric
+func metricSQL(metric string) (value, column, filter string, err error) {
+	switch metric {
+	case MetricCompleted:
+		return "COUNT(*)", "completed_at", "status = 'completed'", nil
+	case MetricFailed:
+		return "COUNT(*)", "completed_at", "status = 'failed'", nil
+	case MetricFailureRate:
+		return "CAST(SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END) AS REAL) / COUNT(*)",
+			"completed_at", "status IN ('completed', 'failed')", nil
+	case MetricAvgRenderTime:
+		return "AVG((julianday(completed_at) - julianday(started_at)) * 86400)",
+			"completed_at", "status = 'completed' AND started_at IS NOT NULL", nil
+	case MetricQueued:
+		return "COUNT(*)", "queued_at", "1 = 1", nil
+	default:
+		return "", "", "", fmt.Errorf("unknown metric %q", metric)
+	}
+}
+
+// GetTimeSeries aggregates a queue metric into buckets between from and to.
+// Buckets with no jobs are included with a zero value so the result can be
+// charted directly.
+func (r *StatsRepository) GetTimeSeries(metric, bucket string, from, to time.Time) ([]TimeSeriesPoint, error) {
+	value, column, filter, err := metricSQL(metric)
+	if err != nil {
+		return nil, err
+	}
+	group, layout, err := bucketSQL(bucket, column)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s AS bucket, %s AS value, COUNT(*) AS count
+		FROM queue
+		WHERE %s
+		AND %s IS NOT NULL
+		AND julianday(%s) >= julianday(?)
+		AND julianday(%s) < julianday(?)
+		GROUP BY bucket
+		ORDER BY bucket
+	`, group, value, filter, column, column, column)
+
+	const sqliteTime = "2006-01-02 15:04:05"
+	rows, err := r.db.Query(query, from.UTC().Format(sqliteTime), to.UTC().Format(sqliteTime))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	found := make(map[string]TimeSeriesPoint)
+	for rows.Next() {
+		var label sql.NullString
+		var v sql.NullFloat64
+		var point TimeSeriesPoint
+		if err := rows.Scan(&label, &v, &point.Count); err != nil {
+			return nil, err
+		}
+		if !label.Valid {
+			continue
+		}
+		point.Bucket = label.String
+		point.Value = v.Float64
+		found[point.Bucket] = point
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	points := []TimeSeriesPoint{}
+	for t := bucketStart(bucket, from.UTC()); t.Before(to); t = nextBucket(bucket, t) {
+		label := t.Format(layout)
+		point, ok := found[label]
+		if !ok {
+			point = TimeSeriesPoint{Bucket: label}
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// bucketStart truncates t to the start of its bucket
+func bucketStart(bucket string, t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch bucket {
+	case BucketHour:
+		return t.Truncate(time.Hour)
+	case BucketWeek:
+		offset := (int(t.Weekday()) + 6) % 7 // Days since Monday
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, time.UTC)
+	case BucketMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// nextBucket returns the start of the bucket after t
+func nextBucket(bucket string, t time.Time) time.Time {
+	switch bucket {
+	case BucketHour:
+		return t.Add(time.Hour)
+	case BucketWeek:
+		return t.AddDate(0, 0, 7)
+	case BucketMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
@@ -23,6 +23,8 @@ func (r *VideoRepository) GetAll() ([]models.Video, error) {
 		       v.background_style, v.spectrum_color, v.has_karaoke,
 		       v.status, v.rendered_at, v.created_at,
 		       v.genre, v.bpm, v.key, v.tempo, v.flag,
+		       COALESCE(v.width, 0), COALESCE(v.height, 0), COALESCE(v.streams, ''),
+		       COALESCE(v.variant, 'mixed'),
 		       s.title, s.artist_name
 		FROM videos v
 		JOIN songs s ON v.song_id = s.id
@@ -47,6 +49,8 @@ func (r *VideoRepository) GetAll() ([]models.Video, error) {
 			&v.BackgroundStyle, &v.SpectrumColor, &v.HasKaraoke,
 			&v.Status, &renderedAt, &createdAt,
 			&v.Genre, &v.BPM, &v.Key, &v.Tempo, &v.Flag,
+			&v.Width, &v.Height, &v.Streams,
+			&v.Variant,
 			&v.SongTitle, &v.ArtistName,
 		)
 		if err != nil {
@@ -70,6 +74,8 @@ func (r *VideoRepository) GetBySongID(songID int) ([]models.Video, error) {
 		       v.background_style, v.spectrum_color, v.has_karaoke,
 		       v.status, v.rendered_at, v.created_at,
 		       v.genre, v.bpm, v.key, v.tempo, v.flag,
+		       COALESCE(v.width, 0), COALESCE(v.height, 0), COALESCE(v.streams, ''),
+		       COALESCE(v.variant, 'mixed'),
 		       s.title, s.artist_name
 		FROM videos v
 		JOIN songs s ON v.song_id = s.id
@@ -94,6 +100,8 @@ func (r *VideoRepository) GetBySongID(songID int) ([]models.Video, error) {
 			&v.BackgroundStyle, &v.SpectrumColor, &v.HasKaraoke,
 			&v.Status, &renderedAt, &createdAt,
 			&v.Genre, &v.BPM, &v.Key, &v.Tempo, &v.Flag,
+			&v.Width, &v.Height, &v.Streams,
+			&v.Variant,
 			&v.SongTitle, &v.ArtistName,
 		)
 		if err != nil {
@@ -109,13 +117,57 @@ func (r *VideoRepository) GetBySongID(songID int) ([]models.Video, error) {
 	return videos, nil
 }
 
+// GetByID returns a single video with its song's title and artist, or nil if
+// it doesn't exist
+func (r *VideoRepository) GetByID(id int) (*models.Video, error) {
+	query := `
+		SELECT v.id, v.song_id, v.video_file_path, v.thumbnail_path, 
+		       v.resolution, v.duration_seconds, v.file_size_bytes, v.fps,
+		       v.background_style, v.spectrum_color, v.has_karaoke,
+		       v.status, v.rendered_at, v.created_at,
+		       v.genre, v.bpm, v.key, v.tempo, v.flag,
+		       COALESCE(v.width, 0), COALESCE(v.height, 0), COALESCE(v.streams, ''),
+		       COALESCE(v.variant, 'mixed'),
+		       s.title, s.artist_name
+		FROM videos v
+		JOIN songs s ON v.song_id = s.id
+		WHERE v.id = ?
+	`
+
+	var v models.Video
+	var renderedAt, createdAt string
+
+	err := r.db.QueryRow(query, id).Scan(
+		&v.ID, &v.SongID, &v.VideoFilePath, &v.ThumbnailPath,
+		&v.Resolution, &v.DurationSeconds, &v.FileSizeBytes, &v.FPS,
+		&v.BackgroundStyle, &v.SpectrumColor, &v.HasKaraoke,
+		&v.Status, &renderedAt, &createdAt,
+		&v.Genre, &v.BPM, &v.Key, &v.Tempo, &v.Flag,
+		&v.Width, &v.Height, &v.Streams,
+		&v.Variant,
+		&v.SongTitle, &v.ArtistName,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	v.RenderedAt, _ = time.Parse(time.RFC3339, renderedAt)
+	v.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+
+	return &v, nil
+}
+
 // Create inserts a new video record
 func (r *VideoRepository) Create(video *models.Video) error {
 	query := `
 		INSERT INTO videos 
 		(song_id, video_file_path, thumbnail_path, resolution, duration_seconds, 
-		 file_size_bytes, fps, background_style, spectrum_color, has_karaoke, status, rendered_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 file_size_bytes, fps, background_style, spectrum_color, has_karaoke, status, rendered_at,
+		 width, height, streams, variant)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := r.db.Exec(
@@ -132,6 +184,10 @@ func (r *VideoRepository) Create(video *models.Video) error {
 		video.HasKaraoke,
 		video.Status,
 		video.RenderedAt,
+		video.Width,
+		video.Height,
+		video.Streams,
+		video.Variant,
 	)
 	if err != nil {
 		return err
@@ -146,12 +202,17 @@ func (r *VideoRepository) Create(video *models.Video) error {
 	return nil
 }
 
-// CreateOrUpdate inserts a new video or updates existing one for the same song
+// CreateOrUpdate inserts a new video or updates the existing one for the same
+// song and variant
 func (r *VideoRepository) CreateOrUpdate(video *models.Video) error {
-	// Check if ANY video already exists for this song (regardless of status)
+	if video.Variant == "" {
+		video.Variant = models.VideoVariantMixed
+	}
+
+	// Check if ANY video already exists for this song and variant (regardless of status)
 	var existingID int
-	query := `SELECT id FROM videos WHERE song_id = ? ORDER BY created_at DESC LIMIT 1`
-	err := r.db.QueryRow(query, video.SongID).Scan(&existingID)
+	query := `SELECT id FROM videos WHERE song_id = ? AND COALESCE(variant, 'mixed') = ? ORDER BY created_at DESC LIMIT 1`
+	err := r.db.QueryRow(query, video.SongID, video.Variant).Scan(&existingID)
 
 	if err == nil {
 		// Video exists, update it
@@ -161,7 +222,8 @@ func (r *VideoRepository) CreateOrUpdate(video *models.Video) error {
 			    duration_seconds = ?, file_size_bytes = ?, fps = ?,
 			    background_style = ?, spectrum_color = ?, has_karaoke = ?,
 			    status = ?, rendered_at = ?,
-			    genre = ?, bpm = ?, key = ?, tempo = ?
+			    genre = ?, bpm = ?, key = ?, tempo = ?,
+			    width = ?, height = ?, streams = ?
 			WHERE id = ?
 		`
 
@@ -182,6 +244,9 @@ func (r *VideoRepository) CreateOrUpdate(video *models.Video) error {
 			video.BPM,
 			video.Key,
 			video.Tempo,
+			video.Width,
+			video.Height,
+			video.Streams,
 			existingID,
 		)
 		if err != nil {
@@ -204,3 +269,32 @@ func (r *VideoRepository) Delete(id int) error {
 	_, err := r.db.Exec("UPDATE videos SET status = 'deleted' WHERE id = ?", id)
 	return err
 }
+
+// PathUsedByOtherSong reports whether a video file path belongs to a different song
+func (r *VideoRepository) PathUsedByOtherSong(path string, songID int) (bool, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM videos WHERE video_file_path = ? AND song_id != ?`, path, songID).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetFilePathsBySongID returns the video file paths for a song regardless of status
+func (r *VideoRepository) GetFilePathsBySongID(songID int) ([]string, error) {
+	rows, err := r.db.Query(`SELECT video_file_path FROM videos WHERE song_id = ?`, songID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+)
+
+// YoutubeUploadRepository handles youtube_uploads database operations
+type YoutubeUploadRepository struct {
+	db *sql.DB
+}
+
+// NewYoutubeUploadRepository creates a new YouTube upload repository
+func NewYoutubeUploadRepository(db *sql.DB) *YoutubeUploadRepository {
+	return &YoutubeUploadRepository{db: db}
+}
+
+// GetPendingBySong returns the song's newest upload record that hasn't been
+// uploaded yet (its metadata is still editable), or nil if there is none
+func (r *YoutubeUploadRepository) GetPendingBySong(songID int) (*models.YoutubeUpload, error) {
+	query := `SELECT id, queue_id, song_id,
+		COALESCE(youtube_video_id, '') as youtube_video_id,
+		COALESCE(youtube_url, '') as youtube_url,
+		title,
+		COALESCE(description, '') as description,
+		COALESCE(tags, '') as tags,
+		COALESCE(category_id, 10) as category_id,
+		COALESCE(privacy_status, 'public') as privacy_status,
+		upload_started_at, upload_completed_at,
+		COALESCE(views, 0) as views,
+		COALESCE(likes, 0) as likes,
+		created_at
+		FROM youtube_uploads
+		WHERE song_id = ? AND youtube_video_id IS NULL
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1`
+
+	var upload models.YoutubeUpload
+	err := r.db.QueryRow(query, songID).Scan(
+		&upload.ID, &upload.QueueID, &upload.SongID,
+		&upload.YoutubeVideoID, &upload.YoutubeURL,
+		&upload.Title, &upload.Description, &upload.Tags,
+		&upload.CategoryID, &upload.PrivacyStatus,
+		&upload.UploadStartedAt, &upload.UploadCompletedAt,
+		&upload.Views, &upload.Likes,
+		&upload.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &upload, nil
+}
+
+// SaveMetadata creates the upload record or updates its reviewable metadata
+// (title, description, tags, category, privacy). New records are linked to the
+// song's latest queue item, or 0 if it was never queued.
+func (r *YoutubeUploadRepository) SaveMetadata(upload *models.YoutubeUpload) error {
+	if upload.ID != 0 {
+		_, err := r.db.Exec(`UPDATE youtube_uploads
+			SET title = ?, description = ?, tags = ?, category_id = ?, privacy_status = ?
+			WHERE id = ?`,
+			upload.Title, upload.Description, upload.Tags, upload.CategoryID, upload.PrivacyStatus,
+			upload.ID,
+		)
+		return err
+	}
+
+	result, err := r.db.Exec(`INSERT INTO youtube_uploads
+		(queue_id, song_id, title, description, tags, category_id, privacy_status)
+		VALUES (COALESCE((SELECT id FROM queue WHERE song_id = ? ORDER BY queued_at DESC, id DESC LIMIT 1), 0),
+		        ?, ?, ?, ?, ?, ?)`,
+		upload.SongID,
+		upload.SongID, upload.Title, upload.Description, upload.Tags, upload.CategoryID, upload.PrivacyStatus,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	upload.ID = int(id)
+
+	return r.db.QueryRow(`SELECT queue_id, created_at FROM youtube_uploads WHERE id = ?`, upload.ID).
+		Scan(&upload.QueueID, &upload.CreatedAt)
+}
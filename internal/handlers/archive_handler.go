@@ -0,0 +1,510 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// exportManifestVersion is bumped whenever the archive layout changes incompatibly
+const exportManifestVersion = 1
+
+// exportArtifact is a file included in a song export archive
+type exportArtifact struct {
+	Name      string `json:"name"`               // Path inside the archive
+	Type      string `json:"type"`               // video, image, audio, subtitles, timestamps, log
+	ImageID   int    `json:"image_id,omitempty"` // Source generated_images row for image files
+	SizeBytes int64  `json:"size_bytes"`
+	source    string
+}
+
+// exportManifest describes the contents of a song export archive
+type exportManifest struct {
+	Version    int              `json:"version"`
+	SongID     int              `json:"song_id"`
+	Title      string           `json:"title"`
+	Artist     string           `json:"artist"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Files      []exportArtifact `json:"files"`
+	Missing    []string         `json:"missing"`
+}
+
+// exportMetadata holds the database records included in a song export archive
+type exportMetadata struct {
+	Song   *models.Song            `json:"song"`
+	Images []models.GeneratedImage `json:"images"`
+	Videos []models.Video          `json:"videos"`
+}
+
+// ExportSong streams a zip of everything produced for a song: rendered videos,
+// generated images, karaoke subtitles, the render log and a metadata JSON.
+// Missing artifacts are skipped and listed in the manifest.
+func (h *SongHandler) ExportSong(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	song, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	images, err := database.GetImagesBySongID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	videos, err := database.NewVideoRepository(database.DB).GetBySongID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Only files under the known storage roots may be exported
+	roots := []string{utils.GetDataPath(), h.config.StoragePath}
+	dataPath := utils.GetDataPath()
+
+	var candidates []exportArtifact
+	for _, v := range videos {
+		candidates = append(candidates, exportArtifact{
+			Name:   "videos/" + filepath.Base(v.VideoFilePath),
+			Type:   "video",
+			source: v.VideoFilePath,
+		})
+	}
+	for _, img := range images {
+		if img.ImagePath == "" || img.ImagePath == "." {
+			continue
+		}
+		source := img.ImagePath
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(dataPath, source)
+		}
+		candidates = append(candidates, exportArtifact{
+			Name:    "images/" + filepath.Base(source),
+			Type:    "image",
+			ImageID: img.ID,
+			source:  source,
+		})
+	}
+	for _, source := range []string{utils.GetSongVocalPath(id), utils.GetSongMusicPath(id), utils.GetSongMixedPath(id)} {
+		if source == "" {
+			continue
+		}
+		candidates = append(candidates, exportArtifact{
+			Name:   "audio/" + filepath.Base(source),
+			Type:   "audio",
+			source: source,
+		})
+	}
+	candidates = append(candidates,
+		exportArtifact{
+			Name:   "subtitles/" + fmt.Sprintf("song_%d_karaoke.ass", id),
+			Type:   "subtitles",
+			source: filepath.Join(utils.GetTempPath(), fmt.Sprintf("song_%d_karaoke.ass", id)),
+		},
+		exportArtifact{
+			Name:   "subtitles/" + fmt.Sprintf("song_%d_timestamps.json", id),
+			Type:   "timestamps",
+			source: filepath.Join(utils.GetTempPath(), fmt.Sprintf("song_%d_timestamps.json", id)),
+		},
+		exportArtifact{
+			Name:   "logs/render.log",
+			Type:   "log",
+			source: filepath.Join(h.config.LogsPath, fmt.Sprintf("%d", id), "log.txt"),
+		},
+	)
+
+	var artifacts []exportArtifact
+	var missing []string
+	seen := make(map[string]bool)
+	for _, a := range candidates {
+		if seen[a.Name] {
+			continue
+		}
+		if !pathWithinRoots(a.source, roots) {
+			log.Printf("Export: skipping %s outside storage roots", a.source)
+			missing = append(missing, a.Name)
+			continue
+		}
+		info, err := os.Stat(a.source)
+		if err != nil || info.IsDir() {
+			missing = append(missing, a.Name)
+			continue
+		}
+		a.SizeBytes = info.Size()
+		seen[a.Name] = true
+		artifacts = append(artifacts, a)
+	}
+
+	metadata, err := json.MarshalIndent(exportMetadata{
+		Song:   song,
+		Images: images,
+		Videos: videos,
+	}, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	manifest, err := json.MarshalIndent(exportManifest{
+		Version:    exportManifestVersion,
+		SongID:     id,
+		Title:      song.Title,
+		Artist:     song.ArtistName,
+		ExportedAt: time.Now().UTC(),
+		Files:      artifacts,
+		Missing:    missing,
+	}, "", "  ")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := utils.SanitizeFilename(fmt.Sprintf("%s_%d_export", song.Title, id)) + ".zip"
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	zw := zip.NewWriter(c.Writer)
+	defer zw.Close()
+
+	if err := writeZipBytes(zw, "manifest.json", manifest); err != nil {
+		log.Printf("Export: failed to write manifest for song %d: %v", id, err)
+		return
+	}
+	if err := writeZipBytes(zw, "metadata.json", metadata); err != nil {
+		log.Printf("Export: failed to write metadata for song %d: %v", id, err)
+		return
+	}
+	for _, a := range artifacts {
+		if err := writeZipFile(zw, a.Name, a.source); err != nil {
+			// Headers are already sent, so the best we can do is log and stop
+			log.Printf("Export: failed to add %s for song %d: %v", a.source, id, err)
+			return
+		}
+	}
+}
+
+// pathWithinRoots reports whether path resolves to a location inside one of roots
+func pathWithinRoots(path string, roots []string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		abs = resolved
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		rootAbs, err := filepath.Abs(root)
+		if err != nil {
+			continue
+		}
+		if resolved, err := filepath.EvalSymlinks(rootAbs); err == nil {
+			rootAbs = resolved
+		}
+		rel, err := filepath.Rel(rootAbs, abs)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeZipBytes adds an in-memory file to the archive
+func writeZipBytes(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// writeZipFile streams a file from disk into the archive
+func writeZipFile(zw *zip.Writer, name, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// maxImportSize bounds the uploaded archive size (videos can be large)
+const maxImportSize = 4 << 30
+
+// ImportSong recreates a song from an archive produced by ExportSong.
+// The song gets a new ID; images, audio, videos, subtitles and the render log
+// are placed in the convention-based locations for that ID.
+func (h *SongHandler) ImportSong(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No archive uploaded (expected form field 'file')"})
+		return
+	}
+	if fileHeader.Size > maxImportSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Archive too large"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to open upload: %v", err)})
+		return
+	}
+	defer file.Close()
+
+	zr, err := zip.NewReader(file, fileHeader.Size)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zip archive"})
+		return
+	}
+
+	entries := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		entries[f.Name] = f
+	}
+
+	var manifest exportManifest
+	if err := readZipJSON(entries, "manifest.json", &manifest); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if manifest.Version != exportManifestVersion {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsupported manifest version %d (expected %d)", manifest.Version, exportManifestVersion)})
+		return
+	}
+
+	var metadata exportMetadata
+	if err := readZipJSON(entries, "metadata.json", &metadata); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if metadata.Song == nil || metadata.Song.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "metadata.json has no song"})
+		return
+	}
+
+	// Validate every listed file before touching the database
+	for _, f := range manifest.Files {
+		if !safeArchiveName(f.Name) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Unsafe file name in manifest: %s", f.Name)})
+			return
+		}
+		if entries[f.Name] == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Manifest lists %s but it is not in the archive", f.Name)})
+			return
+		}
+	}
+
+	// Create the song under a new ID; the album may not exist on this instance
+	song := metadata.Song
+	oldID := song.ID
+	song.ID = 0
+	song.AlbumID = nil
+	song.VocalsStemPath = ""
+	song.MusicStemPath = ""
+	song.MixedAudioPath = ""
+	if err := h.repo.Create(song); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to create song: %v", err)})
+		return
+	}
+	newID := song.ID
+
+	imagesByID := make(map[int]models.GeneratedImage)
+	for _, img := range metadata.Images {
+		imagesByID[img.ID] = img
+	}
+	importedImages := make(map[int]bool)
+
+	var imported []string
+	var warnings []string
+	for _, f := range manifest.Files {
+		base := filepath.Base(f.Name)
+		var dest string
+
+		switch f.Type {
+		case "audio":
+			dest = filepath.Join(utils.GetSongAudioDir(newID), base)
+			stem := strings.TrimSuffix(base, filepath.Ext(base))
+			switch stem {
+			case "vocal":
+				song.VocalsStemPath = dest
+			case "music":
+				song.MusicStemPath = dest
+			case "mixed":
+				song.MixedAudioPath = dest
+			}
+		case "image":
+			dest = filepath.Join(services.SongImagesDir(newID), base)
+			relPath := strings.TrimPrefix(dest, utils.GetDataPath()+"/")
+			if img, ok := imagesByID[f.ImageID]; ok && !importedImages[f.ImageID] {
+				img.SongID = newID
+				img.QueueID = nil
+				img.ImagePath = relPath
+				if err := database.CreateGeneratedImage(&img); err != nil {
+					warnings = append(warnings, fmt.Sprintf("failed to create image record for %s: %v", f.Name, err))
+				}
+				importedImages[f.ImageID] = true
+			}
+		case "video":
+			dest = filepath.Join(utils.GetVideosPath(), base)
+			if _, err := os.Stat(dest); err == nil {
+				ext := filepath.Ext(base)
+				dest = filepath.Join(utils.GetVideosPath(), fmt.Sprintf("%s_%d%s", strings.TrimSuffix(base, ext), newID, ext))
+			}
+		case "subtitles":
+			dest = filepath.Join(utils.GetTempPath(), fmt.Sprintf("song_%d_karaoke.ass", newID))
+		case "timestamps":
+			dest = filepath.Join(utils.GetTempPath(), fmt.Sprintf("song_%d_timestamps.json", newID))
+		case "log":
+			dest = filepath.Join(h.config.LogsPath, fmt.Sprintf("%d", newID), "log.txt")
+		default:
+			warnings = append(warnings, fmt.Sprintf("skipped %s with unknown type %q", f.Name, f.Type))
+			continue
+		}
+
+		if err := extractZipFile(entries[f.Name], dest); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to extract %s: %v", f.Name, err))
+			continue
+		}
+		imported = append(imported, f.Name)
+
+		if f.Type == "video" {
+			if err := importVideoRecord(metadata.Videos, base, newID, dest); err != nil {
+				warnings = append(warnings, fmt.Sprintf("failed to create video record for %s: %v", f.Name, err))
+			}
+		}
+	}
+
+	// Image prompts without files still carry over so they can be regenerated
+	for _, img := range metadata.Images {
+		if importedImages[img.ID] {
+			continue
+		}
+		img.SongID = newID
+		img.QueueID = nil
+		img.ImagePath = ""
+		if err := database.CreateGeneratedImage(&img); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to create image prompt %d: %v", img.ID, err))
+		}
+	}
+
+	// Save rewritten audio paths
+	if err := h.repo.Update(song); err != nil {
+		warnings = append(warnings, fmt.Sprintf("failed to update audio paths: %v", err))
+	}
+
+	log.Printf("Imported song %d as %d (%d files, %d warnings)", oldID, newID, len(imported), len(warnings))
+	c.JSON(http.StatusCreated, gin.H{
+		"song":        song,
+		"original_id": oldID,
+		"imported":    imported,
+		"warnings":    warnings,
+	})
+}
+
+// importVideoRecord creates a videos row for an imported video file, reusing the
+// exported record's settings when one matches the file name
+func importVideoRecord(videos []models.Video, base string, songID int, dest string) error {
+	video := models.Video{
+		Resolution: "1080p",
+		FPS:        30,
+		Status:     "completed",
+		RenderedAt: time.Now(),
+	}
+	for _, v := range videos {
+		if filepath.Base(v.VideoFilePath) == base {
+			video = v
+			break
+		}
+	}
+	video.SongID = songID
+	video.VideoFilePath = dest
+	if info, err := os.Stat(dest); err == nil {
+		video.FileSizeBytes = info.Size()
+	}
+	return database.NewVideoRepository(database.DB).Create(&video)
+}
+
+// readZipJSON decodes a JSON file from the archive
+func readZipJSON(entries map[string]*zip.File, name string, v interface{}) error {
+	f, ok := entries[name]
+	if !ok {
+		return fmt.Errorf("archive is missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// safeArchiveName rejects absolute paths and parent directory references
+func safeArchiveName(name string) bool {
+	if name == "" || strings.Contains(name, "\\") || path.IsAbs(name) {
+		return false
+	}
+	cleaned := path.Clean(name)
+	return cleaned == name && cleaned != ".." && !strings.HasPrefix(cleaned, "../")
+}
+
+// extractZipFile writes an archive entry to dest, creating parent directories
+func extractZipFile(f *zip.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, rc)
+	return err
+}
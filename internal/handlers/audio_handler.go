@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services/ai"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
 	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
@@ -14,15 +17,21 @@ import (
 
 // AudioHandler handles audio analysis requests
 type AudioHandler struct {
-	songRepo *database.SongRepository
-	aiClient *ai.Client
+	songRepo     *database.SongRepository
+	settingsRepo *database.SettingsRepository
+	aiClient     *ai.Client
+	analyzer     audio.AudioAnalyzer
+	separations  *services.StemSeparations
 }
 
 // NewAudioHandler creates a new audio handler
-func NewAudioHandler(songRepo *database.SongRepository, aiClient *ai.Client) *AudioHandler {
+func NewAudioHandler(songRepo *database.SongRepository, settingsRepo *database.SettingsRepository, aiClient *ai.Client, analyzer audio.AudioAnalyzer, separations *services.StemSeparations) *AudioHandler {
 	return &AudioHandler{
-		songRepo: songRepo,
-		aiClient: aiClient,
+		songRepo:     songRepo,
+		settingsRepo: settingsRepo,
+		aiClient:     aiClient,
+		analyzer:     analyzer,
+		separations:  separations,
 	}
 }
 
@@ -54,7 +63,7 @@ func (h *AudioHandler) AnalyzeSong(c *gin.Context) {
 	}
 
 	// Perform audio analysis
-	analysis, err := audio.AnalyzeAudio(audioPath)
+	analysis, err := h.analyzer.Analyze(audioPath)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Audio analysis failed: " + err.Error()})
 		return
@@ -65,8 +74,14 @@ func (h *AudioHandler) AnalyzeSong(c *gin.Context) {
 	song.BPM = analysis.BPM
 	song.Key = analysis.Key
 	song.Tempo = analysis.Tempo
+	if beatTimesJSON, err := audio.BeatTimesJSON(analysis); err != nil {
+		log.Printf("Warning: failed to marshal beat times: %v", err)
+	} else {
+		song.BeatTimes = beatTimesJSON
+	}
 	if song.Genre == "" && analysis.Genre != "" {
 		song.Genre = analysis.Genre
+		song.GenreSource = models.GenreSourceAnalysis
 	}
 
 	// Save updated song
@@ -77,6 +92,7 @@ func (h *AudioHandler) AnalyzeSong(c *gin.Context) {
 
 	// Perform AI metadata enrichment (if AI client is configured)
 	var enrichment interface{} = nil
+	enrichmentApplied := false
 	if h.aiClient != nil {
 		log.Printf("Enriching metadata for song %d after analysis", id)
 		enrich, err := h.aiClient.EnrichSongMetadata(song)
@@ -84,12 +100,13 @@ func (h *AudioHandler) AnalyzeSong(c *gin.Context) {
 			log.Printf("Warning: Failed to enrich metadata: %v", err)
 			// Don't fail the whole request, just log and continue
 		} else {
-			// Save enrichment to database
-			if err := h.songRepo.UpdateMetadataEnrichment(id, enrich); err != nil {
+			// Save enrichment to database (or hold it for review)
+			if applied, err := services.SaveEnrichment(h.songRepo, h.settingsRepo, id, enrich); err != nil {
 				log.Printf("Warning: Failed to save enrichment: %v", err)
 			} else {
 				enrichment = enrich
-				log.Printf("Successfully enriched metadata for song %d", id)
+				enrichmentApplied = applied
+				log.Printf("Enriched metadata for song %d (applied: %v)", id, applied)
 			}
 		}
 	}
@@ -110,7 +127,80 @@ func (h *AudioHandler) AnalyzeSong(c *gin.Context) {
 
 	if enrichment != nil {
 		response["enrichment"] = enrichment
+		response["enrichment_applied"] = enrichmentApplied
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// SeparateStems splits a song's uploaded mixed track into vocal and music
+// stems in the background, returning 202 with the job. Existing stems are
+// only replaced with ?force=true. Progress streams over SSE.
+func (h *AudioHandler) SeparateStems(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	song, err := h.songRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	if utils.HasSongStems(id) && c.Query("force") != "true" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Song already has vocal and music stems; use ?force=true to replace them"})
+		return
+	}
+
+	// Separation needs demucs or spleeter, which most installs won't have
+	if report := h.separations.Separator().CheckEnvironment(); !report.Ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":       "Stem separation is not available",
+			"environment": report,
+		})
+		return
+	}
+
+	job, err := h.separations.Start(id)
+	switch {
+	case errors.Is(err, services.ErrNoMixedTrack):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No mixed track uploaded. Upload one as 'mixed' first."})
+		return
+	case errors.Is(err, services.ErrSeparationRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Stem separation started",
+		"job_id":  job.ID,
+		"job":     job,
+	})
+}
+
+// GetStemSeparation returns the latest stem separation job for a song
+func (h *AudioHandler) GetStemSeparation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	job, ok := h.separations.Get(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No stem separation has run for this song"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
+	"github.com/gin-gonic/gin"
+)
+
+// BrandingHandler handles branding asset requests
+type BrandingHandler struct {
+	settingsRepo *database.SettingsRepository
+	config       *config.Config
+}
+
+// NewBrandingHandler creates a new branding handler
+func NewBrandingHandler(settingsRepo *database.SettingsRepository, cfg *config.Config) *BrandingHandler {
+	return &BrandingHandler{
+		settingsRepo: settingsRepo,
+		config:       cfg,
+	}
+}
+
+// BrandingAsset describes one branding file the renderer can use
+type BrandingAsset struct {
+	Name       string     `json:"name"`
+	Filename   string     `json:"filename"`
+	Present    bool       `json:"present"`
+	URL        string     `json:"url,omitempty"`
+	SizeBytes  int64      `json:"size_bytes,omitempty"`
+	ModifiedAt *time.Time `json:"modified_at,omitempty"`
+}
+
+// brandingAssets are the files the renderer looks for in the branding directory
+var brandingAssets = []struct {
+	name     string
+	filename string
+}{
+	{name: "artist_logo", filename: video.ArtistLogoFilename},
+}
+
+// Get reports the branding directory and which branding assets are present
+func (h *BrandingHandler) Get(c *gin.Context) {
+	assets := make([]BrandingAsset, 0, len(brandingAssets))
+	for _, a := range brandingAssets {
+		asset := BrandingAsset{Name: a.name, Filename: a.filename}
+		if info, err := os.Stat(filepath.Join(h.config.BrandingPath, a.filename)); err == nil && !info.IsDir() {
+			modified := info.ModTime()
+			asset.Present = true
+			asset.URL = "/branding/" + a.filename
+			asset.SizeBytes = info.Size()
+			asset.ModifiedAt = &modified
+		}
+		assets = append(assets, asset)
+	}
+
+	_, dirErr := os.Stat(h.config.BrandingPath)
+
+	c.JSON(http.StatusOK, gin.H{
+		"path":        h.config.BrandingPath,
+		"path_exists": dirErr == nil,
+		"assets":      assets,
+	})
+}
+
+// UploadLogo saves an uploaded PNG/JPG as the artist logo overlaid on videos
+func (h *BrandingHandler) UploadLogo(c *gin.Context) {
+	// Create branding directory
+	if err := os.MkdirAll(h.config.BrandingPath, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create branding directory: " + err.Error()})
+		return
+	}
+
+	// Get uploaded file
+	file, header, err := c.Request.FormFile("logo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	defer file.Close()
+
+	// Validate file type
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only PNG and JPG files are allowed"})
+		return
+	}
+
+	// Save as artist-logo.png (overwrite existing)
+	logoPath := filepath.Join(h.config.BrandingPath, video.ArtistLogoFilename)
+	destFile, err := os.Create(logoPath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save logo: " + err.Error()})
+		return
+	}
+	defer destFile.Close()
+
+	if _, err := io.Copy(destFile, file); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write logo: " + err.Error()})
+		return
+	}
+
+	// Update settings with logo path
+	settings, err := h.settingsRepo.Get()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings.BrandLogoPath = "branding/" + video.ArtistLogoFilename
+	if err := h.settingsRepo.Update(settings); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logo uploaded successfully",
+		"path":    settings.BrandLogoPath,
+		"url":     "/branding/" + video.ArtistLogoFilename,
+	})
+}
@@ -7,19 +7,25 @@ import (
 	"strconv"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services/ai"
 	"github.com/gin-gonic/gin"
 )
 
 type EnrichmentHandler struct {
-	songRepo *database.SongRepository
-	aiClient *ai.Client
+	songRepo     *database.SongRepository
+	settingsRepo *database.SettingsRepository
+	aiClient     *ai.Client
+	jobs         *services.EnrichmentJobs
 }
 
-func NewEnrichmentHandler(songRepo *database.SongRepository, aiClient *ai.Client) *EnrichmentHandler {
+func NewEnrichmentHandler(songRepo *database.SongRepository, settingsRepo *database.SettingsRepository, aiClient *ai.Client, jobs *services.EnrichmentJobs) *EnrichmentHandler {
 	return &EnrichmentHandler{
-		songRepo: songRepo,
-		aiClient: aiClient,
+		songRepo:     songRepo,
+		settingsRepo: settingsRepo,
+		aiClient:     aiClient,
+		jobs:         jobs,
 	}
 }
 
@@ -66,23 +72,140 @@ func (h *EnrichmentHandler) EnrichSongMetadata(c *gin.Context) {
 		return
 	}
 
-	// Update the database
-	if err := h.songRepo.UpdateMetadataEnrichment(songID, enrichment); err != nil {
+	// Update the database (or hold the result for review)
+	applied, err := services.SaveEnrichment(h.songRepo, h.settingsRepo, songID, enrichment)
+	if err != nil {
 		log.Printf("Error saving enrichment for song %d: %v", songID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save enrichment"})
 		return
 	}
 
-	log.Printf("Successfully enriched metadata for song %d", songID)
+	message := "Metadata enriched successfully"
+	if applied {
+		log.Printf("Successfully enriched metadata for song %d", songID)
+	} else {
+		message = "Metadata proposed; review and apply with PUT /songs/:id/metadata"
+		log.Printf("Enrichment for song %d is awaiting review", songID)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":    "Metadata enriched successfully",
+		"message":    message,
 		"song_id":    songID,
+		"applied":    applied,
 		"enrichment": enrichment,
 	})
 }
 
-// EnrichBatch enriches multiple songs in batch
+// ProposeMetadata runs AI enrichment and holds the result for review without
+// changing the song's metadata
+func (h *EnrichmentHandler) ProposeMetadata(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	song, err := h.songRepo.GetByID(songID)
+	if err != nil || song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	log.Printf("Proposing metadata for song %d: %s", songID, song.Title)
+
+	enrichment, err := h.aiClient.EnrichSongMetadata(song)
+	if err != nil {
+		log.Printf("Error enriching song %d: %v", songID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to enrich metadata: %v", err)})
+		return
+	}
+
+	if err := h.songRepo.SetPendingEnrichment(songID, enrichment); err != nil {
+		log.Printf("Error saving proposed enrichment for song %d: %v", songID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save proposed enrichment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"song_id":  songID,
+		"proposed": enrichment,
+	})
+}
+
+// GetProposedMetadata returns the enrichment awaiting review for a song
+func (h *EnrichmentHandler) GetProposedMetadata(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	enrichment, err := h.songRepo.GetPendingEnrichment(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if enrichment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No proposed metadata for this song"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"song_id":  songID,
+		"proposed": enrichment,
+	})
+}
+
+// UpdateMetadata applies reviewed (and possibly edited) metadata to a song
+func (h *EnrichmentHandler) UpdateMetadata(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	var enrichment models.SongMetadataEnrichment
+	if err := c.ShouldBindJSON(&enrichment); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	song, err := h.songRepo.GetByID(songID)
+	if err != nil || song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	settings, err := h.settingsRepo.Get()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !models.IsValidGenre(enrichment.GenrePrimary, settings.Genres()) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  fmt.Sprintf("Invalid primary genre: %q", enrichment.GenrePrimary),
+			"genres": settings.Genres(),
+		})
+		return
+	}
+
+	if err := h.songRepo.UpdateMetadataEnrichment(songID, &enrichment); err != nil {
+		log.Printf("Error saving metadata for song %d: %v", songID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save metadata"})
+		return
+	}
+
+	log.Printf("Applied reviewed metadata for song %d", songID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Metadata updated",
+		"song_id":  songID,
+		"metadata": enrichment,
+	})
+}
+
+// EnrichBatch starts a background job enriching multiple songs. Progress is
+// streamed over /progress/stream and the job can be polled at /enrich/jobs/:id.
 func (h *EnrichmentHandler) EnrichBatch(c *gin.Context) {
 	type BatchRequest struct {
 		SongIDs      []int `json:"song_ids"`
@@ -100,74 +223,25 @@ func (h *EnrichmentHandler) EnrichBatch(c *gin.Context) {
 		return
 	}
 
-	results := make([]map[string]interface{}, 0)
-	successCount := 0
-	errorCount := 0
-
-	for _, songID := range req.SongIDs {
-		// Get the song
-		song, err := h.songRepo.GetByID(songID)
-		if err != nil {
-			errorCount++
-			results = append(results, map[string]interface{}{
-				"song_id": songID,
-				"status":  "error",
-				"message": "Song not found",
-			})
-			continue
-		}
-
-		// Skip if already enriched (unless force_refresh)
-		if song.MetadataEnrichedAt != nil && !req.ForceRefresh {
-			results = append(results, map[string]interface{}{
-				"song_id": songID,
-				"status":  "skipped",
-				"message": "Already enriched",
-			})
-			continue
-		}
+	job := h.jobs.Start(req.SongIDs, req.ForceRefresh)
 
-		// Call AI to generate metadata
-		enrichment, err := h.aiClient.EnrichSongMetadata(song)
-		if err != nil {
-			log.Printf("Error enriching song %d: %v", songID, err)
-			errorCount++
-			results = append(results, map[string]interface{}{
-				"song_id": songID,
-				"status":  "error",
-				"message": fmt.Sprintf("AI enrichment failed: %v", err),
-			})
-			continue
-		}
-
-		// Update the database
-		if err := h.songRepo.UpdateMetadataEnrichment(songID, enrichment); err != nil {
-			log.Printf("Error saving enrichment for song %d: %v", songID, err)
-			errorCount++
-			results = append(results, map[string]interface{}{
-				"song_id": songID,
-				"status":  "error",
-				"message": "Failed to save enrichment",
-			})
-			continue
-		}
-
-		successCount++
-		results = append(results, map[string]interface{}{
-			"song_id": songID,
-			"status":  "success",
-			"title":   song.Title,
-		})
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id":     job.ID,
+		"total":      job.Total,
+		"status":     job.Status,
+		"status_url": "/api/v1/enrich/jobs/" + job.ID,
+	})
+}
 
-		log.Printf("Successfully enriched song %d: %s", songID, song.Title)
+// GetJob returns the status of a batch enrichment job
+func (h *EnrichmentHandler) GetJob(c *gin.Context) {
+	job, ok := h.jobs.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Enrichment job not found"})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"total":   len(req.SongIDs),
-		"success": successCount,
-		"errors":  errorCount,
-		"results": results,
-	})
+	c.JSON(http.StatusOK, job)
 }
 
 // GetEnrichmentStatus returns the enrichment status for all songs
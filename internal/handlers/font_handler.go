@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
+	"github.com/gin-gonic/gin"
+)
+
+// maxFontSize caps font uploads
+const maxFontSize = 20 << 20 // 20 MB
+
+// FontHandler handles uploaded font requests
+type FontHandler struct {
+	registry *video.FontRegistry
+}
+
+// NewFontHandler creates a new font handler
+func NewFontHandler(registry *video.FontRegistry) *FontHandler {
+	return &FontHandler{registry: registry}
+}
+
+// List returns the uploaded fonts available to karaoke and overlays by name
+func (h *FontHandler) List(c *gin.Context) {
+	fonts, err := h.registry.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if fonts == nil {
+		fonts = []video.FontInfo{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"fonts":    fonts,
+		"fallback": video.DefaultSystemFonts(),
+	})
+}
+
+// Upload stores a TTF/OTF font. The optional "name" form field sets the name
+// songs and settings refer to it by; it defaults to the file's base name.
+func (h *FontHandler) Upload(c *gin.Context) {
+	file, header, err := c.Request.FormFile("font")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > maxFontSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Font file too large (max 20 MB)"})
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	name := strings.TrimSpace(c.PostForm("name"))
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+	}
+
+	// Check the signature so renamed non-font files are rejected up front
+	signature := make([]byte, 4)
+	n, _ := io.ReadFull(file, signature)
+	if !video.IsFontFile(signature[:n]) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is not a TrueType or OpenType font"})
+		return
+	}
+
+	font, err := h.registry.Save(name, ext, io.MultiReader(bytes.NewReader(signature[:n]), file))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, font)
+}
+
+// GetFile serves an uploaded font so the frontend can preview it with @font-face
+func (h *FontHandler) GetFile(c *gin.Context) {
+	path, ok := h.registry.Lookup(c.Param("name"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Font not found"})
+		return
+	}
+
+	contentType := "font/ttf"
+	if strings.EqualFold(filepath.Ext(path), ".otf") {
+		contentType = "font/otf"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Cache-Control", "public, max-age=300, must-revalidate")
+	c.File(path)
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/image"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/lyrics"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/pyenv"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
+	"github.com/gin-gonic/gin"
+)
+
+// probeTimeout bounds each individual dependency check
+const probeTimeout = 3 * time.Second
+
+type HealthHandler struct {
+	db     *sql.DB
+	config *config.Config
+	client *http.Client
+}
+
+func NewHealthHandler(db *sql.DB, cfg *config.Config) *HealthHandler {
+	return &HealthHandler{
+		db:     db,
+		config: cfg,
+		client: &http.Client{Timeout: probeTimeout},
+	}
+}
+
+// DependencyStatus is the result of probing a single dependency
+type DependencyStatus struct {
+	Name      string `json:"name"`
+	Healthy   bool   `json:"healthy"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// dependencyProbe checks a single dependency and returns an error if it is unavailable
+type dependencyProbe struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// Ready probes all downstream dependencies concurrently and reports readiness.
+// The Python environment diagnostics are included in full so a failing check
+// says how to fix it.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	var audioEnv, karaokeEnv *pyenv.Report
+	probes := []dependencyProbe{
+		{name: "sqlite", check: h.checkDatabase},
+		{name: "cqai_image", check: h.checkHTTP(h.config.CQAIURL)},
+		{name: "ollama", check: h.checkHTTP(strings.TrimRight(h.config.CQAILLMURL, "/") + "/api/tags")},
+		{name: "whisperx", check: h.checkHTTP(h.config.WhisperXURL)},
+		{name: "ffmpeg", check: checkFFmpeg},
+		{name: "ffprobe", check: checkBinary("ffprobe")},
+		{name: "python3", check: checkBinary("python3")},
+		{name: "python_audio", check: func(ctx context.Context) error {
+			audioEnv = audio.NewAnalyzer(h.config).CheckEnvironment()
+			return audioEnv.Err()
+		}},
+		{name: "python_karaoke", check: func(ctx context.Context) error {
+			karaokeEnv = lyrics.CheckEnvironment(h.config)
+			return karaokeEnv.Err()
+		}},
+	}
+
+	results := make([]DependencyStatus, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe dependencyProbe) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(c.Request.Context(), probeTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := probe.check(ctx)
+			results[i] = DependencyStatus{
+				Name:      probe.name,
+				Healthy:   err == nil,
+				LatencyMS: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, probe)
+	}
+	wg.Wait()
+
+	// Stem separation is optional, so it's reported but doesn't affect readiness
+	separatorEnv := audio.NewSeparator(h.config).CheckEnvironment()
+
+	ready := true
+	for _, result := range results {
+		if !result.Healthy {
+			ready = false
+			break
+		}
+	}
+
+	status := http.StatusOK
+	statusText := "ready"
+	if !ready {
+		status = http.StatusServiceUnavailable
+		statusText = "not_ready"
+	}
+
+	c.JSON(status, gin.H{
+		"status":       statusText,
+		"service":      "track-studio-orchestrator",
+		"dependencies": results,
+		"python":       []*pyenv.Report{audioEnv, karaokeEnv, separatorEnv},
+		"ffmpeg":       video.DetectedCapabilities(),
+	})
+}
+
+// CQAIDiagnostics checks that CQAI's image and LLM services are reachable and
+// the configured models are loaded, using the image generator's endpoints
+func (h *HealthHandler) CQAIDiagnostics(c *gin.Context) {
+	generator := image.NewImageGenerator("", h.config)
+	ctx, cancel := context.WithTimeout(c.Request.Context(), probeTimeout)
+	defer cancel()
+
+	services := make([]image.ServiceStatus, 2)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		services[0] = generator.CheckImageService(ctx, h.client)
+	}()
+	go func() {
+		defer wg.Done()
+		services[1] = generator.CheckLLMService(ctx, h.client)
+	}()
+	wg.Wait()
+
+	status := http.StatusOK
+	statusText := "ok"
+	for _, service := range services {
+		if !service.Available {
+			status = http.StatusServiceUnavailable
+			statusText = "unavailable"
+			break
+		}
+	}
+
+	c.JSON(status, gin.H{
+		"status":      statusText,
+		"image_model": generator.ImageModel,
+		"services":    services,
+	})
+}
+
+// checkDatabase verifies the SQLite connection is usable
+func (h *HealthHandler) checkDatabase(ctx context.Context) error {
+	if h.db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+	return h.db.PingContext(ctx)
+}
+
+// checkHTTP returns a probe that treats any non-5xx response as reachable
+func (h *HealthHandler) checkHTTP(url string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if url == "" {
+			return fmt.Errorf("URL not configured")
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("invalid URL: %w", err)
+		}
+
+		resp, err := h.client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+}
+
+// checkFFmpeg verifies FFmpeg is installed with every filter the renderer
+// requires, detecting its capabilities if startup couldn't
+func checkFFmpeg(ctx context.Context) error {
+	if err := checkBinary("ffmpeg")(ctx); err != nil {
+		return err
+	}
+
+	caps := video.DetectedCapabilities()
+	if caps == nil {
+		var err error
+		if caps, err = video.DetectCapabilities(); err != nil {
+			return err
+		}
+	}
+	if !caps.Ready() {
+		return fmt.Errorf("missing required filters: %s", strings.Join(caps.MissingRequired, ", "))
+	}
+	return nil
+}
+
+// checkBinary returns a probe that verifies an executable is on PATH
+func checkBinary(name string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if _, err := exec.LookPath(name); err != nil {
+			return fmt.Errorf("%s not found in PATH", name)
+		}
+		return nil
+	}
+}
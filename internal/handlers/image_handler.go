@@ -1,29 +1,55 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
 	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/image"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/lyrics"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
+// ImageRegenerator regenerates all of a song's images (implemented by the queue worker)
+type ImageRegenerator interface {
+	RegenerateAllImages(song *models.Song) ([]models.GeneratedImage, error)
+}
+
 type ImageHandler struct {
 	settingsRepo *database.SettingsRepository
+	queueRepo    *database.QueueRepository
+	songRepo     *database.SongRepository
+	regenerator  ImageRegenerator
+	config       *config.Config
+	storage      storage.Storage
+
+	// regenerating holds the IDs of songs whose images are being regenerated
+	regenerating sync.Map
 }
 
-func NewImageHandler(settingsRepo *database.SettingsRepository) *ImageHandler {
+func NewImageHandler(settingsRepo *database.SettingsRepository, queueRepo *database.QueueRepository, songRepo *database.SongRepository, regenerator ImageRegenerator, cfg *config.Config, store storage.Storage) *ImageHandler {
 	return &ImageHandler{
 		settingsRepo: settingsRepo,
+		queueRepo:    queueRepo,
+		songRepo:     songRepo,
+		regenerator:  regenerator,
+		config:       cfg,
+		storage:      store,
 	}
 }
 
@@ -41,11 +67,86 @@ func (h *ImageHandler) GetImagesBySong(c *gin.Context) {
 		return
 	}
 
-	if images == nil {
-		images = []models.GeneratedImage{}
+	response := make([]ImageResponse, 0, len(images))
+	for _, img := range images {
+		response = append(response, newImageResponse(img))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ImageResponse is a generated image with URLs a client can load it from
+type ImageResponse struct {
+	models.GeneratedImage
+	URL     string `json:"url,omitempty"`      // Static /images route, empty if outside the images directory
+	FileURL string `json:"file_url,omitempty"` // GET /api/v1/images/:id/file
+}
+
+func newImageResponse(img models.GeneratedImage) ImageResponse {
+	resp := ImageResponse{GeneratedImage: img}
+	if img.ImagePath == "" {
+		return resp
+	}
+
+	resp.FileURL = fmt.Sprintf("/api/v1/images/%d/file", img.ID)
+	if rel, err := filepath.Rel(utils.GetImagesPath(), resolveImagePath(img.ImagePath)); err == nil &&
+		rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		resp.URL = "/images/" + filepath.ToSlash(rel)
+	}
+	return resp
+}
+
+// resolveImagePath returns the on-disk path for a stored image_path, which is
+// either absolute or relative to the data directory
+func resolveImagePath(imagePath string) string {
+	if filepath.IsAbs(imagePath) {
+		return imagePath
+	}
+	return filepath.Join(utils.GetDataPath(), imagePath)
+}
+
+// GetImageFile serves a single generated image
+func (h *ImageHandler) GetImageFile(c *gin.Context) {
+	imageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	img, err := database.GetImageByID(imageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if img == nil || img.ImagePath == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	path := resolveImagePath(img.ImagePath)
+	if !pathWithinRoots(path, artifactRoots(h.config)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Image path is outside the data directory"})
+		return
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		// The local copy may be gone when images are kept in remote storage
+		if key, keyErr := storage.KeyFor(utils.GetDataPath(), path); keyErr == nil && storage.IsRemote(h.storage) {
+			if url, urlErr := h.storage.URL(c.Request.Context(), key, time.Hour); urlErr == nil {
+				c.Redirect(http.StatusFound, url)
+				return
+			}
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image file not found"})
+		return
 	}
 
-	c.JSON(http.StatusOK, images)
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	// Regenerating an image rewrites the file in place, so clients must revalidate
+	c.Header("Cache-Control", "public, max-age=300, must-revalidate")
+	c.File(path)
 }
 
 // CreateImagePrompt creates a new image record with just a prompt (no actual image yet)
@@ -92,6 +193,60 @@ func (h *ImageHandler) DeleteImagesBySong(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "All images deleted successfully"})
 }
 
+// RegenerateAllImages deletes all of a song's images and generates a new set
+// from its current lyrics and style. Progress is broadcast over the events
+// stream; the request blocks until generation finishes and returns the images.
+func (h *ImageHandler) RegenerateAllImages(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	song, err := h.songRepo.GetByID(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	// The render pipeline reads and writes the same images
+	items, err := h.queueRepo.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for _, item := range items {
+		if item.SongID == songID && item.Status == "processing" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Song is currently being processed"})
+			return
+		}
+	}
+
+	if _, busy := h.regenerating.LoadOrStore(songID, struct{}{}); busy {
+		c.JSON(http.StatusConflict, gin.H{"error": "Images are already being regenerated for this song"})
+		return
+	}
+	defer h.regenerating.Delete(songID)
+
+	images, err := h.regenerator.RegenerateAllImages(song)
+	if err != nil {
+		log.Printf("Error regenerating images for song %d: %v", songID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate images: " + err.Error()})
+		return
+	}
+
+	response := make([]ImageResponse, 0, len(images))
+	for _, img := range images {
+		response = append(response, newImageResponse(img))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // UpdateImagePrompt updates the prompt for an image
 func (h *ImageHandler) UpdateImagePrompt(c *gin.Context) {
 	imageID, err := strconv.Atoi(c.Param("id"))
@@ -103,22 +258,35 @@ func (h *ImageHandler) UpdateImagePrompt(c *gin.Context) {
 	var req struct {
 		Prompt         string `json:"prompt"`
 		NegativePrompt string `json:"negative_prompt"`
+		Seed           *int64 `json:"seed"` // Optional; pinned for the next regeneration
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Seed != nil && *req.Seed < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Seed must not be negative"})
+		return
+	}
 
 	if err := database.UpdateImagePrompt(imageID, req.Prompt, req.NegativePrompt); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Seed != nil {
+		if err := database.UpdateImageSeed(imageID, req.Seed); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Image prompt updated"})
 }
 
-// RegenerateImage triggers regeneration of a specific image
+// RegenerateImage triggers regeneration of a specific image. The stored seed is
+// reused so prompt tweaks keep the composition; an optional JSON body of
+// {"seed": n} uses a specific seed and {"random_seed": true} picks a new one.
 func (h *ImageHandler) RegenerateImage(c *gin.Context) {
 	imageID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -126,27 +294,270 @@ func (h *ImageHandler) RegenerateImage(c *gin.Context) {
 		return
 	}
 
-	image, err := database.GetImageByID(imageID)
+	req, ok := bindRegenerateSeed(c)
+	if !ok {
+		return
+	}
+
+	img, err := database.GetImageByID(imageID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if image == nil {
+	if img == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
 		return
 	}
 
+	seed := req.seed(img.Seed)
+
 	// Regeneration happens in a goroutine to avoid blocking
-	go h.regenerateImageAsync(image)
+	go h.regenerateImageAsync(img, seed)
 
-	c.JSON(http.StatusAccepted, gin.H{
+	response := gin.H{
 		"message":  "Image regeneration started",
 		"image_id": strconv.Itoa(imageID),
+	}
+	if seed >= 0 {
+		response["seed"] = seed
+	}
+	c.JSON(http.StatusAccepted, response)
+}
+
+// regenerateSeedRequest is the optional body of image regeneration requests
+type regenerateSeedRequest struct {
+	Seed       *int64 `json:"seed"`
+	RandomSeed bool   `json:"random_seed"`
+}
+
+// bindRegenerateSeed reads the optional seed body, responding 400 when it's invalid
+func bindRegenerateSeed(c *gin.Context) (regenerateSeedRequest, bool) {
+	var req regenerateSeedRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return req, false
+		}
+	}
+	if req.Seed != nil && *req.Seed < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Seed must not be negative"})
+		return req, false
+	}
+	return req, true
+}
+
+// seed picks the requested seed, else the image's stored one unless a new
+// random seed was asked for
+func (req regenerateSeedRequest) seed(stored *int64) int64 {
+	switch {
+	case req.Seed != nil:
+		return *req.Seed
+	case !req.RandomSeed && stored != nil:
+		return *stored
+	}
+	return image.RandomSeed
+}
+
+// RegenerateSectionImage regenerates the image a lyrics section renders over,
+// chosen by ?section=chorus or ?section=verse&number=2, and returns the
+// updated record once it's done. Sections that share an image (every chorus
+// and the final chorus, every pre-chorus) resolve to the same record. A
+// section without an image record gets one, prompted from its lyrics. The
+// optional body is the same as RegenerateImage's.
+func (h *ImageHandler) RegenerateSectionImage(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	section := strings.ToLower(strings.TrimSpace(c.Query("section")))
+	if section == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "section is required, e.g. ?section=chorus or ?section=verse&number=2"})
+		return
+	}
+	number := 1
+	if raw := c.Query("number"); raw != "" {
+		number, err = strconv.Atoi(raw)
+		if err != nil || number < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "number must be a positive integer"})
+			return
+		}
+	}
+
+	req, ok := bindRegenerateSeed(c)
+	if !ok {
+		return
+	}
+
+	song, err := h.songRepo.GetByID(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	if _, busy := h.regenerating.LoadOrStore(songID, struct{}{}); busy {
+		c.JSON(http.StatusConflict, gin.H{"error": "Images are already being regenerated for this song"})
+		return
+	}
+	defer h.regenerating.Delete(songID)
+
+	filename := image.SectionImageFilename(section, number)
+	sections, sectionLyrics := sectionsUsingImage(song, filename)
+
+	img, err := findSectionImage(songID, filename)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if img == nil {
+		if len(sections) == 0 && !song.Instrumental {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Song has no %s %d section", section, number)})
+			return
+		}
+		if img, err = h.createSectionImage(song, section, number, sectionLyrics); err != nil {
+			log.Printf("Error creating %s image for song %d: %v", filename, songID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create image prompt: " + err.Error()})
+			return
+		}
+	}
+
+	if err := h.regenerateImage(img, req.seed(img.Seed)); err != nil {
+		log.Printf("Error regenerating %s for song %d: %v", filename, songID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to regenerate image: " + err.Error()})
+		return
+	}
+
+	updated, err := database.GetImageByID(img.ID)
+	if err != nil || updated == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Image regenerated but could not be reloaded"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"image":    newImageResponse(*updated),
+		"filename": filename,
+		"sections": sections,
 	})
 }
 
-// regenerateImageAsync regenerates an image in the background
-func (h *ImageHandler) regenerateImageAsync(img *models.GeneratedImage) {
+// sectionsUsingImage lists the song's lyric sections rendered over the named
+// image file (e.g. "chorus 1", "chorus 2", "final-chorus 1") and returns the
+// first one's lyrics for prompting
+func sectionsUsingImage(song *models.Song, filename string) ([]string, string) {
+	sections := []string{}
+	parsed, err := lyrics.ParseLyrics(song.Lyrics)
+	if err != nil {
+		return sections, ""
+	}
+
+	sectionLyrics := ""
+	for _, section := range parsed.Sections {
+		if image.SectionImageFilename(section.Type, section.Number) != filename {
+			continue
+		}
+		sections = append(sections, fmt.Sprintf("%s %d", section.Type, section.Number))
+		if sectionLyrics == "" {
+			sectionLyrics = strings.Join(section.Lines, "\n")
+		}
+	}
+	return sections, sectionLyrics
+}
+
+// findSectionImage returns the song's newest active image record for the
+// named file, matched by stored path or by section type and number
+func findSectionImage(songID int, filename string) (*models.GeneratedImage, error) {
+	images, err := database.GetImagesBySongID(songID)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *models.GeneratedImage
+	for i := range images {
+		img := &images[i]
+		matches := filepath.Base(img.ImagePath) == filename ||
+			image.SectionImageFilename(img.ImageType, img.Sequence()) == filename
+		if matches && (found == nil || img.ID > found.ID) {
+			found = img
+		}
+	}
+	return found, nil
+}
+
+// createSectionImage stores a new image record for a section, with a prompt
+// generated from its lyrics the same way a full image run would
+func (h *ImageHandler) createSectionImage(song *models.Song, section string, number int, sectionLyrics string) (*models.GeneratedImage, error) {
+	imageGen := image.NewImageGenerator("", h.config)
+	imageGen.Genre = song.Genre
+	imageGen.Mood = image.FormatMood(song.Mood)
+	if settings, err := h.settingsRepo.Get(); err != nil {
+		log.Printf("Warning: failed to load settings: %v, using defaults", err)
+	} else {
+		if settings.MasterPrompt != "" {
+			imageGen.MasterPrompt = settings.MasterPrompt
+		}
+		if settings.MasterNegativePrompt != "" {
+			imageGen.MasterNegative = settings.MasterNegativePrompt
+		}
+		imageGen.PromptTemplate = settings.ImagePromptTemplate
+		imageGen.SectionTemplates = settings.ImagePromptSectionTemplates
+	}
+	imageGen.SetMasterPrompts(song.MasterPromptOverride, song.MasterNegativeOverride)
+
+	styleKeywords := image.BuildStyleKeywords(song.Genre, song.BackgroundStyle)
+	prompt, negative, err := imageGen.EnhancePromptWithNegative(section, sectionLyrics, styleKeywords)
+	if err != nil {
+		return nil, err
+	}
+
+	img := &models.GeneratedImage{
+		SongID:         song.ID,
+		Prompt:         prompt,
+		ImageType:      section,
+		SequenceNumber: &number,
+		Width:          imageGen.Width,
+		Height:         imageGen.Height,
+		Model:          imageGen.ImageModel,
+	}
+	if negative != "" {
+		img.NegativePrompt = &negative
+	}
+	if _, err := database.CreateImagePrompt(img); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// applySongImageSettings layers a song's master prompt overrides over the
+// settings already applied to imageGen, and sets its reference image
+func (h *ImageHandler) applySongImageSettings(imageGen *image.ImageGenerator, songID int) {
+	song, err := h.songRepo.GetByID(songID)
+	if err != nil {
+		log.Printf("Warning: failed to load song %d for image settings: %v", songID, err)
+		return
+	}
+	if song != nil {
+		imageGen.SetMasterPrompts(song.MasterPromptOverride, song.MasterNegativeOverride)
+		imageGen.ReferenceImage = song.ReferenceImagePath
+		imageGen.ReferenceStrength = song.ReferenceStrength
+	}
+}
+
+// regenerateImageAsync regenerates an image in the background with the given
+// seed (image.RandomSeed for a new one)
+func (h *ImageHandler) regenerateImageAsync(img *models.GeneratedImage, seed int64) {
+	if err := h.regenerateImage(img, seed); err != nil {
+		log.Printf("Error regenerating image %d: %v", img.ID, err)
+	}
+}
+
+// regenerateImage generates a new file for an image from its stored prompt
+// with the given seed, replacing the old file and updating the record
+func (h *ImageHandler) regenerateImage(img *models.GeneratedImage, seed int64) error {
 	log.Printf("Starting image regeneration for ID %d", img.ID)
 
 	// Load settings for master prompts
@@ -157,7 +568,7 @@ func (h *ImageHandler) regenerateImageAsync(img *models.GeneratedImage) {
 
 	// Setup image generator with the correct output directory
 	outputDir := filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", img.SongID))
-	imageGen := image.NewImageGenerator(outputDir)
+	imageGen := image.NewImageGenerator(outputDir, h.config)
 
 	// Set master prompts from settings if available
 	if settings != nil {
@@ -168,23 +579,16 @@ func (h *ImageHandler) regenerateImageAsync(img *models.GeneratedImage) {
 			imageGen.MasterNegative = settings.MasterNegativePrompt
 		}
 	}
+	h.applySongImageSettings(imageGen, img.SongID)
 
 	// Generate filename based on image type if path is empty
-	var filename string
+	var filename, oldPath string
 	if img.ImagePath != "" && img.ImagePath != "." {
 		filename = filepath.Base(img.ImagePath)
-		// Delete old image file if it exists
-		fullPath := filepath.Join(utils.GetDataPath(), img.ImagePath)
-		if err := os.Remove(fullPath); err != nil {
-			log.Printf("Warning: failed to delete old image file %s: %v", fullPath, err)
-		}
+		oldPath = resolveImagePath(img.ImagePath)
 	} else {
-		// Generate filename from image type, including sequence number only for verses
-		if img.ImageType == "verse" && img.SequenceNumber != nil && *img.SequenceNumber > 0 {
-			filename = fmt.Sprintf("bg-%s-%d.png", img.ImageType, *img.SequenceNumber)
-		} else {
-			filename = fmt.Sprintf("bg-%s.png", img.ImageType)
-		}
+		// Use the file the section renders from, numbered only for verses
+		filename = image.SectionImageFilename(img.ImageType, img.Sequence())
 		log.Printf("No existing image path, using generated filename: %s", filename)
 	}
 
@@ -195,54 +599,476 @@ func (h *ImageHandler) regenerateImageAsync(img *models.GeneratedImage) {
 		negPrompt = *img.NegativePrompt
 		log.Printf("Custom negative prompt: %s", negPrompt)
 	}
-	newPath, err := imageGen.GenerateImageWithNegative(img.Prompt, negPrompt, filename)
+	newPath, usedSeed, err := imageGen.GenerateImageWithSeed(img.Prompt, negPrompt, filename, seed)
 	if err != nil {
-		log.Printf("Error regenerating image: %v", err)
-		return
+		return err
+	}
+
+	if err := database.UpdateImageSeed(img.ID, &usedSeed); err != nil {
+		log.Printf("Error storing image seed: %v", err)
 	}
 
 	log.Printf("Image regenerated successfully: %s", newPath)
 
+	// The new file usually overwrites the old one; only a file stored
+	// elsewhere is left over. It's kept until now so a failed generation
+	// doesn't leave the section without an image.
+	if oldPath != "" && filepath.Clean(oldPath) != filepath.Clean(newPath) {
+		if err := os.Remove(oldPath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to delete old image file %s: %v", oldPath, err)
+		}
+	}
+
+	if err := storage.Mirror(context.Background(), h.storage, utils.GetDataPath(), newPath); err != nil {
+		log.Printf("Warning: failed to upload %s to storage: %v", newPath, err)
+	}
+
 	// Update database with the relative path from data directory
 	dataPath := utils.GetDataPath()
 	relativePath := strings.TrimPrefix(newPath, dataPath+"/")
 	if err := database.UpdateImagePath(img.ID, relativePath); err != nil {
-		log.Printf("Error updating image path in database: %v", err)
-		return
+		return fmt.Errorf("failed to update image path: %w", err)
+	}
+	services.HashImageFile(img.ID, newPath)
+
+	// A regenerated image needs to be approved again
+	if err := database.SetImageApproval(img.ID, false); err != nil {
+		log.Printf("Error resetting image approval: %v", err)
 	}
 
 	log.Printf("Database updated with path: %s", relativePath)
+	return nil
 }
 
-// GeneratePromptFromLyrics generates an image prompt from lyrics using LLM
-func (h *ImageHandler) GeneratePromptFromLyrics(c *gin.Context) {
-	var req struct {
-		Lyrics          string `json:"lyrics"`
-		SectionType     string `json:"section_type"`
-		Genre           string `json:"genre"`
-		BackgroundStyle string `json:"background_style"`
+// Bounds for the number of variants generated per request
+const (
+	defaultVariantCount = 3
+	maxVariantCount     = 6
+)
+
+// variantsDir holds a song's variant images, outside the directory scanned for rendering
+func variantsDir(songID int) string {
+	return filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", songID), "variants")
+}
+
+// GenerateVariants generates alternative candidates for an image from the same
+// prompt with different seeds, stores them as variants of the image and
+// returns them. It blocks until all variants are generated.
+func (h *ImageHandler) GenerateVariants(c *gin.Context) {
+	imageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	count := defaultVariantCount
+	if raw := c.Query("count"); raw != "" {
+		count, err = strconv.Atoi(raw)
+		if err != nil || count < 1 || count > maxVariantCount {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("count must be between 1 and %d", maxVariantCount)})
+			return
+		}
+	}
+
+	img, err := database.GetImageByID(imageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if img == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if img.IsVariant {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Generate variants from the active image, not a variant"})
+		return
+	}
+	if img.Prompt == "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Image has no prompt to generate variants from"})
+		return
 	}
 
+	settings, err := h.settingsRepo.Get()
+	if err != nil {
+		log.Printf("Warning: failed to load settings: %v, using defaults", err)
+	}
+	imageGen := image.NewImageGenerator(variantsDir(img.SongID), h.config)
+	if settings != nil {
+		if settings.MasterPrompt != "" {
+			imageGen.MasterPrompt = settings.MasterPrompt
+		}
+		if settings.MasterNegativePrompt != "" {
+			imageGen.MasterNegative = settings.MasterNegativePrompt
+		}
+	}
+	h.applySongImageSettings(imageGen, img.SongID)
+
+	negPrompt := ""
+	if img.NegativePrompt != nil {
+		negPrompt = *img.NegativePrompt
+	}
+	base := strings.TrimSuffix(filepath.Base(img.ImagePath), filepath.Ext(img.ImagePath))
+	if base == "" || base == "." {
+		base = "bg-" + img.ImageType
+	}
+
+	log.Printf("Generating %d variants of image %d", count, imageID)
+	variants := make([]ImageResponse, 0, count)
+	for i := 0; i < count; i++ {
+		parentID := img.ID
+		variant := &models.GeneratedImage{
+			SongID:         img.SongID,
+			Prompt:         img.Prompt,
+			NegativePrompt: img.NegativePrompt,
+			ImageType:      img.ImageType,
+			SequenceNumber: img.SequenceNumber,
+			Width:          imageGen.Width,
+			Height:         imageGen.Height,
+			Model:          imageGen.ImageModel,
+			ParentImageID:  &parentID,
+			IsVariant:      true,
+		}
+		// The record ID names the file, so create the record first
+		if err := database.CreateGeneratedImage(variant); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		filename := fmt.Sprintf("%s-variant-%d.png", base, variant.ID)
+		newPath, seed, err := imageGen.GenerateImageWithSeed(img.Prompt, negPrompt, filename, image.RandomSeed)
+		if err != nil {
+			log.Printf("Error generating variant %d/%d of image %d: %v", i+1, count, imageID, err)
+			if delErr := database.DeleteImageByID(variant.ID); delErr != nil {
+				log.Printf("Warning: failed to delete variant record %d: %v", variant.ID, delErr)
+			}
+			if len(variants) == 0 {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Failed to generate variants: " + err.Error()})
+				return
+			}
+			break
+		}
+
+		if err := storage.Mirror(context.Background(), h.storage, utils.GetDataPath(), newPath); err != nil {
+			log.Printf("Warning: failed to upload %s to storage: %v", newPath, err)
+		}
+
+		variant.ImagePath = strings.TrimPrefix(newPath, utils.GetDataPath()+"/")
+		variant.Seed = &seed
+		if err := database.UpdateImagePath(variant.ID, variant.ImagePath); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := database.UpdateImageSeed(variant.ID, variant.Seed); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if phash, ok := services.HashImageFile(variant.ID, newPath); ok {
+			variant.PHash = &phash
+		}
+		variants = append(variants, newImageResponse(*variant))
+	}
+
+	c.JSON(http.StatusCreated, variants)
+}
+
+// GetImageSimilarity reports pairs of a song's images that look nearly
+// identical. ?threshold overrides the configured maximum Hamming distance.
+func (h *ImageHandler) GetImageSimilarity(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	threshold := h.config.ImageSimilarityThreshold
+	if threshold < 0 {
+		threshold = image.DefaultSimilarityThreshold
+	}
+	if raw := c.Query("threshold"); raw != "" {
+		threshold, err = strconv.Atoi(raw)
+		if err != nil || threshold < 0 || threshold > 64 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "threshold must be between 0 and 64"})
+			return
+		}
+	}
+
+	report, err := services.BuildImageSimilarityReport(songID, threshold)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetVariants returns the variant candidates generated for an image
+func (h *ImageHandler) GetVariants(c *gin.Context) {
+	imageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	variants, err := database.GetImageVariants(imageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := make([]ImageResponse, 0, len(variants))
+	for _, variant := range variants {
+		response = append(response, newImageResponse(variant))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SelectVariant promotes a variant to the active image used for rendering. The
+// image files and their prompts/seeds are swapped, so the previously active
+// image stays available as a variant.
+func (h *ImageHandler) SelectVariant(c *gin.Context) {
+	imageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+	variantID, err := strconv.Atoi(c.Param("variantId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid variant ID"})
+		return
+	}
+
+	active, err := database.GetImageByID(imageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	variant, err := database.GetImageByID(variantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if active == nil || variant == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if !variant.IsVariant || variant.ParentImageID == nil || *variant.ParentImageID != active.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Image is not a variant of this image"})
+		return
+	}
+	if active.ImagePath == "" || variant.ImagePath == "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Both images must be generated before swapping"})
+		return
+	}
+
+	activePath := resolveImagePath(active.ImagePath)
+	variantPath := resolveImagePath(variant.ImagePath)
+	roots := artifactRoots(h.config)
+	if !pathWithinRoots(activePath, roots) || !pathWithinRoots(variantPath, roots) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Image path is outside the data directory"})
+		return
+	}
+
+	// Swap the files through a temporary name so neither is lost on failure
+	tmpPath := activePath + ".swap"
+	if err := os.Rename(activePath, tmpPath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to swap image files: " + err.Error()})
+		return
+	}
+	if err := os.Rename(variantPath, activePath); err != nil {
+		os.Rename(tmpPath, activePath)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to swap image files: " + err.Error()})
+		return
+	}
+	if err := os.Rename(tmpPath, variantPath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to swap image files: " + err.Error()})
+		return
+	}
+
+	if err := database.SwapImageVariant(active, variant); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, path := range []string{activePath, variantPath} {
+		if err := storage.Mirror(context.Background(), h.storage, utils.GetDataPath(), path); err != nil {
+			log.Printf("Warning: failed to upload %s to storage: %v", path, err)
+		}
+	}
+
+	updated, err := database.GetImageByID(imageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newImageResponse(*updated))
+}
+
+// DescribeImage asks the vision model to describe a generated image and stores
+// the result on the image record as alt-text
+func (h *ImageHandler) DescribeImage(c *gin.Context) {
+	imageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	img, err := database.GetImageByID(imageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if img == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if img.ImagePath == "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Image has not been generated yet"})
+		return
+	}
+
+	path := resolveImagePath(img.ImagePath)
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image file not found"})
+		return
+	}
+
+	imageGen := image.NewImageGenerator("", h.config)
+	description, err := imageGen.DescribeImage(path)
+	if err != nil {
+		log.Printf("Vision description failed for image %d: %v", imageID, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Vision model unavailable: " + err.Error()})
+		return
+	}
+
+	if err := database.UpdateImageDescription(imageID, description); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          imageID,
+		"description": description,
+		"prompt":      img.Prompt,
+	})
+}
+
+// ApproveImage approves a single image and resumes the song's render once all images are approved
+func (h *ImageHandler) ApproveImage(c *gin.Context) {
+	imageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid image ID"})
+		return
+	}
+
+	img, err := database.GetImageByID(imageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if img == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+
+	if err := database.SetImageApproval(imageID, true); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	unapproved, err := database.CountUnapprovedImages(img.SongID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Resume any paused renders once every image is approved
+	var resumed int64
+	if unapproved == 0 {
+		resumed, err = h.queueRepo.ResumeAwaitingApproval(img.SongID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Image approved",
+		"image_id":            imageID,
+		"remaining":           unapproved,
+		"queue_items_resumed": resumed,
+	})
+}
+
+// ApproveSongImages approves all images for a song and resumes any paused renders
+func (h *ImageHandler) ApproveSongImages(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	approved, err := database.ApproveImagesBySongID(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	resumed, err := h.queueRepo.ResumeAwaitingApproval(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":             "Images approved",
+		"song_id":             songID,
+		"images_approved":     approved,
+		"queue_items_resumed": resumed,
+	})
+}
+
+// promptRequest describes the section an image prompt is generated for
+type promptRequest struct {
+	Lyrics          string `json:"lyrics"`
+	SectionType     string `json:"section_type"`
+	Genre           string `json:"genre"`
+	Mood            string `json:"mood"`
+	BackgroundStyle string `json:"background_style"`
+	SongID          int    `json:"song_id"` // Optional; applies the song's master prompt overrides
+}
+
+// bindPromptRequest reads a promptRequest, responding with 400 when it's
+// invalid or has no lyrics
+func bindPromptRequest(c *gin.Context) (promptRequest, bool) {
+	var req promptRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return req, false
 	}
 
 	if req.Lyrics == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Lyrics text is required"})
-		return
+		return req, false
 	}
+	return req, true
+}
 
+// promptGenerator creates an image generator for prompt work only, set up
+// with the master prompts and templates from settings and the request's song
+func (h *ImageHandler) promptGenerator(req promptRequest) *image.ImageGenerator {
 	// Load settings for master prompts
 	settings, err := h.settingsRepo.Get()
 	if err != nil {
 		log.Printf("Warning: failed to load settings: %v, using defaults", err)
 	}
 
-	// Create temporary image generator just for prompt enhancement
-	imageGen := image.NewImageGenerator("")
+	imageGen := image.NewImageGenerator("", h.config)
+	imageGen.Genre = req.Genre
+	imageGen.Mood = req.Mood
 
-	// Set master prompts from settings if available
+	// Set master prompts and prompt templates from settings if available
 	if settings != nil {
 		if settings.MasterPrompt != "" {
 			imageGen.MasterPrompt = settings.MasterPrompt
@@ -250,14 +1076,28 @@ func (h *ImageHandler) GeneratePromptFromLyrics(c *gin.Context) {
 		if settings.MasterNegativePrompt != "" {
 			imageGen.MasterNegative = settings.MasterNegativePrompt
 		}
+		imageGen.PromptTemplate = settings.ImagePromptTemplate
+		imageGen.SectionTemplates = settings.ImagePromptSectionTemplates
+	}
+	if req.SongID > 0 {
+		h.applySongImageSettings(imageGen, req.SongID)
+	}
+	return imageGen
+}
+
+// GeneratePromptFromLyrics generates an image prompt from lyrics using LLM
+func (h *ImageHandler) GeneratePromptFromLyrics(c *gin.Context) {
+	req, ok := bindPromptRequest(c)
+	if !ok {
+		return
 	}
 
-	// Build style keywords
+	imageGen := h.promptGenerator(req)
 	styleKeywords := image.BuildStyleKeywords(req.Genre, req.BackgroundStyle)
 
 	// Use the LLM to enhance the prompt based on lyrics
 	log.Printf("Generating prompt for %s section from lyrics", req.SectionType)
-	enhancedPrompt, promptErr := imageGen.EnhancePromptWithLLM(req.SectionType, req.Lyrics, styleKeywords)
+	enhancedPrompt, negativePrompt, promptErr := imageGen.EnhancePromptWithNegative(req.SectionType, req.Lyrics, styleKeywords)
 	if promptErr != nil {
 		log.Printf("Error generating prompt: %v", promptErr)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate prompt: " + promptErr.Error()})
@@ -265,7 +1105,31 @@ func (h *ImageHandler) GeneratePromptFromLyrics(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"prompt":          enhancedPrompt,
-		"negative_prompt": "",
+		"prompt":                 enhancedPrompt,
+		"negative_prompt":        negativePrompt,
+		"master_negative_prompt": imageGen.MasterNegativePrompt(),
+	})
+}
+
+// PreviewPrompt returns the LLM prompt GeneratePromptFromLyrics would send for
+// the same request, and the negative prompt the image would start from,
+// without calling the LLM. The LLM may add scene-specific negatives on top.
+func (h *ImageHandler) PreviewPrompt(c *gin.Context) {
+	req, ok := bindPromptRequest(c)
+	if !ok {
+		return
+	}
+
+	imageGen := h.promptGenerator(req)
+	styleKeywords := image.BuildStyleKeywords(req.Genre, req.BackgroundStyle)
+	userPrompt := imageGen.BuildUserPrompt(req.SectionType, req.Lyrics, styleKeywords)
+
+	c.JSON(http.StatusOK, gin.H{
+		"prompt":          image.LLMPrompt(userPrompt),
+		"system_prompt":   image.IMAGE_PROMPT_SYSTEM,
+		"user_prompt":     userPrompt,
+		"style_keywords":  styleKeywords,
+		"negative_prompt": imageGen.MasterNegativePrompt(),
+		"llm_model":       imageGen.LLMModel,
 	})
 }
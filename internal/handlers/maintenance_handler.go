@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/image"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceHandler handles storage maintenance requests
+type MaintenanceHandler struct {
+	songRepo *database.SongRepository
+	config   *config.Config
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(songRepo *database.SongRepository, cfg *config.Config) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		songRepo: songRepo,
+		config:   cfg,
+	}
+}
+
+// OrphanedArtifact is a per-song directory whose song no longer exists
+type OrphanedArtifact struct {
+	Path    string `json:"path"`
+	SongID  int    `json:"song_id"`
+	Removed bool   `json:"removed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// GarbageCollect scans the image, audio and log directories for per-song
+// directories with no matching song row and removes them.
+// Pass dry_run=true to only report what would be removed.
+func (h *MaintenanceHandler) GarbageCollect(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	songIDs, err := h.songRepo.GetAllIDs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	scans := []struct {
+		dir    string
+		prefix string
+	}{
+		{utils.GetImagesPath(), "song_"},
+		{utils.GetAudioPath(), "song_"},
+		{h.config.LogsPath, ""},
+	}
+
+	roots := artifactRoots(h.config)
+	orphans := []OrphanedArtifact{}
+	for _, scan := range scans {
+		entries, err := os.ReadDir(scan.dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Printf("GC: failed to read %s: %v", scan.dir, err)
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || !strings.HasPrefix(entry.Name(), scan.prefix) {
+				continue
+			}
+			songID, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), scan.prefix))
+			if err != nil || songIDs[songID] {
+				continue
+			}
+
+			orphan := OrphanedArtifact{
+				Path:   filepath.Join(scan.dir, entry.Name()),
+				SongID: songID,
+			}
+			if !dryRun {
+				if err := removeArtifact(orphan.Path, roots); err != nil {
+					orphan.Error = err.Error()
+				} else {
+					orphan.Removed = true
+				}
+			}
+			orphans = append(orphans, orphan)
+		}
+	}
+
+	log.Printf("GC: found %d orphaned directories (dry run: %v)", len(orphans), dryRun)
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": dryRun,
+		"orphans": orphans,
+		"count":   len(orphans),
+	})
+}
+
+// KeptTempDir is a per-job directory of preserved render intermediates
+type KeptTempDir struct {
+	Path     string    `json:"path"`
+	Modified time.Time `json:"modified"`
+	Removed  bool      `json:"removed"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// PurgeTempFiles removes per-job intermediate directories kept by
+// KEEP_TEMP_FILES that are older than older_than (default 24h).
+// Pass dry_run=true to only report what would be removed.
+func (h *MaintenanceHandler) PurgeTempFiles(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	olderThan := 24 * time.Hour
+	if raw := c.Query("older_than"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid older_than duration (e.g. 24h, 30m)"})
+			return
+		}
+		olderThan = d
+	}
+
+	tempDir := filepath.Join(utils.GetVideosPath(), "temp")
+	entries, err := os.ReadDir(tempDir)
+	if err != nil && !os.IsNotExist(err) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	roots := artifactRoots(h.config)
+	purged := []KeptTempDir{}
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), video.KeptTempPrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		dir := KeptTempDir{
+			Path:     filepath.Join(tempDir, entry.Name()),
+			Modified: info.ModTime(),
+		}
+		if !dryRun {
+			if err := removeArtifact(dir.Path, roots); err != nil {
+				dir.Error = err.Error()
+			} else {
+				dir.Removed = true
+			}
+		}
+		purged = append(purged, dir)
+	}
+
+	log.Printf("Temp purge: found %d kept job directories older than %s (dry run: %v)", len(purged), olderThan, dryRun)
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":    dryRun,
+		"older_than": olderThan.String(),
+		"dirs":       purged,
+		"count":      len(purged),
+	})
+}
+
+// ReconcileImages compares every song's image rows with the files in its
+// image directory and repairs the differences: rows whose file is gone are
+// repointed to a same-named file or cleared for regeneration, and files with
+// no row are adopted with a prompt from the vision model. Only songs with
+// discrepancies are listed. Pass dry_run=true to only report them.
+func (h *MaintenanceHandler) ReconcileImages(c *gin.Context) {
+	dryRun := c.Query("dry_run") == "true"
+
+	songIDs, err := h.songRepo.GetAllIDs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ids := make([]int, 0, len(songIDs))
+	for id := range songIDs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	songs := []*services.ImageReconciliation{}
+	missing, orphans := 0, 0
+	for _, songID := range ids {
+		imageGen := image.NewImageGenerator(services.SongImagesDir(songID), h.config)
+		result, err := services.ReconcileSongImages(songID, imageGen, !dryRun)
+		if err != nil {
+			log.Printf("Image reconcile: %v", err)
+			continue
+		}
+		if result.Clean() {
+			continue
+		}
+		missing += len(result.MissingFiles)
+		orphans += len(result.OrphanFiles)
+		songs = append(songs, result)
+	}
+
+	log.Printf("Image reconcile: %d rows missing files and %d files missing rows across %d songs (dry run: %v)",
+		missing, orphans, len(songs), dryRun)
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run":       dryRun,
+		"songs_scanned": len(ids),
+		"missing_files": missing,
+		"orphan_files":  orphans,
+		"songs":         songs,
+	})
+}
+
+// artifactRoots returns the directories under which artifacts may be deleted
+func artifactRoots(cfg *config.Config) []string {
+	return []string{utils.GetDataPath(), cfg.StoragePath}
+}
+
+// songArtifactPaths returns the convention-based directories and files for a song
+func songArtifactPaths(cfg *config.Config, songID int) []string {
+	return []string{
+		filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", songID)),
+		utils.GetSongAudioDir(songID),
+		filepath.Join(cfg.LogsPath, fmt.Sprintf("%d", songID)),
+		filepath.Join(utils.GetTempPath(), fmt.Sprintf("song_%d_karaoke.ass", songID)),
+		filepath.Join(utils.GetTempPath(), fmt.Sprintf("song_%d_timestamps.json", songID)),
+	}
+}
+
+// removeArtifacts deletes each existing path with removeArtifact, returning
+// the paths removed and any errors
+func removeArtifacts(paths []string, roots []string) ([]string, []string) {
+	removed := []string{}
+	var errs []string
+	for _, path := range paths {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			continue
+		}
+		if err := removeArtifact(path, roots); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		removed = append(removed, path)
+	}
+	return removed, errs
+}
+
+// removeArtifact deletes a file or directory, refusing anything outside roots
+// or a root itself. Missing paths are not an error.
+func removeArtifact(path string, roots []string) error {
+	if path == "" {
+		return nil
+	}
+	if !pathWithinRoots(path, roots) {
+		log.Printf("Refusing to remove %s: outside data paths", path)
+		return fmt.Errorf("refusing to remove %s: outside data paths", path)
+	}
+	for _, root := range roots {
+		if root != "" && filepath.Clean(root) == filepath.Clean(path) {
+			return fmt.Errorf("refusing to remove data root %s", path)
+		}
+	}
+
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.RemoveAll(path); err != nil {
+		log.Printf("Failed to remove %s: %v", path, err)
+		return err
+	}
+	log.Printf("Removed %s", path)
+	return nil
+}
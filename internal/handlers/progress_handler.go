@@ -3,6 +3,8 @@ package handlers
 import (
 	"io"
 	"log"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
@@ -24,16 +26,26 @@ func NewProgressHandler(broadcaster *services.ProgressBroadcaster, queueRepo *da
 	}
 }
 
-// StreamProgress streams progress updates via Server-Sent Events
+// lastEventID returns the ID of the last event a reconnecting SSE client saw,
+// sent by browsers as the Last-Event-ID header, or 0 for a new client
+func lastEventID(c *gin.Context) int64 {
+	id, err := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// StreamProgress streams progress updates via Server-Sent Events. Clients
+// reconnecting with Last-Event-ID are sent the updates they missed.
 func (h *ProgressHandler) StreamProgress(c *gin.Context) {
 	// Set headers for SSE
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
 
-	// Subscribe to progress updates
-	clientChan := h.broadcaster.Subscribe()
+	// Subscribe to progress updates, resuming after the client's last event
+	clientChan := h.broadcaster.Subscribe(lastEventID(c))
 	defer h.broadcaster.Unsubscribe(clientChan)
 
 	// Create a channel for client disconnect
@@ -49,7 +61,11 @@ func (h *ProgressHandler) StreamProgress(c *gin.Context) {
 		case <-clientGone:
 			log.Println("Client disconnected from progress stream")
 			return
-		case update := <-clientChan:
+		case update, ok := <-clientChan:
+			if !ok {
+				// Dropped by the broadcaster for falling too far behind
+				return
+			}
 			// Format and send SSE event
 			data := services.FormatSSE(update)
 			if data != "" {
@@ -73,15 +89,19 @@ func (h *ProgressHandler) StreamProgress(c *gin.Context) {
 // StreamQueueProgress streams progress for a specific queue item
 func (h *ProgressHandler) StreamQueueProgress(c *gin.Context) {
 	queueID := c.Param("id")
+	id, err := strconv.Atoi(queueID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid queue ID"})
+		return
+	}
 
 	// Set headers for SSE
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
-	c.Header("Access-Control-Allow-Origin", "*")
 
-	// Subscribe to progress updates
-	clientChan := h.broadcaster.Subscribe()
+	// Subscribe to progress updates, resuming after the client's last event
+	clientChan := h.broadcaster.Subscribe(lastEventID(c))
 	defer h.broadcaster.Unsubscribe(clientChan)
 
 	// Create a channel for client disconnect
@@ -97,9 +117,13 @@ func (h *ProgressHandler) StreamQueueProgress(c *gin.Context) {
 		case <-clientGone:
 			log.Printf("Client disconnected from queue %s progress stream", queueID)
 			return
-		case update := <-clientChan:
+		case update, ok := <-clientChan:
+			if !ok {
+				// Dropped by the broadcaster for falling too far behind
+				return
+			}
 			// Only send updates for this specific queue item
-			if update.QueueID == 0 || c.Param("id") == string(rune(update.QueueID)) {
+			if update.QueueID == 0 || update.QueueID == id {
 				data := services.FormatSSE(update)
 				if data != "" {
 					_, err := c.Writer.Write([]byte(data))
@@ -120,10 +144,37 @@ func (h *ProgressHandler) StreamQueueProgress(c *gin.Context) {
 	}
 }
 
+// GetSnapshot returns the latest known progress of every queue item, so a
+// dashboard can render current state before the next SSE event arrives
+func (h *ProgressHandler) GetSnapshot(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"updates":   h.broadcaster.Snapshot(),
+		"timestamp": time.Now(),
+	})
+}
+
+// GetQueueSnapshot returns the latest known progress of one queue item
+func (h *ProgressHandler) GetQueueSnapshot(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid queue ID"})
+		return
+	}
+
+	update, ok := h.broadcaster.SnapshotFor(id)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No progress recorded for queue item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, update)
+}
+
 // GetStats returns broadcaster statistics
 func (h *ProgressHandler) GetStats(c *gin.Context) {
 	c.JSON(200, gin.H{
 		"connected_clients": h.broadcaster.ClientCount(),
+		"clients":           h.broadcaster.ClientStats(),
 		"timestamp":         time.Now(),
 	})
 }
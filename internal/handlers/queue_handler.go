@@ -1,26 +1,39 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/middleware"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
+// draftPriority puts draft renders ahead of normally queued songs
+const draftPriority = 100
+
+// WorkNotifier is woken when new work is queued (implemented by the queue worker)
+type WorkNotifier interface {
+	Notify()
+}
+
 // QueueHandler handles queue-related requests
 type QueueHandler struct {
 	repo        *database.QueueRepository
 	broadcaster *services.ProgressBroadcaster
+	notifier    WorkNotifier
 }
 
 // NewQueueHandler creates a new queue handler
-func NewQueueHandler(repo *database.QueueRepository, broadcaster *services.ProgressBroadcaster) *QueueHandler {
+func NewQueueHandler(repo *database.QueueRepository, broadcaster *services.ProgressBroadcaster, notifier WorkNotifier) *QueueHandler {
 	return &QueueHandler{
 		repo:        repo,
 		broadcaster: broadcaster,
+		notifier:    notifier,
 	}
 }
 
@@ -57,11 +70,76 @@ func (h *QueueHandler) GetByID(c *gin.Context) {
 	c.JSON(http.StatusOK, item)
 }
 
-// Create adds a song to the queue
+// History returns a queue item's timeline of status changes and phase
+// starts, each with how long it lasted
+func (h *QueueHandler) History(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	item, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Queue item not found"})
+		return
+	}
+
+	events, err := h.repo.GetEvents(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queue_id": id,
+		"status":   item.Status,
+		"events":   events,
+	})
+}
+
+// Logs returns a queue item's structured processing logs: one entry per
+// finished phase with its status and duration
+func (h *QueueHandler) Logs(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	item, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Queue item not found"})
+		return
+	}
+
+	entries, err := database.NewProcessingLogRepository(database.DB).GetByQueueID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"queue_id": id,
+		"logs":     entries,
+	})
+}
+
+// Create adds a song to the queue. An optional scheduled_at (RFC 3339) holds
+// the item until that time, e.g. to render overnight.
 func (h *QueueHandler) Create(c *gin.Context) {
 	var req struct {
-		SongID   int `json:"song_id" binding:"required"`
-		Priority int `json:"priority"`
+		SongID      int        `json:"song_id" binding:"required"`
+		Priority    int        `json:"priority"`
+		ScheduledAt *time.Time `json:"scheduled_at"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -70,9 +148,59 @@ func (h *QueueHandler) Create(c *gin.Context) {
 	}
 
 	item := &models.QueueItem{
-		SongID:   req.SongID,
-		Status:   models.StatusQueued,
-		Priority: req.Priority,
+		SongID:      req.SongID,
+		Status:      models.StatusQueued,
+		Priority:    req.Priority,
+		ScheduledAt: scheduledUTC(req.ScheduledAt),
+		RequestID:   middleware.GetRequestID(c),
+	}
+
+	if err := h.repo.Create(item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Scheduled items wait for the worker's poll to find them once due
+	if item.ScheduledAt != nil && item.ScheduledAt.After(time.Now()) {
+		scheduled := item.ScheduledAt.Format(time.RFC3339)
+		middleware.Logf(c, "Queued song %d as queue item %d, scheduled for %s", item.SongID, item.ID, scheduled)
+		h.broadcaster.BroadcastFromQueueItem(item, "Queue item scheduled for "+scheduled)
+	} else {
+		middleware.Logf(c, "Queued song %d as queue item %d", item.SongID, item.ID)
+
+		// Broadcast queue item creation and start it without waiting for the next poll
+		h.broadcaster.BroadcastFromQueueItem(item, "Queue item created")
+		h.notifier.Notify()
+	}
+
+	c.JSON(http.StatusCreated, item)
+}
+
+// scheduledUTC converts a scheduled start time to UTC, the form the queue
+// compares against when picking the next item
+func scheduledUTC(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// DraftRender queues a low-resolution preview render of a song. Drafts jump
+// ahead of normal renders and never replace the song's final video.
+func (h *QueueHandler) DraftRender(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	item := &models.QueueItem{
+		SongID:    songID,
+		Status:    models.StatusQueued,
+		Priority:  draftPriority,
+		Draft:     true,
+		RequestID: middleware.GetRequestID(c),
 	}
 
 	if err := h.repo.Create(item); err != nil {
@@ -80,8 +208,10 @@ func (h *QueueHandler) Create(c *gin.Context) {
 		return
 	}
 
-	// Broadcast queue item creation
-	h.broadcaster.BroadcastFromQueueItem(item, "Queue item created")
+	middleware.Logf(c, "Queued draft render of song %d as queue item %d", item.SongID, item.ID)
+
+	h.broadcaster.BroadcastFromQueueItem(item, "Draft render queued")
+	h.notifier.Notify()
 
 	c.JSON(http.StatusCreated, item)
 }
@@ -94,20 +224,47 @@ func (h *QueueHandler) Update(c *gin.Context) {
 		return
 	}
 
-	var item models.QueueItem
-	if err := c.ShouldBindJSON(&item); err != nil {
+	// last_phase is the resume point; a body without it keeps the stored one
+	// so re-queueing a failed item resumes rather than starting over
+	var req struct {
+		models.QueueItem
+		LastPhase *string `json:"last_phase"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	existing, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	item := req.QueueItem
 	item.ID = id
+	if req.LastPhase != nil {
+		item.LastPhase = *req.LastPhase
+	} else if existing != nil {
+		item.LastPhase = existing.LastPhase
+	}
+	item.ScheduledAt = scheduledUTC(item.ScheduledAt)
 	if err := h.repo.Update(&item); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if existing != nil && existing.Status != item.Status {
+		if err := h.repo.AddEvent(&item, "Status changed via API"); err != nil {
+			middleware.Logf(c, "Warning: failed to record history for queue item %d: %v", id, err)
+		}
+	}
+
 	// Broadcast queue item update
 	h.broadcaster.BroadcastFromQueueItem(&item, "Queue item updated")
+	if item.Status == models.StatusQueued {
+		h.notifier.Notify()
+	}
 
 	c.JSON(http.StatusOK, item)
 }
@@ -138,12 +295,70 @@ func (h *QueueHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	// Broadcast cancellation
+	// Broadcast cancellation; the item is gone, so late joiners don't need its snapshot
 	h.broadcaster.BroadcastFromQueueItem(item, "Queue item cancelled")
+	h.broadcaster.ForgetSnapshots(id)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Queue item deleted"})
 }
 
+// Clear removes every queue item with the given status (default queued, or
+// "all"). Processing items are only removed with force=true, which cancels
+// the running job before its next phase.
+func (h *QueueHandler) Clear(c *gin.Context) {
+	status := c.DefaultQuery("status", models.StatusQueued)
+	force := c.Query("force") == "true"
+
+	var statuses []string
+	switch status {
+	case "all":
+		statuses = []string{
+			models.StatusQueued,
+			models.StatusCompleted,
+			models.StatusFailed,
+			models.StatusRetrying,
+			models.StatusAwaitingApproval,
+		}
+		if force {
+			statuses = append(statuses, models.StatusProcessing)
+		}
+	case models.StatusProcessing:
+		if !force {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Processing items can only be cleared with force=true"})
+			return
+		}
+		statuses = []string{status}
+	case models.StatusQueued, models.StatusCompleted, models.StatusFailed,
+		models.StatusRetrying, models.StatusAwaitingApproval:
+		statuses = []string{status}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+
+	deletedIDs, err := h.repo.DeleteByStatus(statuses)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	deleted := len(deletedIDs)
+	middleware.Logf(c, "Cleared %d queue item(s) with status %s (force=%t)", deleted, status, force)
+
+	// One bulk event instead of an update per item
+	h.broadcaster.ForgetSnapshots(deletedIDs...)
+	h.broadcaster.Broadcast(services.ProgressUpdate{
+		Status:      status,
+		CurrentStep: "Queue cleared",
+		Message:     fmt.Sprintf("%d queue item(s) cleared", deleted),
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"deleted": deleted,
+		"status":  status,
+		"force":   force,
+	})
+}
+
 // GetNext returns the next pending queue item
 func (h *QueueHandler) GetNext(c *gin.Context) {
 	item, err := h.repo.GetNextPending()
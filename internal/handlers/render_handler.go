@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
+	"github.com/gin-gonic/gin"
+)
+
+// RenderHandler handles render configuration requests
+type RenderHandler struct{}
+
+// NewRenderHandler creates a new render handler
+func NewRenderHandler() *RenderHandler {
+	return &RenderHandler{}
+}
+
+// Options returns the spectrum styles, colors and positions, transitions,
+// resolutions and quality presets the renderer supports, so clients can
+// offer them without hardcoding lists
+func (h *RenderHandler) Options(c *gin.Context) {
+	c.JSON(http.StatusOK, video.AvailableRenderOptions())
+}
@@ -1,14 +1,11 @@
 package handlers
 
 import (
-	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
-	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
 	"github.com/gin-gonic/gin"
 )
 
@@ -33,85 +30,64 @@ func (h *SettingsHandler) Get(c *gin.Context) {
 	c.JSON(http.StatusOK, settings)
 }
 
-// Update updates the application settings
-func (h *SettingsHandler) Update(c *gin.Context) {
-	var settings models.Settings
-	if err := c.ShouldBindJSON(&settings); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Force ID to 1 (singleton settings)
-	settings.ID = 1
-
-	if err := h.repo.Update(&settings); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	// Return updated settings
-	updated, err := h.repo.Get()
+// Genres returns the genres the enrichment LLM may choose a primary genre from
+func (h *SettingsHandler) Genres(c *gin.Context) {
+	settings, err := h.repo.Get()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, updated)
+	c.JSON(http.StatusOK, gin.H{"genres": settings.Genres()})
 }
 
-// UploadLogo handles brand logo uploads
-func (h *SettingsHandler) UploadLogo(c *gin.Context) {
-	// Create branding directory
-	brandingDir := filepath.Join(utils.GetDataPath(), "branding")
-	if err := os.MkdirAll(brandingDir, 0755); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create branding directory: " + err.Error()})
+// Update updates the application settings
+func (h *SettingsHandler) Update(c *gin.Context) {
+	var settings models.Settings
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get uploaded file
-	file, header, err := c.Request.FormFile("logo")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
-		return
+	// Song defaults are checked like the song fields they fill in
+	for _, err := range []error{
+		validateRange("default_spectrum_opacity", settings.DefaultSpectrumOpacity, 0, 1),
+		validateSpectrumColor("default_spectrum_color", settings.DefaultSpectrumColor),
+		validateOneOf("default_target_resolution", settings.DefaultTargetResolution, video.TargetResolutions),
+		validateOneOf("default_lyric_theme", settings.DefaultLyricTheme, video.LyricThemes),
+		validateOneOf("default_quality", settings.DefaultQuality, video.Qualities),
+	} {
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 	}
-	defer file.Close()
 
-	// Validate file type
-	ext := filepath.Ext(header.Filename)
-	if ext != ".png" && ext != ".jpg" && ext != ".jpeg" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Only PNG and JPG files are allowed"})
-		return
+	logo := video.LogoConfig{
+		Size:    settings.LogoSize,
+		Opacity: settings.LogoOpacity,
+		Corner:  settings.LogoCorner,
+		Margin:  settings.LogoMargin,
 	}
-
-	// Save as artist-logo.png (overwrite existing)
-	logoPath := filepath.Join(brandingDir, "artist-logo.png")
-	destFile, err := os.Create(logoPath)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save logo: " + err.Error()})
+	if err := logo.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer destFile.Close()
 
-	if _, err := io.Copy(destFile, file); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write logo: " + err.Error()})
-		return
-	}
+	// Force ID to 1 (singleton settings)
+	settings.ID = 1
 
-	// Update settings with logo path
-	settings, err := h.repo.Get()
-	if err != nil {
+	if err := h.repo.Update(&settings); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	settings.BrandLogoPath = "branding/artist-logo.png"
-	if err := h.repo.Update(settings); err != nil {
+	// Return updated settings
+	updated, err := h.repo.Get()
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Logo uploaded successfully",
-		"path":    settings.BrandLogoPath,
-	})
+	c.JSON(http.StatusOK, updated)
 }
@@ -1,30 +1,59 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/config"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/lyrics"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
 	"github.com/gin-gonic/gin"
 )
 
 // SongHandler handles song-related requests
 type SongHandler struct {
-	repo   *database.SongRepository
-	config *config.Config
+	repo      *database.SongRepository
+	config    *config.Config
+	previewer OverlayPreviewer
+	subtitles SubtitleRebuilder
 }
 
+// OverlayPreviewer renders a still frame of a song's overlays (implemented by the queue worker)
+type OverlayPreviewer interface {
+	RenderOverlayPreview(song *models.Song) (string, error)
+}
+
+// SubtitleRebuilder regenerates a song's karaoke subtitles from cached
+// timestamps (implemented by the queue worker)
+type SubtitleRebuilder interface {
+	RebuildKaraokeSubtitles(song *models.Song) (string, error)
+}
+
+// maxKaraokeTimingOffset bounds the karaoke offset; anything larger is a
+// wrong audio file rather than a sync problem
+const maxKaraokeTimingOffset = 10.0
+
 // NewSongHandler creates a new song handler
-func NewSongHandler(repo *database.SongRepository, cfg *config.Config) *SongHandler {
+func NewSongHandler(repo *database.SongRepository, cfg *config.Config, previewer OverlayPreviewer, subtitles SubtitleRebuilder) *SongHandler {
 	return &SongHandler{
-		repo:   repo,
-		config: cfg,
+		repo:      repo,
+		config:    cfg,
+		previewer: previewer,
+		subtitles: subtitles,
 	}
 }
 
@@ -69,6 +98,19 @@ func (h *SongHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if fieldErrors := songFieldErrors(&song); fieldErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Invalid song settings",
+			"fields": fieldErrors,
+		})
+		return
+	}
+
+	song.GenreSource = ""
+	if song.Genre != "" {
+		song.GenreSource = models.GenreSourceUser
+	}
+
 	if err := h.repo.Create(&song); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -91,6 +133,33 @@ func (h *SongHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if fieldErrors := songFieldErrors(&song); fieldErrors != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Invalid song settings",
+			"fields": fieldErrors,
+		})
+		return
+	}
+
+	existing, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	// A genre the client changed is the user's; otherwise it keeps its source
+	song.GenreSource = existing.GenreSource
+	if song.Genre != existing.Genre {
+		song.GenreSource = ""
+		if song.Genre != "" {
+			song.GenreSource = models.GenreSourceUser
+		}
+	}
+
 	song.ID = id
 	if err := h.repo.Update(&song); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -100,7 +169,293 @@ func (h *SongHandler) Update(c *gin.Context) {
 	c.JSON(http.StatusOK, song)
 }
 
+// songFieldErrors checks a song's render and karaoke settings, returning
+// errors keyed by JSON field, or nil when they're all valid. Zero and empty
+// values are valid since the renderer replaces them with its defaults; out of
+// range values, which it would also silently replace, are rejected.
+func songFieldErrors(song *models.Song) map[string]string {
+	errs := make(map[string]string)
+	check := func(field string, err error) {
+		if err != nil {
+			errs[field] = err.Error()
+		}
+	}
+
+	if song.VocalOnsetOverride != nil {
+		check("vocal_onset_override", validateNotNegative("vocal_onset_override", *song.VocalOnsetOverride))
+	}
+
+	// Video
+	if song.SpectrumStyle != "" && !video.IsSpectrumStyle(song.SpectrumStyle) {
+		errs["spectrum_style"] = "spectrum_style must be one of: " + strings.Join(spectrumStyleNames(), ", ")
+	}
+	check("spectrum_color", validateSpectrumColor("spectrum_color", song.SpectrumColor))
+	check("spectrum_opacity", validateRange("spectrum_opacity", song.SpectrumOpacity, 0, 1))
+	check("spectrum_position", video.ValidateSpectrumLayout(song.SpectrumPosition, 0))
+	check("spectrum_height", video.ValidateSpectrumLayout("", song.SpectrumHeight))
+	check("target_resolution", validateOneOf("target_resolution", song.TargetResolution, video.TargetResolutions))
+	check("quality", validateOneOf("quality", song.Quality, video.Qualities))
+	check("lyric_theme", validateOneOf("lyric_theme", song.LyricTheme, video.LyricThemes))
+	check("lyric_render_mode", validateOneOf("lyric_render_mode", song.LyricRenderMode, video.LyricRenderModes))
+	check("image_fit", validateOneOf("image_fit", song.ImageFit, video.ImageFits))
+	for _, variant := range song.OutputVariants {
+		if !models.IsValidOutputVariant(variant) {
+			errs["output_variants"] = "output_variants may only contain: " + strings.Join(models.OutputVariants, ", ")
+		}
+	}
+	check("countdown_threshold", validateNotNegative("countdown_threshold", song.CountdownThreshold))
+	check("countdown_bar_width", validateNotNegative("countdown_bar_width", float64(song.CountdownBarWidth)))
+	if song.CountdownColor != "" && !video.IsHexColor(song.CountdownColor) {
+		errs["countdown_color"] = "countdown_color must be a hex color (RRGGBB)"
+	}
+	check("ken_burns_zoom_rate", validateNotNegative("ken_burns_zoom_rate", song.KenBurnsZoomRate))
+	check("ken_burns_direction", validateOneOf("ken_burns_direction", strings.ToLower(strings.TrimSpace(song.KenBurnsDirection)), video.KenBurnsDirections))
+	check("intro_duration", video.ValidateIntroCard(song.IntroDuration, "", ""))
+	check("intro_background_color", video.ValidateIntroCard(0, song.IntroBackgroundColor, ""))
+	check("intro_text_color", video.ValidateIntroCard(0, "", song.IntroTextColor))
+	check("outro_duration", video.ValidateOutroCard("", song.OutroDuration))
+	check("outro_card_path", video.ValidateOutroCard(song.OutroCardPath, 0))
+	check("audio_fade", video.ValidateFade("audio_fade", song.AudioFadeIn, song.AudioFadeOut, song.DurationSeconds))
+	check("video_fade", video.ValidateFade("video_fade", song.VideoFadeIn, song.VideoFadeOut, song.DurationSeconds))
+
+	// Images
+	check("reference_strength", validateRange("reference_strength", song.ReferenceStrength, 0, 1))
+	if song.ReferenceImagePath != "" {
+		if info, err := os.Stat(song.ReferenceImagePath); err != nil || info.IsDir() {
+			errs["reference_image_path"] = "reference_image_path is not a readable file"
+		}
+	}
+
+	// Karaoke
+	check("karaoke_font_size", validateNotNegative("karaoke_font_size", float64(song.KaraokeFontSize)))
+	karaokeColors := map[string]string{
+		"karaoke_primary_color":          song.KaraokePrimaryColor,
+		"karaoke_primary_border_color":   song.KaraokePrimaryBorderColor,
+		"karaoke_highlight_color":        song.KaraokeHighlightColor,
+		"karaoke_highlight_border_color": song.KaraokeHighlightBorderColor,
+	}
+	for field, color := range karaokeColors {
+		if color != "" && !lyrics.IsKaraokeColor(color) {
+			errs[field] = field + " must be a hex color (RRGGBB)"
+		}
+	}
+	check("karaoke_alignment", validateRange("karaoke_alignment", float64(song.KaraokeAlignment), 0, 9))
+	check("karaoke_margin_bottom", validateNotNegative("karaoke_margin_bottom", float64(song.KaraokeMarginBottom)))
+	check("karaoke_whisper_model", validateOneOf("karaoke_whisper_model", strings.ToLower(strings.TrimSpace(song.KaraokeWhisperModel)), lyrics.WhisperModels))
+	check("karaoke_timing_offset", validateRange("karaoke_timing_offset", song.KaraokeTimingOffset, -maxKaraokeTimingOffset, maxKaraokeTimingOffset))
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// spectrumStyleNames lists the spectrum style names, without their aliases
+func spectrumStyleNames() []string {
+	names := make([]string, 0, len(video.SpectrumStyles))
+	for _, style := range video.SpectrumStyles {
+		names = append(names, style.Name)
+	}
+	return names
+}
+
+// validateOneOf checks that a setting is empty or one of the valid choices
+func validateOneOf(field, value string, valid []string) error {
+	if value == "" {
+		return nil
+	}
+	for _, v := range valid {
+		if value == v {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be one of: %s", field, strings.Join(valid, ", "))
+}
+
+// validateSpectrumColor checks that a spectrum color is empty, rainbow or a named color
+func validateSpectrumColor(field, color string) error {
+	return validateOneOf(field, color, video.SpectrumColorNames())
+}
+
+// validateRange checks that a setting is between min and max inclusive
+func validateRange(field string, value, min, max float64) error {
+	if value < min || value > max || math.IsNaN(value) {
+		return fmt.Errorf("%s must be between %g and %g", field, min, max)
+	}
+	return nil
+}
+
+// validateNotNegative checks that a setting isn't negative
+func validateNotNegative(field string, value float64) error {
+	if value < 0 || math.IsNaN(value) {
+		return fmt.Errorf("%s must not be negative", field)
+	}
+	return nil
+}
+
+// songSettingGroups are the settings CopySettings can copy between songs, by
+// the key clients name them with. Content (title, lyrics, audio, analysis,
+// enrichment) is deliberately absent so it can never be overwritten this way.
+var songSettingGroups = map[string]func(dst, src *models.Song){
+	"spectrum": func(dst, src *models.Song) {
+		dst.SpectrumStyle = src.SpectrumStyle
+		dst.SpectrumColor = src.SpectrumColor
+		dst.SpectrumOpacity = src.SpectrumOpacity
+		dst.SpectrumPosition = src.SpectrumPosition
+		dst.SpectrumHeight = src.SpectrumHeight
+	},
+	"karaoke": func(dst, src *models.Song) {
+		dst.KaraokeFontFamily = src.KaraokeFontFamily
+		dst.KaraokeFontSize = src.KaraokeFontSize
+		dst.KaraokePrimaryColor = src.KaraokePrimaryColor
+		dst.KaraokePrimaryBorderColor = src.KaraokePrimaryBorderColor
+		dst.KaraokeHighlightColor = src.KaraokeHighlightColor
+		dst.KaraokeHighlightBorderColor = src.KaraokeHighlightBorderColor
+		dst.KaraokeAlignment = src.KaraokeAlignment
+		dst.KaraokeMarginBottom = src.KaraokeMarginBottom
+		dst.KaraokeWhisperModel = src.KaraokeWhisperModel
+	},
+	"lyric_style": func(dst, src *models.Song) {
+		dst.LyricTheme = src.LyricTheme
+		dst.LyricRenderMode = src.LyricRenderMode
+		dst.EmbedSoftSubtitles = src.EmbedSoftSubtitles
+		dst.SoftSubtitlesOnly = src.SoftSubtitlesOnly
+	},
+	"countdown": func(dst, src *models.Song) {
+		dst.HideCountdown = src.HideCountdown
+		dst.CountdownThreshold = src.CountdownThreshold
+		dst.CountdownBarWidth = src.CountdownBarWidth
+		dst.CountdownColor = src.CountdownColor
+		dst.CountdownText = src.CountdownText
+	},
+	"ken_burns": func(dst, src *models.Song) {
+		dst.EnableKenBurns = src.EnableKenBurns
+		dst.KenBurnsZoomRate = src.KenBurnsZoomRate
+		dst.KenBurnsDirection = src.KenBurnsDirection
+	},
+	"quality": func(dst, src *models.Song) {
+		dst.Quality = src.Quality
+		dst.TargetResolution = src.TargetResolution
+	},
+	"background": func(dst, src *models.Song) {
+		dst.BackgroundStyle = src.BackgroundStyle
+		dst.ImageFit = src.ImageFit
+	},
+	"image_prompts": func(dst, src *models.Song) {
+		dst.MasterPromptOverride = src.MasterPromptOverride
+		dst.MasterNegativeOverride = src.MasterNegativeOverride
+		dst.ReferenceImagePath = src.ReferenceImagePath
+		dst.ReferenceStrength = src.ReferenceStrength
+	},
+	"branding": func(dst, src *models.Song) {
+		dst.BrandLogoPath = src.BrandLogoPath
+		dst.CopyrightText = src.CopyrightText
+		dst.OutroCardPath = src.OutroCardPath
+		dst.OutroDuration = src.OutroDuration
+		dst.IntroCardEnabled = src.IntroCardEnabled
+		dst.IntroDuration = src.IntroDuration
+		dst.IntroBackgroundColor = src.IntroBackgroundColor
+		dst.IntroTextColor = src.IntroTextColor
+	},
+	"fades": func(dst, src *models.Song) {
+		dst.AudioFadeIn = src.AudioFadeIn
+		dst.AudioFadeOut = src.AudioFadeOut
+		dst.VideoFadeIn = src.VideoFadeIn
+		dst.VideoFadeOut = src.VideoFadeOut
+	},
+	"output_variants": func(dst, src *models.Song) {
+		dst.OutputVariants = append([]string(nil), src.OutputVariants...)
+	},
+	"workflow": func(dst, src *models.Song) {
+		dst.RequireImageApproval = src.RequireImageApproval
+	},
+}
+
+// CopySettings copies groups of settings (spectrum, karaoke, quality, ...)
+// from one song to others, so settings dialed in on one song can be applied
+// to a batch. Only the groups in songSettingGroups can be copied.
+func (h *SongHandler) CopySettings(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req struct {
+		SongIDs  []int    `json:"song_ids" binding:"required"`
+		Settings []string `json:"settings" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.SongIDs) == 0 || len(req.Settings) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "song_ids and settings must not be empty"})
+		return
+	}
+	for _, key := range req.Settings {
+		if _, ok := songSettingGroups[key]; !ok {
+			valid := make([]string, 0, len(songSettingGroups))
+			for name := range songSettingGroups {
+				valid = append(valid, name)
+			}
+			sort.Strings(valid)
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown setting %q; valid settings: %s", key, strings.Join(valid, ", "))})
+			return
+		}
+	}
+
+	source, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if source == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	updated := []int{}
+	notFound := []int{}
+	var copyErrors []string
+	seen := map[int]bool{id: true}
+	for _, targetID := range req.SongIDs {
+		if seen[targetID] {
+			continue
+		}
+		seen[targetID] = true
+
+		target, err := h.repo.GetByID(targetID)
+		if err != nil {
+			copyErrors = append(copyErrors, fmt.Sprintf("song %d: %v", targetID, err))
+			continue
+		}
+		if target == nil {
+			notFound = append(notFound, targetID)
+			continue
+		}
+
+		for _, key := range req.Settings {
+			songSettingGroups[key](target, source)
+		}
+		if err := h.repo.Update(target); err != nil {
+			copyErrors = append(copyErrors, fmt.Sprintf("song %d: %v", targetID, err))
+			continue
+		}
+		updated = append(updated, targetID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"source_id": id,
+		"settings":  req.Settings,
+		"updated":   updated,
+		"not_found": notFound,
+		"errors":    copyErrors,
+	})
+}
+
 // Delete deletes a song
+// With cleanup=true, also removes its images, audio, logs, subtitles and video files
 func (h *SongHandler) Delete(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -108,12 +463,35 @@ func (h *SongHandler) Delete(c *gin.Context) {
 		return
 	}
 
+	cleanup := c.Query("cleanup") == "true"
+
+	// Collect video paths before the rows go away
+	var artifacts []string
+	if cleanup {
+		videoPaths, err := database.NewVideoRepository(database.DB).GetFilePathsBySongID(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		artifacts = append(songArtifactPaths(h.config, id), videoPaths...)
+	}
+
 	if err := h.repo.Delete(id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Song deleted"})
+	if !cleanup {
+		c.JSON(http.StatusOK, gin.H{"message": "Song deleted"})
+		return
+	}
+
+	removed, cleanupErrors := removeArtifacts(artifacts, artifactRoots(h.config))
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Song deleted",
+		"removed": removed,
+		"errors":  cleanupErrors,
+	})
 }
 
 // ValidateAudioPaths validates that audio files exist and suggests fixes
@@ -166,6 +544,37 @@ func (h *SongHandler) ValidateAudioPaths(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// OverlayPreview renders a single frame showing where the key, tempo, BPM,
+// title, copyright and logo overlays land, returned as a PNG
+func (h *SongHandler) OverlayPreview(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	song, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	previewPath, err := h.previewer.RenderOverlayPreview(song)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to render overlay preview: %v", err)})
+		return
+	}
+	defer os.Remove(previewPath)
+
+	c.Header("Content-Type", "image/png")
+	c.Header("Cache-Control", "no-store")
+	c.File(previewPath)
+}
+
 // GetRenderLog returns the render log for a song
 func (h *SongHandler) GetRenderLog(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -199,3 +608,331 @@ func (h *SongHandler) GetRenderLog(c *gin.Context) {
 		"path":    logPath,
 	})
 }
+
+// ResetProcessing clears a song's analysis and lyrics processing results
+// (BPM, key, tempo, duration, vocal and beat timing, display lyrics, sections
+// and Whisper engine) and its cached karaoke timing, so the next render redoes
+// them from the audio. With delete_images=true the generated images and their
+// files are removed too so they're regenerated. The song and its audio are
+// kept. Songs being rendered can't be reset.
+func (h *SongHandler) ResetProcessing(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	song, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	processing, err := database.NewQueueRepository(database.DB).IsSongProcessing(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if processing {
+		c.JSON(http.StatusConflict, gin.H{"error": "Song is being processed; reset it after the render finishes"})
+		return
+	}
+
+	deleteImages := c.Query("delete_images") == "true"
+	if deleteImages {
+		if err := database.DeleteImagesBySongID(id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete images: " + err.Error()})
+			return
+		}
+	}
+	if err := h.repo.ResetProcessing(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	artifacts := []string{
+		filepath.Join(utils.GetTempPath(), fmt.Sprintf("song_%d_karaoke.ass", id)),
+		lyrics.TimestampsPath(utils.GetTempPath(), id),
+	}
+	if deleteImages {
+		artifacts = append(artifacts, services.SongImagesDir(id))
+	}
+	removed, cleanupErrors := removeArtifacts(artifacts, artifactRoots(h.config))
+
+	song, err = h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"song":           song,
+		"images_deleted": deleteImages,
+		"removed":        removed,
+		"errors":         cleanupErrors,
+	})
+}
+
+// ReprocessLyrics re-parses sections and re-times lines from the current lyrics
+// without running a full render
+func (h *SongHandler) ReprocessLyrics(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	song, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	// The processor times karaoke lyrics; fall back to raw lyrics if none are set
+	source := "lyrics_karaoke"
+	lyricsText := song.LyricsKaraoke
+	if strings.TrimSpace(lyricsText) == "" {
+		source = "lyrics"
+		lyricsText = song.Lyrics
+	}
+
+	lyricsData, err := lyrics.ParseLyrics(lyricsText)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Failed to parse lyrics",
+			"detail": err.Error(),
+			"source": source,
+		})
+		return
+	}
+
+	if song.DurationSeconds <= 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Song has no duration - run audio analysis first",
+			"source": source,
+		})
+		return
+	}
+
+	// Lines follow the beats saved by audio analysis, or are spread evenly without them
+	beatTimes, err := audio.ParseBeatTimes(song.BeatTimes)
+	if err != nil {
+		log.Printf("Warning: ignoring beat times for song %d: %v", song.ID, err)
+	}
+	timedLines, err := lyrics.AlignLyricsToBeats(lyricsText, beatTimes, song.DurationSeconds)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Failed to align lyrics",
+			"detail": err.Error(),
+			"source": source,
+		})
+		return
+	}
+
+	sectionsJSON, err := json.Marshal(lyricsData.Sections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	timedLinesJSON, err := json.Marshal(timedLines)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	song.LyricsSections = string(sectionsJSON)
+	song.LyricsDisplay = string(timedLinesJSON)
+	song.ManualTiming = false // Reprocessing replaces any hand-corrected timing
+	if err := h.repo.Update(song); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"song_id":     song.ID,
+		"source":      source,
+		"sections":    lyricsData.Sections,
+		"timed_lines": timedLines,
+		"summary":     lyricsData.GetSectionSummary(),
+	})
+}
+
+// GetLyrics returns the song's raw lyrics with parsed sections and timed lines
+func (h *SongHandler) GetLyrics(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	song, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	if song.LyricsSections == "" && song.LyricsDisplay == "" {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":     "Lyrics have not been processed yet",
+			"song_id":   song.ID,
+			"processed": false,
+		})
+		return
+	}
+
+	sections := []lyrics.Section{}
+	if song.LyricsSections != "" {
+		if err := json.Unmarshal([]byte(song.LyricsSections), &sections); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to parse stored sections: %v", err)})
+			return
+		}
+	}
+
+	timedLines := []lyrics.TimedLine{}
+	if song.LyricsDisplay != "" {
+		if err := json.Unmarshal([]byte(song.LyricsDisplay), &timedLines); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to parse stored timing: %v", err)})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"song_id":        song.ID,
+		"processed":      true,
+		"lyrics":         song.Lyrics,
+		"lyrics_karaoke": song.LyricsKaraoke,
+		"sections":       sections,
+		"timed_lines":    timedLines,
+		"manual_timing":  song.ManualTiming,
+	})
+}
+
+// UpdateLyricTiming replaces the song's timed lines with hand-corrected timing
+// and marks it manual so later renders don't re-align it
+func (h *SongHandler) UpdateLyricTiming(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var edits []lyrics.TimingEdit
+	if err := c.ShouldBindJSON(&edits); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected an array of {line, start, end}: " + err.Error()})
+		return
+	}
+
+	song, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	if fieldErrors := lyrics.ValidateTimingEdits(edits, song.DurationSeconds); fieldErrors != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "Invalid lyric timing",
+			"fields": fieldErrors,
+		})
+		return
+	}
+
+	timedLines := lyrics.TimedLinesFromEdits(edits)
+	timedLinesJSON, err := json.Marshal(timedLines)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	song.LyricsDisplay = string(timedLinesJSON)
+	song.ManualTiming = true
+	if err := h.repo.Update(song); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"song_id":       song.ID,
+		"manual_timing": true,
+		"timed_lines":   timedLines,
+	})
+}
+
+// RebuildKaraoke applies an optional new karaoke timing offset and
+// regenerates the song's ASS subtitles from its cached Whisper timestamps,
+// so an offset can be tried without re-transcribing. The next render of the
+// song reuses the same cached timestamps to burn the result.
+func (h *SongHandler) RebuildKaraoke(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	var req struct {
+		Offset *float64 `json:"offset"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	song, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	if req.Offset != nil {
+		if *req.Offset < -maxKaraokeTimingOffset || *req.Offset > maxKaraokeTimingOffset {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("offset must be between -%.0f and %.0f seconds", maxKaraokeTimingOffset, maxKaraokeTimingOffset)})
+			return
+		}
+		song.KaraokeTimingOffset = *req.Offset
+		if err := h.repo.Update(song); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	assPath, err := h.subtitles.RebuildKaraokeSubtitles(song)
+	if errors.Is(err, os.ErrNotExist) {
+		c.JSON(http.StatusConflict, gin.H{"error": "No cached karaoke timestamps for this song; render it once first"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to rebuild karaoke subtitles: %v", err)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"song_id":               song.ID,
+		"karaoke_timing_offset": song.KaraokeTimingOffset,
+		"ass_path":              assPath,
+	})
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// maxTimeSeriesBuckets bounds the response size for long ranges with small buckets
+const maxTimeSeriesBuckets = 2000
+
+type StatsHandler struct {
+	statsRepo *database.StatsRepository
+}
+
+func NewStatsHandler(statsRepo *database.StatsRepository) *StatsHandler {
+	return &StatsHandler{statsRepo: statsRepo}
+}
+
+// GetTimeSeries returns a bucketed queue metric for charting.
+// Query params: metric (completed, failed, failure_rate, avg_render_time, queued),
+// from/to (YYYY-MM-DD or RFC3339, default last 30 days) and bucket (hour, day, week, month).
+func (h *StatsHandler) GetTimeSeries(c *gin.Context) {
+	metric := c.DefaultQuery("metric", database.MetricCompleted)
+	bucket := c.DefaultQuery("bucket", database.BucketDay)
+
+	to := time.Now().UTC()
+	if raw := c.Query("to"); raw != "" {
+		t, dateOnly, err := parseStatsTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' time, use YYYY-MM-DD or RFC3339"})
+			return
+		}
+		// A date-only end includes that whole day
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		to = t
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if raw := c.Query("from"); raw != "" {
+		t, _, err := parseStatsTime(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' time, use YYYY-MM-DD or RFC3339"})
+			return
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "'from' must be before 'to'"})
+		return
+	}
+	if bucketCount(bucket, to.Sub(from)) > maxTimeSeriesBuckets {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Time range too large for bucket size"})
+		return
+	}
+
+	points, err := h.statsRepo.GetTimeSeries(metric, bucket, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	labels := make([]string, len(points))
+	values := make([]float64, len(points))
+	for i, p := range points {
+		labels[i] = p.Bucket
+		values[i] = p.Value
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"metric": metric,
+		"bucket": bucket,
+		"from":   from,
+		"to":     to,
+		"points": points,
+		"labels": labels,
+		"values": values,
+	})
+}
+
+// parseStatsTime parses a date or RFC3339 timestamp, reporting whether it was date-only
+func parseStatsTime(raw string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	return t.UTC(), false, err
+}
+
+// bucketCount estimates how many buckets span covers
+func bucketCount(bucket string, span time.Duration) int {
+	size := 24 * time.Hour
+	switch bucket {
+	case database.BucketHour:
+		size = time.Hour
+	case database.BucketWeek:
+		size = 7 * 24 * time.Hour
+	case database.BucketMonth:
+		size = 28 * 24 * time.Hour
+	}
+	return int(span / size)
+}
@@ -1,26 +1,39 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/storage"
 	"github.com/gin-gonic/gin"
 )
 
+// uploadKinds are the form fields UploadAudio accepts, one per stem
+var uploadKinds = []string{"vocals", "music", "mixed"}
+
 // UploadHandler handles file upload requests
 type UploadHandler struct {
 	songRepo *database.SongRepository
+	storage  storage.Storage
+	uploads  *services.AudioUploads
 }
 
 // NewUploadHandler creates a new upload handler
-func NewUploadHandler(songRepo *database.SongRepository) *UploadHandler {
-	return &UploadHandler{songRepo: songRepo}
+func NewUploadHandler(songRepo *database.SongRepository, store storage.Storage) *UploadHandler {
+	return &UploadHandler{
+		songRepo: songRepo,
+		storage:  store,
+		uploads:  services.NewAudioUploads(filepath.Join(utils.GetTempPath(), "uploads")),
+	}
 }
 
 // UploadAudio handles audio file uploads for a song
@@ -53,92 +66,225 @@ func (h *UploadHandler) UploadAudio(c *gin.Context) {
 
 	var updatedPaths = make(map[string]string)
 
-	// Handle vocals file upload
-	vocalsFile, vocalsHeader, err := c.Request.FormFile("vocals")
-	if err == nil {
-		defer vocalsFile.Close()
+	// Each stem arrives in its own form field: vocals, music and/or mixed
+	for _, kind := range uploadKinds {
+		file, header, err := c.Request.FormFile(kind)
+		if err != nil {
+			continue
+		}
+		defer file.Close()
 
 		// Determine file extension
-		ext := filepath.Ext(vocalsHeader.Filename)
+		ext := filepath.Ext(header.Filename)
 		if ext == "" {
 			ext = ".mp3" // default
 		}
 
-		// Remove any existing vocal files with different extensions
-		for _, oldExt := range []string{".wav", ".mp3", ".flac", ".m4a"} {
-			oldPath := filepath.Join(audioDir, "vocal"+oldExt)
-			if oldExt != ext {
-				os.Remove(oldPath) // Ignore errors if file doesn't exist
-			}
-		}
+		// Remove any existing files for this stem with different extensions
+		base := services.AudioStemNames[kind]
+		services.RemoveOtherStems(audioDir, base, ext)
 
-		// Save vocals file with absolute path
-		vocalsPath := filepath.Join(audioDir, "vocal"+ext)
-		destFile, err := os.Create(vocalsPath)
+		// Save file with absolute path
+		destPath := filepath.Join(audioDir, base+ext)
+		destFile, err := os.Create(destPath)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save vocals file: " + err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to save %s file: %v", kind, err)})
 			return
 		}
 		defer destFile.Close()
 
-		if _, err := io.Copy(destFile, vocalsFile); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write vocals file: " + err.Error()})
+		if _, err := io.Copy(destFile, file); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to write %s file: %v", kind, err)})
 			return
 		}
 
 		// File saved successfully
-		updatedPaths["vocals"] = vocalsPath
+		updatedPaths[kind] = destPath
 	}
 
-	// Handle music/instrumental file upload
-	musicFile, musicHeader, err := c.Request.FormFile("music")
-	if err == nil {
-		defer musicFile.Close()
+	// Check if at least one file was uploaded
+	if len(updatedPaths) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No audio files provided. Include 'vocals', 'music' and/or 'mixed' in the form data."})
+		return
+	}
 
-		// Determine file extension
-		ext := filepath.Ext(musicHeader.Filename)
-		if ext == "" {
-			ext = ".mp3" // default
-		}
+	// No need to update database - paths are convention-based
 
-		// Remove any existing music files with different extensions
-		for _, oldExt := range []string{".wav", ".mp3", ".flac", ".m4a"} {
-			oldPath := filepath.Join(audioDir, "music"+oldExt)
-			if oldExt != ext {
-				os.Remove(oldPath) // Ignore errors if file doesn't exist
-			}
+	// Audio stays on local disk for processing; mirror it to remote storage if configured
+	for _, path := range updatedPaths {
+		if err := storage.Mirror(c.Request.Context(), h.storage, utils.GetDataPath(), path); err != nil {
+			log.Printf("Warning: failed to upload %s to storage: %v", path, err)
 		}
+	}
 
-		// Save music file with absolute path
-		musicPath := filepath.Join(audioDir, "music"+ext)
-		destFile, err := os.Create(musicPath)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save music file: " + err.Error()})
-			return
-		}
-		defer destFile.Close()
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Audio files uploaded successfully",
+		"song_id":        id,
+		"uploaded_paths": updatedPaths,
+	})
+}
 
-		if _, err := io.Copy(destFile, musicFile); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write music file: " + err.Error()})
-			return
-		}
+// InitAudioUpload starts a resumable chunked upload of a vocals, music or
+// mixed stem. The client then PATCHes the bytes in order, each request carrying
+// the Upload-Offset it starts at, and finalizes once all have arrived.
+func (h *UploadHandler) InitAudioUpload(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
 
-		// File saved successfully
-		updatedPaths["music"] = musicPath
+	var req struct {
+		Kind     string `json:"kind" binding:"required"` // vocals, music or mixed
+		Filename string `json:"filename"`
+		Size     int64  `json:"size" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	// Check if at least one file was uploaded
-	// Check if at least one file was uploaded
-	if len(updatedPaths) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No audio files provided. Include 'vocals' and/or 'music' in the form data."})
+	song, err := h.songRepo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
 		return
 	}
 
-	// No need to update database - paths are convention-based
+	upload, err := h.uploads.Init(id, req.Kind, req.Filename, req.Size)
+	if err != nil {
+		h.uploadError(c, err, upload)
+		return
+	}
+
+	c.Header("Upload-Offset", "0")
+	c.JSON(http.StatusCreated, upload)
+}
+
+// GetAudioUpload reports how many bytes of an upload have arrived, which is
+// where an interrupted client resumes
+func (h *UploadHandler) GetAudioUpload(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	upload, err := h.uploads.Get(id, c.Param("uploadId"))
+	if err != nil {
+		h.uploadError(c, err, upload)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.JSON(http.StatusOK, upload)
+}
+
+// PatchAudioUpload appends the raw request body to an upload. The
+// Upload-Offset header must equal the bytes received so far; on a mismatch
+// the response carries the offset to resume from.
+func (h *UploadHandler) PatchAudioUpload(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Offset header must be a non-negative byte offset"})
+		return
+	}
+
+	upload, err := h.uploads.WriteChunk(id, c.Param("uploadId"), offset, c.Request.Body)
+	if err != nil {
+		h.uploadError(c, err, upload)
+		return
+	}
+
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.JSON(http.StatusOK, upload)
+}
+
+// FinalizeAudioUpload moves a complete upload to the song's conventional
+// stem path, replacing any previous stem of the same kind
+func (h *UploadHandler) FinalizeAudioUpload(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	uploadID := c.Param("uploadId")
+	upload, err := h.uploads.Get(id, uploadID)
+	if err != nil {
+		h.uploadError(c, err, upload)
+		return
+	}
+
+	base := services.AudioStemNames[upload.Kind]
+	audioDir := filepath.Join(utils.GetAudioPath(), fmt.Sprintf("song_%d", id))
+	destPath := filepath.Join(audioDir, base+upload.Extension)
+	if err := h.uploads.Finalize(id, uploadID, destPath); err != nil {
+		h.uploadError(c, err, upload)
+		return
+	}
+	services.RemoveOtherStems(audioDir, base, upload.Extension)
+
+	// Audio stays on local disk for processing; mirror it to remote storage if configured
+	if err := storage.Mirror(c.Request.Context(), h.storage, utils.GetDataPath(), destPath); err != nil {
+		log.Printf("Warning: failed to upload %s to storage: %v", destPath, err)
+	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":        "Audio files uploaded successfully",
+		"message":        "Audio file uploaded successfully",
 		"song_id":        id,
-		"uploaded_paths": updatedPaths,
+		"uploaded_paths": map[string]string{upload.Kind: destPath},
 	})
 }
+
+// AbortAudioUpload discards an unfinished upload
+func (h *UploadHandler) AbortAudioUpload(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	if err := h.uploads.Abort(id, c.Param("uploadId")); err != nil {
+		h.uploadError(c, err, nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Upload discarded"})
+}
+
+// uploadError maps chunked upload errors to responses, including the
+// current offset when the client needs it to resume
+func (h *UploadHandler) uploadError(c *gin.Context, err error, upload *services.AudioUpload) {
+	if upload != nil {
+		c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	}
+
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrUploadNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrUploadOffset), errors.Is(err, services.ErrUploadIncomplete):
+		status = http.StatusConflict
+	case errors.Is(err, services.ErrUploadTooLarge):
+		status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrInvalidAudioUpload):
+		status = http.StatusBadRequest
+	}
+
+	response := gin.H{"error": err.Error()}
+	if upload != nil {
+		response["offset"] = upload.Offset
+		response["size"] = upload.Size
+	}
+	c.JSON(status, response)
+}
@@ -1,19 +1,28 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/storage"
 	"github.com/gin-gonic/gin"
 )
 
 type VideoHandler struct {
-	repo *database.VideoRepository
+	repo    *database.VideoRepository
+	storage storage.Storage
 }
 
-func NewVideoHandler(repo *database.VideoRepository) *VideoHandler {
-	return &VideoHandler{repo: repo}
+func NewVideoHandler(repo *database.VideoRepository, store storage.Storage) *VideoHandler {
+	return &VideoHandler{repo: repo, storage: store}
 }
 
 // GetAll returns all videos
@@ -59,3 +68,81 @@ func (h *VideoHandler) Delete(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Video deleted"})
 }
+
+// Download streams a video as an attachment named "Artist - Title.mp4"
+// (with the variant, e.g. "(Instrumental)", after the title for variants).
+// Range requests are supported so players and download managers can seek
+// and resume. When the file is only in remote storage the client is
+// redirected to a presigned URL instead.
+func (h *VideoHandler) Download(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	video, err := h.repo.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if video == nil || video.Status == "deleted" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video not found"})
+		return
+	}
+
+	file, err := os.Open(video.VideoFilePath)
+	if err != nil {
+		h.redirectToStorage(c, video.VideoFilePath)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video file not found"})
+		return
+	}
+
+	title := video.SongTitle
+	if video.Variant != "" && video.Variant != models.VideoVariantMixed {
+		title = fmt.Sprintf("%s (%s)", title, strings.ToUpper(video.Variant[:1])+video.Variant[1:])
+	}
+	filename := utils.DownloadFilename(video.ArtistName, title, video.ID)
+	c.Header("Content-Disposition", contentDisposition(filename))
+	c.Header("Content-Type", "video/mp4")
+	http.ServeContent(c.Writer, c.Request, filename, info.ModTime(), file)
+}
+
+// redirectToStorage sends the client to a presigned URL for a video that
+// isn't on local disk, or responds 404 when there's nowhere else to look
+func (h *VideoHandler) redirectToStorage(c *gin.Context, localPath string) {
+	if h.storage == nil || !storage.IsRemote(h.storage) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video file not found"})
+		return
+	}
+
+	key, err := storage.KeyFor(utils.GetDataPath(), localPath)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video file not found"})
+		return
+	}
+	presigned, err := h.storage.URL(c.Request.Context(), key, time.Hour)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Video file not found"})
+		return
+	}
+	c.Redirect(http.StatusFound, presigned)
+}
+
+// contentDisposition builds an attachment header with an ASCII filename for
+// old clients plus the exact UTF-8 name (RFC 6266)
+func contentDisposition(filename string) string {
+	ascii := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, filename)
+	return fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, ascii, url.PathEscape(filename))
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services/ai"
+	"github.com/gin-gonic/gin"
+)
+
+// YouTubeHandler handles YouTube upload metadata requests
+type YouTubeHandler struct {
+	songRepo     *database.SongRepository
+	uploadRepo   *database.YoutubeUploadRepository
+	settingsRepo *database.SettingsRepository
+	aiClient     *ai.Client
+}
+
+// NewYouTubeHandler creates a new YouTube handler
+func NewYouTubeHandler(songRepo *database.SongRepository, uploadRepo *database.YoutubeUploadRepository, settingsRepo *database.SettingsRepository, aiClient *ai.Client) *YouTubeHandler {
+	return &YouTubeHandler{
+		songRepo:     songRepo,
+		uploadRepo:   uploadRepo,
+		settingsRepo: settingsRepo,
+		aiClient:     aiClient,
+	}
+}
+
+// validPrivacyStatuses are the privacy settings YouTube accepts
+var validPrivacyStatuses = map[string]bool{
+	"public":   true,
+	"unlisted": true,
+	"private":  true,
+}
+
+// GenerateMetadata writes a YouTube title, description and tags for a song with
+// the LLM and stores them on the song's pending upload record for review
+func (h *YouTubeHandler) GenerateMetadata(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	song, err := h.songRepo.GetByID(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if song == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		return
+	}
+
+	template, defaultCopyright := ai.DefaultYouTubeDescriptionTemplate, ""
+	if settings, err := h.settingsRepo.Get(); err != nil {
+		log.Printf("Warning: failed to load settings for YouTube template: %v", err)
+	} else {
+		if settings.YouTubeDescriptionTemplate != "" {
+			template = settings.YouTubeDescriptionTemplate
+		}
+		defaultCopyright = settings.DefaultCopyrightText
+	}
+
+	log.Printf("Generating YouTube metadata for song %d: %s", songID, song.Title)
+
+	title, description, tags, err := h.aiClient.GenerateYouTubeMetadataWithTemplate(song, template, defaultCopyright)
+	if err != nil {
+		log.Printf("Error generating YouTube metadata for song %d: %v", songID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate YouTube metadata: %v", err)})
+		return
+	}
+
+	upload, err := h.uploadRepo.GetPendingBySong(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if upload == nil {
+		upload = &models.YoutubeUpload{
+			SongID:        songID,
+			CategoryID:    10, // Music
+			PrivacyStatus: "public",
+		}
+	}
+
+	tagsJSON, _ := json.Marshal(tags)
+	upload.Title = title
+	upload.Description = description
+	upload.Tags = string(tagsJSON)
+
+	if err := h.uploadRepo.SaveMetadata(upload); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save YouTube metadata"})
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
+// GetMetadata returns the song's pending YouTube upload metadata
+func (h *YouTubeHandler) GetMetadata(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	upload, err := h.uploadRepo.GetPendingBySong(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if upload == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending YouTube metadata for this song"})
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
+
+// UpdateMetadata saves edits to the song's pending YouTube upload metadata
+func (h *YouTubeHandler) UpdateMetadata(c *gin.Context) {
+	songID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID"})
+		return
+	}
+
+	var req struct {
+		Title         *string  `json:"title"`
+		Description   *string  `json:"description"`
+		Tags          []string `json:"tags"`
+		CategoryID    *int     `json:"category_id"`
+		PrivacyStatus *string  `json:"privacy_status"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	upload, err := h.uploadRepo.GetPendingBySong(songID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if upload == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No pending YouTube metadata for this song"})
+		return
+	}
+
+	if req.Title != nil {
+		if *req.Title == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Title cannot be empty"})
+			return
+		}
+		upload.Title = *req.Title
+	}
+	if req.Description != nil {
+		upload.Description = *req.Description
+	}
+	if req.Tags != nil {
+		tagsJSON, _ := json.Marshal(req.Tags)
+		upload.Tags = string(tagsJSON)
+	}
+	if req.CategoryID != nil {
+		upload.CategoryID = *req.CategoryID
+	}
+	if req.PrivacyStatus != nil {
+		if !validPrivacyStatuses[*req.PrivacyStatus] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid privacy status (must be public, unlisted or private)"})
+			return
+		}
+		upload.PrivacyStatus = *req.PrivacyStatus
+	}
+
+	if err := h.uploadRepo.SaveMetadata(upload); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save YouTube metadata"})
+		return
+	}
+
+	c.JSON(http.StatusOK, upload)
+}
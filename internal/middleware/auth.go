@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader carries an API key as an alternative to a bearer token
+	APIKeyHeader = "X-API-Key"
+
+	// apiKeyQueryParam carries an API key on GET requests from clients that
+	// can't set headers, like EventSource progress streams
+	apiKeyQueryParam = "api_key"
+
+	// apiKeyLabelKey is the gin context key holding the authenticated key's label
+	apiKeyLabelKey = "api_key_label"
+)
+
+// APIAuth requires a valid API key on every request when authentication is
+// enabled in config, and does nothing otherwise. The key is read from an
+// "Authorization: Bearer" header, the X-API-Key header or, for GET requests,
+// the api_key query parameter. The matching key's label is stored in the
+// context and logged for requests that change state.
+func APIAuth(cfg *config.Config) gin.HandlerFunc {
+	if !cfg.APIAuthEnabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if len(cfg.APIKeys) == 0 {
+		log.Printf("Warning: API_AUTH_ENABLED is set but API_KEYS is empty; all API requests will be rejected")
+	}
+
+	return func(c *gin.Context) {
+		// Preflights never carry credentials; CORS answers them
+		if c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+
+		key := requestAPIKey(c)
+		if key == "" {
+			unauthorized(c, "missing API key")
+			return
+		}
+
+		label, ok := lookupAPIKey(cfg.APIKeys, key)
+		if !ok {
+			Logf(c, "Warning: rejected invalid API key for %s %s from %s", c.Request.Method, c.Request.URL.Path, c.ClientIP())
+			unauthorized(c, "invalid API key")
+			return
+		}
+
+		c.Set(apiKeyLabelKey, label)
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			Logf(c, "%s %s by API key %q", c.Request.Method, c.Request.URL.Path, label)
+		}
+		c.Next()
+	}
+}
+
+// GetAPIKeyLabel returns the label of the key that authenticated the request,
+// or "" when authentication is disabled
+func GetAPIKeyLabel(c *gin.Context) string {
+	return c.GetString(apiKeyLabelKey)
+}
+
+// requestAPIKey extracts the key from the request's headers or query
+func requestAPIKey(c *gin.Context) string {
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return strings.TrimSpace(key)
+	}
+	if c.Request.Method == http.MethodGet {
+		return c.Query(apiKeyQueryParam)
+	}
+	return ""
+}
+
+// lookupAPIKey finds a key's label, comparing in constant time so response
+// timing doesn't reveal how much of a guess was right
+func lookupAPIKey(keys map[string]string, key string) (string, bool) {
+	label, found := "", false
+	for candidate, candidateLabel := range keys {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			label, found = candidateLabel, true
+		}
+	}
+	return label, found
+}
+
+// unauthorized aborts with a 401 in the API's error format
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="track-studio"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+		"error":      message,
+		"code":       "unauthorized",
+		"request_id": GetRequestID(c),
+	})
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/gin-gonic/gin"
+)
+
+// corsExposedHeaders are the response headers browser clients may read
+var corsExposedHeaders = []string{"Content-Type", "Cache-Control", "Connection", RequestIDHeader, "Upload-Offset"}
+
+// corsMaxAge is how long (in seconds) browsers may cache a preflight response
+const corsMaxAge = "86400"
+
+// CORS answers preflight requests and adds CORS headers for the origins,
+// methods and headers in config. A "*" origin allows any origin; with
+// credentials enabled the request's origin is echoed instead, since browsers
+// reject "*" on credentialed requests. Requests from other origins get no
+// CORS headers, and their preflights are refused.
+func CORS(cfg *config.Config) gin.HandlerFunc {
+	allowAny := false
+	allowed := make(map[string]bool)
+	for _, origin := range cfg.CORSAllowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		allowed[strings.TrimRight(origin, "/")] = true
+	}
+	if allowAny && cfg.CORSAllowCredentials {
+		log.Printf("Warning: CORS allows credentialed requests from any origin; set CORS_ALLOWED_ORIGINS before enabling authentication")
+	}
+
+	methods := strings.Join(cfg.CORSAllowedMethods, ", ")
+	headers := strings.Join(cfg.CORSAllowedHeaders, ", ")
+	exposed := strings.Join(corsExposedHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		preflight := c.Request.Method == http.MethodOptions
+
+		if origin != "" {
+			header := c.Writer.Header()
+			header.Add("Vary", "Origin")
+
+			if !allowAny && !allowed[origin] {
+				if preflight {
+					c.AbortWithStatus(http.StatusForbidden)
+					return
+				}
+				c.Next()
+				return
+			}
+
+			if allowAny && !cfg.CORSAllowCredentials {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.CORSAllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+			header.Set("Access-Control-Allow-Methods", methods)
+			header.Set("Access-Control-Allow-Headers", headers)
+			header.Set("Access-Control-Expose-Headers", exposed)
+			header.Set("Access-Control-Max-Age", corsMaxAge)
+		}
+
+		if preflight {
+			c.AbortWithStatus(http.StatusOK)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimitIdle is how long a client's bucket is kept after it refills
+// completely; idle buckets are dropped to bound memory
+const rateLimitIdle = 10 * time.Minute
+
+// RateLimiter is a per-client token bucket: each client may burst up to the
+// per-minute limit, then is refilled at that rate
+type RateLimiter struct {
+	name      string
+	perMinute int
+	rate      float64 // Tokens per second
+	burst     float64
+
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+	mutex     sync.Mutex
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing perMinute requests per client.
+// A limit of zero or less allows every request.
+func NewRateLimiter(name string, perMinute int) *RateLimiter {
+	return &RateLimiter{
+		name:      name,
+		perMinute: perMinute,
+		rate:      float64(perMinute) / 60,
+		burst:     float64(perMinute),
+		buckets:   make(map[string]*tokenBucket),
+		lastPrune: time.Now(),
+	}
+}
+
+// Allow takes a token from the client's bucket. When the bucket is empty it
+// returns false and how long until the next token.
+func (rl *RateLimiter) Allow(client string) (bool, time.Duration) {
+	if rl.perMinute <= 0 {
+		return true, 0
+	}
+
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := time.Now()
+	rl.prune(now)
+
+	bucket, ok := rl.buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: rl.burst, last: now}
+		rl.buckets[client] = bucket
+	}
+	bucket.tokens = math.Min(rl.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*rl.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		wait := time.Duration((1 - bucket.tokens) / rl.rate * float64(time.Second))
+		return false, wait
+	}
+	bucket.tokens--
+	return true, 0
+}
+
+// Handler rejects requests over the limit with 429 and a Retry-After header
+func (rl *RateLimiter) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if rl.check(c) {
+			c.Next()
+		}
+	}
+}
+
+// check applies the limit to the request, aborting it when exceeded
+func (rl *RateLimiter) check(c *gin.Context) bool {
+	client := rateLimitClient(c)
+	allowed, wait := rl.Allow(client)
+	if allowed {
+		return true
+	}
+
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	Logf(c, "Warning: rate limited %s on %s %s (%s limit)", client, c.Request.Method, c.Request.URL.Path, rl.name)
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+		"error":       fmt.Sprintf("rate limit exceeded: %d %s requests per minute", rl.perMinute, rl.name),
+		"code":        "rate_limited",
+		"retry_after": retryAfter,
+		"request_id":  GetRequestID(c),
+	})
+	return false
+}
+
+// prune drops buckets that have been idle long enough to be full again
+func (rl *RateLimiter) prune(now time.Time) {
+	if now.Sub(rl.lastPrune) < rateLimitIdle {
+		return
+	}
+	rl.lastPrune = now
+	for client, bucket := range rl.buckets {
+		if now.Sub(bucket.last) > rateLimitIdle {
+			delete(rl.buckets, client)
+		}
+	}
+}
+
+// RateLimit limits each client's reads and writes separately using the
+// per-minute limits from config, and does nothing when rate limiting is off.
+// It must run after APIAuth so clients are identified by key.
+func RateLimit(cfg *config.Config) gin.HandlerFunc {
+	if !cfg.RateLimitEnabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+
+	reads := NewRateLimiter("read", cfg.RateLimitReads)
+	writes := NewRateLimiter("write", cfg.RateLimitWrites)
+	return func(c *gin.Context) {
+		limiter := writes
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			limiter = reads
+		}
+		if limiter.check(c) {
+			c.Next()
+		}
+	}
+}
+
+// ExpensiveRateLimit returns the stricter limit for endpoints that start
+// renders, analysis or CQAI work, shared across every route it's applied to
+func ExpensiveRateLimit(cfg *config.Config) gin.HandlerFunc {
+	if !cfg.RateLimitEnabled {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return NewRateLimiter("expensive", cfg.RateLimitExpensive).Handler()
+}
+
+// rateLimitClient identifies the client by API key label when authenticated,
+// otherwise by IP
+func rateLimitClient(c *gin.Context) string {
+	if label := GetAPIKeyLabel(c); label != "" {
+		return "key:" + label
+	}
+	return "ip:" + c.ClientIP()
+}
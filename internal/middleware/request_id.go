@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader carries the request ID in and out of the API
+	RequestIDHeader = "X-Request-ID"
+
+	// requestIDKey is the gin context key holding the request ID
+	requestIDKey = "request_id"
+
+	// maxRequestIDLength caps client-supplied IDs so they stay loggable
+	maxRequestIDLength = 128
+)
+
+// RequestID assigns every request an ID, reusing a well-formed X-Request-ID
+// header from the client and generating one otherwise. The ID is stored in
+// the gin context and echoed back in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Set(requestIDKey, id)
+		c.Writer.Header().Set(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID assigned to the request, or "" outside the middleware
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// Logf logs like log.Printf, prefixed with the request's ID
+func Logf(c *gin.Context, format string, args ...interface{}) {
+	log.Printf("[req=%s] %s", GetRequestID(c), fmt.Sprintf(format, args...))
+}
+
+// newRequestID returns 16 random bytes as hex
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("Warning: failed to generate request ID: %v", err)
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts short IDs of printable ASCII without spaces
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
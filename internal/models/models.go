@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // Artist represents a music artist
 type Artist struct {
@@ -24,13 +27,14 @@ type Album struct {
 
 // Song represents a song with all its metadata and processing info
 type Song struct {
-	ID         int       `json:"id" db:"id"`
-	AlbumID    *int      `json:"album_id" db:"album_id"`
-	Title      string    `json:"title" db:"title"`
-	ArtistName string    `json:"artist_name" db:"artist_name"`
-	Genre      string    `json:"genre" db:"genre"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ID          int       `json:"id" db:"id"`
+	AlbumID     *int      `json:"album_id" db:"album_id"`
+	Title       string    `json:"title" db:"title"`
+	ArtistName  string    `json:"artist_name" db:"artist_name"`
+	Genre       string    `json:"genre" db:"genre"`
+	GenreSource string    `json:"genre_source" db:"genre_source"` // user, analysis or ai; empty when unknown
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 
 	// Audio stems
 	VocalsStemPath string `json:"vocals_stem_path" db:"vocals_stem_path"`
@@ -42,8 +46,10 @@ type Song struct {
 	Lyrics         string `json:"lyrics" db:"lyrics"`                           // Original song lyrics with [Verse], [Chorus], etc.
 	LyricsKaraoke  string `json:"lyrics_karaoke,omitempty" db:"lyrics_karaoke"` // Formatted lyrics for karaoke display (no section labels)
 	LyricsDisplay  string `json:"lyrics_display" db:"lyrics_display"`           // JSON
+	ManualTiming   bool   `json:"manual_timing" db:"manual_timing"`             // LyricsDisplay was hand-corrected; don't re-align
 	LyricsSections string `json:"lyrics_sections" db:"lyrics_sections"`         // JSON
-	WhisperEngine  string `json:"whisper_engine,omitempty" db:"whisper_engine"` // Which engine was used: whisperx, faster-whisper
+	WhisperEngine  string `json:"whisper_engine,omitempty" db:"whisper_engine"` // Which engine and model was used, e.g. "whisperx-api (base)"
+	Language       string `json:"language" db:"language"`                       // ISO code (e.g. "en", "es", "ja") or "auto" to detect
 
 	// Audio analysis
 	BPM             float64 `json:"bpm" db:"bpm"`
@@ -51,18 +57,79 @@ type Song struct {
 	Tempo           string  `json:"tempo" db:"tempo"`
 	DurationSeconds float64 `json:"duration_seconds" db:"duration_seconds"`
 	VocalTiming     string  `json:"vocal_timing" db:"vocal_timing"` // JSON
+	BeatTimes       string  `json:"beat_times" db:"beat_times"`     // JSON array of beat times in seconds
+
+	// Seconds before vocals start, replacing the onset detected from
+	// VocalTiming when set (nil uses detection)
+	VocalOnsetOverride *float64 `json:"vocal_onset_override" db:"vocal_onset_override"`
 
 	// Branding
-	BrandLogoPath string `json:"brand_logo_path" db:"brand_logo_path"`
-	CopyrightText string `json:"copyright_text" db:"copyright_text"`
+	BrandLogoPath string  `json:"brand_logo_path" db:"brand_logo_path"`
+	CopyrightText *string `json:"copyright_text" db:"copyright_text"` // nil uses the settings default; empty draws none
 
 	// Video settings
-	BackgroundStyle  string  `json:"background_style" db:"background_style"`
-	SpectrumStyle    string  `json:"spectrum_style" db:"spectrum_style"`     // Visualization type: showfreqs, showspectrum, showcqt, etc.
-	SpectrumColor    string  `json:"spectrum_color" db:"spectrum_color"`     // Color: rainbow, cyan, blue, red, etc.
-	SpectrumOpacity  float64 `json:"spectrum_opacity" db:"spectrum_opacity"` // Opacity: 0.0-1.0
-	TargetResolution string  `json:"target_resolution" db:"target_resolution"`
-	ShowMetadata     bool    `json:"show_metadata" db:"show_metadata"`
+	BackgroundStyle string `json:"background_style" db:"background_style"`
+
+	// Per-song image prompt overrides; when set they replace the global master
+	// prompt and negative prompt from settings for this song's images
+	MasterPromptOverride   string `json:"master_prompt_override" db:"master_prompt_override"`
+	MasterNegativeOverride string `json:"master_negative_override" db:"master_negative_override"`
+
+	// Reference image every background is generated from (img2img), for a
+	// consistent look across an album. Strength is how far generation may
+	// stray from it, 0-1; zero uses image.DefaultReferenceStrength.
+	ReferenceImagePath string  `json:"reference_image_path" db:"reference_image_path"`
+	ReferenceStrength  float64 `json:"reference_strength" db:"reference_strength"`
+
+	// End screen appended after the song: an image, or a video whose own audio
+	// plays as outro music. Duration is in seconds; zero uses
+	// video.DefaultOutroDuration for images and the whole clip for videos.
+	OutroCardPath string  `json:"outro_card_path" db:"outro_card_path"`
+	OutroDuration float64 `json:"outro_duration" db:"outro_duration"`
+
+	// Title card shown before the song with the title, artist and album over
+	// the album's cover art, or a solid background color when there is none.
+	// Duration is in seconds (zero uses video.DefaultIntroDuration); colors
+	// are hex RRGGBB, empty for the defaults.
+	IntroCardEnabled     bool    `json:"intro_card_enabled" db:"intro_card_enabled"`
+	IntroDuration        float64 `json:"intro_duration" db:"intro_duration"`
+	IntroBackgroundColor string  `json:"intro_background_color" db:"intro_background_color"`
+	IntroTextColor       string  `json:"intro_text_color" db:"intro_text_color"`
+
+	// Seconds to fade the song in from silence and out to silence, and the
+	// picture in from black and out to black; zero for no fade. Audio and
+	// video fades are independent.
+	AudioFadeIn  float64 `json:"audio_fade_in" db:"audio_fade_in"`
+	AudioFadeOut float64 `json:"audio_fade_out" db:"audio_fade_out"`
+	VideoFadeIn  float64 `json:"video_fade_in" db:"video_fade_in"`
+	VideoFadeOut float64 `json:"video_fade_out" db:"video_fade_out"`
+
+	SpectrumStyle      string   `json:"spectrum_style" db:"spectrum_style"`       // Visualization type: showfreqs, showspectrum, showcqt, etc.
+	SpectrumColor      string   `json:"spectrum_color" db:"spectrum_color"`       // Color: rainbow, cyan, blue, red, etc.
+	SpectrumOpacity    float64  `json:"spectrum_opacity" db:"spectrum_opacity"`   // Opacity: 0.0-1.0
+	SpectrumPosition   string   `json:"spectrum_position" db:"spectrum_position"` // top, bottom, center, fullscreen or edges; empty uses the style's default
+	SpectrumHeight     float64  `json:"spectrum_height" db:"spectrum_height"`     // Fraction of the frame height (0 uses the style's default)
+	TargetResolution   string   `json:"target_resolution" db:"target_resolution"`
+	ShowMetadata       bool     `json:"show_metadata" db:"show_metadata"`
+	Quality            string   `json:"quality" db:"quality"`                         // draft, standard, high, archive; empty uses the settings default
+	LyricTheme         string   `json:"lyric_theme" db:"lyric_theme"`                 // scroll, single-line-bottom, two-line-karaoke-box, fade
+	ImageFit           string   `json:"image_fit" db:"image_fit"`                     // letterbox (default), crop, blur-fill
+	OutputVariants     []string `json:"output_variants" db:"output_variants"`         // Extra videos reusing the visuals: instrumental, vocals (JSON array)
+	LyricRenderMode    string   `json:"lyric_render_mode" db:"lyric_render_mode"`     // drawtext or subtitles
+	EmbedSoftSubtitles bool     `json:"soft_subtitles" db:"soft_subtitles"`           // Embed lyrics as a selectable subtitle track
+	SoftSubtitlesOnly  bool     `json:"soft_subtitles_only" db:"soft_subtitles_only"` // Don't burn lyrics in; only the soft track carries them
+
+	// Intro countdown shown before the vocals; zero values use the defaults
+	HideCountdown      bool    `json:"hide_countdown" db:"hide_countdown"`
+	CountdownThreshold float64 `json:"countdown_threshold" db:"countdown_threshold"` // Minimum intro length in seconds (default 2)
+	CountdownBarWidth  int     `json:"countdown_bar_width" db:"countdown_bar_width"` // Pixels (default 600)
+	CountdownColor     string  `json:"countdown_color" db:"countdown_color"`         // Hex RRGGBB (default FFD700)
+	CountdownText      string  `json:"countdown_text" db:"countdown_text"`           // {seconds} is the time remaining (default "Starting in {seconds}s")
+
+	// Ken Burns slow zoom/pan over static images; off by default since it slows renders
+	EnableKenBurns    bool    `json:"enable_ken_burns" db:"enable_ken_burns"`
+	KenBurnsZoomRate  float64 `json:"ken_burns_zoom_rate" db:"ken_burns_zoom_rate"` // Zoom increase per second (default 0.01)
+	KenBurnsDirection string  `json:"ken_burns_direction" db:"ken_burns_direction"` // in (default), out, pan-left, pan-right
 
 	// Karaoke customization
 	KaraokeFontFamily           string `json:"karaoke_font_family" db:"karaoke_font_family"`
@@ -73,6 +140,11 @@ type Song struct {
 	KaraokeHighlightBorderColor string `json:"karaoke_highlight_border_color" db:"karaoke_highlight_border_color"`
 	KaraokeAlignment            int    `json:"karaoke_alignment" db:"karaoke_alignment"`
 	KaraokeMarginBottom         int    `json:"karaoke_margin_bottom" db:"karaoke_margin_bottom"`
+	KaraokeWhisperModel         string `json:"karaoke_whisper_model" db:"karaoke_whisper_model"` // tiny, base, small, medium, large-v3
+
+	// Seconds added to every karaoke subtitle event to fix lyrics that lead
+	// or lag the audio (negative shows them earlier)
+	KaraokeTimingOffset float64 `json:"karaoke_timing_offset" db:"karaoke_timing_offset"`
 
 	// AI-powered metadata enrichment
 	GenrePrimary       string     `json:"genre_primary,omitempty" db:"genre_primary"`
@@ -88,6 +160,10 @@ type Song struct {
 	VocalStyle         string     `json:"vocal_style,omitempty" db:"vocal_style"`
 	MetadataEnrichedAt *time.Time `json:"metadata_enriched_at,omitempty" db:"metadata_enriched_at"`
 	MetadataVersion    int        `json:"metadata_version,omitempty" db:"metadata_version"`
+
+	// Workflow settings
+	RequireImageApproval bool `json:"require_image_approval" db:"require_image_approval"` // Pause before rendering until images are approved
+	Instrumental         bool `json:"instrumental" db:"instrumental"`                     // No vocals: skip lyrics and karaoke
 }
 
 // QueueItem represents a job in the processing queue
@@ -106,11 +182,34 @@ type QueueItem struct {
 	VideoFileSize int64  `json:"video_file_size" db:"video_file_size"`
 	ThumbnailPath string `json:"thumbnail_path" db:"thumbnail_path"`
 
-	Flag *string `json:"flag" db:"flag"` // User-reported issue: image_issue, lyrics_issue, timing_issue
+	Flag *string `json:"flag" db:"flag"` // Reported issue: image_issue, lyrics_issue, timing_issue (also set automatically for implausible lyric timing)
+
+	LastPhase string `json:"last_phase" db:"last_phase"` // Last successfully completed pipeline phase
 
-	QueuedAt    time.Time  `json:"queued_at" db:"queued_at"`
-	StartedAt   *time.Time `json:"started_at" db:"started_at"`
-	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	Draft bool `json:"draft" db:"draft"` // Low-resolution preview render; never uploaded or recorded as the song's video
+
+	RequestID string `json:"request_id" db:"request_id"` // X-Request-ID of the API call that queued the item
+
+	QueuedAt      time.Time  `json:"queued_at" db:"queued_at"`
+	ScheduledAt   *time.Time `json:"scheduled_at" db:"scheduled_at"` // Not started before this time; nil runs as soon as possible
+	StartedAt     *time.Time `json:"started_at" db:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at" db:"completed_at"`
+	LastHeartbeat *time.Time `json:"last_heartbeat" db:"last_heartbeat"` // Updated periodically while processing
+}
+
+// QueueEvent is one entry in a queue item's history: a status change or the
+// start of a pipeline phase
+type QueueEvent struct {
+	ID         int       `json:"id" db:"id"`
+	QueueID    int       `json:"queue_id" db:"queue_id"`
+	Status     string    `json:"status" db:"status"`
+	Step       string    `json:"step" db:"step"`
+	Message    string    `json:"message" db:"message"`
+	RetryCount int       `json:"retry_count" db:"retry_count"` // Failed attempts before this event
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+
+	// Seconds until the next event; nil for the latest
+	DurationSeconds *float64 `json:"duration_seconds"`
 }
 
 // YoutubeUpload represents a YouTube video upload record
@@ -156,9 +255,23 @@ type GeneratedImage struct {
 	Width          int       `json:"width" db:"width"`
 	Height         int       `json:"height" db:"height"`
 	Model          string    `json:"model" db:"model"`
+	Approved       bool      `json:"approved" db:"approved"`
+	Description    *string   `json:"description,omitempty" db:"description"`         // Vision-model alt-text
+	Seed           *int64    `json:"seed,omitempty" db:"seed"`                       // Diffusion seed, nil if unknown
+	ParentImageID  *int      `json:"parent_image_id,omitempty" db:"parent_image_id"` // Active image this is a variant of
+	IsVariant      bool      `json:"is_variant" db:"is_variant"`                     // Candidate only; not used for rendering
+	PHash          *string   `json:"phash,omitempty" db:"phash"`                     // Perceptual hash (hex) for near-duplicate detection
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 }
 
+// Sequence returns the image's section number, or 0 when it has none
+func (img *GeneratedImage) Sequence() int {
+	if img.SequenceNumber == nil {
+		return 0
+	}
+	return *img.SequenceNumber
+}
+
 // Video represents a rendered video file
 type Video struct {
 	ID              int       `json:"id" db:"id"`
@@ -183,11 +296,40 @@ type Video struct {
 	Tempo *string  `json:"tempo,omitempty" db:"tempo"`
 	Flag  *string  `json:"flag,omitempty" db:"flag"` // User-reported issues
 
+	// Probed from the rendered file when it was verified
+	Width   int    `json:"width,omitempty" db:"width"`
+	Height  int    `json:"height,omitempty" db:"height"`
+	Streams string `json:"streams,omitempty" db:"streams"` // JSON array of stream summaries
+
+	// Which audio the video carries; variants share the mixed render's visuals
+	Variant string `json:"variant" db:"variant"` // mixed, instrumental, vocals
+
 	// Joined fields from songs table
 	SongTitle  string `json:"song_title,omitempty" db:"title"`
 	ArtistName string `json:"artist_name,omitempty" db:"artist_name"`
 }
 
+// Video variants: the main render carries the mixed audio, and a song's
+// output variants are re-muxed copies with a single stem
+const (
+	VideoVariantMixed        = "mixed"
+	VideoVariantInstrumental = "instrumental"
+	VideoVariantVocals       = "vocals"
+)
+
+// OutputVariants are the extra variants a song can request
+var OutputVariants = []string{VideoVariantInstrumental, VideoVariantVocals}
+
+// IsValidOutputVariant reports whether variant can be requested in a song's output variants
+func IsValidOutputVariant(variant string) bool {
+	for _, v := range OutputVariants {
+		if v == variant {
+			return true
+		}
+	}
+	return false
+}
+
 // Queue status constants
 const (
 	StatusQueued     = "queued"
@@ -195,21 +337,85 @@ const (
 	StatusCompleted  = "completed"
 	StatusFailed     = "failed"
 	StatusRetrying   = "retrying"
+
+	StatusAwaitingApproval = "awaiting_approval" // Paused after image generation until images are approved
+)
+
+// Pipeline phase constants, in execution order
+const (
+	PhaseSeparation = "separation"
+	PhaseAnalysis   = "analysis"
+	PhaseLyrics     = "lyrics"
+	PhaseImages     = "images"
+	PhaseRender     = "render"
+	PhaseUpload     = "upload"
 )
 
 // Settings represents application-wide settings
 type Settings struct {
-	ID                   int       `json:"id" db:"id"`
-	MasterPrompt         string    `json:"master_prompt" db:"master_prompt"`
-	MasterNegativePrompt string    `json:"master_negative_prompt" db:"master_negative_prompt"`
-	BrandLogoPath        string    `json:"brand_logo_path" db:"brand_logo_path"`
-	DataStoragePath      string    `json:"data_storage_path" db:"data_storage_path"`
-	CreatedAt            time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+	ID                   int    `json:"id" db:"id"`
+	MasterPrompt         string `json:"master_prompt" db:"master_prompt"`
+	MasterNegativePrompt string `json:"master_negative_prompt" db:"master_negative_prompt"`
+	BrandLogoPath        string `json:"brand_logo_path" db:"brand_logo_path"`
+	DataStoragePath      string `json:"data_storage_path" db:"data_storage_path"`
+
+	// Output naming template, e.g. "{artist}-{title}-{id}"
+	VideoFilenameTemplate string `json:"video_filename_template" db:"video_filename_template"`
+
+	// Encode quality used when a song doesn't set one: draft, standard, high, archive
+	DefaultQuality string `json:"default_quality" db:"default_quality"`
+
+	// Registered font for title, metadata and countdown overlays; empty uses the system font
+	OverlayFont string `json:"overlay_font" db:"overlay_font"`
+
+	// Artist logo overlay: size in pixels, opacity 0-1, corner (top-left,
+	// top-right, bottom-left, bottom-right) and margin in pixels; zero or
+	// empty keeps the built-in 256px, 70%, bottom-right, 20px placement
+	LogoSize    int     `json:"logo_size" db:"logo_size"`
+	LogoOpacity float64 `json:"logo_opacity" db:"logo_opacity"`
+	LogoCorner  string  `json:"logo_corner" db:"logo_corner"`
+	LogoMargin  int     `json:"logo_margin" db:"logo_margin"`
+
+	// House style given to new songs that leave these unset (see
+	// ApplySongDefaults); empty or zero keeps the built-in defaults
+	DefaultBackgroundStyle  string  `json:"default_background_style" db:"default_background_style"`
+	DefaultSpectrumColor    string  `json:"default_spectrum_color" db:"default_spectrum_color"`
+	DefaultSpectrumOpacity  float64 `json:"default_spectrum_opacity" db:"default_spectrum_opacity"`
+	DefaultTargetResolution string  `json:"default_target_resolution" db:"default_target_resolution"`
+	DefaultLyricTheme       string  `json:"default_lyric_theme" db:"default_lyric_theme"`
+
+	// Copyright line for songs that don't set their own (see SongCopyright);
+	// empty draws none
+	DefaultCopyrightText string `json:"default_copyright_text" db:"default_copyright_text"`
+
+	// LLM image prompt template with {section}, {genre}, {mood}, {style} and {lyrics}
+	// fields; empty uses the built-in template. Section overrides are keyed by
+	// section type (verse, chorus, bridge, ...)
+	ImagePromptTemplate         string            `json:"image_prompt_template" db:"image_prompt_template"`
+	ImagePromptSectionTemplates map[string]string `json:"image_prompt_section_templates" db:"image_prompt_section_templates"`
+
+	// Genres the enrichment LLM may choose a primary genre from; empty uses DefaultGenres
+	AllowedGenres []string `json:"allowed_genres" db:"allowed_genres"`
+
+	// Save AI enrichment straight onto songs instead of holding it for review
+	AutoApplyEnrichment bool `json:"auto_apply_enrichment" db:"auto_apply_enrichment"`
+
+	// YouTube description format with {{PLACEHOLDER}} fields; empty uses the built-in template
+	YouTubeDescriptionTemplate string    `json:"youtube_description_template" db:"youtube_description_template"`
+	CreatedAt                  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt                  time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// AllowedGenres are the 15 standardized music genres for TrackStudio
-var AllowedGenres = []string{
+// Where a song's genre came from
+const (
+	GenreSourceUser     = "user"     // Entered by the user
+	GenreSourceAnalysis = "analysis" // Detected by the audio analyzer
+	GenreSourceAI       = "ai"       // Inferred by the LLM from the title and lyrics
+)
+
+// DefaultGenres are the 15 standardized music genres for TrackStudio, used
+// when settings don't define their own list
+var DefaultGenres = []string{
 	"Pop",
 	"Rock",
 	"Hip-Hop/Rap",
@@ -227,16 +433,75 @@ var AllowedGenres = []string{
 	"Ballad",
 }
 
-// IsValidGenre checks if a genre is in the allowed list
-func IsValidGenre(genre string) bool {
-	for _, allowed := range AllowedGenres {
-		if allowed == genre {
+// ApplySongDefaults fills a new song's unset render preferences from the
+// settings defaults. Values the song sets itself are kept as overrides.
+func (s *Settings) ApplySongDefaults(song *Song) {
+	if song.BackgroundStyle == "" {
+		song.BackgroundStyle = s.DefaultBackgroundStyle
+	}
+	if song.SpectrumColor == "" {
+		song.SpectrumColor = s.DefaultSpectrumColor
+	}
+	if song.SpectrumOpacity == 0 {
+		song.SpectrumOpacity = s.DefaultSpectrumOpacity
+	}
+	if song.TargetResolution == "" {
+		song.TargetResolution = s.DefaultTargetResolution
+	}
+	if song.LyricTheme == "" {
+		song.LyricTheme = s.DefaultLyricTheme
+	}
+}
+
+// SongCopyright returns the copyright line for a song: its own text when set
+// (empty for none), otherwise the settings default. Settings may be nil.
+func (s *Settings) SongCopyright(song *Song) string {
+	if song.CopyrightText != nil {
+		return *song.CopyrightText
+	}
+	if s == nil {
+		return ""
+	}
+	return s.DefaultCopyrightText
+}
+
+// Genres returns the configured genre list, or DefaultGenres when none is set
+func (s *Settings) Genres() []string {
+	if len(s.AllowedGenres) > 0 {
+		return s.AllowedGenres
+	}
+	return DefaultGenres
+}
+
+// IsValidGenre checks if a genre is in the allowed list (DefaultGenres when empty)
+func IsValidGenre(genre string, allowed []string) bool {
+	if len(allowed) == 0 {
+		allowed = DefaultGenres
+	}
+	for _, g := range allowed {
+		if g == genre {
 			return true
 		}
 	}
 	return false
 }
 
+// NormalizeGenres trims a genre list and drops blanks and case-insensitive duplicates
+func NormalizeGenres(genres []string) []string {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, g := range genres {
+		g = strings.TrimSpace(g)
+		key := strings.ToLower(g)
+		if g == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, g)
+	}
+	return normalized
+}
+
 // SongMetadataEnrichment represents AI-generated metadata for a song
 type SongMetadataEnrichment struct {
 	GenrePrimary     string   `json:"genre_primary"`
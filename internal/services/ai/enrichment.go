@@ -2,50 +2,62 @@ package ai
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/cqai"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/httputil"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/metrics"
 )
 
 // Client handles AI API calls for metadata enrichment
 type Client struct {
-	baseURL string
-	model   string
-	client  *http.Client
+	baseURL      string
+	model        string
+	timeout      time.Duration
+	settingsRepo *database.SettingsRepository // Source of the allowed genre list
 }
 
-// NewClient creates a new AI client using CQAI/Ollama
-func NewClient() *Client {
-	baseURL := os.Getenv("CQAI_URL")
-	if baseURL == "" {
-		baseURL = "http://cqai.nlaakstudios:11434"
+// NewClient creates a new AI client using the CQAI/Ollama endpoint from config
+func NewClient(cfg *config.Config, settingsRepo *database.SettingsRepository) *Client {
+	return &Client{
+		baseURL:      cfg.CQAILLMURL,
+		model:        cfg.LLMModel,
+		timeout:      120 * time.Second, // Longer timeout for local LLM
+		settingsRepo: settingsRepo,
 	}
+}
 
-	model := os.Getenv("CQAI_LLM_MODEL")
-	if model == "" {
-		model = "qwen2.5:7b"
+// allowedGenres returns the genre list from settings, falling back to the defaults
+func (c *Client) allowedGenres() []string {
+	if c.settingsRepo == nil {
+		return models.DefaultGenres
 	}
-
-	return &Client{
-		baseURL: baseURL,
-		model:   model,
-		client: &http.Client{
-			Timeout: 120 * time.Second, // Longer timeout for local LLM
-		},
+	settings, err := c.settingsRepo.Get()
+	if err != nil {
+		log.Printf("Warning: failed to load settings for genre list: %v", err)
+		return models.DefaultGenres
 	}
+	return settings.Genres()
 }
 
 // EnrichSongMetadata generates AI-powered metadata for a song
 func (c *Client) EnrichSongMetadata(song *models.Song) (*models.SongMetadataEnrichment, error) {
 
+	genres := c.allowedGenres()
+
 	// Build the prompt
-	prompt, err := c.buildPrompt(song)
+	prompt, err := c.buildPrompt(song, genres)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build prompt: %w", err)
 	}
@@ -63,21 +75,21 @@ func (c *Client) EnrichSongMetadata(song *models.Song) (*models.SongMetadataEnri
 	}
 
 	// Validate primary genre
-	if !models.IsValidGenre(metadata.GenrePrimary) {
-		return nil, fmt.Errorf("invalid primary genre: %s (must be one of the 15 allowed genres)", metadata.GenrePrimary)
+	if !models.IsValidGenre(metadata.GenrePrimary, genres) {
+		return nil, fmt.Errorf("invalid primary genre: %s (must be one of: %s)", metadata.GenrePrimary, strings.Join(genres, ", "))
 	}
 
 	return metadata, nil
 }
 
 // buildPrompt creates the enrichment prompt from the template
-func (c *Client) buildPrompt(song *models.Song) (string, error) {
+func (c *Client) buildPrompt(song *models.Song, genres []string) (string, error) {
 	// Read the prompt template
 	templatePath := "track-studio-docs/TODOs/AI-PROMPT-METADATA-ENRICHMENT.txt"
 	templateBytes, err := os.ReadFile(templatePath)
 	if err != nil {
 		// Fallback to embedded prompt if file not found
-		return c.buildEmbeddedPrompt(song), nil
+		return c.buildEmbeddedPrompt(song, genres), nil
 	}
 
 	template := string(templateBytes)
@@ -89,12 +101,13 @@ func (c *Client) buildPrompt(song *models.Song) (string, error) {
 	prompt = strings.ReplaceAll(prompt, "{{LYRICS}}", song.Lyrics)
 	prompt = strings.ReplaceAll(prompt, "{{TITLE}}", song.Title)
 	prompt = strings.ReplaceAll(prompt, "{{ARTIST}}", song.ArtistName)
+	prompt = strings.ReplaceAll(prompt, "{{GENRES}}", strings.Join(genres, ", "))
 
 	return prompt, nil
 }
 
 // buildEmbeddedPrompt creates a minimal prompt when template file is not available
-func (c *Client) buildEmbeddedPrompt(song *models.Song) string {
+func (c *Client) buildEmbeddedPrompt(song *models.Song, genres []string) string {
 	return fmt.Sprintf(`You are a professional music metadata analyst. Analyze this song and provide metadata as JSON.
 
 Song: %s by %s
@@ -107,7 +120,7 @@ Lyrics:
 
 Return ONLY a valid JSON object (no markdown, no explanations):
 {
-  "genre_primary": "One of: Pop, Rock, Hip-Hop/Rap, Country, R&B/Soul, Electronic/Dance, Latin, Metal, Jazz, Blues, Folk, Classical, Reggae, Gospel/Christian, Ballad",
+  "genre_primary": "One of: %s",
   "genre_secondary": ["Genre2", "Genre3"],
   "tags": ["tag1", "tag2", "tag3", "tag4", "tag5", "tag6"],
   "style_descriptors": ["descriptor1", "descriptor2", "descriptor3"],
@@ -118,7 +131,7 @@ Return ONLY a valid JSON object (no markdown, no explanations):
   "target_audience": "Description of ideal listener",
   "energy_level": "Low|Medium-Low|Medium|Medium-High|High",
   "vocal_style": "Description of vocal delivery"
-}`, song.Title, song.ArtistName, song.BPM, song.Key, song.Tempo, song.Lyrics)
+}`, song.Title, song.ArtistName, song.BPM, song.Key, song.Tempo, song.Lyrics, strings.Join(genres, ", "))
 }
 
 // anthropicRequest represents the Claude API request structure
@@ -135,7 +148,9 @@ type ollamaResponse struct {
 }
 
 // callLLM sends the prompt to CQAI/Ollama and returns the response
-func (c *Client) callLLM(prompt string) (string, error) {
+func (c *Client) callLLM(prompt string) (_ string, err error) {
+	defer func() { metrics.ObserveRequest("llm", err) }()
+
 	reqBody := ollamaRequest{
 		Model:  c.model,
 		Prompt: prompt,
@@ -147,14 +162,20 @@ func (c *Client) callLLM(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", c.baseURL+"/api/generate", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	buildRequest := func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/api/generate", bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.client.Do(req)
+	// Requests share the CQAI concurrency limit and queue when it is reached
+	resp, err := httputil.DoWithRetry(context.Background(), "LLM enrichment", buildRequest,
+		func(r *http.Request) (*http.Response, error) { return cqai.Do(r, c.timeout) },
+		nil,
+	)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
 	}
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+)
+
+// genreLyricsLimit caps the lyrics sent for genre inference; the opening
+// verses are enough to classify a song and keep the prompt fast
+const genreLyricsLimit = 2000
+
+// InferGenre classifies a song into one of the allowed genres from its title,
+// artist, lyrics and audio analysis. It's a lighter call than full enrichment
+// for songs the audio analyzer couldn't place.
+func (c *Client) InferGenre(song *models.Song) (string, error) {
+	genres := c.allowedGenres()
+
+	response, err := c.callLLM(buildGenrePrompt(song, genres))
+	if err != nil {
+		return "", fmt.Errorf("failed to call LLM: %w", err)
+	}
+
+	genre, ok := matchGenre(response, genres)
+	if !ok {
+		return "", fmt.Errorf("LLM answered %q, which isn't one of: %s", strings.TrimSpace(response), strings.Join(genres, ", "))
+	}
+	return genre, nil
+}
+
+// buildGenrePrompt asks for a single genre name from the list
+func buildGenrePrompt(song *models.Song, genres []string) string {
+	lyrics := displayLyrics(song)
+	if lyrics == "" {
+		lyrics = "(instrumental or no lyrics available)"
+	}
+	lyrics = truncateRunes(lyrics, genreLyricsLimit)
+
+	return fmt.Sprintf(`Classify this song into exactly one genre.
+
+Song: %s by %s
+BPM: %.1f
+Key: %s
+Tempo: %s
+
+Lyrics:
+%s
+
+Choose one of: %s
+
+Reply with ONLY the genre name exactly as written in the list, nothing else.`,
+		song.Title, song.ArtistName, song.BPM, song.Key, song.Tempo, lyrics, strings.Join(genres, ", "))
+}
+
+// matchGenre finds the allowed genre an LLM reply names, ignoring case,
+// quotes and trailing punctuation
+func matchGenre(response string, genres []string) (string, bool) {
+	answer := strings.TrimSpace(response)
+	if line, _, found := strings.Cut(answer, "\n"); found {
+		answer = strings.TrimSpace(line)
+	}
+	answer = strings.Trim(answer, "\"'`*.")
+	answer = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(answer, "Genre:"), "genre:"))
+
+	for _, genre := range genres {
+		if strings.EqualFold(answer, genre) {
+			return genre, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,252 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+)
+
+// DefaultYouTubeDescriptionTemplate is the description layout used when settings
+// don't provide one. Placeholders:
+//
+//	{{INTRO}}      LLM-written hook paragraph
+//	{{TITLE}}      song title
+//	{{ARTIST}}     artist name
+//	{{GENRE}}      primary genre
+//	{{SUMMARY}}    enrichment summary
+//	{{LYRICS}}     full lyrics (section labels removed)
+//	{{CREDITS}}    artist, genre, key and BPM lines
+//	{{COPYRIGHT}}  song copyright line
+//	{{HASHTAGS}}   first few tags as #hashtags
+const DefaultYouTubeDescriptionTemplate = `{{INTRO}}
+
+{{SUMMARY}}
+
+Lyrics:
+{{LYRICS}}
+
+Credits:
+{{CREDITS}}
+
+{{COPYRIGHT}}
+
+{{HASHTAGS}}`
+
+// YouTube limits for video metadata
+const (
+	youtubeMaxTitle       = 100
+	youtubeMaxDescription = 5000
+	youtubeMaxTagsLength  = 500
+	youtubeHashtags       = 3
+)
+
+// youtubeMetadataResponse is the JSON the LLM returns for YouTube metadata
+type youtubeMetadataResponse struct {
+	Title string   `json:"title"`
+	Intro string   `json:"intro"`
+	Tags  []string `json:"tags"`
+}
+
+// GenerateYouTubeMetadata writes an SEO-friendly title, description and tags for
+// a song using the built-in description template
+func (c *Client) GenerateYouTubeMetadata(song *models.Song) (title, description string, tags []string, err error) {
+	return c.GenerateYouTubeMetadataWithTemplate(song, DefaultYouTubeDescriptionTemplate, "")
+}
+
+// GenerateYouTubeMetadataWithTemplate is GenerateYouTubeMetadata with a custom
+// description template. The LLM only writes the title, intro and tags; lyrics,
+// credits and copyright come straight from the song so they are never paraphrased.
+// defaultCopyright is used for songs without their own copyright text.
+func (c *Client) GenerateYouTubeMetadataWithTemplate(song *models.Song, template, defaultCopyright string) (title, description string, tags []string, err error) {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultYouTubeDescriptionTemplate
+	}
+
+	response, err := c.callLLM(c.buildYouTubePrompt(song))
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to call LLM: %w", err)
+	}
+
+	generated, err := parseYouTubeMetadata(response)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to parse YouTube metadata: %w", err)
+	}
+
+	title = truncateRunes(generated.Title, youtubeMaxTitle)
+	tags = limitTags(append(generated.Tags, jsonList(song.Tags)...), youtubeMaxTagsLength)
+	description = truncateRunes(renderYouTubeDescription(template, song, defaultCopyright, generated.Intro, tags), youtubeMaxDescription)
+
+	return title, description, tags, nil
+}
+
+// buildYouTubePrompt asks for the creative parts of the metadata as JSON
+func (c *Client) buildYouTubePrompt(song *models.Song) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are a YouTube music channel SEO specialist. Write metadata for a lyric video.\n\n")
+	fmt.Fprintf(&b, "Song: %s by %s\n", song.Title, song.ArtistName)
+	if genre := songGenre(song); genre != "" {
+		fmt.Fprintf(&b, "Genre: %s\n", genre)
+	}
+	if moods := jsonList(song.Mood); len(moods) > 0 {
+		fmt.Fprintf(&b, "Mood: %s\n", strings.Join(moods, ", "))
+	}
+	if themes := jsonList(song.Themes); len(themes) > 0 {
+		fmt.Fprintf(&b, "Themes: %s\n", strings.Join(themes, ", "))
+	}
+	if similar := jsonList(song.SimilarArtists); len(similar) > 0 {
+		fmt.Fprintf(&b, "Similar artists: %s\n", strings.Join(similar, ", "))
+	}
+	if song.Summary != "" {
+		fmt.Fprintf(&b, "Summary: %s\n", song.Summary)
+	}
+	fmt.Fprintf(&b, "\nLyrics:\n%s\n\n", song.Lyrics)
+	fmt.Fprintf(&b, `Return ONLY a valid JSON object (no markdown, no explanations):
+{
+  "title": "Video title under 100 characters, e.g. \"%s - %s (Lyric Video)\"",
+  "intro": "2-3 engaging sentences that hook listeners and naturally include searchable keywords",
+  "tags": ["10-15 search tags: genre, mood, themes, similar artists, 'lyric video'"]
+}`, song.ArtistName, song.Title)
+	return b.String()
+}
+
+// parseYouTubeMetadata parses the LLM JSON response
+func parseYouTubeMetadata(response string) (*youtubeMetadataResponse, error) {
+	response = strings.TrimSpace(response)
+	response = strings.TrimPrefix(response, "```json")
+	response = strings.TrimPrefix(response, "```")
+	response = strings.TrimSuffix(response, "```")
+	response = strings.TrimSpace(response)
+
+	var metadata youtubeMetadataResponse
+	if err := json.Unmarshal([]byte(response), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+
+	if strings.TrimSpace(metadata.Title) == "" {
+		return nil, fmt.Errorf("missing required field: title")
+	}
+	if strings.TrimSpace(metadata.Intro) == "" {
+		return nil, fmt.Errorf("missing required field: intro")
+	}
+
+	return &metadata, nil
+}
+
+// renderYouTubeDescription fills the description template's placeholders. The
+// copyright is the song's own, or defaultCopyright when it sets none.
+func renderYouTubeDescription(template string, song *models.Song, defaultCopyright, intro string, tags []string) string {
+	copyright := defaultCopyright
+	if song.CopyrightText != nil {
+		copyright = *song.CopyrightText
+	}
+
+	credits := []string{"Artist: " + song.ArtistName}
+	if genre := songGenre(song); genre != "" {
+		credits = append(credits, "Genre: "+genre)
+	}
+	if song.Key != "" {
+		credits = append(credits, "Key: "+song.Key)
+	}
+	if song.BPM > 0 {
+		credits = append(credits, fmt.Sprintf("BPM: %.0f", song.BPM))
+	}
+
+	var hashtags []string
+	for _, tag := range tags {
+		if len(hashtags) == youtubeHashtags {
+			break
+		}
+		if hashtag := strings.Join(strings.Fields(tag), ""); hashtag != "" {
+			hashtags = append(hashtags, "#"+hashtag)
+		}
+	}
+
+	description := strings.NewReplacer(
+		"{{INTRO}}", strings.TrimSpace(intro),
+		"{{TITLE}}", song.Title,
+		"{{ARTIST}}", song.ArtistName,
+		"{{GENRE}}", songGenre(song),
+		"{{SUMMARY}}", song.Summary,
+		"{{LYRICS}}", displayLyrics(song),
+		"{{CREDITS}}", strings.Join(credits, "\n"),
+		"{{COPYRIGHT}}", copyright,
+		"{{HASHTAGS}}", strings.Join(hashtags, " "),
+	).Replace(template)
+
+	// Drop blank runs left by empty placeholders
+	for strings.Contains(description, "\n\n\n") {
+		description = strings.ReplaceAll(description, "\n\n\n", "\n\n")
+	}
+	return strings.TrimSpace(description)
+}
+
+// displayLyrics returns the lyrics without [Section] labels
+func displayLyrics(song *models.Song) string {
+	lyrics := song.LyricsKaraoke
+	if strings.TrimSpace(lyrics) == "" {
+		lyrics = song.Lyrics
+	}
+
+	var lines []string
+	for _, line := range strings.Split(lyrics, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			continue
+		}
+		lines = append(lines, trimmed)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// songGenre prefers the enriched primary genre over the user-entered one
+func songGenre(song *models.Song) string {
+	if song.GenrePrimary != "" {
+		return song.GenrePrimary
+	}
+	return song.Genre
+}
+
+// jsonList decodes a stored JSON string array, returning nil if empty or invalid
+func jsonList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var values []string
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+// limitTags de-duplicates tags and keeps as many as fit YouTube's total length limit
+func limitTags(tags []string, maxLength int) []string {
+	seen := make(map[string]bool)
+	var kept []string
+	length := 0
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		if length+len(tag)+1 > maxLength {
+			break
+		}
+		seen[key] = true
+		kept = append(kept, tag)
+		length += len(tag) + 1
+	}
+	return kept
+}
+
+// truncateRunes shortens s to at most max characters
+func truncateRunes(s string, max int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max]))
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// audioUploadRetention is how long an untouched chunked upload can sit
+// before it's discarded
+const audioUploadRetention = 24 * time.Hour
+
+// AudioExtensions lists the audio file extensions accepted for song stems
+var AudioExtensions = []string{".wav", ".mp3", ".flac", ".m4a"}
+
+// AudioStemNames maps upload kinds to the conventional stem file base name
+var AudioStemNames = map[string]string{
+	"vocals": "vocal",
+	"music":  "music",
+	"mixed":  "mixed",
+}
+
+// Chunked upload errors, mapped to HTTP statuses by the upload handler
+var (
+	ErrUploadNotFound     = errors.New("upload not found")
+	ErrUploadOffset       = errors.New("chunk offset does not match upload offset")
+	ErrUploadTooLarge     = errors.New("chunk runs past the declared upload size")
+	ErrUploadIncomplete   = errors.New("upload is incomplete")
+	ErrInvalidAudioUpload = errors.New("invalid audio upload")
+)
+
+// AudioUpload is the state of a resumable audio stem upload. Offset is the
+// number of bytes received so far; a client that lost its connection asks
+// for it and sends the rest from there.
+type AudioUpload struct {
+	ID        string    `json:"upload_id"`
+	SongID    int       `json:"song_id"`
+	Kind      string    `json:"kind"` // vocals, music or mixed
+	Extension string    `json:"extension"`
+	Size      int64     `json:"size"`
+	Offset    int64     `json:"offset"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AudioUploads tracks chunked uploads on disk so they survive restarts: each
+// upload is a JSON state file plus a .part file holding the bytes so far
+type AudioUploads struct {
+	dir string
+
+	locks map[string]*sync.Mutex
+	mutex sync.Mutex
+}
+
+// NewAudioUploads stores in-progress uploads under dir
+func NewAudioUploads(dir string) *AudioUploads {
+	return &AudioUploads{
+		dir:   dir,
+		locks: make(map[string]*sync.Mutex),
+	}
+}
+
+// Init starts an upload of size bytes of the song's vocals, music or mixed track
+func (au *AudioUploads) Init(songID int, kind, filename string, size int64) (*AudioUpload, error) {
+	if _, ok := AudioStemNames[kind]; !ok {
+		return nil, fmt.Errorf("%w: kind must be vocals, music or mixed", ErrInvalidAudioUpload)
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: size must be positive", ErrInvalidAudioUpload)
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	if ext == "" {
+		ext = ".mp3" // default, as for direct uploads
+	}
+	if !isAudioExtension(ext) {
+		return nil, fmt.Errorf("%w: unsupported file type %s", ErrInvalidAudioUpload, ext)
+	}
+
+	if err := os.MkdirAll(au.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	au.prune()
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+	upload := &AudioUpload{
+		ID:        id,
+		SongID:    songID,
+		Kind:      kind,
+		Extension: ext,
+		Size:      size,
+		CreatedAt: time.Now(),
+	}
+
+	if err := os.WriteFile(au.partPath(id), nil, 0644); err != nil {
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	if err := au.save(upload); err != nil {
+		os.Remove(au.partPath(id))
+		return nil, err
+	}
+
+	log.Printf("Started chunked %s upload %s for song %d (%d bytes)", kind, id, songID, size)
+	return upload, nil
+}
+
+// Get returns an upload's current state
+func (au *AudioUploads) Get(songID int, id string) (*AudioUpload, error) {
+	return au.load(songID, id)
+}
+
+// WriteChunk appends the bytes read from r at offset, which must equal the
+// bytes received so far. Bytes that arrive before a dropped connection are
+// kept, so the returned offset may fall short of the chunk's end.
+func (au *AudioUploads) WriteChunk(songID int, id string, offset int64, r io.Reader) (*AudioUpload, error) {
+	lock := au.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	upload, err := au.load(songID, id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset {
+		return upload, ErrUploadOffset
+	}
+
+	file, err := os.OpenFile(au.partPath(id), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return upload, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer file.Close()
+
+	// Read one byte past the remaining size to notice oversized chunks
+	remaining := upload.Size - upload.Offset
+	written, copyErr := io.Copy(file, io.LimitReader(r, remaining+1))
+	if written > remaining {
+		// Reject the whole chunk rather than keep a truncated stream
+		if err := file.Truncate(upload.Offset); err != nil {
+			return upload, fmt.Errorf("failed to trim upload file: %w", err)
+		}
+		return upload, ErrUploadTooLarge
+	}
+	upload.Offset += written
+
+	if copyErr != nil {
+		return upload, fmt.Errorf("chunk interrupted after %d bytes: %w", written, copyErr)
+	}
+	return upload, nil
+}
+
+// Finalize moves a complete upload to destPath and forgets it
+func (au *AudioUploads) Finalize(songID int, id, destPath string) error {
+	lock := au.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	upload, err := au.load(songID, id)
+	if err != nil {
+		return err
+	}
+	if upload.Offset != upload.Size {
+		return fmt.Errorf("%w: received %d of %d bytes", ErrUploadIncomplete, upload.Offset, upload.Size)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	if err := os.Rename(au.partPath(id), destPath); err != nil {
+		return fmt.Errorf("failed to move upload into place: %w", err)
+	}
+	au.forget(id)
+
+	log.Printf("Finished chunked %s upload %s for song %d: %s", upload.Kind, id, songID, destPath)
+	return nil
+}
+
+// Abort discards an upload and the bytes received so far
+func (au *AudioUploads) Abort(songID int, id string) error {
+	lock := au.lock(id)
+	lock.Lock()
+	defer lock.Unlock()
+
+	if _, err := au.load(songID, id); err != nil {
+		return err
+	}
+	os.Remove(au.partPath(id))
+	au.forget(id)
+	return nil
+}
+
+// load reads an upload's state, taking the offset from the .part file so it
+// always matches the bytes actually on disk
+func (au *AudioUploads) load(songID int, id string) (*AudioUpload, error) {
+	if !validUploadID(id) {
+		return nil, ErrUploadNotFound
+	}
+
+	data, err := os.ReadFile(au.statePath(id))
+	if os.IsNotExist(err) {
+		return nil, ErrUploadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var upload AudioUpload
+	if err := json.Unmarshal(data, &upload); err != nil {
+		return nil, fmt.Errorf("corrupt upload state %s: %w", id, err)
+	}
+	if upload.SongID != songID {
+		return nil, ErrUploadNotFound
+	}
+
+	info, err := os.Stat(au.partPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("upload file missing for %s: %w", id, err)
+	}
+	upload.Offset = info.Size()
+	return &upload, nil
+}
+
+// save writes an upload's state file
+func (au *AudioUploads) save(upload *AudioUpload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(au.statePath(upload.ID), data, 0644); err != nil {
+		return fmt.Errorf("failed to save upload state: %w", err)
+	}
+	return nil
+}
+
+// forget removes an upload's state file and lock
+func (au *AudioUploads) forget(id string) {
+	os.Remove(au.statePath(id))
+
+	au.mutex.Lock()
+	delete(au.locks, id)
+	au.mutex.Unlock()
+}
+
+// lock returns the mutex serializing writes to one upload
+func (au *AudioUploads) lock(id string) *sync.Mutex {
+	au.mutex.Lock()
+	defer au.mutex.Unlock()
+
+	lock, ok := au.locks[id]
+	if !ok {
+		lock = &sync.Mutex{}
+		au.locks[id] = lock
+	}
+	return lock
+}
+
+// prune discards uploads whose bytes haven't changed in audioUploadRetention
+func (au *AudioUploads) prune() {
+	entries, err := os.ReadDir(au.dir)
+	if err != nil {
+		return
+	}
+
+	cutoff := time.Now().Add(-audioUploadRetention)
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok {
+			continue
+		}
+		au.pruneUpload(id, cutoff)
+	}
+}
+
+// pruneUpload discards one upload if it hasn't been written since cutoff.
+// An upload with a chunk or finalize in progress isn't abandoned, so it's
+// skipped rather than waited on.
+func (au *AudioUploads) pruneUpload(id string, cutoff time.Time) {
+	lock := au.lock(id)
+	if !lock.TryLock() {
+		return
+	}
+	defer lock.Unlock()
+
+	lastWrite, err := os.Stat(au.partPath(id))
+	if err == nil && lastWrite.ModTime().After(cutoff) {
+		return
+	}
+	if _, err := os.Stat(au.statePath(id)); os.IsNotExist(err) {
+		au.forget(id) // finalized or aborted meanwhile; drop the lock
+		return
+	}
+	log.Printf("Discarding abandoned audio upload %s", id)
+	os.Remove(au.partPath(id))
+	au.forget(id)
+}
+
+func (au *AudioUploads) statePath(id string) string {
+	return filepath.Join(au.dir, id+".json")
+}
+
+func (au *AudioUploads) partPath(id string) string {
+	return filepath.Join(au.dir, id+".part")
+}
+
+// newUploadID returns 16 random bytes as hex
+func newUploadID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate upload ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validUploadID accepts only IDs newUploadID could have made, so IDs from
+// URLs can't reach outside the upload directory
+func validUploadID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func isAudioExtension(ext string) bool {
+	for _, e := range AudioExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
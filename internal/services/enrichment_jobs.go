@@ -0,0 +1,215 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services/ai"
+)
+
+// enrichmentJobRetention is how long finished enrichment jobs stay queryable
+const enrichmentJobRetention = time.Hour
+
+// EnrichmentStep is the CurrentStep reported in progress updates for enrichment jobs
+const EnrichmentStep = "enrichment"
+
+// EnrichmentResult is the outcome for one song in a batch enrichment job
+type EnrichmentResult struct {
+	SongID  int    `json:"song_id"`
+	Title   string `json:"title,omitempty"`
+	Status  string `json:"status"` // success, skipped, error
+	Applied bool   `json:"applied,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// EnrichmentJob tracks a background batch enrichment
+type EnrichmentJob struct {
+	ID         string             `json:"id"`
+	Status     string             `json:"status"` // processing, completed
+	Total      int                `json:"total"`
+	Processed  int                `json:"processed"`
+	Success    int                `json:"success"`
+	Skipped    int                `json:"skipped"`
+	Errors     int                `json:"errors"`
+	Results    []EnrichmentResult `json:"results"`
+	CreatedAt  time.Time          `json:"created_at"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+}
+
+// EnrichmentJobs runs batch enrichments in the background, reporting progress
+// through the ProgressBroadcaster so slow LLMs don't hold HTTP requests open
+type EnrichmentJobs struct {
+	songRepo     *database.SongRepository
+	settingsRepo *database.SettingsRepository
+	aiClient     *ai.Client
+	broadcaster  *ProgressBroadcaster
+
+	jobs   map[string]*EnrichmentJob
+	nextID int
+	mutex  sync.RWMutex
+}
+
+// NewEnrichmentJobs creates a batch enrichment runner
+func NewEnrichmentJobs(songRepo *database.SongRepository, settingsRepo *database.SettingsRepository, aiClient *ai.Client, broadcaster *ProgressBroadcaster) *EnrichmentJobs {
+	return &EnrichmentJobs{
+		songRepo:     songRepo,
+		settingsRepo: settingsRepo,
+		aiClient:     aiClient,
+		broadcaster:  broadcaster,
+		jobs:         make(map[string]*EnrichmentJob),
+	}
+}
+
+// SaveEnrichment writes enrichment onto the song when settings allow fully
+// automated enrichment, and otherwise holds it for review. It reports whether
+// the song's metadata was updated.
+func SaveEnrichment(songRepo *database.SongRepository, settingsRepo *database.SettingsRepository, songID int, enrichment *models.SongMetadataEnrichment) (bool, error) {
+	autoApply := false
+	if settings, err := settingsRepo.Get(); err != nil {
+		log.Printf("Warning: failed to load settings, holding enrichment for review: %v", err)
+	} else {
+		autoApply = settings.AutoApplyEnrichment
+	}
+
+	if autoApply {
+		return true, songRepo.UpdateMetadataEnrichment(songID, enrichment)
+	}
+	return false, songRepo.SetPendingEnrichment(songID, enrichment)
+}
+
+// Start queues a batch enrichment and returns its job immediately
+func (ej *EnrichmentJobs) Start(songIDs []int, forceRefresh bool) *EnrichmentJob {
+	ej.mutex.Lock()
+	ej.pruneLocked()
+	ej.nextID++
+	job := &EnrichmentJob{
+		ID:        fmt.Sprintf("enrich-%d", ej.nextID),
+		Status:    "processing",
+		Total:     len(songIDs),
+		Results:   make([]EnrichmentResult, 0, len(songIDs)),
+		CreatedAt: time.Now(),
+	}
+	ej.jobs[job.ID] = job
+	snapshot := ej.copyLocked(job)
+	ej.mutex.Unlock()
+
+	log.Printf("Started enrichment job %s for %d songs", job.ID, len(songIDs))
+	go ej.run(job, songIDs, forceRefresh)
+	return snapshot
+}
+
+// Get returns a copy of a job's current state
+func (ej *EnrichmentJobs) Get(id string) (*EnrichmentJob, bool) {
+	ej.mutex.RLock()
+	defer ej.mutex.RUnlock()
+
+	job, ok := ej.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	return ej.copyLocked(job), true
+}
+
+// run enriches each song in turn, broadcasting progress after every song
+func (ej *EnrichmentJobs) run(job *EnrichmentJob, songIDs []int, forceRefresh bool) {
+	ej.broadcast(job, 0, "Enrichment started")
+
+	for _, songID := range songIDs {
+		result := ej.enrichOne(songID, forceRefresh)
+
+		ej.mutex.Lock()
+		job.Results = append(job.Results, result)
+		job.Processed++
+		switch result.Status {
+		case "success":
+			job.Success++
+		case "skipped":
+			job.Skipped++
+		default:
+			job.Errors++
+		}
+		processed := job.Processed
+		ej.mutex.Unlock()
+
+		ej.broadcast(job, songID, fmt.Sprintf("Enriched %d/%d (song %d: %s)", processed, job.Total, songID, result.Status))
+	}
+
+	ej.mutex.Lock()
+	now := time.Now()
+	job.Status = "completed"
+	job.FinishedAt = &now
+	ej.mutex.Unlock()
+
+	ej.broadcast(job, 0, fmt.Sprintf("Enrichment finished: %d succeeded, %d skipped, %d failed",
+		job.Success, job.Skipped, job.Errors))
+	log.Printf("Enrichment job %s finished: %d succeeded, %d skipped, %d failed",
+		job.ID, job.Success, job.Skipped, job.Errors)
+}
+
+// enrichOne enriches a single song for a batch job
+func (ej *EnrichmentJobs) enrichOne(songID int, forceRefresh bool) EnrichmentResult {
+	song, err := ej.songRepo.GetByID(songID)
+	if err != nil || song == nil {
+		return EnrichmentResult{SongID: songID, Status: "error", Message: "Song not found"}
+	}
+
+	// Skip if already enriched (unless force_refresh)
+	if song.MetadataEnrichedAt != nil && !forceRefresh {
+		return EnrichmentResult{SongID: songID, Title: song.Title, Status: "skipped", Message: "Already enriched"}
+	}
+
+	enrichment, err := ej.aiClient.EnrichSongMetadata(song)
+	if err != nil {
+		log.Printf("Error enriching song %d: %v", songID, err)
+		return EnrichmentResult{SongID: songID, Title: song.Title, Status: "error", Message: fmt.Sprintf("AI enrichment failed: %v", err)}
+	}
+
+	applied, err := SaveEnrichment(ej.songRepo, ej.settingsRepo, songID, enrichment)
+	if err != nil {
+		log.Printf("Error saving enrichment for song %d: %v", songID, err)
+		return EnrichmentResult{SongID: songID, Title: song.Title, Status: "error", Message: "Failed to save enrichment"}
+	}
+
+	log.Printf("Successfully enriched song %d: %s", songID, song.Title)
+	return EnrichmentResult{SongID: songID, Title: song.Title, Status: "success", Applied: applied}
+}
+
+// broadcast reports a job's progress to SSE clients
+func (ej *EnrichmentJobs) broadcast(job *EnrichmentJob, songID int, message string) {
+	ej.mutex.RLock()
+	update := ProgressUpdate{
+		JobID:       job.ID,
+		SongID:      songID,
+		Status:      job.Status,
+		CurrentStep: EnrichmentStep,
+		Message:     message,
+	}
+	if job.Total > 0 {
+		update.Progress = job.Processed * 100 / job.Total
+	}
+	ej.mutex.RUnlock()
+
+	ej.broadcaster.Broadcast(update)
+}
+
+// copyLocked returns a copy of job safe to hand out; the caller holds the mutex
+func (ej *EnrichmentJobs) copyLocked(job *EnrichmentJob) *EnrichmentJob {
+	copied := *job
+	copied.Results = append(make([]EnrichmentResult, 0, len(job.Results)), job.Results...)
+	return &copied
+}
+
+// pruneLocked forgets jobs finished longer than enrichmentJobRetention ago;
+// the caller holds the mutex
+func (ej *EnrichmentJobs) pruneLocked() {
+	cutoff := time.Now().Add(-enrichmentJobRetention)
+	for id, job := range ej.jobs {
+		if job.FinishedAt != nil && job.FinishedAt.Before(cutoff) {
+			delete(ej.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/image"
+)
+
+// Repairs applied by ReconcileSongImages
+const (
+	ImageRepointed = "repointed" // Row updated to the file found in the song's image directory
+	ImageCleared   = "cleared"   // Path cleared so the next render regenerates it from its prompt
+	ImageAdopted   = "adopted"   // Row created for the file with a prompt from the vision model
+)
+
+// MissingImageFile is an image row whose file no longer exists
+type MissingImageFile struct {
+	ImageID   int    `json:"image_id"`
+	ImageType string `json:"image_type"`
+	Sequence  int    `json:"sequence"`
+	Path      string `json:"path"`               // Stored image_path
+	FoundAt   string `json:"found_at,omitempty"` // Matching file in the song's image directory
+	Action    string `json:"action,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// OrphanImageFile is an image file in a song's directory that no row points to
+type OrphanImageFile struct {
+	Path      string `json:"path"`
+	ImageType string `json:"image_type"`
+	Sequence  *int   `json:"sequence,omitempty"`
+	ImageID   int    `json:"image_id,omitempty"` // Row created for it
+	Action    string `json:"action,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ImageReconciliation lists where a song's image rows and image files disagree
+type ImageReconciliation struct {
+	SongID       int                `json:"song_id"`
+	MissingFiles []MissingImageFile `json:"missing_files"`
+	OrphanFiles  []OrphanImageFile  `json:"orphan_files"`
+}
+
+// Clean reports whether the song's rows and files agree
+func (r *ImageReconciliation) Clean() bool {
+	return len(r.MissingFiles) == 0 && len(r.OrphanFiles) == 0
+}
+
+// SongImagesDir is the directory a song's background images are written to
+func SongImagesDir(songID int) string {
+	return filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", songID))
+}
+
+// SongImageFiles returns the PNGs in a song's image directory keyed by
+// filename. Variants live in a subdirectory and aren't included.
+func SongImageFiles(songID int) (map[string]string, error) {
+	dir := SongImagesDir(songID)
+	files := make(map[string]string)
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".png") {
+			files[entry.Name()] = filepath.Join(dir, entry.Name())
+		}
+	}
+	return files, nil
+}
+
+// ImageFilePath returns the on-disk path of an image row's file, which is
+// stored either absolute or relative to the data directory, or "" when the
+// row has no file yet
+func ImageFilePath(img *models.GeneratedImage) string {
+	if img.ImagePath == "" || img.ImagePath == "." {
+		return ""
+	}
+	if filepath.IsAbs(img.ImagePath) {
+		return img.ImagePath
+	}
+	return filepath.Join(utils.GetDataPath(), img.ImagePath)
+}
+
+// ImageFileExists reports whether an image row's file is on disk
+func ImageFileExists(img *models.GeneratedImage) bool {
+	path := ImageFilePath(img)
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// storedImagePath converts a file path to the form stored in image_path:
+// relative to the data directory when inside it
+func storedImagePath(path string) string {
+	if rel, err := filepath.Rel(utils.GetDataPath(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// AdoptImageFile creates a row for an image file that has none, taking the
+// section from its filename (bg-verse-2.png) and the prompt from the vision
+// model, so the file is reused instead of regenerated
+func AdoptImageFile(songID int, queueID *int, filePath string, imageGen *image.ImageGenerator) (*models.GeneratedImage, error) {
+	filename := filepath.Base(filePath)
+	imageType, sequence := ParseImageFilename(filename)
+	if imageType == "" {
+		return nil, fmt.Errorf("couldn't parse image type from filename: %s", filename)
+	}
+
+	log.Printf("Extracting prompt from %s using vision AI...", filename)
+	prompt, err := imageGen.ExtractPromptFromImage(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract prompt from %s: %w", filename, err)
+	}
+
+	img := &models.GeneratedImage{
+		SongID:         songID,
+		QueueID:        queueID,
+		ImagePath:      storedImagePath(filePath),
+		Prompt:         prompt,
+		ImageType:      imageType,
+		SequenceNumber: sequence,
+		Width:          imageGen.Width,
+		Height:         imageGen.Height,
+		Model:          imageGen.ImageModel,
+	}
+	if err := database.CreateGeneratedImage(img); err != nil {
+		return nil, fmt.Errorf("failed to create database entry for %s: %w", filename, err)
+	}
+	HashImageFile(img.ID, filePath)
+
+	log.Printf("Successfully reverse-engineered prompt for %s (type: %s)", filename, imageType)
+	return img, nil
+}
+
+// ParseImageFilename extracts the section type and number from an image
+// filename: bg-verse-1.png is ("verse", 1), bg-chorus.png is ("chorus", nil)
+func ParseImageFilename(filename string) (string, *int) {
+	name := strings.TrimPrefix(strings.TrimSuffix(filename, ".png"), "bg-")
+
+	parts := strings.Split(name, "-")
+	if len(parts) == 2 {
+		var sequence int
+		if _, err := fmt.Sscanf(parts[1], "%d", &sequence); err == nil {
+			return parts[0], &sequence
+		}
+	}
+	// No sequence number, or hyphens that are part of the type name
+	return name, nil
+}
+
+// ReconcileSongImages compares a song's active image rows with the files in
+// its image directory. Rows with a file path that no longer exists are
+// repointed to an unclaimed file in the directory with the same name or the
+// section's usual name (bg-verse-2.png), or have their path cleared
+// so the next render regenerates them from the stored prompt; rows still
+// waiting for their first image aren't discrepancies. Files no row points to
+// are adopted with AdoptImageFile. With fix false nothing is changed.
+func ReconcileSongImages(songID int, imageGen *image.ImageGenerator, fix bool) (*ImageReconciliation, error) {
+	files, err := SongImageFiles(songID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read images for song %d: %w", songID, err)
+	}
+	images, err := database.GetImagesBySongID(songID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get images for song %d: %w", songID, err)
+	}
+
+	result := &ImageReconciliation{
+		SongID:       songID,
+		MissingFiles: []MissingImageFile{},
+		OrphanFiles:  []OrphanImageFile{},
+	}
+
+	claimed := make(map[string]bool)
+	for i := range images {
+		if ImageFileExists(&images[i]) {
+			claimed[filepath.Clean(ImageFilePath(&images[i]))] = true
+		}
+	}
+
+	for i := range images {
+		img := &images[i]
+		if ImageFilePath(img) == "" || ImageFileExists(img) {
+			continue
+		}
+
+		missing := MissingImageFile{
+			ImageID:   img.ID,
+			ImageType: img.ImageType,
+			Sequence:  img.Sequence(),
+			Path:      img.ImagePath,
+		}
+		newPath := ""
+		missing.Action = ImageCleared
+		for _, name := range []string{filepath.Base(img.ImagePath), image.SectionImageFilename(img.ImageType, img.Sequence())} {
+			if found, ok := files[name]; ok && !claimed[found] {
+				missing.FoundAt = found
+				missing.Action = ImageRepointed
+				newPath = storedImagePath(found)
+				claimed[found] = true
+				break
+			}
+		}
+
+		if fix {
+			if err := database.UpdateImagePath(img.ID, newPath); err != nil {
+				missing.Error = err.Error()
+			} else if newPath != "" {
+				HashImageFile(img.ID, missing.FoundAt)
+			}
+		}
+		result.MissingFiles = append(result.MissingFiles, missing)
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := files[name]
+		if claimed[path] {
+			continue
+		}
+
+		orphan := OrphanImageFile{Path: path, Action: ImageAdopted}
+		orphan.ImageType, orphan.Sequence = ParseImageFilename(name)
+		if fix {
+			if img, err := AdoptImageFile(songID, nil, path, imageGen); err != nil {
+				orphan.Error = err.Error()
+			} else {
+				orphan.ImageID = img.ID
+			}
+		}
+		result.OrphanFiles = append(result.OrphanFiles, orphan)
+	}
+
+	return result, nil
+}
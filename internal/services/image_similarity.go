@@ -0,0 +1,121 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/image"
+)
+
+// SimilarImagePair is a near-duplicate pair of a song's images
+type SimilarImagePair struct {
+	image.SimilarPair
+	SectionA   string  `json:"section_a"`
+	SectionB   string  `json:"section_b"`
+	Similarity float64 `json:"similarity"` // 1.0 means identical hashes
+}
+
+// ImageSimilarityReport lists a song's images that look too much alike
+type ImageSimilarityReport struct {
+	SongID    int                `json:"song_id"`
+	Threshold int                `json:"threshold"`
+	Checked   int                `json:"images_checked"`
+	Unhashed  []int              `json:"unhashed,omitempty"` // Images whose files couldn't be hashed
+	Pairs     []SimilarImagePair `json:"similar_pairs"`
+}
+
+// HashImageFile computes the perceptual hash of an image record's file and
+// stores it, returning the hex hash. Failures are logged, not returned, so
+// callers can hash opportunistically after writing an image.
+func HashImageFile(imageID int, imagePath string) (string, bool) {
+	if imagePath == "" || imagePath == "." {
+		return "", false
+	}
+	if !filepath.IsAbs(imagePath) {
+		imagePath = filepath.Join(utils.GetDataPath(), imagePath)
+	}
+
+	hash, err := image.PerceptualHash(imagePath)
+	if err != nil {
+		log.Printf("Warning: failed to hash image %d: %v", imageID, err)
+		return "", false
+	}
+
+	phash := image.FormatHash(hash)
+	if err := database.UpdateImageHash(imageID, phash); err != nil {
+		log.Printf("Warning: failed to store hash for image %d: %v", imageID, err)
+	}
+	return phash, true
+}
+
+// BuildImageSimilarityReport compares the perceptual hashes of a song's
+// active images, hashing any that predate hashing, and reports every pair at
+// most threshold bits apart
+func BuildImageSimilarityReport(songID, threshold int) (*ImageSimilarityReport, error) {
+	images, err := database.GetImagesBySongID(songID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImageSimilarityReport{
+		SongID:    songID,
+		Threshold: threshold,
+		Pairs:     []SimilarImagePair{},
+	}
+
+	sections := make(map[int]string, len(images))
+	paths := make(map[int]string, len(images))
+	var hashed []image.HashedImage
+	for _, img := range images {
+		if img.ImagePath == "" || img.ImagePath == "." {
+			continue
+		}
+		report.Checked++
+		sections[img.ID] = imageSectionLabel(img)
+		paths[img.ID] = filepath.Base(img.ImagePath)
+
+		var phash string
+		if img.PHash != nil && *img.PHash != "" {
+			phash = *img.PHash
+		} else if computed, ok := HashImageFile(img.ID, img.ImagePath); ok {
+			phash = computed
+		} else {
+			report.Unhashed = append(report.Unhashed, img.ID)
+			continue
+		}
+
+		hash, err := image.ParseHash(phash)
+		if err != nil {
+			log.Printf("Warning: invalid stored hash for image %d: %v", img.ID, err)
+			report.Unhashed = append(report.Unhashed, img.ID)
+			continue
+		}
+		hashed = append(hashed, image.HashedImage{ID: img.ID, Hash: hash})
+	}
+
+	for _, pair := range image.SimilarPairs(hashed, threshold) {
+		// Sections deliberately sharing one file (e.g. repeated choruses) aren't duplicates
+		if paths[pair.A] == paths[pair.B] {
+			continue
+		}
+		report.Pairs = append(report.Pairs, SimilarImagePair{
+			SimilarPair: pair,
+			SectionA:    sections[pair.A],
+			SectionB:    sections[pair.B],
+			Similarity:  1 - float64(pair.Distance)/64,
+		})
+	}
+	return report, nil
+}
+
+// imageSectionLabel names the section an image belongs to, e.g. "verse 2"
+func imageSectionLabel(img models.GeneratedImage) string {
+	if img.SequenceNumber != nil && *img.SequenceNumber > 0 {
+		return fmt.Sprintf("%s %d", img.ImageType, *img.SequenceNumber)
+	}
+	return img.ImageType
+}
@@ -3,81 +3,292 @@ package services
 import (
 	"encoding/json"
 	"log"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/metrics"
 )
 
+// Backpressure policy: every subscriber gets a bounded buffer and Broadcast
+// never blocks. When a subscriber's buffer is full its oldest update is dropped
+// to make room for the newest, since progress updates supersede each other. A
+// subscriber that stops reading entirely is disconnected (its channel closed)
+// after maxConsecutiveDrops updates in a row were dropped.
+const (
+	subscriberBuffer    = 64
+	maxConsecutiveDrops = 256
+)
+
+// eventHistorySize is how many recent updates are kept for replay to clients
+// reconnecting with Last-Event-ID. It matches the subscriber buffer so a full
+// replay always fits; a client that missed more gets the snapshot instead.
+const eventHistorySize = subscriberBuffer
+
+// snapshotRetention is how long the last update of a finished queue item is
+// kept for late-joining clients
+const snapshotRetention = time.Hour
+
 // ProgressUpdate represents a progress update event
 type ProgressUpdate struct {
-	QueueID      int       `json:"queue_id"`
-	SongID       int       `json:"song_id"`
-	Status       string    `json:"status"`
-	CurrentStep  string    `json:"current_step"`
-	Progress     int       `json:"progress"`
-	Message      string    `json:"message"`
-	ErrorMessage string    `json:"error_message,omitempty"`
-	Timestamp    time.Time `json:"timestamp"`
+	EventID      int64      `json:"event_id"` // Increases with every broadcast; sent as the SSE id
+	QueueID      int        `json:"queue_id"`
+	JobID        string     `json:"job_id,omitempty"` // Set for background jobs outside the queue (e.g. batch enrichment)
+	SongID       int        `json:"song_id"`
+	Status       string     `json:"status"`
+	CurrentStep  string     `json:"current_step"`
+	Progress     int        `json:"progress"`
+	Message      string     `json:"message"`
+	ErrorMessage string     `json:"error_message,omitempty"`
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty"` // When a queued item is scheduled to start
+	Timestamp    time.Time  `json:"timestamp"`
+}
+
+// subscriber tracks delivery for one subscribed client
+type subscriber struct {
+	id          int
+	dropped     atomic.Int64 // Updates dropped over the subscription's lifetime
+	consecutive atomic.Int64 // Updates dropped since the client last kept up
+}
+
+// SubscriberStats reports delivery for one subscribed client
+type SubscriberStats struct {
+	ID      int   `json:"id"`
+	Dropped int64 `json:"dropped"`
 }
 
-// ProgressBroadcaster manages SSE connections for live progress updates
+// ProgressBroadcaster manages SSE connections for live progress updates. It
+// also retains the latest update per queue ID so late joiners can render the
+// current state without waiting for the next event, and the most recent
+// updates so reconnecting clients can catch up on what they missed.
 type ProgressBroadcaster struct {
-	clients map[chan ProgressUpdate]bool
+	clients map[chan ProgressUpdate]*subscriber
+	nextID  int
 	mutex   sync.RWMutex
+
+	// Guarded by mutex: event IDs are assigned and recorded in the same
+	// critical section as delivery, so replay and live updates never overlap
+	lastEventID int64
+	history     []ProgressUpdate
+
+	snapshots map[int]ProgressUpdate
+	snapMutex sync.RWMutex
 }
 
 // NewProgressBroadcaster creates a new progress broadcaster
 func NewProgressBroadcaster() *ProgressBroadcaster {
 	return &ProgressBroadcaster{
-		clients: make(map[chan ProgressUpdate]bool),
+		clients:   make(map[chan ProgressUpdate]*subscriber),
+		snapshots: make(map[int]ProgressUpdate),
 	}
 }
 
-// Subscribe adds a new client to receive progress updates
-func (pb *ProgressBroadcaster) Subscribe() chan ProgressUpdate {
+// Subscribe adds a new client to receive progress updates. A client
+// reconnecting with the ID of the last event it saw (lastEventID > 0) is sent
+// the updates it missed; otherwise, or if they are no longer all buffered, the
+// channel is seeded with the current snapshot, so the client's first updates
+// describe the state of every known queue item. The channel is closed if the
+// client falls too far behind (see the backpressure policy), so readers must
+// stop when it is closed.
+func (pb *ProgressBroadcaster) Subscribe(lastEventID int64) chan ProgressUpdate {
 	pb.mutex.Lock()
 	defer pb.mutex.Unlock()
 
-	client := make(chan ProgressUpdate, 10)
-	pb.clients[client] = true
-	log.Printf("Client subscribed to progress updates. Total clients: %d", len(pb.clients))
+	pb.nextID++
+	client := make(chan ProgressUpdate, subscriberBuffer)
+
+	// Seeding under the client lock orders it before any broadcast this
+	// client receives, since Broadcast records events before delivering
+	seed, replayed := pb.missedSince(lastEventID)
+	if !replayed {
+		seed = pb.Snapshot()
+	}
+	if len(seed) > subscriberBuffer {
+		seed = seed[len(seed)-subscriberBuffer:]
+	}
+	for _, update := range seed {
+		client <- update
+	}
+
+	pb.clients[client] = &subscriber{id: pb.nextID}
+	if replayed {
+		log.Printf("Client %d resumed progress updates after event %d (%d replayed). Total clients: %d",
+			pb.nextID, lastEventID, len(seed), len(pb.clients))
+	} else {
+		log.Printf("Client %d subscribed to progress updates. Total clients: %d", pb.nextID, len(pb.clients))
+	}
 	return client
 }
 
+// missedSince returns the buffered updates after lastEventID. It reports false
+// when there is nothing to resume from or some missed updates were already
+// evicted from the history. The caller must hold pb.mutex.
+func (pb *ProgressBroadcaster) missedSince(lastEventID int64) ([]ProgressUpdate, bool) {
+	if lastEventID <= 0 || lastEventID > pb.lastEventID {
+		return nil, false
+	}
+	if lastEventID == pb.lastEventID {
+		return nil, true
+	}
+	if len(pb.history) == 0 || pb.history[0].EventID > lastEventID+1 {
+		return nil, false
+	}
+
+	start := sort.Search(len(pb.history), func(i int) bool { return pb.history[i].EventID > lastEventID })
+	return append([]ProgressUpdate(nil), pb.history[start:]...), true
+}
+
 // Unsubscribe removes a client from receiving updates
 func (pb *ProgressBroadcaster) Unsubscribe(client chan ProgressUpdate) {
 	pb.mutex.Lock()
 	defer pb.mutex.Unlock()
 
-	if _, ok := pb.clients[client]; ok {
+	if sub, ok := pb.clients[client]; ok {
 		delete(pb.clients, client)
 		close(client)
-		log.Printf("Client unsubscribed from progress updates. Total clients: %d", len(pb.clients))
+		log.Printf("Client %d unsubscribed from progress updates (%d updates dropped). Total clients: %d",
+			sub.id, sub.dropped.Load(), len(pb.clients))
 	}
 }
 
-// Broadcast sends a progress update to all connected clients
+// Broadcast sends a progress update to all connected clients without blocking.
+// Slow clients lose their oldest buffered update; stalled clients are disconnected.
 func (pb *ProgressBroadcaster) Broadcast(update ProgressUpdate) {
-	pb.mutex.RLock()
-	defer pb.mutex.RUnlock()
-
 	update.Timestamp = time.Now()
 
-	for client := range pb.clients {
-		select {
-		case client <- update:
-			// Successfully sent
-		default:
-			// Client buffer full, skip
-			log.Printf("Warning: Client buffer full, skipping update for queue_id=%d", update.QueueID)
+	var stalled []chan ProgressUpdate
+	pb.mutex.Lock()
+	pb.lastEventID++
+	update.EventID = pb.lastEventID
+	// Background jobs and queue-wide events (no queue ID) have no item state to keep
+	if update.JobID == "" && update.QueueID != 0 {
+		pb.recordSnapshot(update)
+	}
+	pb.history = append(pb.history, update)
+	if len(pb.history) > eventHistorySize {
+		pb.history = pb.history[1:]
+	}
+	for client, sub := range pb.clients {
+		if pb.deliver(client, sub, update) {
+			sub.consecutive.Store(0)
+			continue
 		}
+
+		sub.dropped.Add(1)
+		metrics.ProgressUpdatesDropped.Inc()
+		if sub.consecutive.Add(1) >= maxConsecutiveDrops {
+			stalled = append(stalled, client)
+		}
+	}
+	pb.mutex.Unlock()
+
+	for _, client := range stalled {
+		pb.disconnect(client)
 	}
 
+	if update.JobID != "" {
+		log.Printf("Progress update broadcast: job_id=%s, step=%s, progress=%d%%",
+			update.JobID, update.CurrentStep, update.Progress)
+		return
+	}
 	log.Printf("Progress update broadcast: queue_id=%d, step=%s, progress=%d%%",
 		update.QueueID, update.CurrentStep, update.Progress)
 }
 
+// deliver sends update to a client, evicting the client's oldest buffered
+// update if its buffer is full. It reports false if an update was dropped.
+func (pb *ProgressBroadcaster) deliver(client chan ProgressUpdate, sub *subscriber, update ProgressUpdate) bool {
+	select {
+	case client <- update:
+		return true
+	default:
+	}
+
+	// Buffer full: drop the oldest update (unless the client just read it)
+	select {
+	case <-client:
+	default:
+	}
+
+	select {
+	case client <- update:
+	default:
+		// Another broadcast refilled the slot first; this update is the one dropped
+	}
+	return false
+}
+
+// disconnect removes a stalled client, closing its channel so the reader exits
+func (pb *ProgressBroadcaster) disconnect(client chan ProgressUpdate) {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+
+	if sub, ok := pb.clients[client]; ok {
+		delete(pb.clients, client)
+		close(client)
+		metrics.ProgressClientsDisconnected.Inc()
+		log.Printf("Warning: disconnected stalled progress client %d after %d consecutive dropped updates (%d total)",
+			sub.id, sub.consecutive.Load(), sub.dropped.Load())
+	}
+}
+
+// recordSnapshot stores update as the latest state of its queue item and
+// forgets finished items that have been idle past snapshotRetention
+func (pb *ProgressBroadcaster) recordSnapshot(update ProgressUpdate) {
+	pb.snapMutex.Lock()
+	defer pb.snapMutex.Unlock()
+
+	pb.snapshots[update.QueueID] = update
+
+	cutoff := update.Timestamp.Add(-snapshotRetention)
+	for queueID, snap := range pb.snapshots {
+		if isFinished(snap.Status) && snap.Timestamp.Before(cutoff) {
+			delete(pb.snapshots, queueID)
+		}
+	}
+}
+
+// ForgetSnapshots drops the snapshots of queue items that no longer exist,
+// so late joiners aren't sent them
+func (pb *ProgressBroadcaster) ForgetSnapshots(queueIDs ...int) {
+	pb.snapMutex.Lock()
+	defer pb.snapMutex.Unlock()
+
+	for _, queueID := range queueIDs {
+		delete(pb.snapshots, queueID)
+	}
+}
+
+// Snapshot returns the latest update for every known queue item, oldest first
+func (pb *ProgressBroadcaster) Snapshot() []ProgressUpdate {
+	pb.snapMutex.RLock()
+	defer pb.snapMutex.RUnlock()
+
+	snapshot := make([]ProgressUpdate, 0, len(pb.snapshots))
+	for _, update := range pb.snapshots {
+		snapshot = append(snapshot, update)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Timestamp.Before(snapshot[j].Timestamp) })
+	return snapshot
+}
+
+// SnapshotFor returns the latest update for one queue item
+func (pb *ProgressBroadcaster) SnapshotFor(queueID int) (ProgressUpdate, bool) {
+	pb.snapMutex.RLock()
+	defer pb.snapMutex.RUnlock()
+
+	update, ok := pb.snapshots[queueID]
+	return update, ok
+}
+
+// isFinished reports whether a queue status is terminal
+func isFinished(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
+
 // BroadcastFromQueueItem converts a queue item to progress update and broadcasts
 func (pb *ProgressBroadcaster) BroadcastFromQueueItem(item *models.QueueItem, message string) {
 	update := ProgressUpdate{
@@ -89,6 +300,9 @@ func (pb *ProgressBroadcaster) BroadcastFromQueueItem(item *models.QueueItem, me
 		Message:      message,
 		ErrorMessage: item.ErrorMessage,
 	}
+	if item.Status == models.StatusQueued {
+		update.ScheduledAt = item.ScheduledAt
+	}
 	pb.Broadcast(update)
 }
 
@@ -99,12 +313,29 @@ func (pb *ProgressBroadcaster) ClientCount() int {
 	return len(pb.clients)
 }
 
-// FormatSSE formats a progress update as Server-Sent Event
+// ClientStats returns delivery statistics for each connected client
+func (pb *ProgressBroadcaster) ClientStats() []SubscriberStats {
+	pb.mutex.RLock()
+	defer pb.mutex.RUnlock()
+
+	stats := make([]SubscriberStats, 0, len(pb.clients))
+	for _, sub := range pb.clients {
+		stats = append(stats, SubscriberStats{ID: sub.id, Dropped: sub.dropped.Load()})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ID < stats[j].ID })
+	return stats
+}
+
+// FormatSSE formats a progress update as Server-Sent Event. The event ID is
+// sent as the SSE id, which browsers return in Last-Event-ID on reconnect.
 func FormatSSE(update ProgressUpdate) string {
 	data, err := json.Marshal(update)
 	if err != nil {
 		log.Printf("Error marshaling SSE data: %v", err)
 		return ""
 	}
+	if update.EventID > 0 {
+		return "id: " + strconv.FormatInt(update.EventID, 10) + "\ndata: " + string(data) + "\n\n"
+	}
 	return "data: " + string(data) + "\n\n"
 }
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestBroadcastDropsOldestForStalledSubscriber(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	pb := NewProgressBroadcaster()
+	stalled := pb.Subscribe(0)
+	active := pb.Subscribe(0)
+
+	// broadcast sends updates numbered from..to-1, failing if Broadcast blocks
+	broadcast := func(from, to int) {
+		t.Helper()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := from; i < to; i++ {
+				pb.Broadcast(ProgressUpdate{QueueID: 1, Progress: i})
+			}
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Broadcast blocked on a subscriber that never reads")
+		}
+	}
+
+	// receive reads n updates from a subscriber that has them buffered
+	receive := func(client chan ProgressUpdate, n int) []int {
+		t.Helper()
+		var got []int
+		for i := 0; i < n; i++ {
+			select {
+			case update := <-client:
+				got = append(got, update.Progress)
+			default:
+				t.Fatalf("expected %d buffered updates, got %d", n, len(got))
+			}
+		}
+		return got
+	}
+
+	// Fill both buffers, then let the active subscriber catch up while the
+	// stalled one keeps not reading
+	broadcast(0, subscriberBuffer)
+	activeGot := receive(active, subscriberBuffer)
+
+	overflow := 36
+	total := subscriberBuffer + overflow
+	broadcast(subscriberBuffer, total)
+	activeGot = append(activeGot, receive(active, overflow)...)
+
+	// The active subscriber got every update in order
+	for i, progress := range activeGot {
+		if progress != i {
+			t.Fatalf("active subscriber update %d = %d, want %d", i, progress, i)
+		}
+	}
+
+	// The stalled subscriber kept the newest updates; the oldest were dropped
+	stalledGot := receive(stalled, subscriberBuffer)
+	for i, progress := range stalledGot {
+		if want := overflow + i; progress != want {
+			t.Fatalf("stalled subscriber update %d = %d, want %d", i, progress, want)
+		}
+	}
+	select {
+	case update := <-stalled:
+		t.Fatalf("stalled subscriber has an extra update %d", update.Progress)
+	default:
+	}
+
+	// Drops are counted against the stalled subscriber only, and it stays
+	// connected since it didn't reach maxConsecutiveDrops
+	stats := pb.ClientStats()
+	if len(stats) != 2 {
+		t.Fatalf("got %d subscribers, want 2", len(stats))
+	}
+	if stats[0].Dropped != int64(overflow) {
+		t.Errorf("stalled subscriber dropped %d updates, want %d", stats[0].Dropped, overflow)
+	}
+	if stats[1].Dropped != 0 {
+		t.Errorf("active subscriber dropped %d updates, want 0", stats[1].Dropped)
+	}
+}
+
+func TestBroadcastDisconnectsSubscriberThatNeverReads(t *testing.T) {
+	log.SetOutput(io.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+
+	pb := NewProgressBroadcaster()
+	stalled := pb.Subscribe(0)
+
+	for i := 0; i < subscriberBuffer+maxConsecutiveDrops; i++ {
+		pb.Broadcast(ProgressUpdate{QueueID: 1, Progress: i})
+	}
+
+	if count := pb.ClientCount(); count != 0 {
+		t.Fatalf("got %d subscribers, want the stalled one disconnected", count)
+	}
+
+	// The channel is closed after what was buffered, so readers exit
+	for range stalled {
+	}
+}
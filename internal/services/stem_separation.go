@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/storage"
+)
+
+// StemSeparationStep is the CurrentStep reported in progress updates for stem separation
+const StemSeparationStep = "stem_separation"
+
+// Stem separation errors, mapped to HTTP statuses by the audio handler
+var (
+	ErrNoMixedTrack      = errors.New("song has no mixed track to separate")
+	ErrSeparationRunning = errors.New("stem separation already running for this song")
+)
+
+// StemSeparationJob tracks a background stem separation for one song
+type StemSeparationJob struct {
+	ID         string     `json:"id"`
+	SongID     int        `json:"song_id"`
+	Status     string     `json:"status"` // processing, completed, failed
+	Method     string     `json:"method,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// SeparateSongStems splits a song's mixed track into vocal and music stems at
+// the convention paths, replacing stems of other formats, and mirrors them to
+// remote storage when configured. It blocks for the whole separation.
+func SeparateSongStems(separator *audio.Separator, store storage.Storage, songID int) (*audio.StemSeparation, error) {
+	mixedPath := utils.GetSongMixedPath(songID)
+	if mixedPath == "" {
+		return nil, ErrNoMixedTrack
+	}
+
+	audioDir := utils.GetSongAudioDir(songID)
+	separation, err := separator.Separate(mixedPath, audioDir)
+	if err != nil {
+		return nil, err
+	}
+
+	for kind, path := range map[string]string{"vocals": separation.VocalsPath, "music": separation.MusicPath} {
+		RemoveOtherStems(audioDir, AudioStemNames[kind], filepath.Ext(path))
+		if err := storage.Mirror(context.Background(), store, utils.GetDataPath(), path); err != nil {
+			log.Printf("Warning: failed to upload %s to storage: %v", path, err)
+		}
+	}
+
+	log.Printf("Separated stems for song %d using %s", songID, separation.Method)
+	return separation, nil
+}
+
+// RemoveOtherStems deletes a stem's files with extensions other than keepExt
+// so the convention-based lookup finds the new one
+func RemoveOtherStems(audioDir, base, keepExt string) {
+	for _, oldExt := range AudioExtensions {
+		if oldExt != keepExt {
+			os.Remove(filepath.Join(audioDir, base+oldExt)) // Ignore errors if file doesn't exist
+		}
+	}
+}
+
+// StemSeparations runs stem separation in the background, one job per song,
+// reporting through the ProgressBroadcaster since each takes minutes
+type StemSeparations struct {
+	separator   *audio.Separator
+	store       storage.Storage
+	broadcaster *ProgressBroadcaster
+
+	jobs  map[int]*StemSeparationJob // Latest job per song
+	mutex sync.RWMutex
+}
+
+// NewStemSeparations creates a background stem separation runner
+func NewStemSeparations(separator *audio.Separator, store storage.Storage, broadcaster *ProgressBroadcaster) *StemSeparations {
+	return &StemSeparations{
+		separator:   separator,
+		store:       store,
+		broadcaster: broadcaster,
+		jobs:        make(map[int]*StemSeparationJob),
+	}
+}
+
+// Separator returns the separator jobs run with
+func (ss *StemSeparations) Separator() *audio.Separator {
+	return ss.separator
+}
+
+// Start begins separating a song's mixed track and returns its job immediately
+func (ss *StemSeparations) Start(songID int) (*StemSeparationJob, error) {
+	if utils.GetSongMixedPath(songID) == "" {
+		return nil, ErrNoMixedTrack
+	}
+
+	ss.mutex.Lock()
+	if job, ok := ss.jobs[songID]; ok && job.Status == "processing" {
+		ss.mutex.Unlock()
+		return nil, ErrSeparationRunning
+	}
+	job := &StemSeparationJob{
+		ID:        fmt.Sprintf("stems-%d-%d", songID, time.Now().Unix()),
+		SongID:    songID,
+		Status:    "processing",
+		CreatedAt: time.Now(),
+	}
+	ss.jobs[songID] = job
+	snapshot := *job
+	ss.mutex.Unlock()
+
+	log.Printf("Started stem separation job %s for song %d", job.ID, songID)
+	go ss.run(job)
+	return &snapshot, nil
+}
+
+// Get returns a copy of the latest separation job for a song
+func (ss *StemSeparations) Get(songID int) (*StemSeparationJob, bool) {
+	ss.mutex.RLock()
+	defer ss.mutex.RUnlock()
+
+	job, ok := ss.jobs[songID]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *job
+	return &snapshot, true
+}
+
+// run separates the song's stems and records the outcome
+func (ss *StemSeparations) run(job *StemSeparationJob) {
+	ss.broadcast(job, 0, "Separating vocal and music stems (this may take several minutes)")
+
+	separation, err := SeparateSongStems(ss.separator, ss.store, job.SongID)
+
+	ss.mutex.Lock()
+	now := time.Now()
+	job.FinishedAt = &now
+	if err != nil {
+		job.Status = "failed"
+		job.Error = err.Error()
+	} else {
+		job.Status = "completed"
+		job.Method = separation.Method
+	}
+	ss.mutex.Unlock()
+
+	if err != nil {
+		log.Printf("Stem separation job %s failed: %v", job.ID, err)
+		ss.broadcast(job, 100, "Stem separation failed")
+		return
+	}
+	ss.broadcast(job, 100, fmt.Sprintf("Stems separated using %s", separation.Method))
+}
+
+// broadcast reports a job's progress to SSE clients
+func (ss *StemSeparations) broadcast(job *StemSeparationJob, progress int, message string) {
+	ss.mutex.RLock()
+	update := ProgressUpdate{
+		JobID:        job.ID,
+		SongID:       job.SongID,
+		Status:       job.Status,
+		CurrentStep:  StemSeparationStep,
+		Progress:     progress,
+		Message:      message,
+		ErrorMessage: job.Error,
+	}
+	ss.mutex.RUnlock()
+
+	ss.broadcaster.Broadcast(update)
+}
@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -52,20 +53,32 @@ func GetTempPath() string {
 	return filepath.Join(GetDataPath(), "temp")
 }
 
-// GetBrandingPath returns the branding assets directory
-func GetBrandingPath() string {
-	return filepath.Join(GetDataPath(), "branding")
+// GetFontsPath returns the uploaded fonts directory
+func GetFontsPath() string {
+	return filepath.Join(GetDataPath(), "fonts")
 }
 
-// EnsureDataDirectories creates all necessary data directories if they don't exist
-func EnsureDataDirectories() error {
+// GetDebugPath returns the directory render steps are saved to for debugging
+func GetDebugPath() string {
+	return filepath.Join(GetDataPath(), "debug")
+}
+
+// GetSongDebugPath returns the directory a song's render steps are saved to
+func GetSongDebugPath(songID int) string {
+	return filepath.Join(GetDebugPath(), fmt.Sprintf("song_%d", songID))
+}
+
+// EnsureDataDirectories creates all necessary data directories if they don't exist,
+// plus any configured directories outside the data path (e.g. branding)
+func EnsureDataDirectories(extraDirs ...string) error {
 	dirs := []string{
 		GetImagesPath(),
 		GetVideosPath(),
 		GetAudioPath(),
 		GetTempPath(),
-		GetBrandingPath(),
+		GetFontsPath(),
 	}
+	dirs = append(dirs, extraDirs...)
 
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
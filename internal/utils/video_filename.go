@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+)
+
+// DefaultVideoFilenameTemplate matches the original Title_With_Underscores.mp4 naming
+const DefaultVideoFilenameTemplate = "{title}"
+
+var (
+	unsafeFilenameChars = regexp.MustCompile(`[/\\:*?"<>|\x00-\x1f]`)
+	repeatedSeparators  = regexp.MustCompile(`_{2,}`)
+)
+
+// BuildVideoFilename renders a video filename (with .mp4 extension) from a template.
+// Supported placeholders: {title}, {artist}, {id}, {genre}, {key}
+func BuildVideoFilename(song *models.Song, template string) string {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultVideoFilenameTemplate
+	}
+
+	replacer := strings.NewReplacer(
+		"{title}", song.Title,
+		"{artist}", song.ArtistName,
+		"{id}", strconv.Itoa(song.ID),
+		"{genre}", song.Genre,
+		"{key}", song.Key,
+	)
+	name := SanitizeFilename(replacer.Replace(template))
+
+	if name == "" {
+		name = fmt.Sprintf("song_%d", song.ID)
+	}
+
+	return name + ".mp4"
+}
+
+// SanitizeFilename removes filesystem-unsafe characters and replaces spaces with underscores
+func SanitizeFilename(name string) string {
+	name = unsafeFilenameChars.ReplaceAllString(name, "")
+	name = strings.ReplaceAll(strings.TrimSpace(name), " ", "_")
+	name = repeatedSeparators.ReplaceAllString(name, "_")
+	return strings.Trim(name, "._-")
+}
+
+// DownloadFilename returns a human-readable "Artist - Title.mp4" name for
+// serving a video as an attachment. Unsafe characters are removed but spaces
+// are kept; the name falls back to the title alone, then video_<id>.
+func DownloadFilename(artist, title string, videoID int) string {
+	clean := func(s string) string {
+		return strings.Join(strings.Fields(unsafeFilenameChars.ReplaceAllString(s, "")), " ")
+	}
+	artist, title = clean(artist), clean(title)
+
+	name := title
+	if artist != "" && title != "" {
+		name = artist + " - " + title
+	}
+	name = strings.Trim(name, ". ")
+	if name == "" {
+		name = fmt.Sprintf("video_%d", videoID)
+	}
+
+	return name + ".mp4"
+}
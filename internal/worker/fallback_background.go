@@ -0,0 +1,66 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/image"
+)
+
+// fallbackBackgrounds generates stand-in backgrounds for sections whose AI
+// image is missing, so a render succeeds even when CQAI is down. They're
+// written to a subdirectory the image scan ignores and regenerated each
+// render, so a later real image always takes precedence.
+type fallbackBackgrounds struct {
+	dir    string
+	mood   string
+	width  int
+	height int
+
+	generated map[string]string // Image name -> fallback path, so repeated sections share one
+}
+
+// fallbackBackgrounds returns the song's fallback generator, or nil when
+// fallbacks are disabled
+func (p *Processor) fallbackBackgrounds(song *models.Song, imageDir string) *fallbackBackgrounds {
+	if !p.config.FallbackBackgrounds {
+		return nil
+	}
+	return &fallbackBackgrounds{
+		dir:       filepath.Join(imageDir, "fallback"),
+		mood:      strings.TrimSpace(image.FormatMood(song.Mood) + " " + song.Genre),
+		width:     p.config.ImageWidth,
+		height:    p.config.ImageHeight,
+		generated: make(map[string]string),
+	}
+}
+
+// path returns a fallback background for the named section image,
+// generating it on first use
+func (fb *fallbackBackgrounds) path(imageName string) (string, error) {
+	if fb == nil {
+		return "", fmt.Errorf("fallback backgrounds disabled")
+	}
+	if path, ok := fb.generated[imageName]; ok {
+		return path, nil
+	}
+
+	path := filepath.Join(fb.dir, imageName)
+	if err := image.GenerateFallbackBackground(fb.mood, fb.width, fb.height, path); err != nil {
+		return "", err
+	}
+	fb.generated[imageName] = path
+	log.Printf("Using fallback background for %s (mood: %q)", imageName, fb.mood)
+	return path, nil
+}
+
+// used returns how many fallback backgrounds were generated
+func (fb *fallbackBackgrounds) used() int {
+	if fb == nil {
+		return 0
+	}
+	return len(fb.generated)
+}
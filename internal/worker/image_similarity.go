@@ -0,0 +1,103 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/image"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/logger"
+)
+
+// distinctPromptSuffix steers a regenerated near-duplicate away from its twin
+const distinctPromptSuffix = ", distinctly different composition, camera angle and color palette"
+
+// checkImageSimilarity warns when sections ended up with near-identical
+// backgrounds and, when configured, regenerates the later image of each pair
+// once with a prompt nudged toward a different look
+func (p *Processor) checkImageSimilarity(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) {
+	threshold := p.config.ImageSimilarityThreshold
+	if threshold < 0 {
+		return
+	}
+
+	report, err := services.BuildImageSimilarityReport(song.ID, threshold)
+	if err != nil {
+		log.Printf("Warning: failed to check image similarity for song %d: %v", song.ID, err)
+		return
+	}
+
+	for _, pair := range report.Pairs {
+		msg := fmt.Sprintf("Images for %s (%d) and %s (%d) look nearly identical (%d/64 bits differ)",
+			pair.SectionA, pair.A, pair.SectionB, pair.B, pair.Distance)
+		log.Printf("Warning: %s", msg)
+		if renderLog != nil {
+			renderLog.Warning("%s", msg)
+		}
+	}
+
+	if !p.config.RegenerateSimilarImages || len(report.Pairs) == 0 {
+		return
+	}
+
+	outputDir := filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", song.ID))
+	imageGen := image.NewImageGenerator(outputDir, p.config)
+	p.configureImageGenerator(imageGen, song)
+
+	regenerated := make(map[int]bool)
+	for i, pair := range report.Pairs {
+		if regenerated[pair.A] || regenerated[pair.B] {
+			continue
+		}
+		p.updateProgress(item, models.PhaseImages, "Generating images", 100,
+			fmt.Sprintf("Regenerating near-duplicate image for %s (%d/%d)", pair.SectionB, i+1, len(report.Pairs)))
+		if err := p.regenerateDistinctImage(imageGen, pair.B); err != nil {
+			log.Printf("Warning: failed to regenerate near-duplicate image %d: %v", pair.B, err)
+			continue
+		}
+		regenerated[pair.B] = true
+	}
+}
+
+// regenerateDistinctImage regenerates an image with a fresh seed and a prompt
+// asking for a different composition, replacing its file in place
+func (p *Processor) regenerateDistinctImage(imageGen *image.ImageGenerator, imageID int) error {
+	img, err := database.GetImageByID(imageID)
+	if err != nil {
+		return err
+	}
+	if img == nil {
+		return fmt.Errorf("image %d not found", imageID)
+	}
+
+	prompt := img.Prompt
+	if !strings.HasSuffix(prompt, distinctPromptSuffix) {
+		prompt += distinctPromptSuffix
+	}
+	negative := ""
+	if img.NegativePrompt != nil {
+		negative = *img.NegativePrompt
+	}
+
+	newPath, seed, err := imageGen.GenerateImageWithSeed(prompt, negative, filepath.Base(img.ImagePath), image.RandomSeed)
+	if err != nil {
+		return err
+	}
+	p.mirror(newPath)
+
+	if err := database.UpdateImagePrompt(img.ID, prompt, negative); err != nil {
+		return err
+	}
+	if err := database.UpdateImageSeed(img.ID, &seed); err != nil {
+		log.Printf("Warning: failed to store seed for image %d: %v", img.ID, err)
+	}
+	services.HashImageFile(img.ID, newPath)
+
+	log.Printf("Regenerated near-duplicate image %d: %s", img.ID, newPath)
+	return nil
+}
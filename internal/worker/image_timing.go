@@ -0,0 +1,122 @@
+package worker
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/lyrics"
+)
+
+// Last-resort section timing when neither lyric timing nor a duration is known
+const (
+	fallbackSecondsPerLine = 3.0
+	fallbackSectionSeconds = 10.0
+)
+
+// imageTiming is the persisted audio analysis used to place image transitions
+type imageTiming struct {
+	beats      []float64 // Sorted beat times in seconds
+	vocalStart float64   // Start of the first vocal segment
+	vocalEnd   float64   // End of the last vocal segment
+}
+
+// songImageTiming loads the beat times and vocal segments stored for a song.
+// Missing or unparseable data leaves the corresponding fields empty.
+func songImageTiming(song *models.Song) imageTiming {
+	var timing imageTiming
+
+	if beats, err := audio.ParseBeatTimes(song.BeatTimes); err == nil {
+		timing.beats = beats
+	}
+
+	if song.VocalTiming != "" {
+		var segments []audio.VocalSegment
+		if err := json.Unmarshal([]byte(song.VocalTiming), &segments); err == nil && len(segments) > 0 {
+			timing.vocalStart = segments[0].Start
+			timing.vocalEnd = segments[len(segments)-1].End
+		}
+	}
+
+	return timing
+}
+
+// sectionBounds returns when a section's image should show, in song time.
+// In order of preference it uses the section's timed lyric lines (offset by
+// vocalOnset, as the renderer displays them), the section's share of the vocal
+// span (or of the whole song), and finally a fixed time per line. When beat
+// times are known the bounds snap to the nearest beat so images change on the
+// music.
+func (t imageTiming) sectionBounds(section lyrics.Section, timedLines []lyrics.TimedLine, totalLines int, vocalOnset, totalDuration float64) (float64, float64) {
+	start, end, ok := timedSectionBounds(section, timedLines)
+	if ok {
+		start += vocalOnset
+		end += vocalOnset
+	} else if spanStart, spanEnd := t.span(totalDuration); totalLines > 0 && spanEnd > spanStart {
+		perLine := (spanEnd - spanStart) / float64(totalLines)
+		start = spanStart + float64(section.StartLine)*perLine
+		end = spanStart + float64(section.EndLine+1)*perLine
+	} else {
+		start = float64(section.StartLine) * fallbackSecondsPerLine
+		end = float64(section.EndLine+1) * fallbackSecondsPerLine
+	}
+
+	if totalDuration > 0 {
+		start = math.Min(start, totalDuration)
+		end = math.Min(end, totalDuration)
+	}
+	start = snapToBeat(start, t.beats)
+	end = snapToBeat(end, t.beats)
+
+	if start >= end {
+		end = start + fallbackSectionSeconds
+	}
+	return start, end
+}
+
+// span returns the stretch of the song lyrics are sung over: the detected
+// vocal segments if known, otherwise the whole song
+func (t imageTiming) span(totalDuration float64) (float64, float64) {
+	if t.vocalEnd > t.vocalStart {
+		return t.vocalStart, t.vocalEnd
+	}
+	return 0, totalDuration
+}
+
+// timedSectionBounds returns the earliest start and latest end of the timed
+// lines in a section, and false if none of its lines are timed
+func timedSectionBounds(section lyrics.Section, timedLines []lyrics.TimedLine) (float64, float64, bool) {
+	start, end := math.Inf(1), 0.0
+	for i := section.StartLine; i <= section.EndLine && i < len(timedLines); i++ {
+		if i < 0 {
+			continue
+		}
+		start = math.Min(start, timedLines[i].StartTime)
+		end = math.Max(end, timedLines[i].EndTime)
+	}
+	if math.IsInf(start, 1) || end <= 0 {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// snapToBeat returns the beat time nearest to t, or t if there are no beats
+func snapToBeat(t float64, beats []float64) float64 {
+	if len(beats) == 0 {
+		return t
+	}
+
+	i := sort.SearchFloat64s(beats, t)
+	switch {
+	case i == 0:
+		return beats[0]
+	case i == len(beats):
+		return beats[len(beats)-1]
+	case beats[i]-t < t-beats[i-1]:
+		return beats[i]
+	default:
+		return beats[i-1]
+	}
+}
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/lyrics"
+)
+
+// Irregular, unsorted beat times as the analyzer might store them
+const testBeatTimes = "[31.2, 0.4, 19.6, 20.8, 21.3, 30.7, 39.3, 40.9]"
+
+// testTimedLines returns 12 lines sung 2.5s apart from 10s, each lasting 2.4s
+func testTimedLines() []lyrics.TimedLine {
+	lines := make([]lyrics.TimedLine, 12)
+	for i := range lines {
+		start := 10 + 2.5*float64(i)
+		lines[i] = lyrics.TimedLine{StartTime: start, EndTime: start + 2.4, Duration: 2.4}
+	}
+	return lines
+}
+
+func TestSectionBounds(t *testing.T) {
+	// Lines 4-7 of a 12-line, 60-second song
+	section := lyrics.Section{Type: "chorus", Number: 1, StartLine: 4, EndLine: 7}
+
+	tests := []struct {
+		name       string
+		song       models.Song
+		timedLines []lyrics.TimedLine
+		vocalOnset float64
+		wantStart  float64
+		wantEnd    float64
+	}{
+		{
+			name:      "section share of song without beats",
+			song:      models.Song{DurationSeconds: 60},
+			wantStart: 20,
+			wantEnd:   40,
+		},
+		{
+			name:      "section share of song snapped to beats",
+			song:      models.Song{DurationSeconds: 60, BeatTimes: testBeatTimes},
+			wantStart: 19.6,
+			wantEnd:   39.3,
+		},
+		{
+			name:      "unparseable beats are ignored",
+			song:      models.Song{DurationSeconds: 60, BeatTimes: "not json"},
+			wantStart: 20,
+			wantEnd:   40,
+		},
+		{
+			name: "section share of vocal span without beats",
+			song: models.Song{
+				DurationSeconds: 60,
+				VocalTiming:     `[{"start": 12, "end": 30}, {"start": 36, "end": 48}]`,
+			},
+			wantStart: 24,
+			wantEnd:   36,
+		},
+		{
+			name:       "timed lines without beats",
+			song:       models.Song{DurationSeconds: 60},
+			timedLines: testTimedLines(),
+			vocalOnset: 1,
+			wantStart:  21,
+			wantEnd:    30.9,
+		},
+		{
+			name:       "timed lines snapped to beats",
+			song:       models.Song{DurationSeconds: 60, BeatTimes: testBeatTimes},
+			timedLines: testTimedLines(),
+			vocalOnset: 1,
+			wantStart:  20.8,
+			wantEnd:    30.7,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := songImageTiming(&tt.song).sectionBounds(section, tt.timedLines, 12, tt.vocalOnset, tt.song.DurationSeconds)
+			if math.Abs(start-tt.wantStart) > 1e-9 || math.Abs(end-tt.wantEnd) > 1e-9 {
+				t.Errorf("sectionBounds = %.2f-%.2f, want %.2f-%.2f", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestSnapToBeat(t *testing.T) {
+	beats := []float64{1, 2, 4}
+
+	tests := []struct {
+		name  string
+		t     float64
+		beats []float64
+		want  float64
+	}{
+		{"no beats", 3.3, nil, 3.3},
+		{"before first beat", 0.2, beats, 1},
+		{"after last beat", 9, beats, 4},
+		{"nearer earlier beat", 2.9, beats, 2},
+		{"nearer later beat", 3.1, beats, 4},
+		{"on a beat", 2, beats, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := snapToBeat(tt.t, tt.beats); got != tt.want {
+				t.Errorf("snapToBeat(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
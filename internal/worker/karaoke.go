@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/lyrics"
+)
+
+// karaokeOptionsForSong builds karaoke subtitle options from a song's
+// settings, falling back to defaults for missing or invalid fields
+func karaokeOptionsForSong(song *models.Song) *lyrics.KaraokeOptions {
+	karaokeOptions := &lyrics.KaraokeOptions{
+		FontFamily:           song.KaraokeFontFamily,
+		FontSize:             song.KaraokeFontSize,
+		PrimaryColor:         song.KaraokePrimaryColor,
+		PrimaryBorderColor:   song.KaraokePrimaryBorderColor,
+		HighlightColor:       song.KaraokeHighlightColor,
+		HighlightBorderColor: song.KaraokeHighlightBorderColor,
+		Alignment:            song.KaraokeAlignment,
+		MarginBottom:         song.KaraokeMarginBottom,
+		TimingOffset:         song.KaraokeTimingOffset,
+	}
+
+	// Use defaults if critical fields are missing or invalid
+	defaults := lyrics.DefaultKaraokeOptions()
+	if karaokeOptions.FontFamily == "" {
+		karaokeOptions.FontFamily = defaults.FontFamily
+	}
+	if karaokeOptions.FontSize <= 0 {
+		karaokeOptions.FontSize = defaults.FontSize
+	}
+	if karaokeOptions.PrimaryColor == "" {
+		karaokeOptions.PrimaryColor = defaults.PrimaryColor
+	}
+	if karaokeOptions.PrimaryBorderColor == "" {
+		karaokeOptions.PrimaryBorderColor = defaults.PrimaryBorderColor
+	}
+	if karaokeOptions.HighlightColor == "" {
+		karaokeOptions.HighlightColor = defaults.HighlightColor
+	}
+	if karaokeOptions.HighlightBorderColor == "" {
+		karaokeOptions.HighlightBorderColor = defaults.HighlightBorderColor
+	}
+	if karaokeOptions.Alignment <= 0 || karaokeOptions.Alignment > 9 {
+		karaokeOptions.Alignment = defaults.Alignment
+	}
+	return karaokeOptions
+}
+
+// canReuseTimestamps reports whether the cached Whisper timestamps were made
+// from the current vocals with the same model, so a re-burn can skip
+// transcription
+func canReuseTimestamps(song *models.Song, vocalPath, tempDir, whisperModel string) bool {
+	cached, err := os.Stat(lyrics.TimestampsPath(tempDir, song.ID))
+	if err != nil {
+		return false
+	}
+	vocals, err := os.Stat(vocalPath)
+	if err != nil || vocals.ModTime().After(cached.ModTime()) {
+		return false
+	}
+	return strings.HasSuffix(song.WhisperEngine, "("+whisperModel+")")
+}
+
+// RebuildKaraokeSubtitles regenerates a song's karaoke ASS file from its
+// cached timestamps with the song's current style and timing offset, so an
+// offset can be checked without waiting for Whisper. It returns the ASS path.
+func (p *Processor) RebuildKaraokeSubtitles(song *models.Song) (string, error) {
+	if song.Instrumental {
+		return "", fmt.Errorf("song %d is instrumental", song.ID)
+	}
+
+	karaokeGen := lyrics.NewKaraokeGenerator(p.config)
+	result, err := karaokeGen.RegenerateKaraokeSubtitles(song.ID, utils.GetTempPath(), song.LyricsKaraoke, song.WhisperEngine, karaokeOptionsForSong(song))
+	if err != nil {
+		return "", err
+	}
+	return result.ASSPath, nil
+}
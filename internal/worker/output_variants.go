@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/logger"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
+)
+
+// renderOutputVariants renders the song's extra audio versions by swapping
+// the stem into the finished video, so the visuals are never re-rendered.
+// Each variant is stored as its own video record and runs for duration, the
+// final video's length including any intro and outro cards; the stem starts
+// introLength seconds in, where the song does. A variant that can't be made
+// is logged and skipped, since the main video is already complete.
+func (p *Processor) renderOutputVariants(song *models.Song, finalPath string, duration, introLength float64, renderLog *logger.RenderLogger) {
+	done := make(map[string]bool)
+	for _, variant := range song.OutputVariants {
+		if done[variant] {
+			continue
+		}
+		done[variant] = true
+
+		variantPath, err := p.renderOutputVariant(song, finalPath, variant, duration, introLength)
+		if err != nil {
+			log.Printf("Warning: skipping %s version of %s: %v", variant, song.Title, err)
+			if renderLog != nil {
+				renderLog.Warning("Skipped %s version: %v", variant, err)
+			}
+			continue
+		}
+		if renderLog != nil {
+			renderLog.Success("Rendered %s version", variant)
+			renderLog.Property(fmt.Sprintf("Video Path (%s)", variant), variantPath)
+		}
+	}
+}
+
+// renderOutputVariant re-muxes one variant next to the final video and
+// records it
+func (p *Processor) renderOutputVariant(song *models.Song, finalPath, variant string, duration, introLength float64) (string, error) {
+	audioPath := variantAudioPath(song.ID, variant)
+	if audioPath == "" {
+		return "", fmt.Errorf("no %s stem found", variantStem(variant))
+	}
+
+	variantPath := variantVideoPath(finalPath, variant)
+	if err := video.ReplaceAudio(finalPath, audioPath, variantPath, duration, introLength, songAudioFade(song)); err != nil {
+		return "", err
+	}
+
+	probe, err := verifyVideo(variantPath, duration)
+	if err != nil {
+		return "", fmt.Errorf("verification failed: %w", err)
+	}
+
+	var size int64
+	if info, err := os.Stat(variantPath); err == nil {
+		size = info.Size()
+	}
+	p.mirror(variantPath)
+	p.saveVideoRecord(song, variantPath, variant, probe, size)
+	return variantPath, nil
+}
+
+// variantAudioPath returns the stem a variant carries, or "" if the song
+// doesn't have it
+func variantAudioPath(songID int, variant string) string {
+	switch variant {
+	case models.VideoVariantInstrumental:
+		return utils.GetSongMusicPath(songID)
+	case models.VideoVariantVocals:
+		return utils.GetSongVocalPath(songID)
+	}
+	return ""
+}
+
+// variantStem names the stem a variant needs, for messages
+func variantStem(variant string) string {
+	if variant == models.VideoVariantVocals {
+		return "vocal"
+	}
+	return "music"
+}
+
+// variantVideoPath places a variant next to the final video, like drafts
+func variantVideoPath(videoPath, variant string) string {
+	ext := filepath.Ext(videoPath)
+	return strings.TrimSuffix(videoPath, ext) + "_" + variant + ext
+}
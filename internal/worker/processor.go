@@ -1,12 +1,15 @@
 package worker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -14,19 +17,35 @@ import (
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services/ai"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
 	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
 	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/image"
 	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/logger"
 	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/lyrics"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/metrics"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/storage"
 	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/video"
 )
 
+// ErrAwaitingApproval is returned by Process when the pipeline paused for image approval
+var ErrAwaitingApproval = errors.New("awaiting image approval")
+
+// ErrCancelled is returned by Process when the queue item was deleted while it was running
+var ErrCancelled = errors.New("queue item cancelled")
+
 // Processor handles the actual video processing pipeline
 type Processor struct {
 	songRepo    *database.SongRepository
 	broadcaster *services.ProgressBroadcaster
 	config      *config.Config
+	storage     storage.Storage
+	analyzer    audio.AudioAnalyzer
+	aiClient    *ai.Client // Infers a genre when analysis finds none
+
+	// progress is the phase weighting for the job being processed; the worker
+	// runs one job at a time
+	progress *progressPlan
 }
 
 // NewProcessor creates a new processor
@@ -34,14 +53,66 @@ func NewProcessor(
 	songRepo *database.SongRepository,
 	broadcaster *services.ProgressBroadcaster,
 	cfg *config.Config,
+	store storage.Storage,
 ) *Processor {
 	return &Processor{
 		songRepo:    songRepo,
 		broadcaster: broadcaster,
 		config:      cfg,
+		storage:     store,
+		analyzer:    audio.NewAnalyzer(cfg),
+		aiClient:    ai.NewClient(cfg, database.NewSettingsRepository(database.DB)),
 	}
 }
 
+// pipelinePhase is a single resumable step of the processing pipeline
+type pipelinePhase struct {
+	name  string
+	label string
+	run   func(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) error
+
+	// noWork reports whether the phase has nothing to do for a song, so its
+	// progress weight can go to the other phases
+	noWork func(song *models.Song) bool
+}
+
+// phaseOrder lists the pipeline phases in execution order
+var phaseOrder = []string{
+	models.PhaseSeparation,
+	models.PhaseAnalysis,
+	models.PhaseLyrics,
+	models.PhaseImages,
+	models.PhaseRender,
+	models.PhaseUpload,
+}
+
+// phaseIndex returns the position of a phase in the pipeline, or -1 if unknown/empty
+func phaseIndex(phase string) int {
+	for i, name := range phaseOrder {
+		if name == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+// canSkipPhase reports whether a previously completed phase can be reused on resume.
+// The render phase is only skipped if its MP4 is still on disk.
+func (p *Processor) canSkipPhase(item *models.QueueItem, phase string) bool {
+	if phase != models.PhaseRender {
+		return true
+	}
+	if item.VideoFilePath == "" {
+		return false
+	}
+	if _, err := os.Stat(item.VideoFilePath); err != nil {
+		log.Printf("Previous render %s not found, re-rendering", item.VideoFilePath)
+		return false
+	}
+	log.Printf("Reusing previously rendered video: %s", item.VideoFilePath)
+	return true
+}
+
 // Process executes the full video generation pipeline
 func (p *Processor) Process(item *models.QueueItem, song *models.Song) error {
 	log.Printf("Starting processing pipeline for song: %s", song.Title)
@@ -66,6 +137,10 @@ func (p *Processor) Process(item *models.QueueItem, song *models.Song) error {
 		renderLog.Property("Song ID", song.ID)
 		renderLog.Property("Title", song.Title)
 		renderLog.Property("Artist", song.ArtistName)
+		renderLog.Property("Queue Item", item.ID)
+		if item.RequestID != "" {
+			renderLog.Property("Request ID", item.RequestID)
+		}
 		defer func() {
 			if r := recover(); r != nil {
 				renderLog.Error("Pipeline panicked: %v", r)
@@ -74,49 +149,87 @@ func (p *Processor) Process(item *models.QueueItem, song *models.Song) error {
 		}()
 	}
 
-	// Phase 1: Audio Analysis (0-20%)
-	if err := p.analyzeAudio(item, song, renderLog); err != nil {
+	// A completed pipeline that was re-queued starts over from the beginning
+	if item.LastPhase == models.PhaseUpload || (item.Draft && item.LastPhase == models.PhaseRender) {
+		item.LastPhase = ""
+	}
+	if item.LastPhase != "" {
+		log.Printf("Resuming queue item %d after phase: %s", item.ID, item.LastPhase)
 		if renderLog != nil {
-			renderLog.Error("Audio analysis failed: %v", err)
-			renderLog.Close(false, err.Error())
+			renderLog.Info("Resuming after last completed phase: %s", item.LastPhase)
 		}
-		return fmt.Errorf("audio analysis failed: %w", err)
 	}
 
-	// Phase 2: Lyrics Processing (20-30%)
-	if err := p.processLyrics(item, song, renderLog); err != nil {
-		if renderLog != nil {
-			renderLog.Error("Lyrics processing failed: %v", err)
-			renderLog.Close(false, err.Error())
-		}
-		return fmt.Errorf("lyrics processing failed: %w", err)
+	phases := []pipelinePhase{
+		{name: models.PhaseSeparation, label: "Stem separation", run: p.separateStems, noWork: p.noStemSeparation},
+		{name: models.PhaseAnalysis, label: "Audio analysis", run: p.analyzeAudio, noWork: hasAudioAnalysis},
+		{name: models.PhaseLyrics, label: "Lyrics processing", run: p.processLyrics, noWork: isInstrumental},
+		{name: models.PhaseImages, label: "Image generation", run: p.generateImages},
+		{name: models.PhaseRender, label: "Video rendering", run: p.renderVideo},
+		{name: models.PhaseUpload, label: "YouTube upload", run: p.uploadToYouTube},
+	}
+	// Drafts are previews only and stop after rendering
+	if item.Draft {
+		phases = phases[:len(phases)-1]
 	}
 
-	// Phase 3: Image Generation (30-50%)
-	if err := p.generateImages(item, song, renderLog); err != nil {
-		if renderLog != nil {
-			renderLog.Error("Image generation failed: %v", err)
-			renderLog.Close(false, err.Error())
+	// Split overall progress between the phases that will actually run
+	resumeIndex := phaseIndex(item.LastPhase)
+	skip := make([]bool, len(phases))
+	var active []string
+	for i, phase := range phases {
+		skip[i] = i <= resumeIndex && p.canSkipPhase(item, phase.name)
+		if !skip[i] && (phase.noWork == nil || !phase.noWork(song)) {
+			active = append(active, phase.name)
 		}
-		return fmt.Errorf("image generation failed: %w", err)
 	}
+	p.progress = newProgressPlan(p.config.PhaseWeights, active)
 
-	// Phase 4: Video Rendering (50-90%)
-	if err := p.renderVideo(item, song, renderLog); err != nil {
-		if renderLog != nil {
-			renderLog.Error("Video rendering failed: %v", err)
-			renderLog.Close(false, err.Error())
+	for i, phase := range phases {
+		if skip[i] {
+			log.Printf("Skipping phase %s (already completed)", phase.name)
+			continue
 		}
-		return fmt.Errorf("video rendering failed: %w", err)
-	}
 
-	// Phase 5: YouTube Upload (90-100%)
-	if err := p.uploadToYouTube(item, song, renderLog); err != nil {
-		if renderLog != nil {
-			renderLog.Error("YouTube upload failed: %v", err)
-			renderLog.Close(false, err.Error())
+		// Deleting a processing item cancels it before its next phase starts
+		if p.cancelled(item) {
+			if renderLog != nil {
+				renderLog.Info("Queue item deleted - cancelling before %s", phase.label)
+				renderLog.Close(false, "Cancelled")
+			}
+			return ErrCancelled
+		}
+
+		p.recordPhaseStart(item, phase)
+
+		phaseStart := time.Now()
+		err := phase.run(item, song, renderLog)
+		metrics.ObserveDuration(metrics.PhaseDuration.WithLabelValues(phase.name), phaseStart)
+		p.recordPhaseLog(item, phase, time.Since(phaseStart), err)
+		if err != nil {
+			if renderLog != nil {
+				renderLog.Error("%s failed: %v", phase.label, err)
+				renderLog.Close(false, err.Error())
+			}
+			return fmt.Errorf("%s failed: %w", strings.ToLower(phase.label), err)
+		}
+		item.LastPhase = phase.name
+
+		// Pause for image approval if the song requires it
+		if phase.name == models.PhaseImages && song.RequireImageApproval {
+			unapproved, err := database.CountUnapprovedImages(song.ID)
+			if err != nil {
+				return fmt.Errorf("failed to check image approval: %w", err)
+			}
+			if unapproved > 0 {
+				if renderLog != nil {
+					renderLog.Info("Pausing for approval: %d image(s) not yet approved", unapproved)
+					renderLog.Close(true, "Paused awaiting image approval")
+				}
+				p.updateProgress(item, models.PhaseImages, "Awaiting image approval", 100, fmt.Sprintf("%d image(s) awaiting approval", unapproved))
+				return ErrAwaitingApproval
+			}
 		}
-		return fmt.Errorf("youtube upload failed: %w", err)
 	}
 
 	if renderLog != nil {
@@ -127,16 +240,54 @@ func (p *Processor) Process(item *models.QueueItem, song *models.Song) error {
 	return nil
 }
 
+// cancelled reports whether the queue item has been deleted since it was picked up
+func (p *Processor) cancelled(item *models.QueueItem) bool {
+	queueRepo := database.NewQueueRepository(database.DB)
+	current, err := queueRepo.GetByID(item.ID)
+	if err != nil {
+		log.Printf("Warning: failed to check queue item %d for cancellation: %v", item.ID, err)
+		return false
+	}
+	return current == nil
+}
+
+// checkLyricTiming warns when the lyrics look mismatched with the audio and
+// flags the queue item as a timing issue, unless it's already flagged
+func (p *Processor) checkLyricTiming(item *models.QueueItem, song *models.Song, timedLines []lyrics.TimedLine, renderLog *logger.RenderLogger) {
+	issues := lyrics.EstimateTimingSanity(timedLines, song.DurationSeconds)
+	if len(issues) == 0 {
+		return
+	}
+
+	for _, issue := range issues {
+		log.Printf("Warning: lyric timing for song %d looks wrong (%s): %s", song.ID, issue.Code, issue.Message)
+		if renderLog != nil {
+			renderLog.Warning("Lyric timing looks wrong (%s): %s", issue.Code, issue.Message)
+		}
+	}
+
+	if item.ID == 0 || item.Flag != nil {
+		return
+	}
+	flag := "timing_issue"
+	queueRepo := database.NewQueueRepository(database.DB)
+	if err := queueRepo.UpdateFlag(item.ID, &flag); err != nil {
+		log.Printf("Warning: failed to flag queue item %d for lyric timing: %v", item.ID, err)
+		return
+	}
+	item.Flag = &flag
+}
+
 // analyzeAudio performs audio analysis using librosa
 func (p *Processor) analyzeAudio(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) error {
 	// Check if audio analysis already exists
-	if song.BPM > 0 && song.Key != "" && song.DurationSeconds > 0 {
+	if hasAudioAnalysis(song) {
 		log.Printf("Audio analysis already exists for song %s, skipping", song.Title)
-		p.updateProgress(item, "Analyzing audio", 20, fmt.Sprintf("Using existing analysis: %.1f BPM, %s", song.BPM, song.Key))
+		p.updateProgress(item, models.PhaseAnalysis, "Analyzing audio", 100, fmt.Sprintf("Using existing analysis: %.1f BPM, %s", song.BPM, song.Key))
 		return nil
 	}
 
-	p.updateProgress(item, "Analyzing audio", 5, "Loading audio files")
+	p.updateProgress(item, models.PhaseAnalysis, "Analyzing audio", 25, "Loading audio files")
 
 	// Get audio paths using convention-based lookup
 	// For BPM/tempo analysis, prefer music stem (more accurate rhythm detection)
@@ -155,31 +306,45 @@ func (p *Processor) analyzeAudio(item *models.QueueItem, song *models.Song, rend
 		return fmt.Errorf("no audio file available for analysis - please upload audio files first")
 	}
 
-	p.updateProgress(item, "Analyzing audio", 10, "Running audio analysis (BPM, key, timing)")
+	p.updateProgress(item, models.PhaseAnalysis, "Analyzing audio", 50, "Running audio analysis (BPM, key, timing)")
 
 	// Run Python audio analyzer on instrumental track for BPM/tempo
-	analysis, err := audio.AnalyzeAudio(bpmAudioPath)
+	analysis, err := p.analyzer.Analyze(bpmAudioPath)
 	if err != nil {
 		return fmt.Errorf("audio analysis failed: %w", err)
 	}
 
-	p.updateProgress(item, "Analyzing audio", 15, "Processing analysis results")
+	if err := p.checkRenderDuration(analysis.DurationSeconds); err != nil {
+		return fmt.Errorf("audio analysis returned a bad duration: %w", err)
+	}
+
+	p.updateProgress(item, models.PhaseAnalysis, "Analyzing audio", 75, "Processing analysis results")
 
 	// Update song with analysis results
 	song.BPM = analysis.BPM
 	song.Key = analysis.Key
 	song.Tempo = analysis.Tempo
 	song.DurationSeconds = analysis.DurationSeconds
+	if beatTimesJSON, err := audio.BeatTimesJSON(analysis); err != nil {
+		log.Printf("Warning: failed to marshal beat times: %v", err)
+	} else {
+		song.BeatTimes = beatTimesJSON
+	}
 
-	// Update genre from audio analysis (if not already set manually)
+	// Update genre from audio analysis (if not already set manually), or
+	// ask the LLM when the analyzer couldn't tell
 	if song.Genre == "" && analysis.Genre != "" {
 		song.Genre = analysis.Genre
+		song.GenreSource = models.GenreSourceAnalysis
 		log.Printf("Detected genre: %s", analysis.Genre)
+	} else if song.Genre == "" {
+		p.updateProgress(item, models.PhaseAnalysis, "Analyzing audio", 80, "Inferring genre")
+		p.inferGenre(song)
 	}
 
 	// If we have separate vocal track, analyze it for vocal timing
 	if vocalAudioPath != "" && vocalAudioPath != bpmAudioPath {
-		vocalAnalysis, err := audio.AnalyzeAudio(vocalAudioPath)
+		vocalAnalysis, err := p.analyzer.Analyze(vocalAudioPath)
 		if err == nil && len(vocalAnalysis.VocalSegments) > 0 {
 			analysis.VocalSegments = vocalAnalysis.VocalSegments
 			analysis.VocalSegmentCount = vocalAnalysis.VocalSegmentCount
@@ -204,7 +369,7 @@ func (p *Processor) analyzeAudio(item *models.QueueItem, song *models.Song, rend
 		log.Printf("Warning: failed to save audio analysis results: %v", err)
 	}
 
-	p.updateProgress(item, "Analyzing audio", 20, fmt.Sprintf("Analysis complete: %.1f BPM, %s", analysis.BPM, analysis.Key))
+	p.updateProgress(item, models.PhaseAnalysis, "Analyzing audio", 100, fmt.Sprintf("Analysis complete: %.1f BPM, %s", analysis.BPM, analysis.Key))
 
 	log.Printf("Audio analysis complete for song: %s - %s", song.Title, analysis.Summary())
 	return nil
@@ -212,6 +377,16 @@ func (p *Processor) analyzeAudio(item *models.QueueItem, song *models.Song, rend
 
 // processLyrics processes and times the lyrics
 func (p *Processor) processLyrics(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) error {
+	// Instrumentals have no lyrics to parse or time
+	if song.Instrumental {
+		log.Printf("Song %s is instrumental, skipping lyrics processing", song.Title)
+		if renderLog != nil {
+			renderLog.Info("Instrumental track - skipping lyrics processing")
+		}
+		p.updateProgress(item, models.PhaseLyrics, "Processing lyrics", 100, "Instrumental track, no lyrics to process")
+		return nil
+	}
+
 	if renderLog != nil {
 		renderLog.Phase("LYRICS PROCESSING", "Parsing and timing lyrics")
 		renderLog.Property("Song ID", song.ID)
@@ -223,7 +398,7 @@ func (p *Processor) processLyrics(item *models.QueueItem, song *models.Song, ren
 		}
 		renderLog.Debug("Karaoke Lyrics Preview: %s", firstLines)
 	}
-	p.updateProgress(item, "Processing lyrics", 22, "Parsing lyrics structure")
+	p.updateProgress(item, models.PhaseLyrics, "Processing lyrics", 20, "Parsing lyrics structure")
 
 	// Parse lyrics to detect sections
 	if renderLog != nil {
@@ -247,12 +422,10 @@ func (p *Processor) processLyrics(item *models.QueueItem, song *models.Song, ren
 		}
 	}
 
-	p.updateProgress(item, "Processing lyrics", 25, "Aligning lyrics with audio timing")
+	p.updateProgress(item, models.PhaseLyrics, "Processing lyrics", 50, "Aligning lyrics with audio timing")
 
-	// We need beat times from the audio analysis
-	// For now, we'll use a simplified alignment
-	// In production, this would use the beat_times from audio analysis
-	beatTimes := []float64{} // Will be populated from audio analysis in future
+	// Lines follow the beats saved by audio analysis, or are spread evenly without them
+	beatTimes := songBeatTimes(song)
 
 	if renderLog != nil {
 		renderLog.Info("Aligning lyrics to audio timing...")
@@ -260,20 +433,34 @@ func (p *Processor) processLyrics(item *models.QueueItem, song *models.Song, ren
 		renderLog.Property("Beat Times Available", len(beatTimes))
 	}
 
-	timedLines, err := lyrics.AlignLyricsToBeats(song.LyricsKaraoke, beatTimes, song.DurationSeconds)
-	if err != nil {
+	var timedLines []lyrics.TimedLine
+	if song.ManualTiming && song.LyricsDisplay != "" {
+		// Hand-corrected timing wins over automatic alignment
+		if err := json.Unmarshal([]byte(song.LyricsDisplay), &timedLines); err != nil {
+			return fmt.Errorf("failed to parse manual lyric timing: %w", err)
+		}
+		log.Printf("Using %d manually timed lyrics lines", len(timedLines))
 		if renderLog != nil {
-			renderLog.Error("Failed to align lyrics: %v", err)
+			renderLog.Info("Using manual lyric timing; skipping alignment")
+		}
+	} else {
+		timedLines, err = lyrics.AlignLyricsToBeats(song.LyricsKaraoke, beatTimes, song.DurationSeconds)
+		if err != nil {
+			if renderLog != nil {
+				renderLog.Error("Failed to align lyrics: %v", err)
+			}
+			return fmt.Errorf("failed to align lyrics: %w", err)
 		}
-		return fmt.Errorf("failed to align lyrics: %w", err)
-	}
 
-	log.Printf("Aligned %d lyrics lines to audio timing", len(timedLines))
+		log.Printf("Aligned %d lyrics lines to audio timing", len(timedLines))
+	}
 
 	if renderLog != nil {
 		renderLog.Success("Lyrics aligned to audio timing")
 		renderLog.Property("Timed Lines", len(timedLines))
+		renderLog.Property("Lines Per Minute", fmt.Sprintf("%.1f", lyrics.LinesPerMinute(timedLines, song.DurationSeconds)))
 	}
+	p.checkLyricTiming(item, song, timedLines, renderLog)
 
 	// Store processed lyrics data
 	sectionsJSON, err := json.Marshal(lyricsData.Sections)
@@ -317,7 +504,7 @@ func (p *Processor) processLyrics(item *models.QueueItem, song *models.Song, ren
 		}
 	}
 
-	p.updateProgress(item, "Processing lyrics", 30, fmt.Sprintf("Processed %d sections, %d lines", len(lyricsData.Sections), len(timedLines)))
+	p.updateProgress(item, models.PhaseLyrics, "Processing lyrics", 100, fmt.Sprintf("Processed %d sections, %d lines", len(lyricsData.Sections), len(timedLines)))
 
 	log.Printf("Lyrics processing complete for song: %s", song.Title)
 	if renderLog != nil {
@@ -326,18 +513,29 @@ func (p *Processor) processLyrics(item *models.QueueItem, song *models.Song, ren
 	return nil
 }
 
-// generateImages generates background images via CQAI for each unique section
+// generateImages generates background images via CQAI for each unique section,
+// then checks them for near-duplicates
 func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) error {
+	if err := p.generateSectionImages(item, song, renderLog); err != nil {
+		return err
+	}
+	p.checkImageSimilarity(item, song, renderLog)
+	return nil
+}
+
+// generateSectionImages reuses, reverse-engineers or generates an image for each unique section
+func (p *Processor) generateSectionImages(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) error {
 	if renderLog != nil {
 		renderLog.Phase("IMAGE GENERATION", "Generating background images via CQAI")
 		renderLog.Property("Song ID", song.ID)
 		renderLog.Property("Song Title", song.Title)
 	}
-	p.updateProgress(item, "Generating images", 30, "Scanning for existing images")
+	p.updateProgress(item, models.PhaseImages, "Generating images", 0, "Scanning for existing images")
 
 	// Get images directory
-	outputDir := filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", song.ID))
-	imageGen := image.NewImageGenerator(outputDir)
+	outputDir := services.SongImagesDir(song.ID)
+	imageGen := image.NewImageGenerator(outputDir, p.config)
+	p.configureImageGenerator(imageGen, song)
 
 	if renderLog != nil {
 		renderLog.Property("Image Output Directory", outputDir)
@@ -345,19 +543,14 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 	}
 
 	// Step 1: Check for existing image FILES on disk
-	existingFiles := make(map[string]string) // filename -> full path
-	if _, err := os.Stat(outputDir); err == nil {
-		files, err := os.ReadDir(outputDir)
-		if err == nil {
-			for _, file := range files {
-				if !file.IsDir() && strings.HasSuffix(file.Name(), ".png") {
-					existingFiles[file.Name()] = filepath.Join(outputDir, file.Name())
-					log.Printf("Found existing image file: %s", file.Name())
-					if renderLog != nil {
-						renderLog.Debug("Found existing image file: %s", file.Name())
-					}
-				}
-			}
+	existingFiles, err := services.SongImageFiles(song.ID) // filename -> full path
+	if err != nil {
+		log.Printf("Warning: failed to read existing images: %v", err)
+	}
+	for filename := range existingFiles {
+		log.Printf("Found existing image file: %s", filename)
+		if renderLog != nil {
+			renderLog.Debug("Found existing image file: %s", filename)
 		}
 	}
 
@@ -390,61 +583,19 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 
 	// Step 3: Reverse-engineer prompts from orphaned image files (files without database entries)
 	if len(existingFiles) > 0 && len(existingImages) == 0 {
-		p.updateProgress(item, "Generating images", 32, fmt.Sprintf("Reverse-engineering prompts from %d existing images", len(existingFiles)))
+		p.updateProgress(item, models.PhaseImages, "Generating images", 10, fmt.Sprintf("Reverse-engineering prompts from %d existing images", len(existingFiles)))
 		log.Printf("Found %d image files but no database entries - extracting prompts with vision AI", len(existingFiles))
 
 		fileIndex := 0
-		for filename, filePath := range existingFiles {
+		for _, filePath := range existingFiles {
 			fileIndex++
-			progress := 32 + ((fileIndex * 8) / len(existingFiles))
-			p.updateProgress(item, "Generating images", progress, fmt.Sprintf("Analyzing image %d/%d with vision AI", fileIndex, len(existingFiles)))
-
-			// Extract prompt using vision model
-			log.Printf("Extracting prompt from %s using vision AI...", filename)
-			extractedPrompt, err := imageGen.ExtractPromptFromImage(filePath)
-			if err != nil {
-				log.Printf("Warning: failed to extract prompt from %s: %v", filename, err)
-				continue
-			}
-
-			// Parse filename to determine image type and sequence
-			// Format: bg-verse-1.png, bg-chorus.png, bg-intro.png, etc.
-			imageType, sequenceNum := parseImageFilename(filename)
-			if imageType == "" {
-				log.Printf("Warning: couldn't parse image type from filename: %s", filename)
-				continue
-			}
+			progress := 10 + (fileIndex*40)/len(existingFiles)
+			p.updateProgress(item, models.PhaseImages, "Generating images", progress, fmt.Sprintf("Analyzing image %d/%d with vision AI", fileIndex, len(existingFiles)))
 
-			// Always use correct naming for non-verse types
-			var dbFilename string
-			if imageType == "verse" && sequenceNum != nil {
-				dbFilename = fmt.Sprintf("storage/images/song_%d/bg-verse-%d.png", song.ID, *sequenceNum)
-			} else if imageType == "pre-chorus" {
-				dbFilename = fmt.Sprintf("storage/images/song_%d/bg-prechorus.png", song.ID)
-			} else if imageType == "final-chorus" {
-				dbFilename = fmt.Sprintf("storage/images/song_%d/bg-chorus.png", song.ID)
-			} else {
-				dbFilename = fmt.Sprintf("storage/images/song_%d/bg-%s.png", song.ID, imageType)
-			}
-			genImage := &models.GeneratedImage{
-				SongID:         song.ID,
-				QueueID:        &item.ID,
-				ImagePath:      dbFilename,
-				Prompt:         extractedPrompt,
-				NegativePrompt: nil,
-				ImageType:      imageType,
-				SequenceNumber: sequenceNum,
-				Width:          1920,
-				Height:         1080,
-				Model:          "cqai",
+			// Create a row with a prompt extracted by the vision model
+			if _, err := services.AdoptImageFile(song.ID, queueIDRef(item), filePath, imageGen); err != nil {
+				log.Printf("Warning: %v", err)
 			}
-
-			if err := database.CreateGeneratedImage(genImage); err != nil {
-				log.Printf("Warning: failed to create database entry for %s: %v", dbFilename, err)
-				continue
-			}
-
-			log.Printf("Successfully reverse-engineered prompt for %s (type: %s)", filename, imageType)
 		}
 
 		// Refresh the list of existing images from database
@@ -457,48 +608,55 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 	// Step 4: Check which images are missing (have prompts but no files on disk)
 	var missingImages []models.GeneratedImage
 	for _, img := range existingImages {
-		// Check if image path is empty in database
-		if img.ImagePath == "" || img.ImagePath == "." {
+		if services.ImageFilePath(&img) == "" {
 			missingImages = append(missingImages, img)
-			continue
-		}
-
-		// Check if file actually exists on disk
-		fullPath := filepath.Join(utils.GetDataPath(), img.ImagePath)
-		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-			log.Printf("Image exists in database but file missing on disk: %s", fullPath)
+		} else if !services.ImageFileExists(&img) {
+			log.Printf("Image exists in database but file missing on disk: %s", services.ImageFilePath(&img))
 			missingImages = append(missingImages, img)
 		}
 	}
 
 	if len(missingImages) > 0 {
 		log.Printf("Found %d existing prompts with missing images, generating them now", len(missingImages))
-		p.updateProgress(item, "Generating images", 40, fmt.Sprintf("Generating %d missing images from saved prompts", len(missingImages)))
+		p.updateProgress(item, models.PhaseImages, "Generating images", 50, fmt.Sprintf("Generating %d missing images from saved prompts", len(missingImages)))
 
 		// Generate each missing image using its stored prompt
 		for i, img := range missingImages {
-			progress := 40 + ((i+1)*10)/len(missingImages)
+			progress := 50 + ((i+1)*50)/len(missingImages)
 
 			// Generate filename based on image type and sequence number
-			var filename string
-			if img.ImageType == "verse" && img.SequenceNumber != nil && *img.SequenceNumber > 0 {
-				filename = fmt.Sprintf("bg-%s-%d.png", img.ImageType, *img.SequenceNumber)
-			} else {
-				filename = fmt.Sprintf("bg-%s.png", img.ImageType)
-			}
+			filename := image.SectionImageFilename(img.ImageType, img.Sequence())
 
 			message := fmt.Sprintf("Generating %s image (%d/%d)", img.ImageType, i+1, len(missingImages))
-			p.updateProgress(item, "Generating images", progress, message)
+			p.updateProgress(item, models.PhaseImages, "Generating images", progress, message)
 
 			log.Printf("Generating missing image: %s with prompt: %s", filename, img.Prompt)
 
-			// Generate image using the stored prompt
-			imagePath, err := imageGen.GenerateImage(img.Prompt, filename)
+			// Generate image using the stored prompt, broadcasting step progress within the image
+			startProgress := 50 + (i*50)/len(missingImages)
+			negative := ""
+			if img.NegativePrompt != nil {
+				negative = *img.NegativePrompt
+			}
+			// Reuse a stored seed so a tweaked prompt keeps its composition
+			seed := image.RandomSeed
+			if img.Seed != nil {
+				seed = *img.Seed
+			}
+			imagePath, err := imageGen.GenerateImageWithProgress(img.Prompt, negative, filename, seed,
+				p.imageProgress(item, startProgress, progress, message))
 			if err != nil {
 				log.Printf("Warning: failed to generate image %s: %v", filename, err)
 				continue
 			}
 
+			usedSeed := imageGen.LastSeed
+			if err := database.UpdateImageSeed(img.ID, &usedSeed); err != nil {
+				log.Printf("Warning: failed to store seed for image %d: %v", img.ID, err)
+			}
+
+			p.mirror(imagePath)
+
 			// Update database with the new image path
 			dataPath := utils.GetDataPath()
 			relativePath := strings.TrimPrefix(imagePath, dataPath+"/")
@@ -506,24 +664,19 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 				log.Printf("Warning: failed to update image path for %d: %v", img.ID, err)
 				continue
 			}
+			services.HashImageFile(img.ID, imagePath)
 
 			log.Printf("Generated missing image %d/%d: %s", i+1, len(missingImages), imagePath)
 		}
 
-		p.updateProgress(item, "Generating images", 50, "All images ready")
+		p.updateProgress(item, models.PhaseImages, "Generating images", 100, "All images ready")
 		return nil
 	}
 
 	// Step 5: Check if all required images already exist (in database with paths and files on disk)
 	allImagesReady := len(existingImages) > 0
 	for _, img := range existingImages {
-		if img.ImagePath == "" || img.ImagePath == "." {
-			allImagesReady = false
-			break
-		}
-		// Verify file exists on disk
-		fullPath := filepath.Join(utils.GetDataPath(), img.ImagePath)
-		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+		if !services.ImageFileExists(&img) {
 			allImagesReady = false
 			break
 		}
@@ -531,18 +684,23 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 
 	if allImagesReady {
 		log.Printf("All %d images already exist in database with valid paths, skipping generation", len(existingImages))
-		p.updateProgress(item, "Generating images", 50, fmt.Sprintf("Using %d existing images", len(existingImages)))
+		p.updateProgress(item, models.PhaseImages, "Generating images", 100, fmt.Sprintf("Using %d existing images", len(existingImages)))
 		return nil
 	}
 
 	// No existing prompts found, use legacy generation method
 	log.Printf("No existing image prompts found, generating from lyrics")
-	p.updateProgress(item, "Generating images", 34, "Parsing lyrics sections")
+	p.updateProgress(item, models.PhaseImages, "Generating images", 20, "Parsing lyrics sections")
 
-	// Parse lyrics to get sections
-	lyricsData, err := lyrics.ParseLyrics(song.Lyrics)
-	if err != nil {
-		return fmt.Errorf("failed to parse lyrics for images: %w", err)
+	// Parse lyrics to get sections (instrumentals use a fixed set of sections)
+	var lyricsData *lyrics.LyricsData
+	if song.Instrumental {
+		lyricsData = instrumentalSections(song)
+	} else {
+		lyricsData, err = lyrics.ParseLyrics(song.Lyrics)
+		if err != nil {
+			return fmt.Errorf("failed to parse lyrics for images: %w", err)
+		}
 	}
 
 	if len(lyricsData.Sections) == 0 {
@@ -562,33 +720,10 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 	totalSections := len(lyricsData.Sections)
 	for i, section := range lyricsData.Sections {
 		// Calculate progress (34% to 50%)
-		progress := 34 + ((i+1)*16)/totalSections
+		progress := 20 + ((i+1)*80)/totalSections
 
 		// Determine filename - Each verse gets unique image, repeated sections share images
-		var filename string
-		switch section.Type {
-		case "verse":
-			// Each verse gets its own unique image
-			filename = fmt.Sprintf("bg-verse-%d.png", section.Number)
-		case "pre-chorus":
-			// Pre-choruses share one image (they repeat the same lyrics)
-			filename = "bg-prechorus.png"
-		case "chorus":
-			// Choruses share one image (they repeat the same lyrics)
-			filename = "bg-chorus.png"
-		case "final-chorus":
-			// Final chorus gets its own image (if different from regular chorus)
-			filename = "bg-chorus.png"
-		case "bridge":
-			// Bridge is unique, one per song
-			filename = "bg-bridge.png"
-		case "intro":
-			filename = "bg-intro.png"
-		case "outro":
-			filename = "bg-outro.png"
-		default:
-			filename = fmt.Sprintf("bg-%s.png", section.Type)
-		}
+		filename := image.SectionImageFilename(section.Type, section.Number)
 
 		// Check if already generated (reuse for all repeated section types)
 		if existingPath, exists := generatedImages[filename]; exists {
@@ -602,15 +737,17 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 
 		message := fmt.Sprintf("Generating image for %s %d (%s)",
 			section.Type, section.Number, filename)
-		p.updateProgress(item, "Generating images", progress, message)
+		p.updateProgress(item, models.PhaseImages, "Generating images", progress, message)
 
 		// Generate image
 		log.Printf("Generating image for %s %d: %s", section.Type, section.Number, filename)
-		imagePath, prompt, err := imageGen.GenerateFromSection(
+		startProgress := 20 + (i*80)/totalSections
+		imagePath, prompt, negative, err := imageGen.GenerateFromSectionWithProgress(
 			section.Type,
 			section.Number,
 			sectionLyrics,
 			styleKeywords,
+			p.imageProgress(item, startProgress, progress, message),
 		)
 		if err != nil {
 			log.Printf("Warning: failed to generate image for %s %d: %v",
@@ -619,29 +756,43 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 			continue
 		}
 
+		p.mirror(imagePath)
 		generatedImages[filename] = imagePath
 		imagePaths = append(imagePaths, imagePath)
 		log.Printf("Generated image %d/%d: %s", len(generatedImages), totalSections, imagePath)
 
 		// Store image in database with captured prompt
+		var negativePtr *string
+		if negative != "" {
+			negativePtr = &negative
+		}
+		// An empty prompt means the file already existed and nothing was generated
+		var seedPtr *int64
+		if prompt != "" {
+			seed := imageGen.LastSeed
+			seedPtr = &seed
+		}
 		genImage := &models.GeneratedImage{
 			SongID:         song.ID,
-			QueueID:        &item.ID,
+			QueueID:        queueIDRef(item),
 			ImagePath:      imagePath,
 			Prompt:         prompt,
-			NegativePrompt: nil,
+			NegativePrompt: negativePtr,
 			ImageType:      section.Type,
 			SequenceNumber: &section.Number,
-			Width:          1920,
-			Height:         1080,
-			Model:          "cqai",
+			Width:          imageGen.Width,
+			Height:         imageGen.Height,
+			Model:          imageGen.ImageModel,
+			Seed:           seedPtr,
 		}
 		if err := database.CreateGeneratedImage(genImage); err != nil {
 			log.Printf("Warning: failed to store image record in database: %v", err)
+		} else {
+			services.HashImageFile(genImage.ID, imagePath)
 		}
 	}
 
-	p.updateProgress(item, "Generating images", 50,
+	p.updateProgress(item, models.PhaseImages, "Generating images", 100,
 		fmt.Sprintf("Generated %d unique images from %d sections",
 			len(generatedImages), totalSections))
 
@@ -651,18 +802,84 @@ func (p *Processor) generateImages(item *models.QueueItem, song *models.Song, re
 	return nil
 }
 
+// RegenerateAllImages deletes a song's background images, both files and
+// database records, and generates a new set from its current lyrics, genre
+// and background style. Progress is broadcast for the song with queue ID 0.
+func (p *Processor) RegenerateAllImages(song *models.Song) ([]models.GeneratedImage, error) {
+	outputDir := filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", song.ID))
+	if files, err := os.ReadDir(outputDir); err == nil {
+		for _, file := range files {
+			if !file.IsDir() && strings.HasSuffix(file.Name(), ".png") {
+				if err := os.Remove(filepath.Join(outputDir, file.Name())); err != nil {
+					log.Printf("Warning: failed to delete image file %s: %v", file.Name(), err)
+				}
+			}
+		}
+	}
+
+	if err := os.RemoveAll(filepath.Join(outputDir, "variants")); err != nil {
+		log.Printf("Warning: failed to delete image variants: %v", err)
+	}
+
+	if err := database.DeleteImagesBySongID(song.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete existing images: %w", err)
+	}
+
+	// Report progress as a standalone images job rather than a slice of a render
+	p.progress = newProgressPlan(p.config.PhaseWeights, []string{models.PhaseImages})
+	item := &models.QueueItem{SongID: song.ID, Status: "processing"}
+
+	log.Printf("Regenerating all images for song %d: %s", song.ID, song.Title)
+	if err := p.generateImages(item, song, nil); err != nil {
+		item.Status = "failed"
+		item.ErrorMessage = err.Error()
+		p.broadcaster.BroadcastFromQueueItem(item, "Image regeneration failed")
+		return nil, err
+	}
+
+	images, err := database.GetImagesBySongID(song.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load regenerated images: %w", err)
+	}
+
+	item.Status = "completed"
+	p.broadcaster.BroadcastFromQueueItem(item, fmt.Sprintf("Regenerated %d images", len(images)))
+	return images, nil
+}
+
+// queueIDRef links generated images to the queue item, or to none for
+// standalone jobs (ID 0) such as RegenerateAllImages
+func queueIDRef(item *models.QueueItem) *int {
+	if item.ID == 0 {
+		return nil
+	}
+	return &item.ID
+}
+
 // renderVideo renders the final video
 func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) error {
 	if renderLog != nil {
 		renderLog.Phase("VIDEO RENDERING", "Composing final video with FFmpeg")
 	}
 
-	p.updateProgress(item, "Rendering video", 55, "Preparing video assets")
+	var temps tempFiles
+	defer temps.cleanup()
+
+	if err := p.checkRenderDuration(song.DurationSeconds); err != nil {
+		if renderLog != nil {
+			renderLog.Error("%v", err)
+		}
+		return err
+	}
+
+	p.updateProgress(item, models.PhaseRender, "Rendering video", 12, "Preparing video assets")
 
 	// Setup paths
 	outputDir := utils.GetVideosPath()
-	videoPath := filepath.Join(outputDir, fmt.Sprintf("%s.mp4",
-		strings.ReplaceAll(song.Title, " ", "_")))
+	videoPath := p.resolveVideoPath(outputDir, song)
+	if item.Draft {
+		videoPath = draftVideoPath(videoPath)
+	}
 
 	if renderLog != nil {
 		renderLog.Property("Output Directory", outputDir)
@@ -681,7 +898,7 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 
 	if vocalPath != "" && musicPath != "" {
 		// Mix vocals and instrumental together
-		mixedPath := filepath.Join(utils.GetTempPath(), fmt.Sprintf("mixed_%d.wav", song.ID))
+		mixedPath := temps.add(filepath.Join(utils.GetTempPath(), fmt.Sprintf("mixed_%d.wav", song.ID)))
 		if renderLog != nil {
 			renderLog.Info("Mixing vocal and music tracks")
 			renderLog.Property("Mixed Output", mixedPath)
@@ -697,7 +914,6 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 			if renderLog != nil {
 				renderLog.Success("Audio tracks mixed successfully")
 			}
-			defer os.Remove(mixedPath)
 		}
 	} else {
 		// Use best available audio (prefers music > vocal > mixed)
@@ -726,7 +942,7 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 		renderLog.Success("Audio file validated successfully")
 	}
 
-	p.updateProgress(item, "Rendering video", 60, "Loading lyrics and images")
+	p.updateProgress(item, models.PhaseRender, "Rendering video", 25, "Loading lyrics and images")
 
 	// Parse lyrics data from stored JSON fields
 	var lyricsData lyrics.LyricsData
@@ -750,29 +966,36 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 		lyricsData.TimedLines = timedLines
 	}
 
-	// Build image segments from sections
+	// Get vocal onset time from the song's override or detected vocal timing
+	vocalOnset := p.vocalOnset(song, renderLog)
+
+	// Build image segments from sections (instrumentals spread images evenly)
 	imageDir := filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", song.ID))
-	imageSegments, err := p.buildImageSegments(&lyricsData, imageDir, song.DurationSeconds)
+	fallbacks := p.fallbackBackgrounds(song, imageDir)
+	var imageSegments []video.ImageSegment
+	var err error
+	if song.Instrumental {
+		imageSegments, err = p.buildEvenImageSegments(imageDir, song.DurationSeconds, fallbacks)
+	} else {
+		imageSegments, err = p.buildImageSegments(&lyricsData, imageDir, song.DurationSeconds, vocalOnset, songImageTiming(song), fallbacks)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to build image segments: %w", err)
 	}
+	if n := fallbacks.used(); n > 0 && renderLog != nil {
+		renderLog.Warning("Using %d generated fallback background(s) for sections without an AI image", n)
+	}
 
 	// Build timed lyrics from TimedLines
 	timedLyrics := p.buildTimedLyrics(&lyricsData)
 
-	// Get vocal onset time from database
-	vocalOnset := 0.0
-	if song.VocalTiming != "" {
-		var vocalSegments []audio.VocalSegment
-		if err := json.Unmarshal([]byte(song.VocalTiming), &vocalSegments); err == nil {
-			if len(vocalSegments) > 0 {
-				vocalOnset = vocalSegments[0].Start
-				log.Printf("Applying vocal onset offset: %.2fs", vocalOnset)
-			}
-		}
+	// Drafts spread lines evenly after the vocal onset instead of relying on
+	// stored or transcribed timing
+	if item.Draft {
+		timedLyrics = p.buildEvenTimedLyrics(song, song.DurationSeconds-vocalOnset)
 	}
 
-	p.updateProgress(item, "Rendering video", 70, "Composing video with FFmpeg")
+	p.updateProgress(item, models.PhaseRender, "Rendering video", 50, "Composing video with FFmpeg")
 
 	// Generate karaoke subtitles if vocals path is available
 	assSubtitlePath := ""
@@ -792,56 +1015,31 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 		}
 	}
 
-	if vocalPath != "" {
+	if song.Instrumental {
+		if renderLog != nil {
+			renderLog.Info("Instrumental track - skipping karaoke generation")
+		}
+	} else if item.Draft {
+		if renderLog != nil {
+			renderLog.Info("Draft render - skipping karaoke generation")
+		}
+	} else if vocalPath != "" {
 		log.Printf("DEBUG [Karaoke Check]: LyricsKaraoke length=%d", len(song.LyricsKaraoke))
 		if len(song.LyricsKaraoke) > 0 {
 			log.Printf("DEBUG [Karaoke Check]: First 100 chars: %s", song.LyricsKaraoke[:min(100, len(song.LyricsKaraoke))])
 		}
 		log.Println("Generating word-level karaoke timestamps...")
-		p.updateProgress(item, "Rendering video", 72, "Generating karaoke timestamps")
+		p.updateProgress(item, models.PhaseRender, "Rendering video", 55, "Generating karaoke timestamps")
 
 		if renderLog != nil {
 			renderLog.Info("Generating karaoke timestamps with Whisper...")
 		}
 
-		// Create karaoke generator with python scripts path from config
-		karaokeGen := lyrics.NewKaraokeGenerator(p.config.PythonScripts)
+		// Create karaoke generator with python scripts path and WhisperX URL from config
+		karaokeGen := lyrics.NewKaraokeGenerator(p.config)
 
 		// Prepare karaoke customization options from song settings
-		karaokeOptions := &lyrics.KaraokeOptions{
-			FontFamily:           song.KaraokeFontFamily,
-			FontSize:             song.KaraokeFontSize,
-			PrimaryColor:         song.KaraokePrimaryColor,
-			PrimaryBorderColor:   song.KaraokePrimaryBorderColor,
-			HighlightColor:       song.KaraokeHighlightColor,
-			HighlightBorderColor: song.KaraokeHighlightBorderColor,
-			Alignment:            song.KaraokeAlignment,
-			MarginBottom:         song.KaraokeMarginBottom,
-		}
-
-		// Use defaults if critical fields are missing or invalid
-		defaults := lyrics.DefaultKaraokeOptions()
-		if karaokeOptions.FontFamily == "" {
-			karaokeOptions.FontFamily = defaults.FontFamily
-		}
-		if karaokeOptions.FontSize <= 0 {
-			karaokeOptions.FontSize = defaults.FontSize
-		}
-		if karaokeOptions.PrimaryColor == "" {
-			karaokeOptions.PrimaryColor = defaults.PrimaryColor
-		}
-		if karaokeOptions.PrimaryBorderColor == "" {
-			karaokeOptions.PrimaryBorderColor = defaults.PrimaryBorderColor
-		}
-		if karaokeOptions.HighlightColor == "" {
-			karaokeOptions.HighlightColor = defaults.HighlightColor
-		}
-		if karaokeOptions.HighlightBorderColor == "" {
-			karaokeOptions.HighlightBorderColor = defaults.HighlightBorderColor
-		}
-		if karaokeOptions.Alignment <= 0 || karaokeOptions.Alignment > 9 {
-			karaokeOptions.Alignment = defaults.Alignment
-		}
+		karaokeOptions := karaokeOptionsForSong(song)
 
 		if renderLog != nil {
 			renderLog.Info("Karaoke configuration:")
@@ -850,6 +1048,17 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 			renderLog.Property("  Primary Color", karaokeOptions.PrimaryColor)
 			renderLog.Property("  Highlight Color", karaokeOptions.HighlightColor)
 			renderLog.Property("  Alignment", karaokeOptions.Alignment)
+			renderLog.Property("  Timing Offset", fmt.Sprintf("%+.2fs", karaokeOptions.TimingOffset))
+		}
+
+		whisperModel := lyrics.NormalizeWhisperModel(song.KaraokeWhisperModel)
+		if whisperModel != song.KaraokeWhisperModel && song.KaraokeWhisperModel != "" {
+			log.Printf("Warning: invalid karaoke whisper model %q, using %s", song.KaraokeWhisperModel, whisperModel)
+		}
+		language := lyrics.NormalizeLanguage(song.Language)
+		if renderLog != nil {
+			renderLog.Property("Whisper Model", whisperModel)
+			renderLog.Property("Language", language)
 		}
 
 		// Generate ASS subtitles from vocals, using lyrics_karaoke for display
@@ -862,7 +1071,17 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 			renderLog.Info("Attempting WhisperX (GPU) first, will fallback to Faster-Whisper (CPU) if unavailable")
 		}
 
-		assPath, whisperEngine, err := karaokeGen.GenerateKaraokeSubtitles(vocalPath, int(song.ID), tempDir, song.LyricsKaraoke, karaokeOptions)
+		var karaokeResult *lyrics.KaraokeResult
+		var err error
+		if canReuseTimestamps(song, vocalPath, tempDir, whisperModel) {
+			// Same vocals and model as last time, so only the ASS needs rebuilding
+			if renderLog != nil {
+				renderLog.Info("Reusing cached karaoke timestamps from %s", song.WhisperEngine)
+			}
+			karaokeResult, err = karaokeGen.RegenerateKaraokeSubtitles(int(song.ID), tempDir, song.LyricsKaraoke, song.WhisperEngine, karaokeOptions)
+		} else {
+			karaokeResult, err = karaokeGen.GenerateKaraokeSubtitles(vocalPath, int(song.ID), tempDir, song.LyricsKaraoke, whisperModel, language, karaokeOptions)
+		}
 		if err != nil {
 			log.Printf("Warning: failed to generate karaoke subtitles: %v, using fallback lyrics", err)
 			if renderLog != nil {
@@ -870,17 +1089,23 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 				renderLog.Info("This likely means Python modules are missing (faster_whisper or torch)")
 			}
 		} else {
-			assSubtitlePath = assPath
-			song.WhisperEngine = whisperEngine
-			log.Printf("Generated karaoke subtitles using %s: %s", whisperEngine, assSubtitlePath)
+			assSubtitlePath = karaokeResult.ASSPath
+			song.WhisperEngine = karaokeResult.WhisperEngine
+			log.Printf("Generated karaoke subtitles using %s: %s", karaokeResult.WhisperEngine, assSubtitlePath)
+
+			// Store the detected language so later renders don't need to detect it again
+			if language == lyrics.LanguageAuto && karaokeResult.Language != lyrics.LanguageAuto {
+				song.Language = karaokeResult.Language
+			}
 
 			if renderLog != nil {
 				renderLog.Success("Karaoke subtitles generated successfully")
-				renderLog.Property("Whisper Engine Used", whisperEngine)
+				renderLog.Property("Whisper Engine Used", karaokeResult.WhisperEngine)
+				renderLog.Property("Detected Language", karaokeResult.Language)
 				renderLog.Property("ASS File Path", assSubtitlePath)
 			}
 
-			// Save whisper engine info to database
+			// Save whisper engine and language info to database
 			if err := p.songRepo.Update(song); err != nil {
 				log.Printf("Warning: failed to save whisper engine to database: %v", err)
 				if renderLog != nil {
@@ -895,33 +1120,76 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 	}
 
 	// Create video renderer with branding path
-	brandingPath := filepath.Join(p.config.StoragePath, "branding")
-	renderer := video.NewVideoRenderer(outputDir, brandingPath)
+	brandingPath := p.config.BrandingPath
+	renderer := p.newVideoRenderer(outputDir, song)
+	if item.Draft {
+		renderer.Width = video.DraftWidth
+		renderer.Height = video.DraftHeight
+		renderer.Quality = video.QualityDraft
+	}
+	if renderLog != nil {
+		renderLog.Property("Quality", renderer.Quality)
+	}
 
+	copyright := p.songCopyright(song)
 	if renderLog != nil {
 		renderLog.Info("Preparing video render options...")
 		renderLog.Property("Branding Path", brandingPath)
+		renderLog.Property("Copyright", copyright)
 	}
 
 	// Prepare render options
 	opts := &video.VideoRenderOptions{
-		AudioPath:         audioPath,
-		Duration:          song.DurationSeconds,
-		ImagePaths:        imageSegments,
-		LyricsData:        timedLyrics,
-		VocalOnset:        vocalOnset,
-		CrossfadeDuration: 2.0,             // 2 second crossfade between images
-		EnableKaraoke:     false,           // Karaoke highlighting disabled by default
-		ASSSubtitlePath:   assSubtitlePath, // Use generated ASS subtitles if available
-		Key:               song.Key,
-		Tempo:             song.Tempo,
-		BPM:               song.BPM,
-		Title:             song.Title,
-		Artist:            song.ArtistName,
-		SpectrumStyle:     getSpectrumStyle(song.SpectrumStyle),
-		SpectrumColor:     getSpectrumColorHex(song.SpectrumColor),
-		SpectrumOpacity:   getSpectrumOpacity(song.SpectrumOpacity),
-		OutputPath:        videoPath,
+		AudioPath:          audioPath,
+		Duration:           song.DurationSeconds,
+		ImagePaths:         imageSegments,
+		LyricsData:         timedLyrics,
+		VocalOnset:         vocalOnset,
+		CrossfadeDuration:  2.0,             // 2 second crossfade between images
+		EnableKaraoke:      false,           // Karaoke highlighting disabled by default
+		ASSSubtitlePath:    assSubtitlePath, // Use generated ASS subtitles if available
+		LyricTheme:         video.NormalizeLyricTheme(song.LyricTheme),
+		LyricRenderMode:    video.NormalizeLyricRenderMode(song.LyricRenderMode),
+		EmbedSoftSubtitles: song.EmbedSoftSubtitles,
+		SoftSubtitlesOnly:  song.EmbedSoftSubtitles && song.SoftSubtitlesOnly,
+		Key:                song.Key,
+		Tempo:              song.Tempo,
+		BPM:                song.BPM,
+		Title:              song.Title,
+		Artist:             song.ArtistName,
+		CopyrightText:      copyright,
+		Album:              p.albumTitle(song),
+		Genre:              videoGenreTag(song),
+		Comment:            videoCommentTag(song),
+		SpectrumStyle:      video.NormalizeSpectrumStyle(song.SpectrumStyle),
+		SpectrumColor:      getSpectrumColorHex(song.SpectrumColor),
+		SpectrumOpacity:    getSpectrumOpacity(song.SpectrumOpacity),
+		SpectrumPosition:   song.SpectrumPosition,
+		SpectrumHeight:     song.SpectrumHeight,
+		Fades:              songFades(song),
+		OutputPath:         videoPath,
+	}
+	opts.Countdown = video.CountdownConfig{
+		Hidden:    song.HideCountdown,
+		Threshold: song.CountdownThreshold,
+		BarWidth:  song.CountdownBarWidth,
+		Color:     song.CountdownColor,
+		Text:      song.CountdownText,
+	}
+	opts.KenBurns = video.KenBurnsConfig{
+		Enabled:   song.EnableKenBurns,
+		ZoomRate:  song.KenBurnsZoomRate,
+		Direction: song.KenBurnsDirection,
+	}
+
+	// Drafts burn lyrics through libass, which scales to the smaller frame,
+	// and cut straight between images
+	if item.Draft {
+		opts.HardCuts = true
+		opts.KenBurns.Enabled = false
+		opts.LyricRenderMode = video.LyricRenderSubtitles
+		opts.EmbedSoftSubtitles = false
+		opts.SoftSubtitlesOnly = false
 	}
 
 	if renderLog != nil {
@@ -941,6 +1209,9 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 		renderLog.Property("  Key", opts.Key)
 		renderLog.Property("  Tempo", opts.Tempo)
 		renderLog.Property("  BPM", opts.BPM)
+		renderLog.Property("  Album Tag", opts.Album)
+		renderLog.Property("  Genre Tag", opts.Genre)
+		renderLog.Property("  Comment Tag", opts.Comment)
 
 		// Detailed visualization settings logging
 		renderLog.Info("Visualization Settings:")
@@ -950,16 +1221,26 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 		renderLog.Property("  Spectrum Color (Processed)", opts.SpectrumColor)
 		renderLog.Property("  Spectrum Opacity (DB)", song.SpectrumOpacity)
 		renderLog.Property("  Spectrum Opacity (Processed)", opts.SpectrumOpacity)
+		renderLog.Property("  Spectrum Position", opts.SpectrumPosition)
+		renderLog.Property("  Spectrum Height", opts.SpectrumHeight)
+		if song.AudioFadeIn > 0 || song.AudioFadeOut > 0 {
+			renderLog.Property("  Audio Fade", fmt.Sprintf("in %.1fs, out %.1fs", song.AudioFadeIn, song.AudioFadeOut))
+		}
+		if song.VideoFadeIn > 0 || song.VideoFadeOut > 0 {
+			renderLog.Property("  Video Fade", fmt.Sprintf("in %.1fs, out %.1fs", song.VideoFadeIn, song.VideoFadeOut))
+		}
 	}
 
-	p.updateProgress(item, "Rendering video", 75, "Rendering video (this may take a few minutes)")
+	p.updateProgress(item, models.PhaseRender, "Rendering video", 62, "Rendering video (this may take a few minutes)")
 
 	if renderLog != nil {
 		renderLog.Info("Starting FFmpeg video render...")
 	}
 
 	// Render the video
+	renderStart := time.Now()
 	finalPath, err := renderer.RenderVideo(opts)
+	metrics.ObserveDuration(metrics.RenderDuration, renderStart)
 	if err != nil {
 		if renderLog != nil {
 			renderLog.Error("Video rendering failed: %v", err)
@@ -972,7 +1253,67 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 		renderLog.Property("Final Video Path", finalPath)
 	}
 
-	p.updateProgress(item, "Rendering video", 90, "Video rendering complete")
+	expectedDuration := song.DurationSeconds
+	introLength := 0.0
+	if song.IntroCardEnabled {
+		p.updateProgress(item, models.PhaseRender, "Rendering video", 90, "Prepending intro card")
+		added, err := renderer.PrependIntroCard(finalPath, video.IntroCard{
+			Duration:        song.IntroDuration,
+			BackgroundColor: song.IntroBackgroundColor,
+			TextColor:       song.IntroTextColor,
+			BackgroundPath:  p.albumCoverArt(song),
+			Title:           opts.Title,
+			Artist:          opts.Artist,
+			Album:           opts.Album,
+		})
+		if err != nil {
+			if renderLog != nil {
+				renderLog.Error("Intro card failed: %v", err)
+			}
+			return fmt.Errorf("intro card failed: %w", err)
+		}
+		expectedDuration += added
+		introLength = added
+		renderer.SaveDebugStep(6, "intro", finalPath)
+		if renderLog != nil {
+			renderLog.Success("Intro card prepended")
+			renderLog.Property("Intro Length", fmt.Sprintf("%.2fs", added))
+		}
+	}
+	if song.OutroCardPath != "" {
+		p.updateProgress(item, models.PhaseRender, "Rendering video", 92, "Appending outro card")
+		added, err := renderer.AppendOutroCard(finalPath, song.OutroCardPath, song.OutroDuration)
+		if err != nil {
+			if renderLog != nil {
+				renderLog.Error("Outro card failed: %v", err)
+			}
+			return fmt.Errorf("outro card failed: %w", err)
+		}
+		expectedDuration += added
+		renderer.SaveDebugStep(7, "outro", finalPath)
+		if renderLog != nil {
+			renderLog.Success("Outro card appended")
+			renderLog.Property("Outro Card", song.OutroCardPath)
+			renderLog.Property("Outro Length", fmt.Sprintf("%.2fs", added))
+		}
+	}
+
+	p.updateProgress(item, models.PhaseRender, "Rendering video", 95, "Verifying rendered video")
+	probe, err := verifyVideo(finalPath, expectedDuration)
+	if err != nil {
+		if renderLog != nil {
+			renderLog.Error("Video verification failed: %v", err)
+		}
+		return fmt.Errorf("video verification failed: %w", err)
+	}
+	if renderLog != nil {
+		renderLog.Success("Video verified")
+		renderLog.Property("Probed Duration", fmt.Sprintf("%.2fs", probe.Duration))
+		renderLog.Property("Probed Resolution", probe.Resolution())
+		renderLog.Property("Streams", len(probe.Streams))
+	}
+
+	p.updateProgress(item, models.PhaseRender, "Rendering video", 100, "Video rendering complete")
 
 	// Get file size
 	fileInfo, err := os.Stat(finalPath)
@@ -984,18 +1325,34 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 
 	// Store video path
 	item.VideoFilePath = finalPath
+	p.mirror(finalPath)
 
 	log.Printf("Video rendering complete for song: %s - Output: %s (%.2f MB)",
 		song.Title, finalPath, float64(item.VideoFileSize)/(1024*1024))
 
-	// Create or update video record in database
+	// The draft stays on the queue item only; the song's video record is the final render
+	if item.Draft {
+		return nil
+	}
+
+	p.saveVideoRecord(song, finalPath, models.VideoVariantMixed, probe, item.VideoFileSize)
+
+	// Extra audio versions reuse the finished visuals
+	p.renderOutputVariants(song, finalPath, expectedDuration, introLength, renderLog)
+
+	return nil
+}
+
+// saveVideoRecord creates or updates the song's video record for a variant.
+// Failures are logged rather than failing the render, since the file exists.
+func (p *Processor) saveVideoRecord(song *models.Song, path, variant string, probe *video.ProbeResult, size int64) {
 	videoRepo := database.NewVideoRepository(database.DB)
 	videoRecord := &models.Video{
 		SongID:          song.ID,
-		VideoFilePath:   finalPath,
+		VideoFilePath:   path,
 		Resolution:      song.TargetResolution,
-		DurationSeconds: &song.DurationSeconds,
-		FileSizeBytes:   item.VideoFileSize,
+		DurationSeconds: &probe.Duration,
+		FileSizeBytes:   size,
 		FPS:             30,
 		BackgroundStyle: &song.BackgroundStyle,
 		SpectrumColor:   &song.SpectrumColor,
@@ -1006,87 +1363,214 @@ func (p *Processor) renderVideo(item *models.QueueItem, song *models.Song, rende
 		BPM:             &song.BPM,
 		Key:             &song.Key,
 		Tempo:           &song.Tempo,
+		Variant:         variant,
+	}
+	videoRecord.Width = probe.Width
+	videoRecord.Height = probe.Height
+	if streamsJSON, err := json.Marshal(probe.Streams); err == nil {
+		videoRecord.Streams = string(streamsJSON)
 	}
 
 	if err := videoRepo.CreateOrUpdate(videoRecord); err != nil {
 		log.Printf("Error creating/updating video record in database: %v", err)
 		// Don't fail the whole process if video record creation fails
 	} else {
-		log.Printf("Video record created/updated in database: ID=%d", videoRecord.ID)
+		log.Printf("Video record created/updated in database: ID=%d (%s)", videoRecord.ID, variant)
 	}
+}
 
-	return nil
+// verifyVideo probes a rendered file and fails when it's missing a stream,
+// runs the wrong length or won't decode, so broken renders never complete
+func verifyVideo(path string, expectedDuration float64) (*video.ProbeResult, error) {
+	probe, err := video.VerifyVideo(path, expectedDuration)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Verified %s: %.2fs, %s, %d streams", path, probe.Duration, probe.Resolution(), len(probe.Streams))
+	return probe, nil
 }
 
-// buildImageSegments creates timed image segments from lyrics sections
-func (p *Processor) buildImageSegments(lyricsData *lyrics.LyricsData, imageDir string, totalDuration float64) ([]video.ImageSegment, error) {
-	var segments []video.ImageSegment
+// draftVideoPath places a draft render next to the final video without replacing it
+func draftVideoPath(videoPath string) string {
+	ext := filepath.Ext(videoPath)
+	return strings.TrimSuffix(videoPath, ext) + "_draft" + ext
+}
+
+// newVideoRenderer creates a renderer configured from settings and the song
+func (p *Processor) newVideoRenderer(outputDir string, song *models.Song) *video.VideoRenderer {
+	renderer := video.NewVideoRenderer(outputDir, p.config.BrandingPath)
+	renderer.StrictText = p.config.StrictDrawtext
+	renderer.KeepTempFiles = p.config.KeepTempFiles
+	if p.config.DebugRenderSteps {
+		renderer.DebugDir = utils.GetSongDebugPath(song.ID)
+	}
+	renderer.Quality = p.renderQuality(song)
+	renderer.ImageFit = video.NormalizeImageFit(song.ImageFit)
+	renderer.Fonts = video.NewFontRegistry(utils.GetFontsPath())
+	renderer.LyricFont = song.KaraokeFontFamily
+	p.applyOverlaySettings(renderer)
+	return renderer
+}
+
+// RenderOverlayPreview renders one frame of the song's first background image
+// (or a placeholder if it has none) with the metadata and branding overlays
+// exactly as a full render would place them. It returns the PNG's path, which
+// the caller should remove when done.
+func (p *Processor) RenderOverlayPreview(song *models.Song) (string, error) {
+	renderer := p.newVideoRenderer(utils.GetVideosPath(), song)
+
+	imagePath := ""
+	imageDir := filepath.Join(utils.GetImagesPath(), fmt.Sprintf("song_%d", song.ID))
+	if segments, err := p.buildEvenImageSegments(imageDir, 1, nil); err == nil {
+		imagePath = segments[0].ImagePath
+	} else {
+		log.Printf("No background image for song %d overlay preview, using placeholder: %v", song.ID, err)
+	}
+
+	opts := &video.VideoRenderOptions{
+		Key:           song.Key,
+		Tempo:         song.Tempo,
+		BPM:           song.BPM,
+		Title:         song.Title,
+		Artist:        song.ArtistName,
+		CopyrightText: p.songCopyright(song),
+	}
+
+	previewPath := filepath.Join(utils.GetTempPath(), fmt.Sprintf("overlay_preview_song_%d_%d.png", song.ID, time.Now().UnixNano()))
+	if err := renderer.RenderOverlayPreview(imagePath, opts, previewPath); err != nil {
+		return "", err
+	}
+	return previewPath, nil
+}
+
+// configureImageGenerator applies the master prompts and LLM prompt templates
+// from settings, then the song's genre, mood, master prompt overrides and
+// reference image
+func (p *Processor) configureImageGenerator(imageGen *image.ImageGenerator, song *models.Song) {
+	imageGen.Genre = song.Genre
+	imageGen.Mood = image.FormatMood(song.Mood)
+
+	settingsRepo := database.NewSettingsRepository(database.DB)
+	if settings, err := settingsRepo.Get(); err != nil {
+		log.Printf("Warning: failed to load settings for image generation: %v", err)
+	} else {
+		imageGen.SetMasterPrompts(settings.MasterPrompt, settings.MasterNegativePrompt)
+		imageGen.PromptTemplate = settings.ImagePromptTemplate
+		imageGen.SectionTemplates = settings.ImagePromptSectionTemplates
+	}
+
+	imageGen.SetMasterPrompts(song.MasterPromptOverride, song.MasterNegativeOverride)
+	imageGen.ReferenceImage = song.ReferenceImagePath
+	imageGen.ReferenceStrength = song.ReferenceStrength
+}
+
+// applyOverlaySettings sets the renderer's overlay font and logo placement
+// from settings, leaving the defaults when settings can't be loaded
+func (p *Processor) applyOverlaySettings(renderer *video.VideoRenderer) {
+	settingsRepo := database.NewSettingsRepository(database.DB)
+	settings, err := settingsRepo.Get()
+	if err != nil {
+		log.Printf("Warning: failed to load settings for overlays: %v", err)
+		return
+	}
+	renderer.OverlayFont = settings.OverlayFont
+	renderer.Logo = video.LogoConfig{
+		Size:    settings.LogoSize,
+		Opacity: settings.LogoOpacity,
+		Corner:  settings.LogoCorner,
+		Margin:  settings.LogoMargin,
+	}
+}
+
+// songCopyright returns the song's copyright line, falling back to the
+// settings default for songs that don't set one
+func (p *Processor) songCopyright(song *models.Song) string {
+	settings, err := database.NewSettingsRepository(database.DB).Get()
+	if err != nil {
+		log.Printf("Warning: failed to load settings for copyright: %v", err)
+	}
+	return settings.SongCopyright(song)
+}
+
+// renderQuality returns the song's encode quality, falling back to the global default
+func (p *Processor) renderQuality(song *models.Song) string {
+	quality := song.Quality
+	if quality == "" {
+		settingsRepo := database.NewSettingsRepository(database.DB)
+		if settings, err := settingsRepo.Get(); err != nil {
+			log.Printf("Warning: failed to load settings for render quality: %v", err)
+		} else {
+			quality = settings.DefaultQuality
+		}
+	}
+
+	normalized := video.NormalizeQuality(quality)
+	if quality != "" && normalized != quality {
+		log.Printf("Warning: invalid render quality %q, using %s", quality, normalized)
+	}
+	return normalized
+}
+
+// resolveVideoPath builds the output path from the configured naming template,
+// appending the song ID if another song already owns that filename
+func (p *Processor) resolveVideoPath(outputDir string, song *models.Song) string {
+	template := utils.DefaultVideoFilenameTemplate
+	settingsRepo := database.NewSettingsRepository(database.DB)
+	if settings, err := settingsRepo.Get(); err != nil {
+		log.Printf("Warning: failed to load settings for video naming: %v", err)
+	} else if settings.VideoFilenameTemplate != "" {
+		template = settings.VideoFilenameTemplate
+	}
+
+	filename := utils.BuildVideoFilename(song, template)
+	videoPath := filepath.Join(outputDir, filename)
 
-	// Build timing map from timed lines
-	lineTimings := make(map[int]*lyrics.TimedLine) // line index -> timing
-	for i := range lyricsData.TimedLines {
-		lineTimings[i] = &lyricsData.TimedLines[i]
+	videoRepo := database.NewVideoRepository(database.DB)
+	collision, err := videoRepo.PathUsedByOtherSong(videoPath, song.ID)
+	if err != nil {
+		log.Printf("Warning: failed to check video filename collision: %v", err)
+	}
+	if collision {
+		filename = fmt.Sprintf("%s_%d.mp4", strings.TrimSuffix(filename, ".mp4"), song.ID)
+		videoPath = filepath.Join(outputDir, filename)
+		log.Printf("Video filename already used by another song, using %s", filename)
 	}
 
+	return videoPath
+}
+
+// buildImageSegments creates timed image segments from lyrics sections,
+// aligned to the song's persisted beat and vocal timing (see sectionBounds).
+// Sections without an image use a fallback background, or are skipped when
+// fallbacks is nil.
+func (p *Processor) buildImageSegments(lyricsData *lyrics.LyricsData, imageDir string, totalDuration, vocalOnset float64, timing imageTiming, fallbacks *fallbackBackgrounds) ([]video.ImageSegment, error) {
+	var segments []video.ImageSegment
+
+	totalLines := 0
 	for _, section := range lyricsData.Sections {
-		var imageName string
-		switch section.Type {
-		case "verse":
-			// Each verse has unique image
-			imageName = fmt.Sprintf("bg-verse-%d.png", section.Number)
-		case "pre-chorus":
-			// Pre-choruses share one image (no number)
-			imageName = "bg-prechorus.png"
-		case "chorus":
-			// Choruses share one image (no number)
-			imageName = "bg-chorus.png"
-		case "final-chorus":
-			// Final chorus uses the same chorus image
-			imageName = "bg-chorus.png"
-		case "bridge":
-			// Bridge is unique, one per song (no number)
-			imageName = "bg-bridge.png"
-		case "intro":
-			imageName = "bg-intro.png"
-		case "outro":
-			imageName = "bg-outro.png"
-		default:
-			imageName = fmt.Sprintf("bg-%s.png", section.Type)
+		if section.EndLine+1 > totalLines {
+			totalLines = section.EndLine + 1
 		}
+	}
 
+	for _, section := range lyricsData.Sections {
+		// Each verse has a unique image; repeated sections share one
+		imageName := image.SectionImageFilename(section.Type, section.Number)
 		imagePath := filepath.Join(imageDir, imageName)
 
 		// Check if image exists
 		if _, err := os.Stat(imagePath); err != nil {
 			log.Printf("Warning: image not found: %s", imagePath)
-			continue
-		}
-
-		// Calculate timing from section lines
-		startTime := totalDuration
-		endTime := 0.0
-
-		// Use section line range to find timings
-		for i := section.StartLine; i <= section.EndLine && i < len(lyricsData.TimedLines); i++ {
-			timing := &lyricsData.TimedLines[i]
-			if timing.StartTime < startTime {
-				startTime = timing.StartTime
+			if fallbacks == nil {
+				continue
 			}
-			if timing.EndTime > endTime {
-				endTime = timing.EndTime
+			if imagePath, err = fallbacks.path(imageName); err != nil {
+				log.Printf("Warning: failed to generate fallback background for %s: %v", imageName, err)
+				continue
 			}
 		}
 
-		// Ensure valid timing
-		if startTime >= totalDuration || endTime <= 0 {
-			// Use section position as fallback
-			startTime = float64(section.StartLine) * 3.0 // ~3 seconds per line
-			endTime = float64(section.EndLine+1) * 3.0
-		}
-
-		if startTime >= endTime {
-			endTime = startTime + 10.0 // default 10 seconds
-		}
+		startTime, endTime := timing.sectionBounds(section, lyricsData.TimedLines, totalLines, vocalOnset, totalDuration)
 
 		segments = append(segments, video.ImageSegment{
 			ImagePath: imagePath,
@@ -1102,6 +1586,95 @@ func (p *Processor) buildImageSegments(lyricsData *lyrics.LyricsData, imageDir s
 	return segments, nil
 }
 
+// instrumentalSections returns a fixed set of sections used to generate
+// backgrounds for songs without lyrics
+func instrumentalSections(song *models.Song) *lyrics.LyricsData {
+	description := fmt.Sprintf("Instrumental piece titled \"%s\"", song.Title)
+	sectionTypes := []struct {
+		sectionType string
+		number      int
+	}{
+		{"intro", 1},
+		{"verse", 1},
+		{"verse", 2},
+		{"bridge", 1},
+		{"outro", 1},
+	}
+
+	data := &lyrics.LyricsData{}
+	for i, st := range sectionTypes {
+		data.Sections = append(data.Sections, lyrics.Section{
+			Type:      st.sectionType,
+			Number:    st.number,
+			Lines:     []string{description},
+			StartLine: i,
+			EndLine:   i,
+		})
+	}
+	return data
+}
+
+// buildEvenImageSegments spreads every background image in the directory evenly
+// across the song duration (used for instrumentals, which have no lyric timing).
+// With no images, a single fallback background covers the song unless
+// fallbacks is nil.
+func (p *Processor) buildEvenImageSegments(imageDir string, totalDuration float64, fallbacks *fallbackBackgrounds) ([]video.ImageSegment, error) {
+	files, err := os.ReadDir(imageDir)
+	if err != nil && (fallbacks == nil || !os.IsNotExist(err)) {
+		return nil, fmt.Errorf("failed to read image directory: %w", err)
+	}
+
+	var imagePaths []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasPrefix(file.Name(), "bg-") && strings.HasSuffix(file.Name(), ".png") {
+			imagePaths = append(imagePaths, filepath.Join(imageDir, file.Name()))
+		}
+	}
+
+	if len(imagePaths) == 0 && fallbacks != nil {
+		log.Printf("Warning: no background images in %s", imageDir)
+		if fallbackPath, err := fallbacks.path("bg-instrumental.png"); err != nil {
+			log.Printf("Warning: failed to generate fallback background: %v", err)
+		} else {
+			imagePaths = append(imagePaths, fallbackPath)
+		}
+	}
+
+	if len(imagePaths) == 0 {
+		return nil, fmt.Errorf("no image segments created")
+	}
+
+	// Keep a natural order: intro first, outro last, everything else in between
+	sort.SliceStable(imagePaths, func(i, j int) bool {
+		return imageSortRank(imagePaths[i]) < imageSortRank(imagePaths[j])
+	})
+
+	segmentDuration := totalDuration / float64(len(imagePaths))
+	segments := make([]video.ImageSegment, 0, len(imagePaths))
+	for i, imagePath := range imagePaths {
+		segments = append(segments, video.ImageSegment{
+			ImagePath: imagePath,
+			StartTime: float64(i) * segmentDuration,
+			EndTime:   float64(i+1) * segmentDuration,
+		})
+	}
+
+	return segments, nil
+}
+
+// imageSortRank orders background images so intro comes first and outro last
+func imageSortRank(imagePath string) int {
+	name := filepath.Base(imagePath)
+	switch {
+	case strings.HasPrefix(name, "bg-intro"):
+		return 0
+	case strings.HasPrefix(name, "bg-outro"):
+		return 2
+	default:
+		return 1
+	}
+}
+
 // buildTimedLyrics converts lyrics TimedLines to video LyricLines
 func (p *Processor) buildTimedLyrics(lyricsData *lyrics.LyricsData) []video.LyricLine {
 	var timedLyrics []video.LyricLine
@@ -1121,6 +1694,26 @@ func (p *Processor) buildTimedLyrics(lyricsData *lyrics.LyricsData) []video.Lyri
 	return timedLyrics
 }
 
+// buildEvenTimedLyrics spreads the song's lyric lines evenly across duration seconds
+func (p *Processor) buildEvenTimedLyrics(song *models.Song, duration float64) []video.LyricLine {
+	if song.Instrumental || duration <= 0 {
+		return nil
+	}
+
+	lyricsText := song.LyricsKaraoke
+	if strings.TrimSpace(lyricsText) == "" {
+		lyricsText = song.Lyrics
+	}
+
+	timedLines, err := lyrics.AlignLyricsToBeats(lyricsText, songBeatTimes(song), duration)
+	if err != nil {
+		log.Printf("Warning: no lyric lines to time for draft render: %v", err)
+		return nil
+	}
+
+	return p.buildTimedLyrics(&lyrics.LyricsData{TimedLines: timedLines})
+}
+
 // mixAudioTracks mixes vocals and instrumental tracks together
 func (p *Processor) mixAudioTracks(vocalsPath, instrumentalPath, outputPath string) error {
 	// Ensure output directory exists
@@ -1146,85 +1739,65 @@ func (p *Processor) mixAudioTracks(vocalsPath, instrumentalPath, outputPath stri
 	return nil
 }
 
-// parseImageFilename extracts image type and sequence number from filename
-// Examples: bg-verse-1.png -> ("verse", 1), bg-chorus.png -> ("chorus", 0), bg-intro.png -> ("intro", 0)
-func parseImageFilename(filename string) (string, *int) {
-	// Remove extension
-	name := strings.TrimSuffix(filename, ".png")
-
-	// Remove "bg-" prefix if present
-	name = strings.TrimPrefix(name, "bg-")
-
-	// Split by hyphen to check for sequence number
-	parts := strings.Split(name, "-")
-
-	if len(parts) == 1 {
-		// No sequence number: bg-intro.png, bg-chorus.png, etc.
-		return parts[0], nil
-	}
-
-	if len(parts) == 2 {
-		// Has sequence number: bg-verse-1.png, bg-verse-2.png
-		imageType := parts[0]
-
-		// Try to parse sequence number
-		var seqNum int
-		if _, err := fmt.Sscanf(parts[1], "%d", &seqNum); err == nil {
-			return imageType, &seqNum
-		}
-
-		// If parsing fails, treat whole thing as type
-		return name, nil
-	}
-
-	// Multiple hyphens - join as type name
-	return name, nil
-}
-
 // uploadToYouTube uploads the video to YouTube
 func (p *Processor) uploadToYouTube(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) error {
 	if renderLog != nil {
 		renderLog.Phase("YOUTUBE UPLOAD", "Uploading video to YouTube (stub)")
 	}
-	p.updateProgress(item, "Uploading to YouTube", 92, "Preparing upload")
+	p.updateProgress(item, models.PhaseUpload, "Uploading to YouTube", 20, "Preparing upload")
 	time.Sleep(500 * time.Millisecond)
 
-	p.updateProgress(item, "Uploading to YouTube", 95, "Uploading video")
+	p.updateProgress(item, models.PhaseUpload, "Uploading to YouTube", 50, "Uploading video")
 	time.Sleep(1 * time.Second)
 
-	p.updateProgress(item, "Uploading to YouTube", 98, "Setting metadata")
+	p.updateProgress(item, models.PhaseUpload, "Uploading to YouTube", 80, "Setting metadata")
 	time.Sleep(300 * time.Millisecond)
 
-	p.updateProgress(item, "Uploading to YouTube", 100, "Upload complete")
+	p.updateProgress(item, models.PhaseUpload, "Uploading to YouTube", 100, "Upload complete")
 
 	log.Printf("YouTube upload complete for song: %s", song.Title)
 	return nil
 }
 
-// getSpectrumStyle returns the FFmpeg spectrum visualization style
-func getSpectrumStyle(styleName string) string {
-	// Map style name to FFmpeg filter
-	// Support direct filter names or aliases
-	switch styleName {
-	case "stereo", "dual", "leftright":
-		return "stereo" // Stereo visualizer with left/right channel bars (default)
-	case "showfreqs", "bars", "equalizer", "freq":
-		return "showfreqs" // Classic equalizer bars
-	case "showspectrum", "spectrum", "spectro":
-		return "showspectrum" // Stationary spectrum display
-	case "showcqt", "cqt", "professional":
-		return "showcqt" // High-quality CQT spectrum with bars
-	case "showwaves", "wave", "waveform":
-		return "showwaves" // Smooth waveform
-	case "showvolume", "volume", "meter":
-		return "showvolume" // Volume meter
-	case "avectorscope", "scope", "circle":
-		return "avectorscope" // Circular vector scope
-	default:
-		return "stereo" // Default to stereo visualizer
+// inferGenre sets a song's genre from the LLM when neither the user nor audio
+// analysis provided one. Failures only log, since a song renders fine
+// without a genre.
+func (p *Processor) inferGenre(song *models.Song) {
+	genre, err := p.aiClient.InferGenre(song)
+	if err != nil {
+		log.Printf("Warning: failed to infer genre for song %d: %v", song.ID, err)
+		return
+	}
+	song.Genre = genre
+	song.GenreSource = models.GenreSourceAI
+	log.Printf("Inferred genre: %s", genre)
+}
+
+// songFades returns the song's audio and picture fades
+func songFades(song *models.Song) video.Fades {
+	return video.Fades{
+		Audio: video.Fade{In: song.AudioFadeIn, Out: song.AudioFadeOut},
+		Video: video.Fade{In: song.VideoFadeIn, Out: song.VideoFadeOut},
 	}
 }
 
+// songAudioFade returns the song's audio fades, ending with the song itself
+// so the fade out isn't pushed into an outro card
+func songAudioFade(song *models.Song) video.Fade {
+	return video.Fade{In: song.AudioFadeIn, Out: song.AudioFadeOut, Length: song.DurationSeconds}
+}
+
+// songBeatTimes returns the beat times saved by audio analysis, or nil when
+// there are none or they can't be read
+func songBeatTimes(song *models.Song) []float64 {
+	beats, err := audio.ParseBeatTimes(song.BeatTimes)
+	if err != nil {
+		log.Printf("Warning: ignoring beat times for song %d: %v", song.ID, err)
+		return nil
+	}
+	return beats
+}
+
 // getSpectrumColorHex returns color setting (rainbow or color name)
 func getSpectrumColorHex(colorName string) string {
 	// Return color as-is if it's "rainbow" or a recognized color name
@@ -1243,8 +1816,40 @@ func getSpectrumOpacity(opacity float64) float64 {
 	return 0.3 // Default 30% opacity
 }
 
-// updateProgress updates the queue item progress and broadcasts it
-func (p *Processor) updateProgress(item *models.QueueItem, step string, progress int, message string) {
+// recordPhaseStart adds the start of a pipeline phase to the item's history,
+// so the timeline shows how long each phase took
+func (p *Processor) recordPhaseStart(item *models.QueueItem, phase pipelinePhase) {
+	event := *item
+	event.CurrentStep = phase.name
+	if err := database.NewQueueRepository(database.DB).AddEvent(&event, "Started "+strings.ToLower(phase.label)); err != nil {
+		log.Printf("Warning: failed to record phase start for queue item %d: %v", item.ID, err)
+	}
+}
+
+// recordPhaseLog stores a structured log entry for a finished pipeline phase:
+// its outcome and how long it ran, for processing-time analytics
+func (p *Processor) recordPhaseLog(item *models.QueueItem, phase pipelinePhase, duration time.Duration, phaseErr error) {
+	entry := &models.ProcessingLog{
+		QueueID:         item.ID,
+		Step:            phase.name,
+		Status:          models.StatusCompleted,
+		Message:         phase.label + " completed",
+		DurationSeconds: duration.Seconds(),
+	}
+	if phaseErr != nil {
+		entry.Status = models.StatusFailed
+		entry.Message = phaseErr.Error()
+	}
+	if err := database.NewProcessingLogRepository(database.DB).Create(entry); err != nil {
+		log.Printf("Warning: failed to record processing log for queue item %d: %v", item.ID, err)
+	}
+}
+
+// updateProgress updates the queue item progress and broadcasts it.
+// phaseProgress is the 0-100% progress within phase, converted to overall
+// job progress using the configured phase weights.
+func (p *Processor) updateProgress(item *models.QueueItem, phase, step string, phaseProgress int, message string) {
+	progress := p.overallProgress(phase, phaseProgress)
 	item.CurrentStep = step
 	item.Progress = progress
 
@@ -1252,3 +1857,95 @@ func (p *Processor) updateProgress(item *models.QueueItem, step string, progress
 
 	log.Printf("[Queue %d] %s: %d%% - %s", item.ID, step, progress, message)
 }
+
+// overallProgress converts progress within a phase into overall job progress
+func (p *Processor) overallProgress(phase string, phaseProgress int) int {
+	if p.progress == nil {
+		p.progress = newProgressPlan(p.config.PhaseWeights, phaseOrder)
+	}
+	return p.progress.overall(phase, phaseProgress)
+}
+
+// hasAudioAnalysis reports whether a song's BPM, key and duration are already known
+func hasAudioAnalysis(song *models.Song) bool {
+	return song.BPM > 0 && song.Key != "" && song.DurationSeconds > 0
+}
+
+// isInstrumental reports whether a song has no lyrics to process
+func isInstrumental(song *models.Song) bool {
+	return song.Instrumental
+}
+
+// mirror copies a generated artifact to remote storage when one is configured.
+// The local copy is kept since rendering reads images from disk.
+func (p *Processor) mirror(localPath string) {
+	if err := storage.Mirror(context.Background(), p.storage, utils.GetDataPath(), localPath); err != nil {
+		log.Printf("Warning: failed to upload %s to storage: %v", localPath, err)
+	}
+}
+
+// imageProgress returns a callback that broadcasts intra-image step progress,
+// interpolating the image phase percentage between startProgress and endProgress
+func (p *Processor) imageProgress(item *models.QueueItem, startProgress, endProgress int, message string) image.ProgressFunc {
+	return func(step, total int) {
+		if total <= 0 {
+			return
+		}
+		progress := p.overallProgress(models.PhaseImages, startProgress+(endProgress-startProgress)*step/total)
+		item.CurrentStep = "Generating images"
+		item.Progress = progress
+		p.broadcaster.BroadcastFromQueueItem(item, fmt.Sprintf("%s - step %d/%d", message, step, total))
+	}
+}
+
+// albumTitle looks up the song's album title for the MP4 album tag
+func (p *Processor) albumTitle(song *models.Song) string {
+	if song.AlbumID == nil {
+		return ""
+	}
+	title, err := p.songRepo.GetAlbumTitle(*song.AlbumID)
+	if err != nil {
+		log.Printf("Warning: failed to load album %d for song %d: %v", *song.AlbumID, song.ID, err)
+		return ""
+	}
+	return title
+}
+
+// albumCoverArt looks up the song's album cover art for the intro card
+func (p *Processor) albumCoverArt(song *models.Song) string {
+	if song.AlbumID == nil {
+		return ""
+	}
+	path, err := p.songRepo.GetAlbumCoverArt(*song.AlbumID)
+	if err != nil {
+		log.Printf("Warning: failed to load album %d cover art for song %d: %v", *song.AlbumID, song.ID, err)
+		return ""
+	}
+	if path != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(utils.GetDataPath(), path)
+	}
+	return path
+}
+
+// videoGenreTag prefers the AI-enriched primary genre over the user-entered genre
+func videoGenreTag(song *models.Song) string {
+	if song.GenrePrimary != "" {
+		return song.GenrePrimary
+	}
+	return song.Genre
+}
+
+// videoCommentTag describes the song's BPM, key and summary for the MP4 comment tag
+func videoCommentTag(song *models.Song) string {
+	var parts []string
+	if song.BPM > 0 {
+		parts = append(parts, fmt.Sprintf("BPM: %.0f", song.BPM))
+	}
+	if song.Key != "" {
+		parts = append(parts, "Key: "+song.Key)
+	}
+	if song.Summary != "" {
+		parts = append(parts, song.Summary)
+	}
+	return strings.Join(parts, " | ")
+}
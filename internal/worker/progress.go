@@ -0,0 +1,65 @@
+package worker
+
+import "github.com/AndrewDonelson/track-studio-orchestrator/config"
+
+// progressPlan maps each pipeline phase to its slice of overall job progress.
+// Skipped phases get no slice; their weight is redistributed to the rest.
+type progressPlan struct {
+	start map[string]float64
+	span  map[string]float64
+}
+
+// newProgressPlan divides 0-100% between the active phases in proportion to
+// their weights. If all active phases have zero weight they share it equally.
+func newProgressPlan(weights map[string]int, active []string) *progressPlan {
+	if weights == nil {
+		weights = config.DefaultPhaseWeights()
+	}
+
+	total := 0
+	for _, phase := range active {
+		total += weights[phase]
+	}
+
+	plan := &progressPlan{
+		start: make(map[string]float64),
+		span:  make(map[string]float64),
+	}
+	offset := 0.0
+	for _, phase := range phaseOrder {
+		plan.start[phase] = offset
+		if !containsPhase(active, phase) {
+			continue
+		}
+
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(weights[phase]) / float64(total)
+		} else {
+			share = 100 / float64(len(active))
+		}
+		plan.span[phase] = share
+		offset += share
+	}
+	return plan
+}
+
+// overall converts a phase's own 0-100% progress into overall job progress
+func (pp *progressPlan) overall(phase string, phaseProgress int) int {
+	if phaseProgress < 0 {
+		phaseProgress = 0
+	}
+	if phaseProgress > 100 {
+		phaseProgress = 100
+	}
+	return int(pp.start[phase] + pp.span[phase]*float64(phaseProgress)/100 + 0.5)
+}
+
+func containsPhase(phases []string, phase string) bool {
+	for _, p := range phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// checkRenderDuration rejects song durations that would render an empty
+// video or tie the worker up for hours. Either usually means a corrupt
+// analysis or a bad upload, so it's better to fail the job with a clear
+// reason than hand FFmpeg the value.
+func (p *Processor) checkRenderDuration(seconds float64) error {
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds <= 0 {
+		return fmt.Errorf("invalid song duration %.2fs - check the audio file and re-run audio analysis", seconds)
+	}
+
+	limit := p.config.MaxVideoDuration
+	if limit > 0 && seconds > limit.Seconds() {
+		duration := time.Duration(seconds * float64(time.Second)).Round(time.Second)
+		return fmt.Errorf("song duration %s exceeds the %s limit (MAX_VIDEO_DURATION) - check the audio file and re-run audio analysis", duration, limit)
+	}
+	return nil
+}
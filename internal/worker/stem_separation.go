@@ -0,0 +1,61 @@
+package worker
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/utils"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/logger"
+)
+
+// noStemSeparation reports whether a song needs no stem separation: it
+// already has stems, has no mixed track, or auto-separation is off
+func (p *Processor) noStemSeparation(song *models.Song) bool {
+	return !p.config.AutoSeparateStems || utils.HasSongStems(song.ID) || utils.GetSongMixedPath(song.ID) == ""
+}
+
+// separateStems splits a mixed-only upload into vocal and music stems so
+// analysis and karaoke get clean inputs. When no separator is installed, or
+// separation fails, the pipeline carries on with the mixed track.
+func (p *Processor) separateStems(item *models.QueueItem, song *models.Song, renderLog *logger.RenderLogger) error {
+	if p.noStemSeparation(song) {
+		return nil
+	}
+
+	separator := audio.NewSeparator(p.config)
+	if report := separator.CheckEnvironment(); !report.Ready {
+		log.Printf("Stem separation unavailable for song %d, using mixed track: %v", song.ID, report.Err())
+		if renderLog != nil {
+			renderLog.Info("Stem separation unavailable (%v) - using mixed track", report.Err())
+		}
+		p.updateProgress(item, models.PhaseSeparation, "Separating stems", 100, "Stem separation unavailable, using mixed track")
+		return nil
+	}
+
+	p.updateProgress(item, models.PhaseSeparation, "Separating stems", 10, "Splitting mixed track into vocal and music stems (this may take several minutes)")
+	if renderLog != nil {
+		renderLog.Info("Separating stems with %s model %s...", separator.PythonPath, separator.Model)
+	}
+
+	separation, err := services.SeparateSongStems(separator, p.storage, song.ID)
+	if err != nil {
+		log.Printf("Warning: stem separation failed for song %d, using mixed track: %v", song.ID, err)
+		if renderLog != nil {
+			renderLog.Warning("Stem separation failed, using mixed track: %v", err)
+		}
+		p.updateProgress(item, models.PhaseSeparation, "Separating stems", 100, "Stem separation failed, using mixed track")
+		return nil
+	}
+
+	if renderLog != nil {
+		renderLog.Success("Stems separated")
+		renderLog.Property("Method", separation.Method)
+		renderLog.Property("Vocal Stem", separation.VocalsPath)
+		renderLog.Property("Music Stem", separation.MusicPath)
+	}
+	p.updateProgress(item, models.PhaseSeparation, "Separating stems", 100, fmt.Sprintf("Stems separated using %s", separation.Method))
+	return nil
+}
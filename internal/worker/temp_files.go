@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"log"
+	"os"
+)
+
+// tempFiles tracks the temporary files a job creates so one deferred cleanup
+// removes them whether the job succeeds or fails. Paths are added before the
+// file is written, so a partial file left by a failed step is removed too.
+type tempFiles struct {
+	paths []string
+}
+
+// add tracks path for cleanup and returns it
+func (t *tempFiles) add(path string) string {
+	t.paths = append(t.paths, path)
+	return path
+}
+
+// cleanup removes every tracked file that exists
+func (t *tempFiles) cleanup() {
+	for _, path := range t.paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: failed to remove temp file %s: %v", path, err)
+		}
+	}
+	t.paths = nil
+}
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/audio"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/logger"
+)
+
+// vocalOnset returns how many seconds into the song the vocals start, which
+// shifts all lyric timing. A song's override wins; otherwise the first
+// detected vocal segment is used unless it lands implausibly late, which
+// usually means detection missed the opening vocals.
+func (p *Processor) vocalOnset(song *models.Song, renderLog *logger.RenderLogger) float64 {
+	if song.Instrumental {
+		return 0
+	}
+
+	if override := song.VocalOnsetOverride; override != nil {
+		switch {
+		case *override < 0:
+			log.Printf("Warning: ignoring negative vocal onset override %.2fs for song %d", *override, song.ID)
+		case song.DurationSeconds > 0 && *override >= song.DurationSeconds:
+			log.Printf("Warning: ignoring vocal onset override %.2fs past the end of song %d (%.2fs)", *override, song.ID, song.DurationSeconds)
+		default:
+			log.Printf("Applying vocal onset override: %.2fs", *override)
+			if renderLog != nil {
+				renderLog.Info("Using vocal onset override: %.2fs", *override)
+			}
+			return *override
+		}
+	}
+
+	if song.VocalTiming == "" {
+		return 0
+	}
+	var vocalSegments []audio.VocalSegment
+	if err := json.Unmarshal([]byte(song.VocalTiming), &vocalSegments); err != nil || len(vocalSegments) == 0 {
+		return 0
+	}
+	detected := vocalSegments[0].Start
+
+	fraction := p.config.MaxVocalOnsetFraction
+	if limit := fraction * song.DurationSeconds; limit > 0 && detected > limit {
+		log.Printf("Warning: ignoring detected vocal onset %.2fs for song %d; it's beyond %.0f%% of the song (%.2fs)",
+			detected, song.ID, fraction*100, limit)
+		if renderLog != nil {
+			renderLog.Warning("Detected vocal onset %.2fs is beyond %.0f%% of the song - ignoring it (set a vocal onset override to correct)",
+				detected, fraction*100)
+		}
+		return 0
+	}
+
+	log.Printf("Applying vocal onset offset: %.2fs", detected)
+	return detected
+}
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
@@ -9,6 +10,21 @@ import (
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/database"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/models"
 	"github.com/AndrewDonelson/track-studio-orchestrator/internal/services"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/metrics"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/storage"
+)
+
+const (
+	// heartbeatInterval is how often a processing item's heartbeat is persisted
+	heartbeatInterval = 30 * time.Second
+
+	// staleThreshold is how old a processing item's heartbeat must be before
+	// the item is considered abandoned; a few missed heartbeats, not one
+	staleThreshold = 3 * heartbeatInterval
+
+	// maxReclaims is how many times an abandoned item is re-queued before it
+	// is failed instead, so a job that crashes the server isn't retried forever
+	maxReclaims = 3
 )
 
 // Worker processes queue items
@@ -18,8 +34,13 @@ type Worker struct {
 	broadcaster  *services.ProgressBroadcaster
 	processor    *Processor
 	pollInterval time.Duration
+	wake         chan struct{}
 	ctx          context.Context
 	cancel       context.CancelFunc
+
+	// Used for image regeneration outside the queue
+	cfg   *config.Config
+	store storage.Storage
 }
 
 // NewWorker creates a new queue worker
@@ -29,8 +50,9 @@ func NewWorker(
 	broadcaster *services.ProgressBroadcaster,
 	pollInterval time.Duration,
 	cfg *config.Config,
+	store storage.Storage,
 ) *Worker {
-	processor := NewProcessor(songRepo, broadcaster, cfg)
+	processor := NewProcessor(songRepo, broadcaster, cfg, store)
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Worker{
@@ -39,8 +61,11 @@ func NewWorker(
 		broadcaster:  broadcaster,
 		processor:    processor,
 		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
 		ctx:          ctx,
 		cancel:       cancel,
+		cfg:          cfg,
+		store:        store,
 	}
 }
 
@@ -48,6 +73,9 @@ func NewWorker(
 func (w *Worker) Start() {
 	log.Println("Queue worker started")
 
+	// Recover items orphaned by a crash or restart
+	w.reclaimStale()
+
 	ticker := time.NewTicker(w.pollInterval)
 	defer ticker.Stop()
 
@@ -61,11 +89,44 @@ func (w *Worker) Start() {
 			log.Println("Queue worker stopped")
 			return
 		case <-ticker.C:
+			w.reclaimStale()
+			w.processNext()
+		case <-w.wake:
 			w.processNext()
 		}
 	}
 }
 
+// Notify wakes the worker to check for new work without waiting for the next
+// poll. It never blocks; a wake-up already pending covers this one.
+func (w *Worker) Notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// RegenerateAllImages wipes and regenerates every background image for a
+// song, blocking until done. It runs on its own processor beside the queue.
+func (w *Worker) RegenerateAllImages(song *models.Song) ([]models.GeneratedImage, error) {
+	processor := NewProcessor(w.songRepo, w.broadcaster, w.cfg, w.store)
+	return processor.RegenerateAllImages(song)
+}
+
+// RenderOverlayPreview renders a single overlay preview frame for a song.
+// It runs on its own processor beside the queue.
+func (w *Worker) RenderOverlayPreview(song *models.Song) (string, error) {
+	processor := NewProcessor(w.songRepo, w.broadcaster, w.cfg, w.store)
+	return processor.RenderOverlayPreview(song)
+}
+
+// RebuildKaraokeSubtitles regenerates a song's karaoke subtitles from cached
+// timestamps. It runs on its own processor beside the queue.
+func (w *Worker) RebuildKaraokeSubtitles(song *models.Song) (string, error) {
+	processor := NewProcessor(w.songRepo, w.broadcaster, w.cfg, w.store)
+	return processor.RebuildKaraokeSubtitles(song)
+}
+
 // Stop gracefully stops the worker
 func (w *Worker) Stop() {
 	log.Println("Stopping queue worker...")
@@ -74,6 +135,8 @@ func (w *Worker) Stop() {
 
 // processNext processes the next pending queue item
 func (w *Worker) processNext() {
+	w.updateQueueMetrics()
+
 	// Get next pending item
 	item, err := w.queueRepo.GetNextPending()
 	if err != nil {
@@ -86,7 +149,7 @@ func (w *Worker) processNext() {
 		return
 	}
 
-	log.Printf("Processing queue item %d (song %d)", item.ID, item.SongID)
+	log.Printf("Processing queue item %d (song %d, request %s)", item.ID, item.SongID, item.RequestID)
 
 	// Get song details
 	song, err := w.songRepo.GetByID(item.SongID)
@@ -102,7 +165,7 @@ func (w *Worker) processNext() {
 	}
 
 	// Mark as processing
-	now := time.Now()
+	now := time.Now().UTC()
 	item.Status = models.StatusProcessing
 	item.StartedAt = &now
 	item.Progress = 0
@@ -111,13 +174,31 @@ func (w *Worker) processNext() {
 		log.Printf("Error updating queue item: %v", err)
 		return
 	}
+	if err := w.queueRepo.Heartbeat(item.ID); err != nil {
+		log.Printf("Error recording heartbeat for queue item %d: %v", item.ID, err)
+	}
+
+	w.recordEvent(item, "Processing started")
 
 	// Broadcast start
 	w.broadcaster.BroadcastFromQueueItem(item, "Processing started")
 
+	// Keep a heartbeat while processing so a crash leaves detectable state
+	stopHeartbeat := w.startHeartbeat(item.ID)
+
 	// Process the item
-	if err := w.processor.Process(item, song); err != nil {
-		log.Printf("Error processing queue item %d: %v", item.ID, err)
+	err = w.processor.Process(item, song)
+	stopHeartbeat()
+	if errors.Is(err, ErrAwaitingApproval) {
+		w.pauseForApproval(item)
+		return
+	}
+	if errors.Is(err, ErrCancelled) {
+		log.Printf("Queue item %d cancelled while processing", item.ID)
+		return
+	}
+	if err != nil {
+		log.Printf("Error processing queue item %d (request %s): %v", item.ID, item.RequestID, err)
 		w.failQueueItem(item, err.Error())
 		return
 	}
@@ -133,11 +214,66 @@ func (w *Worker) processNext() {
 		return
 	}
 
+	metrics.JobsProcessed.WithLabelValues(models.StatusCompleted).Inc()
+	w.recordEvent(item, "Processing completed successfully")
+
 	// Broadcast completion
 	w.broadcaster.BroadcastFromQueueItem(item, "Processing completed successfully")
 	log.Printf("Queue item %d completed successfully", item.ID)
 }
 
+// reclaimStale recovers processing items whose heartbeat has gone stale. Items
+// abandoned moments before a restart are picked up on a later poll, once
+// their heartbeat passes staleThreshold.
+func (w *Worker) reclaimStale() {
+	requeued, failed, err := w.queueRepo.ReclaimStale(staleThreshold, maxReclaims)
+	if err != nil {
+		log.Printf("Error reclaiming stale queue items: %v", err)
+		return
+	}
+	if requeued > 0 {
+		log.Printf("Reclaimed %d stale queue item(s)", requeued)
+	}
+	if failed > 0 {
+		log.Printf("Failed %d stale queue item(s) after %d interruptions", failed, maxReclaims)
+	}
+}
+
+// startHeartbeat persists a heartbeat for the item until the returned stop function is called
+func (w *Worker) startHeartbeat(itemID int) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := w.queueRepo.Heartbeat(itemID); err != nil {
+					log.Printf("Error recording heartbeat for queue item %d: %v", itemID, err)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// pauseForApproval parks a queue item until its images are approved
+func (w *Worker) pauseForApproval(item *models.QueueItem) {
+	item.Status = models.StatusAwaitingApproval
+	item.CurrentStep = "Awaiting image approval"
+
+	if err := w.queueRepo.Update(item); err != nil {
+		log.Printf("Error updating queue item awaiting approval: %v", err)
+		return
+	}
+
+	w.recordEvent(item, "Waiting for image approval before rendering")
+	w.broadcaster.BroadcastFromQueueItem(item, "Waiting for image approval before rendering")
+	log.Printf("Queue item %d paused awaiting image approval", item.ID)
+}
+
 // failQueueItem marks a queue item as failed
 func (w *Worker) failQueueItem(item *models.QueueItem, errorMsg string) {
 	item.Status = models.StatusFailed
@@ -151,6 +287,36 @@ func (w *Worker) failQueueItem(item *models.QueueItem, errorMsg string) {
 		return
 	}
 
+	metrics.JobsProcessed.WithLabelValues(models.StatusFailed).Inc()
+	w.recordEvent(item, errorMsg)
+
 	w.broadcaster.BroadcastFromQueueItem(item, "Processing failed")
 	log.Printf("Queue item %d failed: %s", item.ID, errorMsg)
 }
+
+// recordEvent adds the item's new status to its history
+func (w *Worker) recordEvent(item *models.QueueItem, message string) {
+	if err := w.queueRepo.AddEvent(item, message); err != nil {
+		log.Printf("Error recording history for queue item %d: %v", item.ID, err)
+	}
+}
+
+// updateQueueMetrics refreshes the queue size gauges
+func (w *Worker) updateQueueMetrics() {
+	counts, err := w.queueRepo.CountByStatus()
+	if err != nil {
+		log.Printf("Error counting queue items for metrics: %v", err)
+		return
+	}
+
+	for _, status := range []string{
+		models.StatusQueued,
+		models.StatusProcessing,
+		models.StatusCompleted,
+		models.StatusFailed,
+		models.StatusRetrying,
+		models.StatusAwaitingApproval,
+	} {
+		metrics.QueueItems.WithLabelValues(status).Set(float64(counts[status]))
+	}
+}
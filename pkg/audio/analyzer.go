@@ -1,13 +1,30 @@
 package audio
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/pyenv"
+)
+
+// Defaults used when the analyzer isn't configured
+const (
+	defaultPython  = "python3"
+	defaultTimeout = 5 * time.Minute
 )
 
+// analyzerModules are the Python modules analyzer.py imports
+var analyzerModules = []string{"librosa", "numpy"}
+
 // AudioAnalysis contains the results of audio analysis
 type AudioAnalysis struct {
 	DurationSeconds   float64        `json:"duration_seconds"`
@@ -27,6 +44,33 @@ type AudioAnalysis struct {
 	ErrorType         string         `json:"error_type,omitempty"`
 }
 
+// BeatTimesJSON encodes an analysis's beat times for storage on a song,
+// returning "" when no beats were detected
+func BeatTimesJSON(analysis *AudioAnalysis) (string, error) {
+	if len(analysis.BeatTimes) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(analysis.BeatTimes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ParseBeatTimes decodes beat times stored by BeatTimesJSON, sorted. An empty
+// string has no beats.
+func ParseBeatTimes(beatTimesJSON string) ([]float64, error) {
+	if beatTimesJSON == "" {
+		return nil, nil
+	}
+	var beats []float64
+	if err := json.Unmarshal([]byte(beatTimesJSON), &beats); err != nil {
+		return nil, fmt.Errorf("invalid beat times: %w", err)
+	}
+	sort.Float64s(beats)
+	return beats, nil
+}
+
 // VocalSegment represents a detected vocal segment
 type VocalSegment struct {
 	Start    float64 `json:"start"`
@@ -34,40 +78,66 @@ type VocalSegment struct {
 	Duration float64 `json:"duration"`
 }
 
-// AnalyzeAudio analyzes an audio file using the Python librosa script
+// AudioAnalyzer analyzes audio files; implemented by Analyzer and by fakes in tests
+type AudioAnalyzer interface {
+	Analyze(audioPath string) (*AudioAnalysis, error)
+}
+
+// Analyzer runs the Python librosa analyzer script
+type Analyzer struct {
+	PythonPath string        // Interpreter, e.g. a venv's bin/python
+	ScriptPath string        // analyzer.py; empty discovers it beside the working directory or binary
+	Timeout    time.Duration // Per-file limit; zero means no limit
+}
+
+// NewAnalyzer creates an analyzer using the interpreter, script path and
+// timeout from config, falling back to python3 and script discovery when unset.
+// A nil config uses the defaults.
+func NewAnalyzer(cfg *config.Config) *Analyzer {
+	analyzer := &Analyzer{PythonPath: defaultPython, Timeout: defaultTimeout}
+	if cfg != nil {
+		if cfg.AnalyzerPython != "" {
+			analyzer.PythonPath = cfg.AnalyzerPython
+		}
+		if cfg.AnalyzerTimeout > 0 {
+			analyzer.Timeout = cfg.AnalyzerTimeout
+		}
+		analyzer.ScriptPath = cfg.AnalyzerScript
+	}
+	return analyzer
+}
+
+// AnalyzeAudio analyzes an audio file with the default analyzer (python3 and
+// script discovery). Prefer NewAnalyzer(cfg).Analyze so config is honored.
 func AnalyzeAudio(audioPath string) (*AudioAnalysis, error) {
-	// Get absolute path to analyzer script
-	// First try relative to working directory, then relative to binary
-	cwd, err := os.Getwd()
+	return NewAnalyzer(nil).Analyze(audioPath)
+}
+
+// Analyze analyzes an audio file using the Python librosa script
+func (a *Analyzer) Analyze(audioPath string) (*AudioAnalysis, error) {
+	scriptPath, err := a.scriptPath()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get working directory: %w", err)
+		return nil, err
 	}
 
-	// Try working directory first (for development)
-	scriptPath := filepath.Join(cwd, "pkg", "audio", "analyzer.py")
-	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-		// Fall back to binary location (for production)
-		execPath, err := os.Executable()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get executable path: %w", err)
-		}
-		execDir := filepath.Dir(execPath)
-		scriptPath = filepath.Join(execDir, "pkg", "audio", "analyzer.py")
+	// Fail fast with an actionable error rather than a subprocess traceback
+	if err := a.CheckEnvironment().Err(); err != nil {
+		return nil, err
+	}
 
-		// If still not found, try relative to binary's parent directory
-		if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-			// Try going up from bin/ directory
-			scriptPath = filepath.Join(filepath.Dir(execDir), "pkg", "audio", "analyzer.py")
-			if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
-				return nil, fmt.Errorf("analyzer script not found in any expected location (tried: %s/pkg/audio/analyzer.py, %s/pkg/audio/analyzer.py, %s)",
-					cwd, filepath.Dir(execPath), scriptPath)
-			}
-		}
+	ctx := context.Background()
+	if a.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Timeout)
+		defer cancel()
 	}
 
 	// Execute Python script
-	cmd := exec.Command("python3", scriptPath, audioPath)
+	cmd := exec.CommandContext(ctx, a.PythonPath, scriptPath, audioPath)
 	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("analyzer script timed out after %s", a.Timeout)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("analyzer script failed: %w, output: %s", err, string(output))
 	}
@@ -86,6 +156,69 @@ func AnalyzeAudio(audioPath string) (*AudioAnalysis, error) {
 	return &analysis, nil
 }
 
+// CheckEnvironment verifies the interpreter, the analyzer script, its Python
+// modules and ffmpeg (used by librosa to decode compressed audio)
+func (a *Analyzer) CheckEnvironment() *pyenv.Report {
+	report := pyenv.NewReport("audio analysis", a.PythonPath)
+	if report.CheckBinary(a.PythonPath, "install Python 3 or set AUDIO_ANALYZER_PYTHON to a venv's bin/python") {
+		report.CheckModules("pip install "+strings.Join(analyzerModules, " "), analyzerModules...)
+	}
+
+	if scriptPath, err := a.scriptPath(); err != nil {
+		report.Add("analyzer.py", err.Error(), "set AUDIO_ANALYZER_SCRIPT to the path of pkg/audio/analyzer.py", false)
+	} else {
+		report.Add("analyzer.py", scriptPath, "", true)
+	}
+
+	report.CheckBinary("ffmpeg", "install ffmpeg (e.g. apt install ffmpeg)")
+	return report
+}
+
+// scriptPath returns the configured analyzer script, or discovers it when unset
+func (a *Analyzer) scriptPath() (string, error) {
+	if a.ScriptPath == "" {
+		return findScript("analyzer.py")
+	}
+	if _, err := os.Stat(a.ScriptPath); err != nil {
+		return "", fmt.Errorf("configured analyzer script not found: %s", a.ScriptPath)
+	}
+	return a.ScriptPath, nil
+}
+
+// findScript locates one of this package's Python scripts, trying the
+// working directory first (development) and then paths relative to the binary
+// (production)
+func findScript(name string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	// Try working directory first (for development)
+	scriptPath := filepath.Join(cwd, "pkg", "audio", name)
+	if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+		// Fall back to binary location (for production)
+		execPath, err := os.Executable()
+		if err != nil {
+			return "", fmt.Errorf("failed to get executable path: %w", err)
+		}
+		execDir := filepath.Dir(execPath)
+		scriptPath = filepath.Join(execDir, "pkg", "audio", name)
+
+		// If still not found, try relative to binary's parent directory
+		if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+			// Try going up from bin/ directory
+			scriptPath = filepath.Join(filepath.Dir(execDir), "pkg", "audio", name)
+			if _, err := os.Stat(scriptPath); os.IsNotExist(err) {
+				return "", fmt.Errorf("%s not found in any expected location (tried: %s/pkg/audio/%s, %s/pkg/audio/%s, %s)",
+					name, cwd, name, filepath.Dir(execPath), name, scriptPath)
+			}
+		}
+	}
+
+	return scriptPath, nil
+}
+
 // GetVocalTimingInfo returns formatted vocal timing information
 func (a *AudioAnalysis) GetVocalTimingInfo() string {
 	if len(a.VocalSegments) == 0 {
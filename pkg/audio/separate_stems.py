@@ -0,0 +1,113 @@
+#!/usr/bin/env python3
+"""
+Stem Separation Service for Track Studio
+Splits a mixed track into vocal and music stems using Demucs, falling back
+to Spleeter when Demucs isn't installed
+"""
+import sys
+import json
+import os
+import shutil
+import subprocess
+import tempfile
+import importlib.util
+import warnings
+
+# Suppress warnings
+warnings.filterwarnings('ignore')
+
+
+def separate_with_demucs(file_path: str, work_dir: str, model: str):
+    """Run Demucs in two-stem mode, returning (vocals, accompaniment) paths"""
+    # Demucs logs progress to stdout; keep stdout clean for the JSON result
+    subprocess.run(
+        [sys.executable, '-m', 'demucs.separate',
+         '--two-stems', 'vocals',
+         '-n', model,
+         '-o', work_dir,
+         file_path],
+        check=True,
+        stdout=sys.stderr,
+    )
+
+    track = os.path.splitext(os.path.basename(file_path))[0]
+    stem_dir = os.path.join(work_dir, model, track)
+    return os.path.join(stem_dir, 'vocals.wav'), os.path.join(stem_dir, 'no_vocals.wav')
+
+
+def separate_with_spleeter(file_path: str, work_dir: str):
+    """Run Spleeter's 2-stem model, returning (vocals, accompaniment) paths"""
+    from spleeter.separator import Separator
+
+    separator = Separator('spleeter:2stems')
+    separator.separate_to_file(file_path, work_dir)
+
+    track = os.path.splitext(os.path.basename(file_path))[0]
+    stem_dir = os.path.join(work_dir, track)
+    return os.path.join(stem_dir, 'vocals.wav'), os.path.join(stem_dir, 'accompaniment.wav')
+
+
+def separate_stems(file_path: str, output_dir: str, model: str) -> dict:
+    """Separate a mixed track into vocal.wav and music.wav in output_dir"""
+    try:
+        if not os.path.exists(file_path):
+            raise FileNotFoundError(f'Audio file not found: {file_path}')
+
+        os.makedirs(output_dir, exist_ok=True)
+        with tempfile.TemporaryDirectory(prefix='stems_') as work_dir:
+            if importlib.util.find_spec('demucs') is not None:
+                method = f'demucs ({model})'
+                vocals, music = separate_with_demucs(file_path, work_dir, model)
+            elif importlib.util.find_spec('spleeter') is not None:
+                method = 'spleeter (2stems)'
+                vocals, music = separate_with_spleeter(file_path, work_dir)
+            else:
+                raise ImportError('Neither demucs nor spleeter is installed')
+
+            for stem in (vocals, music):
+                if not os.path.exists(stem):
+                    raise FileNotFoundError(f'Separator did not produce {stem}')
+
+            vocal_path = os.path.join(output_dir, 'vocal.wav')
+            music_path = os.path.join(output_dir, 'music.wav')
+            shutil.move(vocals, vocal_path)
+            shutil.move(music, music_path)
+
+        return {
+            'vocals': vocal_path,
+            'music': music_path,
+            'method': method,
+            'success': True
+        }
+
+    except Exception as e:
+        return {
+            'success': False,
+            'error': str(e),
+            'error_type': type(e).__name__
+        }
+
+
+def main():
+    """Command-line interface"""
+    if len(sys.argv) not in (3, 4):
+        print(json.dumps({
+            'success': False,
+            'error': 'Usage: python separate_stems.py <audio_file_path> <output_dir> [demucs_model]'
+        }))
+        sys.exit(1)
+
+    file_path = sys.argv[1]
+    output_dir = sys.argv[2]
+    model = sys.argv[3] if len(sys.argv) == 4 else 'htdemucs'
+    result = separate_stems(file_path, output_dir, model)
+
+    # Output JSON
+    print(json.dumps(result, indent=2))
+
+    # Exit with appropriate code
+    sys.exit(0 if result.get('success', False) else 1)
+
+
+if __name__ == '__main__':
+    main()
@@ -0,0 +1,149 @@
+package audio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/pyenv"
+)
+
+// Defaults used when the stem separator isn't configured
+const (
+	DefaultSeparatorModel   = "htdemucs"
+	defaultSeparatorTimeout = 30 * time.Minute
+)
+
+// separatorModules are the interchangeable Python packages separate_stems.py
+// can use, preferred first
+var separatorModules = []string{"demucs", "spleeter"}
+
+// StemSeparation is the result of splitting a mixed track
+type StemSeparation struct {
+	VocalsPath string `json:"vocals"`
+	MusicPath  string `json:"music"`
+	Method     string `json:"method"` // e.g. "demucs (htdemucs)"
+	Success    bool   `json:"success"`
+	Error      string `json:"error,omitempty"`
+	ErrorType  string `json:"error_type,omitempty"`
+}
+
+// Separator runs the Python stem separation script
+type Separator struct {
+	PythonPath string        // Interpreter with demucs or spleeter installed
+	ScriptPath string        // separate_stems.py; empty discovers it like analyzer.py
+	Model      string        // Demucs model name
+	Timeout    time.Duration // Per-track limit; zero means no limit
+}
+
+// NewSeparator creates a separator using the interpreter, script, model and
+// timeout from config. The interpreter falls back to the analyzer's, then
+// python3.
+func NewSeparator(cfg *config.Config) *Separator {
+	separator := &Separator{PythonPath: defaultPython, Model: DefaultSeparatorModel, Timeout: defaultSeparatorTimeout}
+	if cfg != nil {
+		if cfg.AnalyzerPython != "" {
+			separator.PythonPath = cfg.AnalyzerPython
+		}
+		if cfg.SeparatorPython != "" {
+			separator.PythonPath = cfg.SeparatorPython
+		}
+		if cfg.SeparatorModel != "" {
+			separator.Model = cfg.SeparatorModel
+		}
+		if cfg.SeparatorTimeout > 0 {
+			separator.Timeout = cfg.SeparatorTimeout
+		}
+		separator.ScriptPath = cfg.SeparatorScript
+	}
+	return separator
+}
+
+// Separate splits a mixed track into vocal.wav and music.wav in outputDir,
+// overwriting any stems already there. This takes minutes per song.
+func (s *Separator) Separate(inputPath, outputDir string) (*StemSeparation, error) {
+	scriptPath, err := s.scriptPath()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.CheckEnvironment().Err(); err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	// The script prints its JSON result to stdout and separator logs to stderr
+	cmd := exec.CommandContext(ctx, s.PythonPath, scriptPath, inputPath, outputDir, s.Model)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("stem separation timed out after %s", s.Timeout)
+	}
+
+	var separation StemSeparation
+	if jsonErr := json.Unmarshal(output, &separation); jsonErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("stem separation script failed: %w, output: %s", err, tail(stderr.String(), 2000))
+		}
+		return nil, fmt.Errorf("failed to parse stem separation output: %w, raw output: %s", jsonErr, string(output))
+	}
+	if !separation.Success {
+		return nil, fmt.Errorf("stem separation failed: %s (%s)", separation.Error, separation.ErrorType)
+	}
+	return &separation, nil
+}
+
+// Available reports whether separation can run, without the report details
+func (s *Separator) Available() bool {
+	return s.CheckEnvironment().Ready
+}
+
+// CheckEnvironment verifies the interpreter, the separation script and that
+// demucs or spleeter is installed
+func (s *Separator) CheckEnvironment() *pyenv.Report {
+	report := pyenv.NewReport("stem separation", s.PythonPath)
+	if report.CheckBinary(s.PythonPath, "install Python 3 or set STEM_SEPARATOR_PYTHON to a venv's bin/python") {
+		report.CheckAnyModule("pip install demucs (or spleeter)", separatorModules...)
+	}
+
+	if scriptPath, err := s.scriptPath(); err != nil {
+		report.Add("separate_stems.py", err.Error(), "set STEM_SEPARATOR_SCRIPT to the path of pkg/audio/separate_stems.py", false)
+	} else {
+		report.Add("separate_stems.py", scriptPath, "", true)
+	}
+
+	report.CheckBinary("ffmpeg", "install ffmpeg (e.g. apt install ffmpeg)")
+	return report
+}
+
+// scriptPath returns the configured separation script, or discovers it when unset
+func (s *Separator) scriptPath() (string, error) {
+	if s.ScriptPath == "" {
+		return findScript("separate_stems.py")
+	}
+	if _, err := os.Stat(s.ScriptPath); err != nil {
+		return "", fmt.Errorf("configured stem separation script not found: %s", s.ScriptPath)
+	}
+	return s.ScriptPath, nil
+}
+
+// tail returns the last n bytes of s, where tracebacks end
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "..." + s[len(s)-n:]
+}
@@ -0,0 +1,110 @@
+package cqai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultConcurrencyLimit is the number of CQAI/Ollama requests allowed in flight at once
+const DefaultConcurrencyLimit = 2
+
+var (
+	mu    sync.Mutex
+	slots = make(chan struct{}, DefaultConcurrencyLimit)
+
+	// transport is shared so connections to CQAI are reused across callers
+	transport = http.DefaultTransport
+)
+
+// SetConcurrencyLimit changes how many CQAI requests may be in flight at once.
+// Requests already holding a slot finish against the previous limit.
+func SetConcurrencyLimit(limit int) {
+	if limit < 1 {
+		limit = DefaultConcurrencyLimit
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if cap(slots) != limit {
+		slots = make(chan struct{}, limit)
+	}
+}
+
+// Acquire waits for a free request slot and returns a function that releases it.
+// Callers queue rather than fail when the limit is reached.
+func Acquire(ctx context.Context) (func(), error) {
+	mu.Lock()
+	ch := slots
+	mu.Unlock()
+
+	select {
+	case ch <- struct{}{}:
+		var once sync.Once
+		return func() { once.Do(func() { <-ch }) }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Do sends req once a request slot is free. The timeout covers only the request
+// itself, not time spent queued, so adaptive timeouts stay meaningful.
+// The slot is released when the response body is closed.
+func Do(req *http.Request, timeout time.Duration) (*http.Response, error) {
+	resp, _, err := DoTimed(req, timeout)
+	return resp, err
+}
+
+// DoTimed is like Do but also reports how long the request waited for a slot
+func DoTimed(req *http.Request, timeout time.Duration) (*http.Response, time.Duration, error) {
+	queuedAt := time.Now()
+	release, err := Acquire(req.Context())
+	if err != nil {
+		return nil, time.Since(queuedAt), err
+	}
+	queued := time.Since(queuedAt)
+
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	client := &http.Client{Transport: transport}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		release()
+		return nil, queued, err
+	}
+
+	resp.Body = &gatedBody{ReadCloser: resp.Body, done: func() {
+		cancel()
+		release()
+	}}
+	return resp, queued, nil
+}
+
+// Post is a gated equivalent of http.Post with a per-request timeout
+func Post(url, contentType string, body io.Reader, timeout time.Duration) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return Do(req, timeout)
+}
+
+// gatedBody releases the request slot once the caller is done with the response
+type gatedBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *gatedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
@@ -0,0 +1,98 @@
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryOptions controls how DoWithRetry retries a request
+type RetryOptions struct {
+	Attempts  int           // Total attempts including the first
+	BaseDelay time.Duration // Delay before the first retry, doubled each attempt
+	MaxDelay  time.Duration // Upper bound on a single delay
+}
+
+// DefaultRetryOptions returns 3 attempts with exponential backoff starting at 1s
+func DefaultRetryOptions() *RetryOptions {
+	return &RetryOptions{
+		Attempts:  3,
+		BaseDelay: 1 * time.Second,
+		MaxDelay:  10 * time.Second,
+	}
+}
+
+// RequestBuilder creates a fresh request for each attempt so bodies can be resent
+type RequestBuilder func(ctx context.Context) (*http.Request, error)
+
+// Doer sends a request, e.g. http.Client.Do or a rate-limited client
+type Doer func(req *http.Request) (*http.Response, error)
+
+// DoWithRetry sends a request, retrying network errors and 5xx responses with
+// exponential backoff and jitter. 4xx responses are returned immediately.
+// No retry is attempted if waiting would pass ctx's deadline. After the last
+// attempt the final response (or error) is returned for the caller to handle.
+func DoWithRetry(ctx context.Context, name string, build RequestBuilder, do Doer, opts *RetryOptions) (*http.Response, error) {
+	if opts == nil {
+		opts = DefaultRetryOptions()
+	}
+	attempts := opts.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := build(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := do(req)
+		if err == nil && resp.StatusCode < 500 {
+			return resp, nil
+		}
+		if attempt >= attempts || ctx.Err() != nil {
+			return resp, err
+		}
+
+		delay := backoff(opts, attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = fmt.Sprintf("status %d", resp.StatusCode)
+			// Drain so the connection can be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		log.Printf("%s: attempt %d/%d failed (%s), retrying in %v", name, attempt, attempts, reason, delay.Round(time.Millisecond))
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// backoff returns the delay before the given retry: base * 2^(attempt-1),
+// capped at MaxDelay, with ±25% random jitter
+func backoff(opts *RetryOptions, attempt int) time.Duration {
+	delay := opts.BaseDelay << (attempt - 1)
+	if opts.MaxDelay > 0 && (delay > opts.MaxDelay || delay <= 0) {
+		delay = opts.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return delay*3/4 + jitter
+}
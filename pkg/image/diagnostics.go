@@ -0,0 +1,134 @@
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ZIMAGE_HEALTH_PATH is the z-image API's health endpoint, relative to BaseURL
+const ZIMAGE_HEALTH_PATH = "/api/health"
+
+// ServiceStatus is the result of probing one CQAI service
+type ServiceStatus struct {
+	Name      string        `json:"name"`
+	URL       string        `json:"url"`
+	Available bool          `json:"available"`
+	LatencyMS int64         `json:"latency_ms"`
+	Models    []ModelStatus `json:"models,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// ModelStatus reports whether a configured model is loaded on the server.
+// Only required models affect the service's availability.
+type ModelStatus struct {
+	Name     string `json:"name"`
+	Present  bool   `json:"present"`
+	Required bool   `json:"required"`
+}
+
+// ollamaTags is the response of Ollama's /api/tags
+type ollamaTags struct {
+	Models []struct {
+		Name  string `json:"name"`
+		Model string `json:"model"`
+	} `json:"models"`
+}
+
+// CheckImageService pings the z-image health endpoint. These probes bypass
+// the CQAI concurrency limiter so they answer promptly while renders run.
+func (ig *ImageGenerator) CheckImageService(ctx context.Context, client *http.Client) ServiceStatus {
+	status := ServiceStatus{
+		Name: "zimage",
+		URL:  strings.TrimRight(ig.BaseURL, "/") + ZIMAGE_HEALTH_PATH,
+	}
+
+	start := time.Now()
+	resp, err := probeGet(ctx, client, status.URL)
+	status.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		status.Error = fmt.Sprintf("returned status %d", resp.StatusCode)
+		return status
+	}
+	status.Available = true
+	return status
+}
+
+// CheckLLMService queries Ollama's model list and verifies the LLM model is
+// loaded. The vision model is reported too but is only needed for prompt
+// extraction, so a missing one doesn't make the service unavailable.
+func (ig *ImageGenerator) CheckLLMService(ctx context.Context, client *http.Client) ServiceStatus {
+	status := ServiceStatus{
+		Name: "ollama",
+		URL:  strings.TrimRight(ig.LLMURL, "/") + "/api/tags",
+	}
+
+	start := time.Now()
+	resp, err := probeGet(ctx, client, status.URL)
+	status.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		status.Error = fmt.Sprintf("returned status %d", resp.StatusCode)
+		return status
+	}
+
+	var tags ollamaTags
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		status.Error = fmt.Sprintf("failed to parse model list: %v", err)
+		return status
+	}
+
+	loaded := make(map[string]bool)
+	for _, m := range tags.Models {
+		loaded[m.Name] = true
+		loaded[m.Model] = true
+	}
+	status.Models = []ModelStatus{
+		{Name: ig.LLMModel, Present: hasModel(loaded, ig.LLMModel), Required: true},
+		{Name: ig.VisionModel, Present: hasModel(loaded, ig.VisionModel)},
+	}
+
+	status.Available = true
+	var missing []string
+	for _, m := range status.Models {
+		if m.Required && !m.Present {
+			status.Available = false
+			missing = append(missing, m.Name)
+		}
+	}
+	if len(missing) > 0 {
+		status.Error = fmt.Sprintf("model not loaded: %s (run: ollama pull %s)", strings.Join(missing, ", "), missing[0])
+	}
+	return status
+}
+
+// hasModel matches a configured model name against Ollama's list, where an
+// untagged name means ":latest"
+func hasModel(loaded map[string]bool, name string) bool {
+	if loaded[name] {
+		return true
+	}
+	return !strings.Contains(name, ":") && loaded[name+":latest"]
+}
+
+func probeGet(ctx context.Context, client *http.Client, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	return client.Do(req)
+}
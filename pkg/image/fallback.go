@@ -0,0 +1,135 @@
+package image
+
+import (
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fallbackPalette is a gradient's top and bottom colors
+type fallbackPalette struct {
+	top, bottom color.RGBA
+}
+
+// fallbackPalettes maps mood and genre keywords to gradients, checked in
+// order so the first keyword found in the mood wins
+var fallbackPalettes = []struct {
+	keywords []string
+	palette  fallbackPalette
+}{
+	{[]string{"dark", "sad", "melanchol", "somber", "lonely", "grief"}, fallbackPalette{rgb(28, 36, 74), rgb(6, 8, 20)}},
+	{[]string{"angry", "aggressive", "intense", "rock", "metal", "punk"}, fallbackPalette{rgb(140, 22, 22), rgb(20, 4, 6)}},
+	{[]string{"romantic", "love", "sensual", "r&b", "soul"}, fallbackPalette{rgb(150, 40, 100), rgb(40, 10, 50)}},
+	{[]string{"happy", "upbeat", "joy", "bright", "uplifting", "pop"}, fallbackPalette{rgb(250, 150, 60), rgb(200, 60, 110)}},
+	{[]string{"energetic", "euphoric", "electronic", "edm", "dance", "techno"}, fallbackPalette{rgb(90, 30, 170), rgb(10, 150, 190)}},
+	{[]string{"calm", "chill", "peaceful", "dream", "relax", "ambient", "lo-fi"}, fallbackPalette{rgb(30, 120, 130), rgb(12, 30, 60)}},
+	{[]string{"nostalgic", "warm", "country", "folk", "acoustic"}, fallbackPalette{rgb(180, 120, 70), rgb(60, 30, 20)}},
+	{[]string{"mysterious", "jazz", "blues", "smooth"}, fallbackPalette{rgb(20, 70, 110), rgb(8, 12, 30)}},
+	{[]string{"epic", "cinematic", "orchestral", "hopeful"}, fallbackPalette{rgb(200, 160, 70), rgb(30, 40, 70)}},
+}
+
+// GenerateFallbackBackground writes a w x h PNG gradient whose colors come
+// from the mood (mood and genre words, e.g. "melancholic dreamy pop"). It
+// stands in for a section image the image service failed to produce, so a
+// render still has a background. Unrecognized moods get a stable color
+// derived from the text.
+func GenerateFallbackBackground(mood string, w, h int, outPath string) error {
+	if w <= 0 || h <= 0 {
+		return fmt.Errorf("invalid fallback background size %dx%d", w, h)
+	}
+
+	palette := paletteForMood(mood)
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	// Diagonal gradient from the top-left, darkened toward the edges
+	cx, cy := float64(w)/2, float64(h)/2
+	maxDist := math.Hypot(cx, cy)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			t := (float64(x)/float64(w) + float64(y)/float64(h)) / 2
+			vignette := 1 - 0.45*math.Pow(math.Hypot(float64(x)-cx, float64(y)-cy)/maxDist, 2)
+			img.SetRGBA(x, y, color.RGBA{
+				R: blend(palette.top.R, palette.bottom.R, t, vignette),
+				G: blend(palette.top.G, palette.bottom.G, t, vignette),
+				B: blend(palette.top.B, palette.bottom.B, t, vignette),
+				A: 255,
+			})
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outPath), 0755); err != nil {
+		return fmt.Errorf("failed to create fallback background directory: %w", err)
+	}
+	file, err := os.Create(outPath)
+	if err != nil {
+		return fmt.Errorf("failed to create fallback background: %w", err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to encode fallback background: %w", err)
+	}
+	return file.Close()
+}
+
+// paletteForMood picks the first palette whose keyword appears in the mood,
+// or derives one from a hash of the mood text
+func paletteForMood(mood string) fallbackPalette {
+	mood = strings.ToLower(mood)
+	for _, entry := range fallbackPalettes {
+		for _, keyword := range entry.keywords {
+			if strings.Contains(mood, keyword) {
+				return entry.palette
+			}
+		}
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(mood))
+	hue := float64(hash.Sum32()%360) / 360
+	return fallbackPalette{
+		top:    hsvToRGB(hue, 0.65, 0.6),
+		bottom: hsvToRGB(math.Mod(hue+0.08, 1), 0.8, 0.15),
+	}
+}
+
+// blend interpolates between two channel values and applies the vignette
+func blend(from, to uint8, t, vignette float64) uint8 {
+	v := (float64(from) + (float64(to)-float64(from))*t) * vignette
+	return uint8(math.Max(0, math.Min(255, math.Round(v))))
+}
+
+func rgb(r, g, b uint8) color.RGBA {
+	return color.RGBA{R: r, G: g, B: b, A: 255}
+}
+
+// hsvToRGB converts hue, saturation and value (all 0-1) to a color
+func hsvToRGB(h, s, v float64) color.RGBA {
+	i := math.Floor(h * 6)
+	f := h*6 - i
+	p := v * (1 - s)
+	q := v * (1 - f*s)
+	t := v * (1 - (1-f)*s)
+
+	var r, g, b float64
+	switch int(i) % 6 {
+	case 0:
+		r, g, b = v, t, p
+	case 1:
+		r, g, b = q, v, p
+	case 2:
+		r, g, b = p, v, t
+	case 3:
+		r, g, b = p, q, v
+	case 4:
+		r, g, b = t, p, v
+	default:
+		r, g, b = v, p, q
+	}
+	return rgb(uint8(r*255), uint8(g*255), uint8(b*255))
+}
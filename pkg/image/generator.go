@@ -2,16 +2,23 @@ package image
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/cqai"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/httputil"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/metrics"
 )
 
 const (
@@ -19,6 +26,7 @@ const (
 	CQAI_LLM_URL   = "http://cqai.nlaakstudios:11434" // Ollama API for LLM
 	IMAGE_MODEL    = "z-image-nsfw"
 	LLM_MODEL      = "qwen2.5:7b"
+	VISION_MODEL   = "llama3.2-vision:11b"
 	DEFAULT_WIDTH  = 1920
 	DEFAULT_HEIGHT = 1024
 	DEFAULT_STEPS  = 25
@@ -39,24 +47,50 @@ CRITICAL RULES:
 
 AVOID mentioning: signs, labels, text, writing, billboards, readable content
 
+Also list 5-10 things to AVOID in this specific scene given its mood, as a short
+comma-separated negative list (e.g. "crowds, bright daylight" for an intimate night verse).
+Do not repeat generic quality terms like blurry or low quality.
+
+OUTPUT FORMAT (exactly two lines):
+PROMPT: <image prompt>
+NEGATIVE: <comma-separated things to avoid>
+
 EXAMPLE OUTPUT:
-"Intimate couple embracing on weathered wooden dock at golden hour, dramatic sunset rays streaming through scattered clouds creating warm rim lighting on subjects, romantic and serene atmosphere, rich color palette with deep oranges, soft pinks, purple sky gradients and silver water reflections, shot with 85mm lens at f/2.8 creating shallow depth of field, rule of thirds composition emphasizing connection between subjects, photorealistic, professional photography, cinematic composition, 8K, ultra detailed, sharp focus"
+PROMPT: Intimate couple embracing on weathered wooden dock at golden hour, dramatic sunset rays streaming through scattered clouds creating warm rim lighting on subjects, romantic and serene atmosphere, rich color palette with deep oranges, soft pinks, purple sky gradients and silver water reflections, shot with 85mm lens at f/2.8 creating shallow depth of field, rule of thirds composition emphasizing connection between subjects, photorealistic, professional photography, cinematic composition, 8K, ultra detailed, sharp focus
+NEGATIVE: crowds, strangers, harsh midday sun, cluttered background, urban skyline, neon lights
 
-Output ONLY the image prompt with NO preamble or explanation.`
+Output ONLY these two lines with NO preamble or explanation.`
 )
 
 type ImageGenerator struct {
 	BaseURL        string
 	LLMURL         string
-	MasterPrompt   string // From settings
+	MasterPrompt   string // From settings (or a song override); steers the style of every LLM prompt
 	MasterNegative string // From settings
-	ImageModel     string
-	LLMModel       string
-	OutputDir      string
-	Width          int
-	Height         int
-	Steps          int
-	Timeout        time.Duration
+	PromptTemplate string // LLM user prompt template from settings; empty uses DefaultPromptTemplate
+
+	// SectionTemplates overrides PromptTemplate per section type (e.g. "chorus")
+	SectionTemplates map[string]string
+
+	// Song context substituted into {genre} and {mood}
+	Genre       string
+	Mood        string
+	ImageModel  string
+	LLMModel    string
+	VisionModel string
+	OutputDir   string
+	Width       int
+	Height      int
+	Steps       int
+	Timeout     time.Duration
+
+	// ReferenceImage, when set, is the image every generation starts from
+	// (img2img) at ReferenceStrength, so all of a song's backgrounds share its look
+	ReferenceImage    string
+	ReferenceStrength float64
+
+	// LastSeed is the seed used by the most recent image generation
+	LastSeed int64
 
 	// Timing statistics for adaptive timeouts and ETAs
 	LLMTimings       []time.Duration
@@ -86,6 +120,11 @@ type ZImageRequest struct {
 	Width          int    `json:"width"`
 	Height         int    `json:"height"`
 	Steps          int    `json:"steps"`
+	Seed           int64  `json:"seed"`
+
+	// img2img: a base64 image to start from, and how far to move away from it (0-1)
+	InitImage string  `json:"init_image,omitempty"`
+	Strength  float64 `json:"strength,omitempty"`
 }
 
 type ZImageResponse struct {
@@ -94,15 +133,19 @@ type ZImageResponse struct {
 	Height         int     `json:"height"`
 	Steps          int     `json:"steps"`
 	GenerationTime float64 `json:"generation_time"` // seconds
+	Seed           *int64  `json:"seed,omitempty"`  // Seed the API actually used, if reported
 	Error          string  `json:"error,omitempty"`
 }
 
-func NewImageGenerator(outputDir string) *ImageGenerator {
-	return &ImageGenerator{
+// NewImageGenerator creates a new image generator using endpoints and models from config.
+// A nil config falls back to the built-in defaults.
+func NewImageGenerator(outputDir string, cfg *config.Config) *ImageGenerator {
+	ig := &ImageGenerator{
 		BaseURL:          CQAI_BASE_URL,
 		LLMURL:           CQAI_LLM_URL,
 		ImageModel:       IMAGE_MODEL,
 		LLMModel:         LLM_MODEL,
+		VisionModel:      VISION_MODEL,
 		OutputDir:        outputDir,
 		Width:            DEFAULT_WIDTH,
 		Height:           DEFAULT_HEIGHT,
@@ -112,11 +155,49 @@ func NewImageGenerator(outputDir string) *ImageGenerator {
 		ImageTimings:     make([]time.Duration, 0),
 		MaxTimingSamples: 10, // Keep last 10 samples for rolling average
 	}
+
+	if cfg != nil {
+		if cfg.CQAIURL != "" {
+			ig.BaseURL = cfg.CQAIURL
+		}
+		if cfg.CQAILLMURL != "" {
+			ig.LLMURL = cfg.CQAILLMURL
+		}
+		if cfg.ImageModel != "" {
+			ig.ImageModel = cfg.ImageModel
+		}
+		if cfg.LLMModel != "" {
+			ig.LLMModel = cfg.LLMModel
+		}
+		if cfg.VisionModel != "" {
+			ig.VisionModel = cfg.VisionModel
+		}
+		if cfg.ImageWidth > 0 {
+			ig.Width = cfg.ImageWidth
+		}
+		if cfg.ImageHeight > 0 {
+			ig.Height = cfg.ImageHeight
+		}
+		if cfg.ImageSteps > 0 {
+			ig.Steps = cfg.ImageSteps
+		}
+	}
+
+	return ig
 }
 
 func (ig *ImageGenerator) EnhancePromptWithLLM(sectionType, lyricsContent, styleKeywords string) (string, error) {
+	prompt, _, err := ig.EnhancePromptWithNegative(sectionType, lyricsContent, styleKeywords)
+	return prompt, err
+}
+
+// EnhancePromptWithNegative is EnhancePromptWithLLM that also returns the
+// scene-specific negative prompt suggested by the LLM, which may be empty.
+// The master negative is not included; GenerateImageWithNegative adds it.
+func (ig *ImageGenerator) EnhancePromptWithNegative(sectionType, lyricsContent, styleKeywords string) (_ string, _ string, err error) {
 	startTime := time.Now()
 	defer func() {
+		metrics.ObserveRequest("llm", err)
 		duration := time.Since(startTime)
 		ig.LLMTimings = append(ig.LLMTimings, duration)
 		if len(ig.LLMTimings) > ig.MaxTimingSamples {
@@ -124,72 +205,228 @@ func (ig *ImageGenerator) EnhancePromptWithLLM(sectionType, lyricsContent, style
 		}
 	}()
 
-	// Limit lyrics to prevent token overflow (approx 500 chars)
-	if len(lyricsContent) > 500 {
-		lyricsContent = lyricsContent[:500] + "..."
-	}
-
-	// Create cinematic image prompt using MasterImagePrompt template
-	userPrompt := fmt.Sprintf(`Song Section: %s
-Additional Style: %s
-
-Lyrics:
-%s
-
-Generate a cinematic, photorealistic image prompt that captures the visual essence of these lyrics. Remember: NO text or letters in the image.`,
-		sectionType,
-		styleKeywords,
-		lyricsContent)
-
 	req := LLMRequest{
 		Model:  ig.LLMModel,
-		Prompt: IMAGE_PROMPT_SYSTEM + "\n\n" + userPrompt,
+		Prompt: LLMPrompt(ig.BuildUserPrompt(sectionType, lyricsContent, styleKeywords)),
 		Stream: false,
 	}
 
 	reqBody, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal LLM request: %w", err)
+		return "", "", fmt.Errorf("failed to marshal LLM request: %w", err)
 	}
 
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Post(
-		ig.LLMURL+"/api/generate",
-		"application/json",
-		bytes.NewBuffer(reqBody),
+	resp, err := httputil.DoWithRetry(context.Background(), "LLM prompt enhancement",
+		jsonPost(ig.LLMURL+"/api/generate", reqBody),
+		func(r *http.Request) (*http.Response, error) { return cqai.Do(r, 60*time.Second) },
+		nil,
 	)
 	if err != nil {
-		return "", fmt.Errorf("LLM request failed: %w", err)
+		return "", "", fmt.Errorf("LLM request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("LLM API error %d: %s", resp.StatusCode, string(body))
+		return "", "", fmt.Errorf("LLM API error %d: %s", resp.StatusCode, string(body))
 	}
 
 	var llmResp LLMResponse
 	if err := json.NewDecoder(resp.Body).Decode(&llmResp); err != nil {
-		return "", fmt.Errorf("failed to decode LLM response: %w", err)
+		return "", "", fmt.Errorf("failed to decode LLM response: %w", err)
+	}
+
+	enhancedPrompt, negative := parsePromptResponse(llmResp.Response)
+	return enhancedPrompt, negative, nil
+}
+
+// BuildUserPrompt assembles the user half of the LLM prompt that
+// EnhancePromptWithNegative sends after IMAGE_PROMPT_SYSTEM: the section's
+// template filled with the genre, mood, lyrics and style keywords, led by the
+// master prompt
+func (ig *ImageGenerator) BuildUserPrompt(sectionType, lyricsContent, styleKeywords string) string {
+	// The master prompt sets the visual language shared by every image
+	if ig.MasterPrompt != "" {
+		styleKeywords = strings.TrimSuffix(ig.MasterPrompt+", "+styleKeywords, ", ")
+	}
+
+	// Limit lyrics to prevent token overflow (approx 500 chars)
+	if len(lyricsContent) > 500 {
+		lyricsContent = lyricsContent[:500] + "..."
+	}
+
+	// Create cinematic image prompt from the configured template
+	return RenderPromptTemplate(ig.promptTemplateFor(sectionType), PromptVars{
+		Section: sectionType,
+		Genre:   ig.Genre,
+		Mood:    ig.Mood,
+		Style:   styleKeywords,
+		Lyrics:  lyricsContent,
+	})
+}
+
+// LLMPrompt is the full prompt sent to the LLM: IMAGE_PROMPT_SYSTEM followed
+// by the user prompt
+func LLMPrompt(userPrompt string) string {
+	return IMAGE_PROMPT_SYSTEM + "\n\n" + userPrompt
+}
+
+// MasterNegativePrompt returns the negative prompt every image starts from:
+// the configured master negative, or MASTER_NEGATIVE_PROMPT when unset
+func (ig *ImageGenerator) MasterNegativePrompt() string {
+	if ig.MasterNegative != "" {
+		return ig.MasterNegative
+	}
+	return MASTER_NEGATIVE_PROMPT
+}
+
+// parsePromptResponse splits an LLM response into the image prompt and the
+// scene-specific negative list. Responses without the PROMPT:/NEGATIVE: markers
+// are treated as a bare prompt.
+func parsePromptResponse(response string) (string, string) {
+	var promptLines []string
+	negative := ""
+	for _, line := range strings.Split(response, "\n") {
+		trimmed := strings.TrimSpace(line)
+		upper := strings.ToUpper(trimmed)
+		switch {
+		case strings.HasPrefix(upper, "NEGATIVE:"):
+			negative = strings.TrimSpace(trimmed[len("NEGATIVE:"):])
+		case strings.HasPrefix(upper, "PROMPT:"):
+			promptLines = append(promptLines, strings.TrimSpace(trimmed[len("PROMPT:"):]))
+		case trimmed != "":
+			promptLines = append(promptLines, trimmed)
+		}
 	}
 
 	// Clean up the response (remove any potential quotes or formatting)
-	enhancedPrompt := strings.TrimSpace(llmResp.Response)
-	enhancedPrompt = strings.Trim(enhancedPrompt, "\"'")
+	prompt := strings.Trim(strings.Join(promptLines, " "), "\"'")
+	negative = strings.Trim(negative, "\"'.")
+	return prompt, negative
+}
 
-	return enhancedPrompt, nil
+// SetMasterPrompts replaces the master prompt and negative prompt with any
+// non-empty values, so settings can be layered with per-song overrides
+func (ig *ImageGenerator) SetMasterPrompts(prompt, negative string) {
+	if prompt != "" {
+		ig.MasterPrompt = prompt
+	}
+	if negative != "" {
+		ig.MasterNegative = negative
+	}
 }
 
+// RandomSeed asks the Generate*WithSeed methods to pick a new random seed
+const RandomSeed int64 = -1
+
+// maxSeed keeps seeds within the unsigned 32-bit range diffusion backends accept
+const maxSeed = 1 << 32
+
 func (ig *ImageGenerator) GenerateImage(prompt, outputFilename string) (string, error) {
 	// Calls GenerateImageWithNegative with empty custom negative
 	return ig.GenerateImageWithNegative(prompt, "", outputFilename)
 }
 
+// ProgressFunc receives intra-image progress as diffusion steps completed out of total
+type ProgressFunc func(step, total int)
+
+// defaultImageEstimate is assumed for progress estimates before any image has been timed
+const defaultImageEstimate = 60 * time.Second
+
+// GenerateImageWithProgress generates an image, reporting progress while it runs.
+// The z-image API doesn't stream step progress, so steps are estimated from the
+// rolling average generation time. The estimate holds at total-1 until the image
+// actually arrives, then reports total. A nil onProgress behaves like GenerateImage.
+// customNegative is appended to the master negative as in GenerateImageWithNegative,
+// and seed is used as in GenerateImageWithSeed.
+func (ig *ImageGenerator) GenerateImageWithProgress(prompt, customNegative, outputFilename string, seed int64, onProgress ProgressFunc) (string, error) {
+	if onProgress == nil {
+		imagePath, _, err := ig.GenerateImageWithSeed(prompt, customNegative, outputFilename, seed)
+		return imagePath, err
+	}
+
+	total := ig.Steps
+	if total <= 0 {
+		total = DEFAULT_STEPS
+	}
+	estimate := ig.GetAverageImageTime()
+	if estimate <= 0 {
+		estimate = defaultImageEstimate
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		start := time.Now()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		lastStep := 0
+		onProgress(0, total)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				step := int(float64(total) * float64(time.Since(start)) / float64(estimate))
+				if step > total-1 {
+					step = total - 1
+				}
+				if step > lastStep {
+					lastStep = step
+					onProgress(step, total)
+				}
+			}
+		}
+	}()
+
+	imagePath, _, err := ig.GenerateImageWithSeed(prompt, customNegative, outputFilename, seed)
+	close(done)
+	<-stopped
+
+	if err == nil {
+		onProgress(total, total)
+	}
+	return imagePath, err
+}
+
 // GenerateImageWithNegative generates an image with custom negative prompt appended to master
 func (ig *ImageGenerator) GenerateImageWithNegative(prompt, customNegative, outputFilename string) (string, error) {
+	imagePath, _, err := ig.GenerateImageWithSeed(prompt, customNegative, outputFilename, RandomSeed)
+	return imagePath, err
+}
+
+// GenerateImageWithSeed is GenerateImageWithNegative with a fixed diffusion seed,
+// so the same prompt reproduces the same composition. A negative seed (RandomSeed)
+// picks a new one. It returns the seed used, which is also kept in LastSeed.
+// When ReferenceImage is set the image is generated from it.
+func (ig *ImageGenerator) GenerateImageWithSeed(prompt, customNegative, outputFilename string, seed int64) (string, int64, error) {
+	return ig.generateImage(prompt, customNegative, outputFilename, seed, ig.ReferenceImage, ig.ReferenceStrength)
+}
+
+// DefaultReferenceStrength is used when a reference image is given without a
+// strength: enough change to follow the prompt while keeping the reference's
+// palette and composition
+const DefaultReferenceStrength = 0.6
+
+// GenerateImageFromReference generates an image from a reference image
+// (img2img) rather than from noise. strength is how far the result may move
+// away from the reference: near 0 keeps it almost unchanged, 1 ignores it.
+// Zero uses DefaultReferenceStrength.
+func (ig *ImageGenerator) GenerateImageFromReference(prompt, refPath string, strength float64, outputFilename string) (string, error) {
+	imagePath, _, err := ig.generateImage(prompt, "", outputFilename, RandomSeed, refPath, strength)
+	return imagePath, err
+}
+
+// generateImage sends one z-image request, starting from refPath when it's set
+func (ig *ImageGenerator) generateImage(prompt, customNegative, outputFilename string, seed int64, refPath string, strength float64) (_ string, _ int64, err error) {
 	startTime := time.Now()
+	var queued time.Duration // Time spent waiting for a CQAI slot, excluded from timings
 	defer func() {
-		duration := time.Since(startTime)
+		metrics.ObserveRequest("image", err)
+		duration := time.Since(startTime) - queued
+		metrics.ImageGenerationDuration.Observe(duration.Seconds())
 		ig.ImageTimings = append(ig.ImageTimings, duration)
 		if len(ig.ImageTimings) > ig.MaxTimingSamples {
 			ig.ImageTimings = ig.ImageTimings[1:]
@@ -197,7 +434,11 @@ func (ig *ImageGenerator) GenerateImageWithNegative(prompt, customNegative, outp
 	}()
 
 	if err := os.MkdirAll(ig.OutputDir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create output directory: %w", err)
+		return "", 0, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if seed < 0 {
+		seed = rand.Int63n(maxSeed)
 	}
 
 	// Use prompt as-is (LLM already added quality modifiers)
@@ -205,10 +446,7 @@ func (ig *ImageGenerator) GenerateImageWithNegative(prompt, customNegative, outp
 
 	// Combine master negative prompt with custom negative prompt
 	// Use settings master negative if available, otherwise use default constant
-	masterNeg := ig.MasterNegative
-	if masterNeg == "" {
-		masterNeg = MASTER_NEGATIVE_PROMPT
-	}
+	masterNeg := ig.MasterNegativePrompt()
 	finalNegative := masterNeg
 	if customNegative != "" {
 		finalNegative = fmt.Sprintf("%s, %s", masterNeg, customNegative)
@@ -221,18 +459,36 @@ func (ig *ImageGenerator) GenerateImageWithNegative(prompt, customNegative, outp
 		Width:          ig.Width,
 		Height:         ig.Height,
 		Steps:          ig.Steps,
+		Seed:           seed,
+	}
+	if refPath != "" {
+		if strength < 0 || strength > 1 {
+			return "", 0, fmt.Errorf("reference strength %g must be between 0 and 1", strength)
+		}
+		if strength == 0 {
+			strength = DefaultReferenceStrength
+		}
+		refData, err := os.ReadFile(refPath)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to read reference image: %w", err)
+		}
+		req.InitImage = base64.StdEncoding.EncodeToString(refData)
+		req.Strength = strength
 	}
 
 	// Log the exact request being sent to CQAI
 	log.Printf("═══ CQAI Image Generation Request ═══")
 	log.Printf("Prompt: %s", enhancedPrompt)
 	log.Printf("Negative Prompt: %s", finalNegative)
-	log.Printf("Model: %s, Size: %dx%d, Steps: %d", ig.ImageModel, ig.Width, ig.Height, ig.Steps)
+	log.Printf("Model: %s, Size: %dx%d, Steps: %d, Seed: %d", ig.ImageModel, ig.Width, ig.Height, ig.Steps, seed)
+	if refPath != "" {
+		log.Printf("Reference Image: %s (strength %.2f)", refPath, req.Strength)
+	}
 	log.Printf("═════════════════════════════════════")
 
 	reqBody, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal image request: %w", err)
+		return "", 0, fmt.Errorf("failed to marshal image request: %w", err)
 	}
 
 	// Calculate adaptive timeout: average + 20% buffer, minimum 60s
@@ -244,80 +500,96 @@ func (ig *ImageGenerator) GenerateImageWithNegative(prompt, customNegative, outp
 		}
 	}
 
-	client := &http.Client{Timeout: timeout}
-	resp, err := client.Post(
-		ig.BaseURL+"/api/zimage/generate",
-		"application/json",
-		bytes.NewBuffer(reqBody),
+	resp, err := httputil.DoWithRetry(context.Background(), "CQAI image generation",
+		jsonPost(ig.BaseURL+"/api/zimage/generate", reqBody),
+		func(r *http.Request) (*http.Response, error) {
+			resp, wait, err := cqai.DoTimed(r, timeout)
+			queued += wait
+			return resp, err
+		},
+		nil,
 	)
 	if err != nil {
-		return "", fmt.Errorf("image generation request failed: %w", err)
+		return "", 0, fmt.Errorf("image generation request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("image API error %d: %s", resp.StatusCode, string(body))
+		return "", 0, fmt.Errorf("image API error %d: %s", resp.StatusCode, string(body))
 	}
 
 	var imgResp ZImageResponse
 	if err := json.NewDecoder(resp.Body).Decode(&imgResp); err != nil {
-		return "", fmt.Errorf("failed to decode image response: %w", err)
+		return "", 0, fmt.Errorf("failed to decode image response: %w", err)
 	}
 
 	if imgResp.Error != "" {
-		return "", fmt.Errorf("image generation error: %s", imgResp.Error)
+		return "", 0, fmt.Errorf("image generation error: %s", imgResp.Error)
 	}
 
 	if imgResp.Image == "" {
-		return "", fmt.Errorf("no image data returned from API")
+		return "", 0, fmt.Errorf("no image data returned from API")
 	}
 
 	imageData, err := base64.StdEncoding.DecodeString(imgResp.Image)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode base64 image: %w", err)
+		return "", 0, fmt.Errorf("failed to decode base64 image: %w", err)
 	}
 
 	outputPath := filepath.Join(ig.OutputDir, outputFilename)
 	if err := os.WriteFile(outputPath, imageData, 0644); err != nil {
-		return "", fmt.Errorf("failed to write image file: %w", err)
+		return "", 0, fmt.Errorf("failed to write image file: %w", err)
 	}
 
 	fmt.Printf("Image generated: %dx%d, %d steps, %.2fs\n",
 		imgResp.Width, imgResp.Height, imgResp.Steps, imgResp.GenerationTime)
+	if imgResp.Seed != nil {
+		seed = *imgResp.Seed
+	}
+	ig.LastSeed = seed
+
 	fmt.Printf("Image saved: %s\n", outputPath)
-	return outputPath, nil
+	return outputPath, seed, nil
 }
 
-func (ig *ImageGenerator) GenerateFromSection(sectionType string, sectionNumber int, lyrics, styleKeywords string) (string, string, error) {
-	var filename string
+// SectionImageFilename returns the background image file a lyrics section is
+// rendered over. Each verse has its own image; repeated sections share one, so
+// every chorus (including the final chorus) uses bg-chorus.png and every
+// pre-chorus bg-prechorus.png.
+func SectionImageFilename(sectionType string, number int) string {
 	switch sectionType {
 	case "verse":
-		filename = fmt.Sprintf("bg-verse-%d.png", sectionNumber)
+		if number > 0 {
+			return fmt.Sprintf("bg-verse-%d.png", number)
+		}
 	case "pre-chorus":
-		filename = "bg-prechorus.png"
-	case "chorus":
-		filename = "bg-chorus.png"
-	case "bridge":
-		filename = "bg-bridge.png"
-	case "intro":
-		filename = "bg-intro.png"
-	case "outro":
-		filename = "bg-outro.png"
-	default:
-		filename = fmt.Sprintf("bg-%s-%d.png", sectionType, sectionNumber)
+		return "bg-prechorus.png"
+	case "final-chorus":
+		return "bg-chorus.png"
 	}
+	return fmt.Sprintf("bg-%s.png", sectionType)
+}
+
+func (ig *ImageGenerator) GenerateFromSection(sectionType string, sectionNumber int, lyrics, styleKeywords string) (string, string, error) {
+	imagePath, prompt, _, err := ig.GenerateFromSectionWithProgress(sectionType, sectionNumber, lyrics, styleKeywords, nil)
+	return imagePath, prompt, err
+}
 
+// GenerateFromSectionWithProgress is GenerateFromSection with intra-image progress
+// reporting. It also returns the scene-specific negative prompt used.
+func (ig *ImageGenerator) GenerateFromSectionWithProgress(sectionType string, sectionNumber int, lyrics, styleKeywords string, onProgress ProgressFunc) (string, string, string, error) {
+	filename := SectionImageFilename(sectionType, sectionNumber)
 	outputPath := filepath.Join(ig.OutputDir, filename)
 	if _, err := os.Stat(outputPath); err == nil {
 		// Return empty prompt for existing images
-		return outputPath, "", nil
+		return outputPath, "", "", nil
 	}
 
 	fmt.Printf("Enhancing prompt for %s %d with LLM...\n", sectionType, sectionNumber)
-	enhancedPrompt, err := ig.EnhancePromptWithLLM(sectionType, lyrics, styleKeywords)
+	enhancedPrompt, negative, err := ig.EnhancePromptWithNegative(sectionType, lyrics, styleKeywords)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to enhance prompt: %w", err)
+		return "", "", "", fmt.Errorf("failed to enhance prompt: %w", err)
 	}
 
 	promptPreview := enhancedPrompt
@@ -327,13 +599,13 @@ func (ig *ImageGenerator) GenerateFromSection(sectionType string, sectionNumber
 	fmt.Printf("Enhanced prompt: %s\n", promptPreview)
 
 	fmt.Printf("Generating image for %s %d...\n", sectionType, sectionNumber)
-	imagePath, err := ig.GenerateImage(enhancedPrompt, filename)
+	imagePath, err := ig.GenerateImageWithProgress(enhancedPrompt, negative, filename, RandomSeed, onProgress)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to generate image: %w", err)
+		return "", "", "", fmt.Errorf("failed to generate image: %w", err)
 	}
 
 	fmt.Printf("Image saved: %s\n", imagePath)
-	return imagePath, enhancedPrompt, nil
+	return imagePath, enhancedPrompt, negative, nil
 }
 
 // GetAverageLLMTime returns the average time for LLM prompt enhancement
@@ -421,14 +693,6 @@ type VisionLLMRequest struct {
 
 // ExtractPromptFromImage uses a vision model to reverse-engineer a prompt from an existing image
 func (ig *ImageGenerator) ExtractPromptFromImage(imagePath string) (string, error) {
-	// Read and encode image to base64
-	imageData, err := os.ReadFile(imagePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read image: %w", err)
-	}
-
-	base64Image := base64.StdEncoding.EncodeToString(imageData)
-
 	// System prompt for reverse-engineering image prompts
 	visionPrompt := `Analyze this image and create a detailed prompt that could regenerate a similar image. 
 
@@ -443,8 +707,53 @@ CRITICAL RULES:
 STRUCTURE:
 [Subject and scene] at [location], [lighting description], [mood/atmosphere], [color palette], [camera/composition details], photorealistic, professional photography, 8K resolution, ultra detailed, sharp focus, cinematic composition`
 
+	prompt, err := ig.askVision("Vision prompt extraction", imagePath, visionPrompt)
+	if err != nil {
+		return "", err
+	}
+	if prompt == "" {
+		return "", fmt.Errorf("vision model returned empty prompt")
+	}
+
+	return prompt, nil
+}
+
+// DescribeImage returns a short plain-language description of an image,
+// suitable for alt-text and for checking it matches its prompt
+func (ig *ImageGenerator) DescribeImage(imagePath string) (string, error) {
+	visionPrompt := `Describe this image for someone who cannot see it.
+
+RULES:
+1. 1-3 sentences, under 60 words
+2. Describe the main subject, setting, lighting and mood
+3. Mention any visible text, letters or logos
+4. Plain language, no photography jargon
+5. Output ONLY the description with NO preamble`
+
+	description, err := ig.askVision("Vision image description", imagePath, visionPrompt)
+	if err != nil {
+		return "", err
+	}
+	if description == "" {
+		return "", fmt.Errorf("vision model returned empty description")
+	}
+
+	return strings.Trim(description, "\"'"), nil
+}
+
+// askVision sends an image and instruction to the Ollama vision model and
+// returns the trimmed response text
+func (ig *ImageGenerator) askVision(name, imagePath, visionPrompt string) (string, error) {
+	// Read and encode image to base64
+	imageData, err := os.ReadFile(imagePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+
+	base64Image := base64.StdEncoding.EncodeToString(imageData)
+
 	req := VisionLLMRequest{
-		Model:  "llama3.2-vision:11b", // Ollama vision model
+		Model:  ig.VisionModel, // Ollama vision model
 		Prompt: visionPrompt,
 		Images: []string{base64Image},
 		Stream: false,
@@ -456,7 +765,11 @@ STRUCTURE:
 	}
 
 	// Call Ollama API with vision support
-	resp, err := http.Post(ig.LLMURL+"/api/generate", "application/json", bytes.NewBuffer(reqBody))
+	resp, err := httputil.DoWithRetry(context.Background(), name,
+		jsonPost(ig.LLMURL+"/api/generate", reqBody),
+		func(r *http.Request) (*http.Response, error) { return cqai.Do(r, ig.Timeout) },
+		nil,
+	)
 	if err != nil {
 		return "", fmt.Errorf("vision API request failed: %w", err)
 	}
@@ -476,10 +789,17 @@ STRUCTURE:
 		return "", fmt.Errorf("failed to parse vision response: %w", err)
 	}
 
-	prompt := strings.TrimSpace(llmResp.Response)
-	if prompt == "" {
-		return "", fmt.Errorf("vision model returned empty prompt")
-	}
+	return strings.TrimSpace(llmResp.Response), nil
+}
 
-	return prompt, nil
+// jsonPost builds a fresh JSON POST request for each retry attempt
+func jsonPost(url string, body []byte) httputil.RequestBuilder {
+	return func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}
 }
@@ -0,0 +1,153 @@
+package image
+
+import (
+	"fmt"
+	stdimage "image"
+	_ "image/jpeg" // Register decoders for the formats image files arrive in
+	_ "image/png"
+	"math"
+	"math/bits"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// DefaultSimilarityThreshold is the largest Hamming distance between two
+// perceptual hashes at which images count as near-duplicates (out of 64 bits)
+const DefaultSimilarityThreshold = 10
+
+// pHash works on a downscaled grayscale copy and keeps the lowest frequencies
+const (
+	phashSize    = 32
+	phashLowFreq = 8
+)
+
+// PerceptualHash computes a 64-bit DCT perceptual hash (pHash) of an image
+// file. Visually similar images have hashes a small Hamming distance apart,
+// regardless of resolution or minor compression differences.
+func PerceptualHash(path string) (uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	img, _, err := stdimage.Decode(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+
+	pixels := grayscaleThumbnail(img)
+	coefficients := lowFrequencyDCT(pixels)
+
+	// Threshold against the median, skipping the DC term which only carries
+	// overall brightness
+	sorted := append([]float64(nil), coefficients[1:]...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for i, c := range coefficients {
+		if i > 0 && c > median {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash, nil
+}
+
+// HammingDistance returns the number of differing bits between two hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// FormatHash encodes a hash as 16 hex digits for storage and JSON
+// (a raw uint64 loses precision in JavaScript clients)
+func FormatHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// ParseHash decodes a hash written by FormatHash
+func ParseHash(s string) (uint64, error) {
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// HashedImage pairs an image ID with its perceptual hash
+type HashedImage struct {
+	ID   int
+	Hash uint64
+}
+
+// SimilarPair is two images whose hashes are within the similarity threshold
+type SimilarPair struct {
+	A        int `json:"image_a"`
+	B        int `json:"image_b"`
+	Distance int `json:"distance"`
+}
+
+// SimilarPairs returns every pair of images at most threshold bits apart,
+// closest first
+func SimilarPairs(images []HashedImage, threshold int) []SimilarPair {
+	var pairs []SimilarPair
+	for i := 0; i < len(images); i++ {
+		for j := i + 1; j < len(images); j++ {
+			distance := HammingDistance(images[i].Hash, images[j].Hash)
+			if distance <= threshold {
+				pairs = append(pairs, SimilarPair{A: images[i].ID, B: images[j].ID, Distance: distance})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].Distance < pairs[j].Distance })
+	return pairs
+}
+
+// grayscaleThumbnail box-averages an image down to phashSize x phashSize luma values
+func grayscaleThumbnail(img stdimage.Image) [phashSize][phashSize]float64 {
+	var pixels [phashSize][phashSize]float64
+	var counts [phashSize][phashSize]float64
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := (y - bounds.Min.Y) * phashSize / height
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			col := (x - bounds.Min.X) * phashSize / width
+			r, g, b, _ := img.At(x, y).RGBA()
+			pixels[row][col] += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+			counts[row][col]++
+		}
+	}
+
+	for y := range pixels {
+		for x := range pixels[y] {
+			if counts[y][x] > 0 {
+				pixels[y][x] /= counts[y][x]
+			}
+		}
+	}
+	return pixels
+}
+
+// lowFrequencyDCT returns the top-left phashLowFreq x phashLowFreq block of
+// the 2D DCT-II of pixels, row-major
+func lowFrequencyDCT(pixels [phashSize][phashSize]float64) []float64 {
+	var cosines [phashLowFreq][phashSize]float64
+	for u := 0; u < phashLowFreq; u++ {
+		for x := 0; x < phashSize; x++ {
+			cosines[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * phashSize))
+		}
+	}
+
+	coefficients := make([]float64, 0, phashLowFreq*phashLowFreq)
+	for v := 0; v < phashLowFreq; v++ {
+		for u := 0; u < phashLowFreq; u++ {
+			var sum float64
+			for y := 0; y < phashSize; y++ {
+				for x := 0; x < phashSize; x++ {
+					sum += pixels[y][x] * cosines[u][x] * cosines[v][y]
+				}
+			}
+			coefficients = append(coefficients, sum)
+		}
+	}
+	return coefficients
+}
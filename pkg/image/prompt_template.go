@@ -0,0 +1,64 @@
+package image
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// DefaultPromptTemplate is the LLM user prompt used when settings don't
+// provide one. It reproduces the original built-in prompt.
+const DefaultPromptTemplate = `Song Section: {section}
+Additional Style: {style}
+
+Lyrics:
+{lyrics}
+
+Generate a cinematic, photorealistic image prompt that captures the visual essence of these lyrics. Remember: NO text or letters in the image.`
+
+// PromptVars are the values substituted into a prompt template.
+// Supported placeholders: {section}, {genre}, {mood}, {style}, {lyrics}
+type PromptVars struct {
+	Section string
+	Genre   string
+	Mood    string
+	Style   string
+	Lyrics  string
+}
+
+// RenderPromptTemplate substitutes vars into template, falling back to
+// DefaultPromptTemplate when template is blank
+func RenderPromptTemplate(template string, vars PromptVars) string {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultPromptTemplate
+	}
+
+	replacer := strings.NewReplacer(
+		"{section}", vars.Section,
+		"{genre}", vars.Genre,
+		"{mood}", vars.Mood,
+		"{style}", vars.Style,
+		"{lyrics}", vars.Lyrics,
+	)
+	return replacer.Replace(template)
+}
+
+// promptTemplateFor picks the section-type override if there is one, then the
+// generator's template, then the default
+func (ig *ImageGenerator) promptTemplateFor(sectionType string) string {
+	for section, override := range ig.SectionTemplates {
+		if strings.EqualFold(section, sectionType) && strings.TrimSpace(override) != "" {
+			return override
+		}
+	}
+	return ig.PromptTemplate
+}
+
+// FormatMood turns a song's mood (a JSON array, or plain text) into a
+// comma-separated list for prompt templates
+func FormatMood(raw string) string {
+	var moods []string
+	if err := json.Unmarshal([]byte(raw), &moods); err == nil {
+		return strings.Join(moods, ", ")
+	}
+	return strings.TrimSpace(raw)
+}
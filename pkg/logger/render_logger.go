@@ -137,6 +137,11 @@ func (rl *RenderLogger) Output(output string) {
 	rl.file.Sync()
 }
 
+// Warning logs a problem that doesn't stop the render
+func (rl *RenderLogger) Warning(format string, args ...interface{}) {
+	rl.log("WARNING", format, args...)
+}
+
 // Error logs an error message
 func (rl *RenderLogger) Error(format string, args ...interface{}) {
 	rl.log("ERROR", format, args...)
@@ -0,0 +1,82 @@
+package lyrics
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// ShiftASSFile moves every Dialogue event in an ASS file by offset seconds
+// (negative shows lines earlier). Events pushed before zero are clamped to
+// the start of the video, and events that would end before it are dropped.
+func ShiftASSFile(path string, offset float64) error {
+	if offset == 0 {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	shifted, err := ShiftASS(string(data), offset)
+	if err != nil {
+		return fmt.Errorf("failed to shift %s: %w", path, err)
+	}
+	return os.WriteFile(path, []byte(shifted), 0644)
+}
+
+// ShiftASS moves the start and end of every Dialogue event in ASS content by
+// offset seconds; see ShiftASSFile
+func ShiftASS(content string, offset float64) (string, error) {
+	lines := strings.Split(content, "\n")
+	kept := lines[:0]
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "Dialogue:") {
+			kept = append(kept, line)
+			continue
+		}
+
+		// Dialogue: Layer,Start,End,Style,Name,MarginL,MarginR,MarginV,Effect,Text
+		fields := strings.SplitN(line, ",", 4)
+		if len(fields) < 4 {
+			return "", fmt.Errorf("line %d: malformed Dialogue event", i+1)
+		}
+		start, err := parseASSTime(fields[1])
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
+		}
+		end, err := parseASSTime(fields[2])
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		end += offset
+		if end <= 0 {
+			continue
+		}
+		start = math.Max(start+offset, 0)
+
+		fields[1] = formatASSTime(start)
+		fields[2] = formatASSTime(end)
+		kept = append(kept, strings.Join(fields, ","))
+	}
+	return strings.Join(kept, "\n"), nil
+}
+
+// parseASSTime parses an ASS timestamp (H:MM:SS.cc) into seconds
+func parseASSTime(s string) (float64, error) {
+	var hours, minutes int
+	var seconds float64
+	if _, err := fmt.Sscanf(strings.TrimSpace(s), "%d:%d:%f", &hours, &minutes, &seconds); err != nil {
+		return 0, fmt.Errorf("invalid ASS time %q", s)
+	}
+	return float64(hours*3600+minutes*60) + seconds, nil
+}
+
+// formatASSTime formats seconds as an ASS timestamp (H:MM:SS.cc)
+func formatASSTime(seconds float64) string {
+	centis := int(math.Round(seconds * 100))
+	return fmt.Sprintf("%d:%02d:%02d.%02d", centis/360000, centis/6000%60, centis/100%60, centis%100)
+}
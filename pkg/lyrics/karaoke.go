@@ -2,6 +2,7 @@ package lyrics
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,7 +12,19 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/httputil"
+	"github.com/AndrewDonelson/track-studio-orchestrator/pkg/pyenv"
+)
+
+// Python scripts the karaoke generator runs from the python-scripts directory
+const (
+	timestampsScript = "generate_timestamps.py"
+	assScript        = "generate_karaoke_ass.py"
 )
 
 // KaraokeOptions holds customization settings for karaoke subtitles
@@ -24,6 +37,10 @@ type KaraokeOptions struct {
 	HighlightBorderColor string
 	Alignment            int
 	MarginBottom         int
+
+	// TimingOffset shifts every subtitle event by this many seconds to
+	// correct lyrics that lead or lag the audio (negative shows them earlier)
+	TimingOffset float64
 }
 
 // DefaultKaraokeOptions returns default karaoke settings
@@ -40,11 +57,53 @@ func DefaultKaraokeOptions() *KaraokeOptions {
 	}
 }
 
+// karaokeColorPattern matches the colors the ASS generator accepts: hex
+// RRGGBB, optionally with a leading #
+var karaokeColorPattern = regexp.MustCompile(`^#?[0-9A-Fa-f]{6}$`)
+
+// IsKaraokeColor reports whether color can be used for karaoke subtitles
+func IsKaraokeColor(color string) bool {
+	return karaokeColorPattern.MatchString(color)
+}
+
+// DefaultWhisperModel is the Whisper model used when none (or an invalid one) is configured
+const DefaultWhisperModel = "base"
+
+// WhisperModels lists the Whisper model sizes supported for karaoke timing, fastest first
+var WhisperModels = []string{"tiny", "base", "small", "medium", "large-v3"}
+
+// NormalizeWhisperModel validates a Whisper model name, falling back to DefaultWhisperModel
+func NormalizeWhisperModel(model string) string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	for _, m := range WhisperModels {
+		if model == m {
+			return m
+		}
+	}
+	return DefaultWhisperModel
+}
+
+// LanguageAuto lets Whisper detect the spoken language instead of assuming one
+const LanguageAuto = "auto"
+
+// DefaultLanguage is the language used when a song doesn't specify one
+const DefaultLanguage = "en"
+
+// NormalizeLanguage lowercases an ISO language code, falling back to DefaultLanguage when empty
+func NormalizeLanguage(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return DefaultLanguage
+	}
+	return language
+}
+
 // KaraokeGenerator handles word-level timestamp generation and ASS subtitle creation
 type KaraokeGenerator struct {
 	PythonPath   string
 	ScriptsDir   string
 	WhisperModel string
+	WhisperXURL  string
 	VenvPath     string
 }
 
@@ -57,8 +116,10 @@ type WhisperResult struct {
 }
 
 // NewKaraokeGenerator creates a new karaoke generator instance
-// scriptsPath should be the full path to python-scripts directory (from config.PythonScripts)
-func NewKaraokeGenerator(scriptsPath string) *KaraokeGenerator {
+// using the python-scripts directory and WhisperX API URL from config
+func NewKaraokeGenerator(cfg *config.Config) *KaraokeGenerator {
+	scriptsPath := cfg.PythonScripts
+
 	// Detect venv path - check multiple locations
 	venvPaths := []string{
 		// If scriptsPath is in data directory, check nearby venv
@@ -82,14 +143,41 @@ func NewKaraokeGenerator(scriptsPath string) *KaraokeGenerator {
 	return &KaraokeGenerator{
 		PythonPath:   venvPath,
 		ScriptsDir:   scriptsPath,
-		WhisperModel: "base", // Use "base" for faster processing, "large-v3" for best quality
+		WhisperModel: DefaultWhisperModel, // Use "base" for faster processing, "large-v3" for best quality
+		WhisperXURL:  cfg.WhisperXURL,
 		VenvPath:     venvPath,
 	}
 }
 
+// CheckEnvironment verifies the Python environment used for karaoke generation
+// with the interpreter and scripts directory from config
+func CheckEnvironment(cfg *config.Config) *pyenv.Report {
+	return NewKaraokeGenerator(cfg).CheckEnvironment()
+}
+
+// CheckEnvironment verifies the interpreter, the karaoke scripts and the
+// faster_whisper module needed when the WhisperX API is unavailable
+func (kg *KaraokeGenerator) CheckEnvironment() *pyenv.Report {
+	report := pyenv.NewReport("karaoke generation", kg.PythonPath)
+	if report.CheckBinary(kg.PythonPath, "install Python 3 or create a .venv beside the python-scripts directory") {
+		report.CheckModules("pip install faster-whisper (in the karaoke venv)", "faster_whisper")
+	}
+	for _, script := range []string{timestampsScript, assScript} {
+		report.CheckFile(script, filepath.Join(kg.ScriptsDir, script), "copy python-scripts/ into "+kg.ScriptsDir)
+	}
+	return report
+}
+
 // GenerateTimestamps generates word-level timestamps from vocals track
-func (kg *KaraokeGenerator) GenerateTimestamps(vocalsPath string, outputJSON string) (*WhisperResult, error) {
-	log.Printf("Generating word-level timestamps from: %s", vocalsPath)
+// using the given Whisper model (empty uses the generator's default) and
+// language ("auto" lets Whisper detect it; the detected code is set on the result)
+func (kg *KaraokeGenerator) GenerateTimestamps(vocalsPath string, outputJSON string, model string, language string) (*WhisperResult, error) {
+	if model == "" {
+		model = kg.WhisperModel
+	}
+	model = NormalizeWhisperModel(model)
+	language = NormalizeLanguage(language)
+	log.Printf("Generating word-level timestamps from: %s (model: %s, language: %s)", vocalsPath, model, language)
 
 	// Ensure output directory exists
 	if err := os.MkdirAll(filepath.Dir(outputJSON), 0755); err != nil {
@@ -97,10 +185,10 @@ func (kg *KaraokeGenerator) GenerateTimestamps(vocalsPath string, outputJSON str
 	}
 
 	// Try API method first, fallback to local script
-	result, err := kg.generateTimestampsViaAPI(vocalsPath, outputJSON)
+	result, err := kg.generateTimestampsViaAPI(vocalsPath, outputJSON, model, language)
 	if err != nil {
 		log.Printf("API method failed, falling back to local script: %v", err)
-		result, err = kg.generateTimestampsViaScript(vocalsPath, outputJSON)
+		result, err = kg.generateTimestampsViaScript(vocalsPath, outputJSON, model, language)
 		if err != nil {
 			return nil, fmt.Errorf("both API and local methods failed: %w", err)
 		}
@@ -116,7 +204,7 @@ func (kg *KaraokeGenerator) GenerateTimestamps(vocalsPath string, outputJSON str
 }
 
 // generateTimestampsViaAPI calls the WhisperX API service
-func (kg *KaraokeGenerator) generateTimestampsViaAPI(vocalsPath string, outputJSON string) (*WhisperResult, error) {
+func (kg *KaraokeGenerator) generateTimestampsViaAPI(vocalsPath string, outputJSON string, model string, language string) (*WhisperResult, error) {
 	// Open the audio file
 	file, err := os.Open(vocalsPath)
 	if err != nil {
@@ -138,22 +226,26 @@ func (kg *KaraokeGenerator) generateTimestampsViaAPI(vocalsPath string, outputJS
 	}
 
 	// Add other parameters
-	writer.WriteField("language", "en")
-	writer.WriteField("model", kg.WhisperModel)
+	writer.WriteField("language", language)
+	writer.WriteField("model", model)
 	writer.WriteField("align_mode", "false")
 
 	writer.Close()
 
 	// Make HTTP request to WhisperX API
-	apiURL := "http://192.168.1.76:8181/transcribe/sync"
-	req, err := http.NewRequest("POST", apiURL, &b)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	apiURL := strings.TrimRight(kg.WhisperXURL, "/") + "/transcribe/sync"
+	formData := b.Bytes()
+	buildRequest := func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(formData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		return req, nil
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	client := &http.Client{Timeout: 10 * time.Minute} // Long timeout for processing
-	resp, err := client.Do(req)
+	resp, err := httputil.DoWithRetry(context.Background(), "WhisperX transcription", buildRequest, client.Do, nil)
 	if err != nil {
 		return nil, fmt.Errorf("API request failed: %w", err)
 	}
@@ -191,13 +283,19 @@ func (kg *KaraokeGenerator) generateTimestampsViaAPI(vocalsPath string, outputJS
 }
 
 // generateTimestampsViaScript uses the local Python script (fallback method)
-func (kg *KaraokeGenerator) generateTimestampsViaScript(vocalsPath string, outputJSON string) (*WhisperResult, error) {
+func (kg *KaraokeGenerator) generateTimestampsViaScript(vocalsPath string, outputJSON string, model string, language string) (*WhisperResult, error) {
+	// Fail fast with an actionable error rather than a subprocess traceback
+	if err := kg.CheckEnvironment().Err(); err != nil {
+		return nil, err
+	}
+
 	cmd := exec.Command(
 		kg.PythonPath,
-		filepath.Join(kg.ScriptsDir, "generate_timestamps.py"),
+		filepath.Join(kg.ScriptsDir, timestampsScript),
 		"--vocals", vocalsPath,
 		"--output", outputJSON,
-		"--model", kg.WhisperModel,
+		"--model", model,
+		"--language", language,
 	)
 
 	output, err := cmd.CombinedOutput()
@@ -277,6 +375,11 @@ func (kg *KaraokeGenerator) convertAPIResponseToWhisperResult(apiResponse map[st
 		result.Segments = append(result.Segments, segment)
 	}
 
+	// Language reported by Whisper (detected when language was "auto")
+	if language, ok := jsonData["language"].(string); ok {
+		result.Language = language
+	}
+
 	// Set transcription text
 	if transcription, ok := apiResponse["transcription"].(string); ok {
 		result.Text = transcription
@@ -301,7 +404,7 @@ func (kg *KaraokeGenerator) GenerateASSFile(timestampsJSON string, outputASS str
 
 	// Prepare command arguments
 	cmdArgs := []string{
-		filepath.Join(kg.ScriptsDir, "generate_karaoke_ass.py"),
+		filepath.Join(kg.ScriptsDir, assScript),
 		"--timestamps", timestampsJSON,
 		"--output", outputASS,
 		"--font-family", options.FontFamily,
@@ -337,21 +440,38 @@ func (kg *KaraokeGenerator) GenerateASSFile(timestampsJSON string, outputASS str
 	}
 
 	log.Printf("ASS generation output:\n%s", string(output))
+
+	if options.TimingOffset != 0 {
+		if err := ShiftASSFile(outputASS, options.TimingOffset); err != nil {
+			return fmt.Errorf("failed to apply timing offset: %w", err)
+		}
+		log.Printf("Shifted karaoke subtitles by %+.2fs", options.TimingOffset)
+	}
 	return nil
 }
 
+// KaraokeResult describes generated karaoke subtitles
+type KaraokeResult struct {
+	ASSPath       string
+	WhisperEngine string // Engine and model used, e.g. "whisperx-api (large-v3)"
+	Language      string // Language Whisper transcribed (the detected one when "auto")
+}
+
 // GenerateKaraokeSubtitles is the complete pipeline: vocals → timestamps → ASS
 // If lyricsKaraoke is provided, uses actual lyrics for display instead of Whisper transcription
-// Returns the ASS path and the whisper engine used (whisperx or faster-whisper)
-func (kg *KaraokeGenerator) GenerateKaraokeSubtitles(vocalsPath string, songID int, workingDir string, lyricsKaraoke string, options *KaraokeOptions) (string, string, error) {
+// whisperModel selects the Whisper model size (tiny/base/small/medium/large-v3, default base)
+// language is an ISO code, or "auto" to let Whisper detect it
+func (kg *KaraokeGenerator) GenerateKaraokeSubtitles(vocalsPath string, songID int, workingDir string, lyricsKaraoke string, whisperModel string, language string, options *KaraokeOptions) (*KaraokeResult, error) {
 	// Define output paths
-	timestampsJSON := filepath.Join(workingDir, fmt.Sprintf("song_%d_timestamps.json", songID))
+	timestampsJSON := TimestampsPath(workingDir, songID)
 	assPath := filepath.Join(workingDir, fmt.Sprintf("song_%d_karaoke.ass", songID))
 
 	// Step 1: Generate timestamps (uses Whisper for timing only)
-	result, err := kg.GenerateTimestamps(vocalsPath, timestampsJSON)
+	model := NormalizeWhisperModel(whisperModel)
+	language = NormalizeLanguage(language)
+	result, err := kg.GenerateTimestamps(vocalsPath, timestampsJSON, model, language)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to generate timestamps: %w", err)
+		return nil, fmt.Errorf("failed to generate timestamps: %w", err)
 	}
 
 	// Extract which engine was used
@@ -359,13 +479,63 @@ func (kg *KaraokeGenerator) GenerateKaraokeSubtitles(vocalsPath string, songID i
 	if whisperEngine == "" {
 		whisperEngine = "faster-whisper" // default fallback
 	}
+	whisperEngine = fmt.Sprintf("%s (%s)", whisperEngine, model)
+
+	// Prefer the language Whisper reports over the requested one
+	detectedLanguage := language
+	if result.Language != "" {
+		detectedLanguage = NormalizeLanguage(result.Language)
+	}
 
 	// Step 2: Generate ASS file (with actual lyrics if provided)
 	err = kg.GenerateASSFile(timestampsJSON, assPath, lyricsKaraoke, options)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to generate ASS file: %w", err)
+		return nil, fmt.Errorf("failed to generate ASS file: %w", err)
 	}
 
-	log.Printf("Successfully generated karaoke subtitles using %s: %s", whisperEngine, assPath)
-	return assPath, whisperEngine, nil
+	log.Printf("Successfully generated karaoke subtitles using %s (language: %s): %s", whisperEngine, detectedLanguage, assPath)
+	return &KaraokeResult{
+		ASSPath:       assPath,
+		WhisperEngine: whisperEngine,
+		Language:      detectedLanguage,
+	}, nil
+}
+
+// TimestampsPath returns where GenerateKaraokeSubtitles caches a song's
+// word-level timestamps within workingDir
+func TimestampsPath(workingDir string, songID int) string {
+	return filepath.Join(workingDir, fmt.Sprintf("song_%d_timestamps.json", songID))
+}
+
+// RegenerateKaraokeSubtitles rebuilds a song's ASS file from the timestamps
+// cached by an earlier GenerateKaraokeSubtitles run, skipping Whisper. It is
+// much faster than a full run, so style and offset tweaks can be re-burned
+// cheaply. whisperEngine is reported back unchanged since no transcription runs.
+func (kg *KaraokeGenerator) RegenerateKaraokeSubtitles(songID int, workingDir string, lyricsKaraoke string, whisperEngine string, options *KaraokeOptions) (*KaraokeResult, error) {
+	timestampsJSON := TimestampsPath(workingDir, songID)
+	assPath := filepath.Join(workingDir, fmt.Sprintf("song_%d_karaoke.ass", songID))
+
+	data, err := os.ReadFile(timestampsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("no cached timestamps: %w", err)
+	}
+	var cached WhisperResult
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, fmt.Errorf("invalid cached timestamps: %w", err)
+	}
+
+	if err := kg.GenerateASSFile(timestampsJSON, assPath, lyricsKaraoke, options); err != nil {
+		return nil, fmt.Errorf("failed to generate ASS file: %w", err)
+	}
+
+	log.Printf("Regenerated karaoke subtitles from cached timestamps: %s", assPath)
+	result := &KaraokeResult{
+		ASSPath:       assPath,
+		WhisperEngine: whisperEngine,
+		Language:      LanguageAuto,
+	}
+	if cached.Language != "" {
+		result.Language = NormalizeLanguage(cached.Language)
+	}
+	return result, nil
 }
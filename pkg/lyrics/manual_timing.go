@@ -0,0 +1,71 @@
+package lyrics
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TimingEdit is one hand-corrected lyric line
+type TimingEdit struct {
+	Line  string  `json:"line"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// ValidateTimingEdits checks that hand-corrected lines are non-empty, run
+// forward without overlapping and fit within the song. It returns field
+// errors keyed like "[2].start", or nil when the edits are valid. A duration
+// of zero skips the upper bound check.
+func ValidateTimingEdits(edits []TimingEdit, duration float64) map[string]string {
+	errs := make(map[string]string)
+	if len(edits) == 0 {
+		errs["lines"] = "at least one line is required"
+		return errs
+	}
+
+	prevEnd := 0.0
+	for i, edit := range edits {
+		field := func(name string) string { return fmt.Sprintf("[%d].%s", i, name) }
+
+		if strings.TrimSpace(edit.Line) == "" {
+			errs[field("line")] = "must not be empty"
+		}
+
+		switch {
+		case edit.Start < 0:
+			errs[field("start")] = "must not be negative"
+		case i > 0 && edit.Start < prevEnd:
+			errs[field("start")] = fmt.Sprintf("must not be before the previous line ends (%.2fs)", prevEnd)
+		}
+
+		switch {
+		case edit.End <= edit.Start:
+			errs[field("end")] = "must be after start"
+		case duration > 0 && edit.End > duration:
+			errs[field("end")] = fmt.Sprintf("must not be past the end of the song (%.2fs)", duration)
+		}
+
+		if edit.End > prevEnd {
+			prevEnd = edit.End
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// TimedLinesFromEdits converts validated edits into timed lines
+func TimedLinesFromEdits(edits []TimingEdit) []TimedLine {
+	lines := make([]TimedLine, len(edits))
+	for i, edit := range edits {
+		lines[i] = TimedLine{
+			Line:      strings.TrimSpace(edit.Line),
+			StartTime: edit.Start,
+			EndTime:   edit.End,
+			Duration:  edit.End - edit.Start,
+		}
+	}
+	return lines
+}
@@ -9,7 +9,7 @@ import (
 
 // Section represents a detected section in lyrics (verse, chorus, bridge, etc.)
 type Section struct {
-	Type      string   `json:"type"`       // "verse", "chorus", "bridge", "intro", "outro"
+	Type      string   `json:"type"`       // "verse", "pre-chorus", "chorus", "final-chorus", "bridge", "intro", "outro"
 	Number    int      `json:"number"`     // Which occurrence (verse 1, verse 2, etc.)
 	StartLine int      `json:"start_line"` // Line number where section starts
 	EndLine   int      `json:"end_line"`   // Line number where section ends
@@ -33,8 +33,17 @@ type LyricsData struct {
 	HasSections bool        `json:"has_sections"`
 }
 
-// ParseLyrics parses raw lyrics text into structured sections
+// ParseLyrics parses raw lyrics text into structured sections using the default options
 func ParseLyrics(rawLyrics string) (*LyricsData, error) {
+	return ParseLyricsWithOptions(rawLyrics, DefaultParseOptions())
+}
+
+// ParseLyricsWithOptions parses raw lyrics text into structured sections
+func ParseLyricsWithOptions(rawLyrics string, opts *ParseOptions) (*LyricsData, error) {
+	if opts == nil {
+		opts = DefaultParseOptions()
+	}
+
 	if strings.TrimSpace(rawLyrics) == "" {
 		return nil, fmt.Errorf("empty lyrics")
 	}
@@ -59,6 +68,15 @@ func ParseLyrics(rawLyrics string) (*LyricsData, error) {
 
 	// Detect sections
 	sections := detectSections(cleanLines)
+
+	// If no explicit sections found, detect implicitly by repetition
+	if len(sections) == 0 {
+		if opts.Strategy == StrategyChunked {
+			sections = detectImplicitSections(cleanLines)
+		} else {
+			sections = detectSimilaritySections(rawLyrics, cleanLines)
+		}
+	}
 	data.Sections = sections
 	data.HasSections = len(sections) > 0
 
@@ -69,10 +87,12 @@ func ParseLyrics(rawLyrics string) (*LyricsData, error) {
 func detectSections(lines []string) []Section {
 	var sections []Section
 
-	// Patterns for explicit section markers
+	// Patterns for explicit section markers. Choruses may carry a repeat
+	// count, e.g. [Chorus x2] or [Final Chorus (x2)]
 	versePattern := regexp.MustCompile(`(?i)^\[?verse\s*(\d+)?\]?$`)
-	chorusPattern := regexp.MustCompile(`(?i)^\[?chorus\]?$`)
-	preChorusPattern := regexp.MustCompile(`(?i)^\[?pre-?chorus\]?$`)
+	chorusPattern := regexp.MustCompile(`(?i)^\[?chorus\s*(\d+)?(\s*\(?x\d+\)?)?\]?$`)
+	preChorusPattern := regexp.MustCompile(`(?i)^\[?pre[\s-]?chorus\s*(\d+)?\]?$`)
+	finalChorusPattern := regexp.MustCompile(`(?i)^\[?(final|outro)[\s-]?chorus(\s*\(?x\d+\)?)?\]?$`)
 	bridgePattern := regexp.MustCompile(`(?i)^\[?bridge\]?$`)
 	introPattern := regexp.MustCompile(`(?i)^\[?intro\]?$`)
 	outroPattern := regexp.MustCompile(`(?i)^\[?outro\]?$`)
@@ -86,6 +106,7 @@ func detectSections(lines []string) []Section {
 
 	verseCount := 1
 	chorusCount := 0
+	preChorusCount := 0
 	inSection := false
 
 	for i, line := range lines {
@@ -127,14 +148,31 @@ func detectSections(lines []string) []Section {
 			continue
 		}
 
+		// Final chorus is checked before chorus/pre-chorus so it isn't misattributed
+		if finalChorusPattern.MatchString(line) {
+			if inSection {
+				currentSection.EndLine = i - 1
+				sections = append(sections, currentSection)
+			}
+			currentSection = Section{
+				Type:      "final-chorus",
+				Number:    1,
+				StartLine: i + 1,
+				Lines:     []string{},
+			}
+			inSection = true
+			continue
+		}
+
 		if preChorusPattern.MatchString(line) {
 			if inSection {
 				currentSection.EndLine = i - 1
 				sections = append(sections, currentSection)
 			}
+			preChorusCount++
 			currentSection = Section{
 				Type:      "pre-chorus",
-				Number:    1,
+				Number:    preChorusCount,
 				StartLine: i + 1,
 				Lines:     []string{},
 			}
@@ -199,18 +237,13 @@ func detectSections(lines []string) []Section {
 		sections = append(sections, currentSection)
 	}
 
-	// If no explicit sections found, detect implicitly by repetition
-	if len(sections) == 0 {
-		sections = detectImplicitSections(lines)
-	}
-
 	return sections
 }
 
 // detectImplicitSections finds repeated sections (likely chorus) without explicit markers
+// using fixed 4-line chunks and exact matching (StrategyChunked)
 func detectImplicitSections(lines []string) []Section {
 	// Simple heuristic: group lines into 4-line chunks and look for repetition
-	// More sophisticated algorithms could use edit distance
 
 	var sections []Section
 	chunkSize := 4
@@ -0,0 +1,135 @@
+package lyrics
+
+import "testing"
+
+// wantSection is the type, number and lines expected of a parsed section
+type wantSection struct {
+	typ    string
+	number int
+	lines  []string
+}
+
+func TestDetectSectionsMarkers(t *testing.T) {
+	tests := []struct {
+		name   string
+		lyrics string
+		want   []wantSection
+	}{
+		{
+			name: "pre-chorus",
+			lyrics: `[Verse 1]
+Walking down the road
+[Pre-Chorus]
+Here it comes
+[Chorus]
+Sing it loud`,
+			want: []wantSection{
+				{"verse", 1, []string{"Walking down the road"}},
+				{"pre-chorus", 1, []string{"Here it comes"}},
+				{"chorus", 1, []string{"Sing it loud"}},
+			},
+		},
+		{
+			name: "prechorus and pre chorus spellings",
+			lyrics: `[Prechorus]
+Here it comes
+[Chorus]
+Sing it loud
+[Pre Chorus]
+Here it comes again
+[chorus]
+Sing it louder`,
+			want: []wantSection{
+				{"pre-chorus", 1, []string{"Here it comes"}},
+				{"chorus", 1, []string{"Sing it loud"}},
+				{"pre-chorus", 2, []string{"Here it comes again"}},
+				{"chorus", 2, []string{"Sing it louder"}},
+			},
+		},
+		{
+			name: "final chorus",
+			lyrics: `[Chorus]
+Sing it loud
+[Bridge]
+Slow it down
+[Final Chorus]
+Sing it one last time`,
+			want: []wantSection{
+				{"chorus", 1, []string{"Sing it loud"}},
+				{"bridge", 1, []string{"Slow it down"}},
+				{"final-chorus", 1, []string{"Sing it one last time"}},
+			},
+		},
+		{
+			name: "outro-chorus",
+			lyrics: `[Verse]
+Walking down the road
+[Outro-Chorus]
+Fading out`,
+			want: []wantSection{
+				{"verse", 1, []string{"Walking down the road"}},
+				{"final-chorus", 1, []string{"Fading out"}},
+			},
+		},
+		{
+			name: "chorus with repeat count",
+			lyrics: `[Verse 1]
+Walking down the road
+[Chorus x2]
+Sing it loud
+Sing it proud
+[Verse 2]
+Back again
+[Final Chorus (x2)]
+Sing it one last time`,
+			want: []wantSection{
+				{"verse", 1, []string{"Walking down the road"}},
+				{"chorus", 1, []string{"Sing it loud", "Sing it proud"}},
+				{"verse", 2, []string{"Back again"}},
+				{"final-chorus", 1, []string{"Sing it one last time"}},
+			},
+		},
+		{
+			name: "intro and outro",
+			lyrics: `[Intro]
+Hum along
+[Verse]
+Walking down the road
+[Outro]
+Goodnight`,
+			want: []wantSection{
+				{"intro", 1, []string{"Hum along"}},
+				{"verse", 1, []string{"Walking down the road"}},
+				{"outro", 1, []string{"Goodnight"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseLyrics(tt.lyrics)
+			if err != nil {
+				t.Fatalf("ParseLyrics: %v", err)
+			}
+			if len(data.Sections) != len(tt.want) {
+				t.Fatalf("got %d sections %v, want %d", len(data.Sections), sectionTypes(data.Sections), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := data.Sections[i]
+				if got.Type != want.typ || got.Number != want.number {
+					t.Errorf("section %d = %s %d, want %s %d", i, got.Type, got.Number, want.typ, want.number)
+				}
+				if len(got.Lines) != len(want.lines) {
+					t.Errorf("section %d lines = %q, want %q", i, got.Lines, want.lines)
+					continue
+				}
+				for j := range want.lines {
+					if got.Lines[j] != want.lines[j] {
+						t.Errorf("section %d lines = %q, want %q", i, got.Lines, want.lines)
+						break
+					}
+				}
+			}
+		})
+	}
+}
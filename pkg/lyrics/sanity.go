@@ -0,0 +1,72 @@
+package lyrics
+
+import "fmt"
+
+// Thresholds for EstimateTimingSanity
+const (
+	MaxLinesPerMinute = 60.0 // Denser than this and lines flash by too fast to read
+	MinLinesPerMinute = 4.0  // Sparser than this suggests lyrics are missing
+	MaxTrailingGap    = 45.0 // Seconds of silence allowed after the last lyric
+	overrunTolerance  = 1.0  // Seconds lyrics may run past the end of the song
+)
+
+// TimingIssue describes an implausible relationship between lyrics and audio
+type TimingIssue struct {
+	Code    string `json:"code"` // too_dense, too_sparse, ends_early, overruns
+	Message string `json:"message"`
+}
+
+// LinesPerMinute returns the lyric density for a song of the given duration
+func LinesPerMinute(timedLines []TimedLine, duration float64) float64 {
+	if duration <= 0 {
+		return 0
+	}
+	return float64(len(timedLines)) / (duration / 60)
+}
+
+// EstimateTimingSanity checks timed lyrics against the audio duration and
+// returns any issues suggesting the lyrics don't belong to this audio, such
+// as far too many lines for its length or lyrics ending long before the song
+// does. It returns nil when timing looks plausible.
+func EstimateTimingSanity(timedLines []TimedLine, duration float64) []TimingIssue {
+	if len(timedLines) == 0 || duration <= 0 {
+		return nil
+	}
+
+	var issues []TimingIssue
+
+	lpm := LinesPerMinute(timedLines, duration)
+	switch {
+	case lpm > MaxLinesPerMinute:
+		issues = append(issues, TimingIssue{
+			Code:    "too_dense",
+			Message: fmt.Sprintf("%d lines in %.0fs is %.0f lines per minute (max %.0f); lyrics may belong to a longer song", len(timedLines), duration, lpm, MaxLinesPerMinute),
+		})
+	case lpm < MinLinesPerMinute && duration >= 60:
+		issues = append(issues, TimingIssue{
+			Code:    "too_sparse",
+			Message: fmt.Sprintf("%d lines in %.0fs is %.1f lines per minute (min %.0f); lyrics may be incomplete", len(timedLines), duration, lpm, MinLinesPerMinute),
+		})
+	}
+
+	lastEnd := 0.0
+	for _, line := range timedLines {
+		if line.EndTime > lastEnd {
+			lastEnd = line.EndTime
+		}
+	}
+	if gap := duration - lastEnd; gap > MaxTrailingGap {
+		issues = append(issues, TimingIssue{
+			Code:    "ends_early",
+			Message: fmt.Sprintf("last lyric ends at %.1fs, %.0fs before the song ends at %.1fs", lastEnd, gap, duration),
+		})
+	}
+	if lastEnd > duration+overrunTolerance {
+		issues = append(issues, TimingIssue{
+			Code:    "overruns",
+			Message: fmt.Sprintf("last lyric ends at %.1fs, after the song ends at %.1fs", lastEnd, duration),
+		})
+	}
+
+	return issues
+}
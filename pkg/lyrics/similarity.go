@@ -0,0 +1,237 @@
+package lyrics
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DetectionStrategy selects how sections are detected when lyrics have no explicit markers
+type DetectionStrategy string
+
+const (
+	// StrategySimilarity groups lines into blank-line separated blocks and clusters
+	// near-identical blocks (edit distance / word overlap) into choruses
+	StrategySimilarity DetectionStrategy = "similarity"
+
+	// StrategyChunked is the original fixed 4-line chunking with exact matching
+	StrategyChunked DetectionStrategy = "chunked"
+)
+
+// Similarity thresholds for treating two blocks as the same section
+const (
+	blockJaccardThreshold     = 0.6
+	blockLevenshteinThreshold = 0.8
+)
+
+// ParseOptions controls how lyrics are parsed
+type ParseOptions struct {
+	Strategy DetectionStrategy
+}
+
+// DefaultParseOptions returns the default parse options
+func DefaultParseOptions() *ParseOptions {
+	return &ParseOptions{
+		Strategy: StrategySimilarity,
+	}
+}
+
+// lyricBlock is a run of consecutive lyric lines, indexed into the cleaned line list
+type lyricBlock struct {
+	start int
+	end   int // inclusive
+	lines []string
+}
+
+// detectSimilaritySections detects sections by blank-line grouping and fuzzy repetition
+func detectSimilaritySections(rawLyrics string, lines []string) []Section {
+	blocks := splitBlocks(rawLyrics)
+
+	// No blank-line structure to work with - fall back to 4-line blocks
+	if len(blocks) <= 1 {
+		blocks = nil
+		for i := 0; i < len(lines); i += 4 {
+			end := i + 4
+			if end > len(lines) {
+				end = len(lines)
+			}
+			blocks = append(blocks, lyricBlock{start: i, end: end - 1, lines: lines[i:end]})
+		}
+	}
+
+	// Cluster similar blocks together
+	clusterOf := make([]int, len(blocks))
+	var clusterSizes []int
+	var representatives []int
+	for i, block := range blocks {
+		clusterOf[i] = -1
+		for c, rep := range representatives {
+			if blocksSimilar(blocks[rep].lines, block.lines) {
+				clusterOf[i] = c
+				clusterSizes[c]++
+				break
+			}
+		}
+		if clusterOf[i] == -1 {
+			clusterOf[i] = len(representatives)
+			representatives = append(representatives, i)
+			clusterSizes = append(clusterSizes, 1)
+		}
+	}
+
+	// The most repeated cluster is the chorus
+	chorusCluster := -1
+	maxSize := 1
+	for c, size := range clusterSizes {
+		if size > maxSize {
+			maxSize = size
+			chorusCluster = c
+		}
+	}
+
+	var sections []Section
+	verseNum := 0
+	chorusNum := 0
+	for i, block := range blocks {
+		section := Section{
+			StartLine: block.start,
+			EndLine:   block.end,
+			Lines:     block.lines,
+		}
+		if clusterOf[i] == chorusCluster {
+			chorusNum++
+			section.Type = "chorus"
+			section.Number = chorusNum
+		} else {
+			verseNum++
+			section.Type = "verse"
+			section.Number = verseNum
+		}
+		sections = append(sections, section)
+	}
+
+	return sections
+}
+
+// splitBlocks groups non-empty lines of the original text into blank-line separated blocks
+func splitBlocks(rawLyrics string) []lyricBlock {
+	var blocks []lyricBlock
+	var current *lyricBlock
+	index := 0
+
+	for _, line := range strings.Split(rawLyrics, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			if current != nil {
+				blocks = append(blocks, *current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil {
+			current = &lyricBlock{start: index}
+		}
+		current.lines = append(current.lines, trimmed)
+		current.end = index
+		index++
+	}
+
+	if current != nil {
+		blocks = append(blocks, *current)
+	}
+
+	return blocks
+}
+
+// blocksSimilar reports whether two blocks are likely the same section with minor variations
+func blocksSimilar(a, b []string) bool {
+	normA := normalizeLines(a)
+	normB := normalizeLines(b)
+	if normA == "" || normB == "" {
+		return false
+	}
+
+	if jaccardSimilarity(normA, normB) >= blockJaccardThreshold {
+		return true
+	}
+
+	return levenshteinRatio(normA, normB) >= blockLevenshteinThreshold
+}
+
+// normalizeLines lowercases and strips punctuation so minor variations compare equal
+func normalizeLines(lines []string) string {
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		for _, r := range strings.ToLower(line) {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+				b.WriteRune(r)
+			}
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// jaccardSimilarity returns the word-set overlap of two normalized strings
+func jaccardSimilarity(a, b string) float64 {
+	setA := make(map[string]bool)
+	for _, w := range strings.Fields(a) {
+		setA[w] = true
+	}
+	setB := make(map[string]bool)
+	for _, w := range strings.Fields(b) {
+		setB[w] = true
+	}
+
+	intersection := 0
+	for w := range setA {
+		if setB[w] {
+			intersection++
+		}
+	}
+
+	union := len(setA) + len(setB) - intersection
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
+
+// levenshteinRatio returns 1 - (edit distance / longer length)
+func levenshteinRatio(a, b string) float64 {
+	ra := []rune(a)
+	rb := []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein computes the edit distance between two rune slices
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,177 @@
+package lyrics
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Public domain lyrics without section markers; the last chorus of each
+// varies slightly, as sung choruses often do
+const (
+	clementineLyrics = `In a cavern, in a canyon,
+Excavating for a mine,
+Dwelt a miner, forty-niner,
+And his daughter Clementine.
+
+Oh my darling, oh my darling,
+Oh my darling, Clementine!
+You are lost and gone forever,
+Dreadful sorry, Clementine.
+
+Light she was and like a fairy,
+And her shoes were number nine,
+Herring boxes, without topses,
+Sandals were for Clementine.
+
+Oh my darling, oh my darling,
+Oh my darling, Clementine!
+You are lost and gone forever,
+Dreadful sorry, Clementine.
+
+Drove she ducklings to the water
+Ev'ry morning just at nine,
+Hit her foot against a splinter,
+Fell into the foaming brine.
+
+Oh my darlin', oh my darlin',
+Oh my darlin' Clementine,
+Thou art lost and gone forever,
+Dreadful sorry, Clementine.`
+
+	susannaLyrics = `I come from Alabama with a banjo on my knee,
+I'm going to Louisiana, my true love for to see.
+It rained all night the day I left, the weather it was dry,
+The sun so hot I froze to death; Susanna, don't you cry.
+
+Oh! Susanna, oh don't you cry for me,
+For I come from Alabama with a banjo on my knee.
+
+I had a dream the other night when everything was still,
+I thought I saw Susanna a-coming down the hill.
+The buckwheat cake was in her mouth, the tear was in her eye,
+Says I, I'm coming from the south, Susanna, don't you cry.
+
+Oh, Susanna, oh don't you cry for me,
+'Cause I come from Alabama with my banjo on my knee.`
+)
+
+// sectionTypes returns the type of each section in order
+func sectionTypes(sections []Section) []string {
+	types := make([]string, len(sections))
+	for i, section := range sections {
+		types[i] = section.Type
+	}
+	return types
+}
+
+func TestDetectSimilaritySections(t *testing.T) {
+	tests := []struct {
+		name   string
+		lyrics string
+		want   []string
+	}{
+		{
+			name:   "clementine",
+			lyrics: clementineLyrics,
+			want:   []string{"verse", "chorus", "verse", "chorus", "verse", "chorus"},
+		},
+		{
+			name:   "oh susanna",
+			lyrics: susannaLyrics,
+			want:   []string{"verse", "chorus", "verse", "chorus"},
+		},
+		{
+			name: "no repeats",
+			lyrics: `Amazing grace, how sweet the sound
+That saved a wretch like me.
+
+I once was lost, but now am found,
+Was blind, but now I see.`,
+			want: []string{"verse", "verse"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := ParseLyrics(tt.lyrics)
+			if err != nil {
+				t.Fatalf("ParseLyrics: %v", err)
+			}
+			if got := sectionTypes(data.Sections); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("section types = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectSimilaritySectionsNumbering(t *testing.T) {
+	data, err := ParseLyrics(clementineLyrics)
+	if err != nil {
+		t.Fatalf("ParseLyrics: %v", err)
+	}
+
+	want := []struct {
+		typ        string
+		number     int
+		start, end int
+	}{
+		{"verse", 1, 0, 3},
+		{"chorus", 1, 4, 7},
+		{"verse", 2, 8, 11},
+		{"chorus", 2, 12, 15},
+		{"verse", 3, 16, 19},
+		{"chorus", 3, 20, 23},
+	}
+	if len(data.Sections) != len(want) {
+		t.Fatalf("got %d sections, want %d", len(data.Sections), len(want))
+	}
+	for i, w := range want {
+		s := data.Sections[i]
+		if s.Type != w.typ || s.Number != w.number || s.StartLine != w.start || s.EndLine != w.end {
+			t.Errorf("section %d = %s %d (lines %d-%d), want %s %d (lines %d-%d)",
+				i, s.Type, s.Number, s.StartLine, s.EndLine, w.typ, w.number, w.start, w.end)
+		}
+	}
+}
+
+func TestDetectSimilaritySectionsWithoutBlankLines(t *testing.T) {
+	// Without blank lines the lyrics fall back to 4-line blocks
+	lyrics := `In a cavern, in a canyon,
+Excavating for a mine,
+Dwelt a miner, forty-niner,
+And his daughter Clementine.
+Oh my darling, oh my darling,
+Oh my darling, Clementine!
+You are lost and gone forever,
+Dreadful sorry, Clementine.
+Light she was and like a fairy,
+And her shoes were number nine,
+Herring boxes, without topses,
+Sandals were for Clementine.
+Oh my darling, oh my darling,
+Oh my darling, Clementine!
+You are lost and gone forever,
+Dreadful sorry, Clementine.`
+
+	data, err := ParseLyrics(lyrics)
+	if err != nil {
+		t.Fatalf("ParseLyrics: %v", err)
+	}
+	want := []string{"verse", "chorus", "verse", "chorus"}
+	if got := sectionTypes(data.Sections); !reflect.DeepEqual(got, want) {
+		t.Errorf("section types = %v, want %v", got, want)
+	}
+}
+
+func TestChunkedStrategyRequiresExactRepeats(t *testing.T) {
+	// The original strategy only matches identical choruses, so the varied
+	// last chorus of Clementine is a verse
+	data, err := ParseLyricsWithOptions(clementineLyrics, &ParseOptions{Strategy: StrategyChunked})
+	if err != nil {
+		t.Fatalf("ParseLyricsWithOptions: %v", err)
+	}
+	want := []string{"verse", "chorus", "verse", "chorus", "verse", "verse"}
+	if got := sectionTypes(data.Sections); !reflect.DeepEqual(got, want) {
+		t.Errorf("section types = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "trackstudio"
+
+var (
+	// JobsProcessed counts queue items that finished processing, by result
+	JobsProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_processed_total",
+		Help:      "Total queue items processed, labeled by result (completed, failed).",
+	}, []string{"result"})
+
+	// QueueItems tracks the number of queue items by status
+	QueueItems = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_items",
+		Help:      "Current number of queue items by status.",
+	}, []string{"status"})
+
+	// PhaseDuration observes how long each pipeline phase takes
+	PhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "phase_duration_seconds",
+		Help:      "Duration of each processing pipeline phase.",
+		Buckets:   []float64{1, 5, 15, 30, 60, 120, 300, 600, 1200, 2400},
+	}, []string{"phase"})
+
+	// ImageGenerationDuration observes CQAI image generation latency
+	ImageGenerationDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "image_generation_seconds",
+		Help:      "Latency of CQAI image generation requests.",
+		Buckets:   []float64{5, 15, 30, 60, 90, 120, 180, 300},
+	})
+
+	// RenderDuration observes the FFmpeg video render duration
+	RenderDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "render_duration_seconds",
+		Help:      "Duration of FFmpeg video renders.",
+		Buckets:   []float64{30, 60, 120, 300, 600, 900, 1800, 3600},
+	})
+
+	// ExternalRequests counts requests to downstream AI services, by service and result
+	ExternalRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "external_requests_total",
+		Help:      "Requests to CQAI image and LLM services, labeled by service and result (ok, error).",
+	}, []string{"service", "result"})
+
+	// ProgressUpdatesDropped counts progress updates dropped for slow SSE clients
+	ProgressUpdatesDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "progress_updates_dropped_total",
+		Help:      "Progress updates dropped because a subscriber's buffer was full.",
+	})
+
+	// ProgressClientsDisconnected counts SSE clients disconnected for not reading updates
+	ProgressClientsDisconnected = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "progress_clients_disconnected_total",
+		Help:      "Progress subscribers disconnected after too many consecutive dropped updates.",
+	})
+)
+
+// Register registers all collectors with the default Prometheus registry
+func Register() {
+	prometheus.MustRegister(
+		JobsProcessed,
+		QueueItems,
+		PhaseDuration,
+		ImageGenerationDuration,
+		RenderDuration,
+		ExternalRequests,
+		ProgressUpdatesDropped,
+		ProgressClientsDisconnected,
+	)
+}
+
+// ObserveRequest records the outcome of a downstream request
+func ObserveRequest(service string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	ExternalRequests.WithLabelValues(service, result).Inc()
+}
+
+// ObserveDuration records the time elapsed since start on a histogram
+func ObserveDuration(h prometheus.Observer, start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,185 @@
+// Package pyenv diagnoses the Python environment that audio analysis and
+// karaoke generation shell out to, so problems surface at startup and in the
+// readiness endpoint instead of deep inside a render.
+package pyenv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// moduleCheckTimeout bounds the Python subprocess that looks up modules
+const moduleCheckTimeout = 10 * time.Second
+
+// Check is the result of a single environment diagnostic
+type Check struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"` // What to do when the check fails
+}
+
+// Report collects the diagnostics for one component's Python environment
+type Report struct {
+	Component string  `json:"component"`
+	Python    string  `json:"python"`
+	Ready     bool    `json:"ready"`
+	Checks    []Check `json:"checks"`
+}
+
+// NewReport starts a report for a component run with the given interpreter
+func NewReport(component, python string) *Report {
+	return &Report{Component: component, Python: python, Ready: true}
+}
+
+// Add records a check, marking the report not ready if it failed
+func (r *Report) Add(name, detail, fix string, ok bool) {
+	check := Check{Name: name, OK: ok, Detail: detail}
+	if !ok {
+		check.Fix = fix
+		r.Ready = false
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// CheckBinary verifies an executable is on PATH (or exists, for a path)
+func (r *Report) CheckBinary(name, fix string) bool {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		r.Add(name, fmt.Sprintf("%s not found", name), fix, false)
+		return false
+	}
+	r.Add(name, path, fix, true)
+	return true
+}
+
+// CheckFile verifies a script or other required file exists
+func (r *Report) CheckFile(name, path, fix string) bool {
+	if _, err := os.Stat(path); err != nil {
+		r.Add(name, fmt.Sprintf("%s not found", path), fix, false)
+		return false
+	}
+	r.Add(name, path, fix, true)
+	return true
+}
+
+// CheckModules verifies the interpreter can import each module. Modules are
+// located with importlib rather than imported, so heavy packages stay cheap.
+func (r *Report) CheckModules(fix string, modules ...string) {
+	if _, err := exec.LookPath(r.Python); err != nil {
+		for _, module := range modules {
+			r.Add(module, "python interpreter unavailable", fix, false)
+		}
+		return
+	}
+
+	missing, err := r.missingModules(modules)
+	if err != nil {
+		for _, module := range modules {
+			r.Add(module, fmt.Sprintf("module check failed: %v", err), fix, false)
+		}
+		return
+	}
+
+	for _, module := range modules {
+		if contains(missing, module) {
+			r.Add(module, "module not installed", fix, false)
+		} else {
+			r.Add(module, "installed", fix, true)
+		}
+	}
+}
+
+// CheckAnyModule verifies the interpreter can import at least one of
+// several interchangeable modules, recording a single check. It returns the
+// first installed module, or "" when none is.
+func (r *Report) CheckAnyModule(fix string, modules ...string) string {
+	name := strings.Join(modules, " or ")
+	if _, err := exec.LookPath(r.Python); err != nil {
+		r.Add(name, "python interpreter unavailable", fix, false)
+		return ""
+	}
+
+	missing, err := r.missingModules(modules)
+	if err != nil {
+		r.Add(name, fmt.Sprintf("module check failed: %v", err), fix, false)
+		return ""
+	}
+
+	for _, module := range modules {
+		if !contains(missing, module) {
+			r.Add(name, module+" installed", fix, true)
+			return module
+		}
+	}
+	r.Add(name, "none installed", fix, false)
+	return ""
+}
+
+// missingModules returns the modules the interpreter can't find
+func (r *Report) missingModules(modules []string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), moduleCheckTimeout)
+	defer cancel()
+
+	script := `import importlib.util, json, sys
+print(json.dumps([m for m in sys.argv[1:] if importlib.util.find_spec(m) is None]))`
+	output, err := exec.CommandContext(ctx, r.Python, append([]string{"-c", script}, modules...)...).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	if err := json.Unmarshal(output, &missing); err != nil {
+		return nil, err
+	}
+	return missing, nil
+}
+
+// Missing returns the names of the failed checks
+func (r *Report) Missing() []string {
+	var missing []string
+	for _, check := range r.Checks {
+		if !check.OK {
+			missing = append(missing, check.Name)
+		}
+	}
+	return missing
+}
+
+// Err returns nil when ready, or an error naming what is missing
+func (r *Report) Err() error {
+	if r.Ready {
+		return nil
+	}
+	return fmt.Errorf("python environment not ready: missing %s", strings.Join(r.Missing(), ", "))
+}
+
+// Log writes the report, with a fix for each failed check
+func (r *Report) Log() {
+	if r.Ready {
+		log.Printf("Python environment for %s: ready (%s)", r.Component, r.Python)
+		return
+	}
+
+	log.Printf("WARNING: Python environment for %s is NOT ready (%s):", r.Component, r.Python)
+	for _, check := range r.Checks {
+		if !check.OK {
+			log.Printf("  ✗ %s: %s - %s", check.Name, check.Detail, check.Fix)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
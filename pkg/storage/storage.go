@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/AndrewDonelson/track-studio-orchestrator/config"
+)
+
+// Backend names accepted in config
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// Storage stores artifacts (videos, images, audio) by key, where a key is a
+// slash-separated path relative to the data directory, e.g. "videos/song.mp4"
+type Storage interface {
+	// Put stores size bytes from r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Exists reports whether key is stored
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete removes key; deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+	// URL returns a URL clients can fetch key from, valid for at least expiry
+	URL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// New creates the storage backend selected by config
+func New(cfg *config.Config, dataPath string) (Storage, error) {
+	switch cfg.StorageBackend {
+	case "", BackendLocal:
+		return NewLocalStorage(dataPath), nil
+	case BackendS3:
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.StorageBackend)
+	}
+}
+
+// IsRemote reports whether artifacts must be copied off local disk
+func IsRemote(s Storage) bool {
+	_, local := s.(*LocalStorage)
+	return !local
+}
+
+// KeyFor converts a local path under dataPath into a storage key
+func KeyFor(dataPath, localPath string) (string, error) {
+	rel, err := filepath.Rel(dataPath, localPath)
+	if err != nil {
+		return "", err
+	}
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the data path", localPath)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// PutFile stores a local file under key
+func PutFile(ctx context.Context, s Storage, key, localPath, contentType string) error {
+	// Local storage already has the file in place
+	if local, ok := s.(*LocalStorage); ok && local.path(key) == filepath.Clean(localPath) {
+		return nil
+	}
+
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, key, f, info.Size(), contentType)
+}
+
+// Mirror copies a file under dataPath to remote storage, keyed by its path
+// relative to dataPath. It is a no-op for local storage.
+func Mirror(ctx context.Context, s Storage, dataPath, localPath string) error {
+	if s == nil || !IsRemote(s) {
+		return nil
+	}
+	key, err := KeyFor(dataPath, localPath)
+	if err != nil {
+		return err
+	}
+	return PutFile(ctx, s, key, localPath, mime.TypeByExtension(filepath.Ext(localPath)))
+}
+
+// ValidKey rejects empty, absolute and parent-relative keys
+func ValidKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
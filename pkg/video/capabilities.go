@@ -0,0 +1,191 @@
+package video
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// capabilityProbeTimeout bounds each ffmpeg invocation of the capability probe
+const capabilityProbeTimeout = 10 * time.Second
+
+// Oldest FFmpeg release with every filter the renderer uses (xfade arrived in 4.3)
+const (
+	minFFmpegMajor = 4
+	minFFmpegMinor = 3
+)
+
+// requiredFilters are used by every render; without them rendering fails
+var requiredFilters = []string{
+	"scale", "pad", "crop", "split", "boxblur", "overlay", "concat",
+	"format", "colorchannelmixer", "eq", "drawtext", "drawbox",
+	"aformat", "channelsplit", "showfreqs", "transpose", "hflip", "vflip", "amix",
+}
+
+// optionalFilters are only used by some options; when one is missing the
+// renderer substitutes the fallback instead of failing
+var optionalFilters = []struct {
+	name     string
+	fallback string
+}{
+	{"xfade", "hard cuts between images"},
+	{"zoompan", "still images without Ken Burns motion"},
+	{"subtitles", "lyrics drawn with drawtext"},
+	{"showcqt", "stereo spectrum"},
+	{"showspectrum", "stereo spectrum"},
+	{"showwaves", "stereo spectrum"},
+	{"showvolume", "stereo spectrum"},
+	{"avectorscope", "stereo spectrum"},
+	{"acrossfade", "hard cut to the outro card"},
+	{"fade", "intro card and song without fades to black"},
+	{"afade", "song without audio fades"},
+}
+
+var ffmpegVersionPattern = regexp.MustCompile(`^ffmpeg version n?(\d+)\.(\d+)`)
+
+// Capabilities describes the installed FFmpeg: its version and which of the
+// filters the renderer uses are available
+type Capabilities struct {
+	Version         string   `json:"version"`
+	VersionOK       bool     `json:"version_ok"` // At least the minimum, or a build whose version can't be parsed
+	FilterCount     int      `json:"filter_count"`
+	MissingRequired []string `json:"missing_required,omitempty"`
+	MissingOptional []string `json:"missing_optional,omitempty"`
+
+	filters map[string]bool
+}
+
+// Ready reports whether every required filter is available
+func (c *Capabilities) Ready() bool {
+	return len(c.MissingRequired) == 0
+}
+
+// HasFilter reports whether FFmpeg provides the named filter
+func (c *Capabilities) HasFilter(name string) bool {
+	return c.filters[name]
+}
+
+// Log writes a readable summary of the capabilities, naming the fallback
+// used for each missing optional filter
+func (c *Capabilities) Log() {
+	log.Printf("FFmpeg %s: %d filters available", c.Version, c.FilterCount)
+	if !c.VersionOK {
+		log.Printf("WARNING: FFmpeg %s is older than %d.%d; some render options may fail", c.Version, minFFmpegMajor, minFFmpegMinor)
+	}
+	if len(c.MissingRequired) > 0 {
+		log.Printf("WARNING: FFmpeg is missing required filters: %s; renders will fail", strings.Join(c.MissingRequired, ", "))
+	}
+	for _, filter := range optionalFilters {
+		if !c.HasFilter(filter.name) {
+			log.Printf("Warning: FFmpeg filter %s unavailable, using %s instead", filter.name, filter.fallback)
+		}
+	}
+}
+
+var (
+	capabilitiesMu sync.Mutex
+	capabilities   *Capabilities
+)
+
+// DetectCapabilities runs `ffmpeg -version` and `ffmpeg -filters` once at
+// startup and records the result for the renderer, which then swaps in
+// fallbacks for missing optional filters. It returns an error when FFmpeg
+// can't be run.
+func DetectCapabilities() (*Capabilities, error) {
+	versionOutput, err := runFFmpeg("-hide_banner", "-version")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg -version failed: %w", err)
+	}
+	filtersOutput, err := runFFmpeg("-hide_banner", "-filters")
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg -filters failed: %w", err)
+	}
+
+	caps := &Capabilities{filters: parseFilters(filtersOutput)}
+	caps.Version, caps.VersionOK = parseFFmpegVersion(versionOutput)
+	caps.FilterCount = len(caps.filters)
+	for _, name := range requiredFilters {
+		if !caps.filters[name] {
+			caps.MissingRequired = append(caps.MissingRequired, name)
+		}
+	}
+	for _, filter := range optionalFilters {
+		if !caps.filters[filter.name] {
+			caps.MissingOptional = append(caps.MissingOptional, filter.name)
+		}
+	}
+
+	capabilitiesMu.Lock()
+	capabilities = caps
+	capabilitiesMu.Unlock()
+	return caps, nil
+}
+
+// DetectedCapabilities returns the capabilities found at startup, or nil if
+// DetectCapabilities hasn't succeeded
+func DetectedCapabilities() *Capabilities {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	return capabilities
+}
+
+// hasFilter reports whether the renderer can use a filter. Before detection
+// every filter is assumed available, so renders behave as they always have.
+func hasFilter(name string) bool {
+	caps := DetectedCapabilities()
+	return caps == nil || caps.HasFilter(name)
+}
+
+// runFFmpeg runs ffmpeg with args and returns its standard output
+func runFFmpeg(args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), capabilityProbeTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, "ffmpeg", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// parseFFmpegVersion extracts the version from `ffmpeg -version` output and
+// whether it meets the minimum. Git builds ("N-113-g...") can't be compared
+// and are assumed recent.
+func parseFFmpegVersion(output string) (string, bool) {
+	firstLine, _, _ := strings.Cut(output, "\n")
+	fields := strings.Fields(firstLine)
+	version := "unknown"
+	if len(fields) >= 3 && fields[0] == "ffmpeg" && fields[1] == "version" {
+		version = fields[2]
+	}
+
+	match := ffmpegVersionPattern.FindStringSubmatch(firstLine)
+	if match == nil {
+		return version, true
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return version, major > minFFmpegMajor || (major == minFFmpegMajor && minor >= minFFmpegMinor)
+}
+
+// parseFilters reads filter names from `ffmpeg -filters` output, whose
+// entries look like " TSC xfade             VV->V      Cross fade ...".
+// Legend lines ("T.. = Timeline support") have no "->" column and are skipped.
+func parseFilters(output string) map[string]bool {
+	filters := make(map[string]bool)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && strings.Contains(fields[2], "->") {
+			filters[fields[1]] = true
+		}
+	}
+	return filters
+}
@@ -0,0 +1,96 @@
+package video
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+)
+
+// CountdownSecondsPlaceholder is replaced by the live seconds remaining in countdown text
+const CountdownSecondsPlaceholder = "{seconds}"
+
+// Intro countdown defaults, matching the original hardcoded overlay
+const (
+	DefaultCountdownThreshold = 2.0 // Intros this short or shorter get no countdown
+	DefaultCountdownBarWidth  = 600
+	DefaultCountdownColor     = "FFD700"
+	DefaultCountdownText      = "Starting in {seconds}s"
+	DefaultCountdownPosition  = 0.75 // Bar position as a fraction of the frame height from the top
+)
+
+var hexColorPattern = regexp.MustCompile(`^[0-9A-Fa-f]{6}$`)
+
+// IsHexColor reports whether color is hex RRGGBB, optionally with # or 0x
+func IsHexColor(color string) bool {
+	return hexColorPattern.MatchString(trimHexColor(color))
+}
+
+// CountdownConfig controls the progress bar and "Starting in Ns" text shown
+// before the vocals start. Zero values fall back to the defaults above, so a
+// zero CountdownConfig reproduces the original overlay.
+type CountdownConfig struct {
+	Hidden    bool    // Don't show the countdown at all
+	Threshold float64 // Minimum vocal onset in seconds before the countdown shows
+	BarWidth  int     // Progress bar width in pixels
+	Color     string  // Bar and text color as hex (RRGGBB, optionally with # or 0x)
+	Text      string  // Countdown text; {seconds} is replaced by the seconds remaining
+	Position  float64 // Bar position as a fraction of the frame height from the top
+}
+
+// withDefaults returns the config with zero values replaced by defaults
+func (c CountdownConfig) withDefaults() CountdownConfig {
+	if c.Threshold <= 0 {
+		c.Threshold = DefaultCountdownThreshold
+	}
+	if c.BarWidth <= 0 {
+		c.BarWidth = DefaultCountdownBarWidth
+	}
+	c.Color = strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(c.Color), "#"), "0x")
+	if c.Color == "" {
+		c.Color = DefaultCountdownColor
+	} else if !hexColorPattern.MatchString(c.Color) {
+		log.Printf("Warning: invalid countdown color %q, using %s", c.Color, DefaultCountdownColor)
+		c.Color = DefaultCountdownColor
+	}
+	if strings.TrimSpace(c.Text) == "" {
+		c.Text = DefaultCountdownText
+	}
+	if c.Position <= 0 || c.Position >= 1 {
+		c.Position = DefaultCountdownPosition
+	}
+	return c
+}
+
+// introCountdownFilters draws a progress bar and countdown before the vocals start
+func (vr *VideoRenderer) introCountdownFilters(vocalOnset float64, config CountdownConfig) []string {
+	if config.Hidden {
+		return nil
+	}
+	config = config.withDefaults()
+
+	// Only worth showing for intros (non-vocal sections) longer than the threshold
+	if vocalOnset <= config.Threshold {
+		return nil
+	}
+
+	progressBarY := int(float64(vr.Height) * config.Position)
+	progressFilter := fmt.Sprintf("drawbox=x=(w-%d)/2:y=%d:w=%d*min(1\\,t/%.2f):h=6:color=0x%s:enable=lt(t\\,%.2f)",
+		config.BarWidth, progressBarY, config.BarWidth, vocalOnset, config.Color, vocalOnset)
+
+	countdownFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=36:fontcolor=0x%s:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2:enable=lt(t\\,%.2f)",
+		vr.countdownText(config.Text, vocalOnset), progressBarY-40, config.Color, vr.fontPath(vr.overlayFamily(true)), vocalOnset)
+
+	return []string{progressFilter, countdownFilter}
+}
+
+// countdownText escapes countdown text for drawtext, expanding each
+// {seconds} placeholder to the live seconds remaining until vocalOnset
+func (vr *VideoRenderer) countdownText(text string, vocalOnset float64) string {
+	seconds := fmt.Sprintf("%%{eif\\:max(0\\,%.2f-t)\\:d}", vocalOnset)
+	parts := strings.Split(text, CountdownSecondsPlaceholder)
+	for i, part := range parts {
+		parts[i] = vr.escapeText(part)
+	}
+	return strings.Join(parts, seconds)
+}
@@ -0,0 +1,64 @@
+package video
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// debugStepPattern matches the step copies written to DebugDir
+const debugStepPattern = "step*_*"
+
+// resetDebugDir empties the debug directory of a previous render's steps, so
+// it only ever holds one render's sequence
+func (vr *VideoRenderer) resetDebugDir() {
+	if vr.DebugDir == "" {
+		return
+	}
+	if err := os.MkdirAll(vr.DebugDir, 0755); err != nil {
+		log.Printf("Warning: failed to create debug directory %s: %v", vr.DebugDir, err)
+		return
+	}
+	old, _ := filepath.Glob(filepath.Join(vr.DebugDir, debugStepPattern))
+	for _, path := range old {
+		os.Remove(path)
+	}
+	log.Printf("Saving render steps to %s", vr.DebugDir)
+}
+
+// SaveDebugStep copies a step's output into DebugDir as step<N>_<name><ext>
+// (step2_spectrum.mp4) when debug steps are on. Failures are logged, never
+// returned, so debugging can't fail a render.
+func (vr *VideoRenderer) SaveDebugStep(step int, name, path string) {
+	if vr.DebugDir == "" {
+		return
+	}
+	dest := filepath.Join(vr.DebugDir, fmt.Sprintf("step%d_%s%s", step, name, filepath.Ext(path)))
+	if err := copyFile(path, dest); err != nil {
+		log.Printf("Warning: failed to save render step %d (%s): %v", step, name, err)
+		return
+	}
+	log.Printf("Render step %d (%s) saved: %s", step, name, dest)
+}
+
+// copyFile copies src to dest, replacing dest
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,124 @@
+package video
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// Fade fades in at the start and out at the end, in seconds; zero skips
+// that fade
+type Fade struct {
+	In  float64
+	Out float64
+
+	// Length is when the song ends and the fade out finishes; zero uses the
+	// render duration
+	Length float64
+}
+
+// Fades are a render's audio fades (from and to silence) and picture fades
+// (from and to black), applied in the final encode over every overlay. Each
+// is set on its own; a zero Video leaves the picture alone.
+type Fades struct {
+	Audio Fade
+	Video Fade
+}
+
+// any reports whether anything fades
+func (f Fades) any() bool {
+	return f.Audio.In > 0 || f.Audio.Out > 0 || f.Video.In > 0 || f.Video.Out > 0
+}
+
+// ValidateFade checks a song's fade durations, named by field (audio_fade,
+// video_fade): neither may be negative, and together they must fit in the
+// song when its duration is known
+func ValidateFade(field string, fadeIn, fadeOut, duration float64) error {
+	if fadeIn < 0 || math.IsNaN(fadeIn) {
+		return fmt.Errorf("%s_in must not be negative", field)
+	}
+	if fadeOut < 0 || math.IsNaN(fadeOut) {
+		return fmt.Errorf("%s_out must not be negative", field)
+	}
+	if duration > 0 && fadeIn+fadeOut > duration {
+		return fmt.Errorf("%s_in and %s_out together (%.1fs) exceed the song's %.1fs duration", field, field, fadeIn+fadeOut, duration)
+	}
+	return nil
+}
+
+// fitted returns the fade with its length set and, if the fades don't fit in
+// it, shortened in proportion
+func (f Fade) fitted(length float64) Fade {
+	if f.Length <= 0 {
+		f.Length = length
+	}
+	f.In, f.Out = math.Max(f.In, 0), math.Max(f.Out, 0)
+	if total := f.In + f.Out; total > f.Length && total > 0 {
+		log.Printf("Warning: %.1fs of fades don't fit in %.1fs, shortening them", total, f.Length)
+		f.In *= f.Length / total
+		f.Out *= f.Length / total
+	}
+	return f
+}
+
+// filter builds the fade in and out for an audio (afade) or video (fade)
+// filter for a song starting start seconds into the stream, or "" when
+// there's nothing to fade or the filter is missing
+func (f Fade) filter(name string, start float64) string {
+	if f.In <= 0 && f.Out <= 0 {
+		return ""
+	}
+	if !hasFilter(name) {
+		log.Printf("Warning: FFmpeg has no %s filter, skipping fades", name)
+		return ""
+	}
+
+	filter := ""
+	if f.In > 0 {
+		filter = fmt.Sprintf("%s=t=in:st=%.3f:d=%.3f", name, start, f.In)
+	}
+	if f.Out > 0 {
+		if filter != "" {
+			filter += ","
+		}
+		filter += fmt.Sprintf("%s=t=out:st=%.3f:d=%.3f", name, start+f.Length-f.Out, f.Out)
+	}
+	return filter
+}
+
+// args returns -af and -vf arguments applying the fades to a render of the
+// given length. Both fade out over the same final seconds, whatever the
+// song's stored duration says.
+func (f Fades) args(length float64) []string {
+	audio := f.Audio
+	audio.Length = 0
+	picture := f.Video
+	picture.Length = 0
+
+	var args []string
+	if filter := audio.fitted(length).filter("afade", 0); filter != "" {
+		args = append(args, "-af", filter)
+	}
+	if filter := picture.fitted(length).filter("fade", 0); filter != "" {
+		args = append(args, "-vf", filter)
+	}
+	return args
+}
+
+// fadeLength returns how long the final encode of videoPath and audioPath
+// runs: the shorter of the two, capped at duration, so the fade out ends on
+// the last frame. duration is used when the inputs can't be probed.
+func fadeLength(videoPath, audioPath string, duration float64) float64 {
+	length := duration
+	for _, path := range []string{videoPath, audioPath} {
+		probe, err := ProbeVideo(path)
+		if err != nil || probe.Duration <= 0 {
+			log.Printf("Warning: couldn't probe %s for fade timing, using %.2fs", path, duration)
+			continue
+		}
+		if length <= 0 || probe.Duration < length {
+			length = probe.Duration
+		}
+	}
+	return length
+}
@@ -0,0 +1,312 @@
+package video
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// System fonts tried, in order, when no font path is configured
+var (
+	boldFontCandidates = []string{
+		"/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf",   // Debian/Ubuntu
+		"/usr/share/fonts/TTF/DejaVuSansCondensed-Bold.ttf",               // Arch
+		"/usr/share/fonts/dejavu-sans-fonts/DejaVuSansCondensed-Bold.ttf", // Fedora
+		"/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf",
+		"/System/Library/Fonts/Supplemental/Arial Bold.ttf", // macOS
+		"/Library/Fonts/Arial Bold.ttf",
+	}
+	regularFontCandidates = []string{
+		"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+		"/usr/share/fonts/TTF/DejaVuSans.ttf",
+		"/usr/share/fonts/dejavu-sans-fonts/DejaVuSans.ttf",
+		"/System/Library/Fonts/Supplemental/Arial.ttf",
+		"/Library/Fonts/Arial.ttf",
+	}
+)
+
+// fontconfig patterns used to find a substitute when no candidate exists
+const (
+	fcBoldPattern    = "sans-serif:bold"
+	fcRegularPattern = "sans-serif"
+)
+
+// Font families understood by fontPath besides registered font names
+const (
+	FontFamilyBold    = "bold"
+	FontFamilyRegular = "regular"
+)
+
+// SystemFonts are the fallback font files used when no registered font matches
+type SystemFonts struct {
+	Bold    string `json:"bold"`
+	Regular string `json:"regular"`
+}
+
+var (
+	systemFontsMu sync.Mutex
+	systemFonts   *SystemFonts
+)
+
+// ConfigureSystemFonts resolves the fallback fonts once at startup. Each
+// configured path is used if it exists; otherwise the known system locations
+// are tried, then fontconfig (fc-match). It returns an error naming any weight
+// with no usable font file, since drawtext renders will fail without one.
+func ConfigureSystemFonts(boldPath, regularPath string) (SystemFonts, error) {
+	var missing []string
+	bold, ok := resolveSystemFont("bold", boldPath, boldFontCandidates, fcBoldPattern)
+	if !ok {
+		missing = append(missing, "bold")
+	}
+	regular, ok := resolveSystemFont("regular", regularPath, regularFontCandidates, fcRegularPattern)
+	if !ok {
+		missing = append(missing, "regular")
+	}
+
+	fonts := SystemFonts{Bold: bold, Regular: regular}
+	systemFontsMu.Lock()
+	systemFonts = &fonts
+	systemFontsMu.Unlock()
+
+	if len(missing) > 0 {
+		return fonts, fmt.Errorf("no %s font file found (set FONT_BOLD_PATH/FONT_REGULAR_PATH or install fonts-dejavu/fontconfig)",
+			strings.Join(missing, " or "))
+	}
+	return fonts, nil
+}
+
+// DefaultSystemFonts returns the fallback fonts, resolving them from the
+// system if ConfigureSystemFonts was never called
+func DefaultSystemFonts() SystemFonts {
+	systemFontsMu.Lock()
+	defer systemFontsMu.Unlock()
+	if systemFonts == nil {
+		var fonts SystemFonts
+		fonts.Bold, _ = resolveSystemFont("bold", "", boldFontCandidates, fcBoldPattern)
+		fonts.Regular, _ = resolveSystemFont("regular", "", regularFontCandidates, fcRegularPattern)
+		systemFonts = &fonts
+	}
+	return *systemFonts
+}
+
+// resolveSystemFont picks the configured path, a known candidate or the
+// fontconfig match, in that order. If none exist the first candidate is
+// returned so FFmpeg reports which file it couldn't open.
+func resolveSystemFont(weight, configured string, candidates []string, pattern string) (string, bool) {
+	if configured != "" {
+		if fileExists(configured) {
+			return configured, true
+		}
+		log.Printf("Warning: configured %s font %s not found, looking for a substitute", weight, configured)
+	}
+
+	for _, path := range candidates {
+		if fileExists(path) {
+			return path, true
+		}
+	}
+
+	if path := fcMatch(pattern); path != "" {
+		log.Printf("Using fontconfig substitute for %s font: %s", weight, path)
+		return path, true
+	}
+
+	return candidates[0], false
+}
+
+// fcMatch asks fontconfig for the best TTF/OTF file matching pattern
+func fcMatch(pattern string) string {
+	output, err := exec.Command("fc-match", "--format=%{file}", pattern).Output()
+	if err != nil {
+		return ""
+	}
+	path := strings.TrimSpace(string(output))
+	if !fontExtensions[strings.ToLower(filepath.Ext(path))] || !fileExists(path) {
+		return ""
+	}
+	return path
+}
+
+// fontExtensions are the font formats FFmpeg drawtext and libass can load
+var fontExtensions = map[string]bool{
+	".ttf": true,
+	".otf": true,
+}
+
+// fontNamePattern limits registered font names to safe filenames
+var fontNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]{0,63}$`)
+
+// FontInfo describes a registered font file
+type FontInfo struct {
+	Name      string `json:"name"`
+	Filename  string `json:"filename"`
+	Path      string `json:"path"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// FontRegistry resolves font names to uploaded TTF/OTF files in a directory,
+// falling back to common system fonts. A nil registry only uses system fonts.
+type FontRegistry struct {
+	Dir string
+}
+
+// NewFontRegistry creates a font registry backed by dir
+func NewFontRegistry(dir string) *FontRegistry {
+	return &FontRegistry{Dir: dir}
+}
+
+// List returns the registered fonts sorted by name
+func (r *FontRegistry) List() ([]FontInfo, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(r.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fonts := []FontInfo{}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || !fontExtensions[ext] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		fonts = append(fonts, FontInfo{
+			Name:      strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())),
+			Filename:  entry.Name(),
+			Path:      filepath.Join(r.Dir, entry.Name()),
+			SizeBytes: info.Size(),
+		})
+	}
+
+	sort.Slice(fonts, func(i, j int) bool { return strings.ToLower(fonts[i].Name) < strings.ToLower(fonts[j].Name) })
+	return fonts, nil
+}
+
+// Lookup returns the file for a registered font name (case-insensitive)
+func (r *FontRegistry) Lookup(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if r == nil || name == "" {
+		return "", false
+	}
+
+	fonts, err := r.List()
+	if err != nil {
+		return "", false
+	}
+	for _, font := range fonts {
+		if strings.EqualFold(font.Name, name) {
+			return font.Path, true
+		}
+	}
+	return "", false
+}
+
+// Resolve returns the file for a registered font name, or the bold/regular
+// system fallback when the name is empty or not registered
+func (r *FontRegistry) Resolve(name string, bold bool) string {
+	if path, ok := r.Lookup(name); ok {
+		return path
+	}
+	if bold {
+		return DefaultSystemFonts().Bold
+	}
+	return DefaultSystemFonts().Regular
+}
+
+// Save stores a TTF/OTF font under name, replacing any font with the same name
+func (r *FontRegistry) Save(name, ext string, src io.Reader) (*FontInfo, error) {
+	ext = strings.ToLower(ext)
+	if !fontExtensions[ext] {
+		return nil, fmt.Errorf("unsupported font format %q (use .ttf or .otf)", ext)
+	}
+	if !fontNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid font name %q (letters, digits, spaces, '.', '_' and '-' only)", name)
+	}
+
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fonts directory: %w", err)
+	}
+
+	// Drop a same-named font in the other format so lookups stay unambiguous
+	if existing, ok := r.Lookup(name); ok {
+		os.Remove(existing)
+	}
+
+	path := filepath.Join(r.Dir, name+ext)
+	dest, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create font file: %w", err)
+	}
+	size, err := io.Copy(dest, src)
+	dest.Close()
+	if err != nil {
+		os.Remove(path)
+		return nil, fmt.Errorf("failed to write font file: %w", err)
+	}
+
+	return &FontInfo{Name: name, Filename: name + ext, Path: path, SizeBytes: size}, nil
+}
+
+// IsFontFile reports whether header starts with a TrueType/OpenType signature
+func IsFontFile(header []byte) bool {
+	if len(header) < 4 {
+		return false
+	}
+	switch string(header[:4]) {
+	case "\x00\x01\x00\x00", "OTTO", "true", "typ1":
+		return true
+	}
+	return false
+}
+
+// subtitlesFilter builds a libass subtitles filter that can also load
+// registered fonts by the family names used in the ASS styles
+func (vr *VideoRenderer) subtitlesFilter(assPath string) string {
+	filter := "subtitles=" + assPath
+	if vr.Fonts != nil {
+		if info, err := os.Stat(vr.Fonts.Dir); err == nil && info.IsDir() {
+			filter += ":fontsdir=" + vr.Fonts.Dir
+		}
+	}
+	return filter
+}
+
+// fontPath resolves a font family to a file for drawtext. The family is a
+// registered font name, FontFamilyBold or FontFamilyRegular; anything else
+// that isn't registered falls back to the bold system font.
+func (vr *VideoRenderer) fontPath(family string) string {
+	if path, ok := vr.Fonts.Lookup(family); ok {
+		return path
+	}
+	if family == FontFamilyRegular {
+		return DefaultSystemFonts().Regular
+	}
+	return DefaultSystemFonts().Bold
+}
+
+// overlayFamily returns the font family for title, metadata and countdown text
+func (vr *VideoRenderer) overlayFamily(bold bool) string {
+	if vr.OverlayFont != "" {
+		return vr.OverlayFont
+	}
+	if bold {
+		return FontFamilyBold
+	}
+	return FontFamilyRegular
+}
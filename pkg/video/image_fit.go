@@ -0,0 +1,46 @@
+package video
+
+import "fmt"
+
+// How background images whose aspect ratio differs from the frame are fitted
+const (
+	ImageFitLetterbox = "letterbox" // Scale to fit inside the frame with black bars (default)
+	ImageFitCrop      = "crop"      // Scale to cover the frame, cropping the overflow
+	ImageFitBlurFill  = "blur-fill" // Fit inside the frame over a blurred, cropped copy of the image
+)
+
+// imageFitBlur is the box blur radius for blur-fill backgrounds
+const imageFitBlur = 20
+
+// ImageFits lists the supported image fit modes
+var ImageFits = []string{ImageFitLetterbox, ImageFitCrop, ImageFitBlurFill}
+
+// NormalizeImageFit returns fit if supported, otherwise the default letterbox
+func NormalizeImageFit(fit string) string {
+	for _, f := range ImageFits {
+		if fit == f {
+			return fit
+		}
+	}
+	return ImageFitLetterbox
+}
+
+// fitFilter scales an input to the frame using the renderer's ImageFit mode.
+// Every variant has one input and one output, so it can be chained with
+// further filters.
+func (vr *VideoRenderer) fitFilter() string {
+	w, h := vr.Width, vr.Height
+	switch NormalizeImageFit(vr.ImageFit) {
+	case ImageFitCrop:
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d", w, h, w, h)
+	case ImageFitBlurFill:
+		return fmt.Sprintf("split=2[fitbg][fitfg];"+
+			"[fitbg]scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,boxblur=%d:2[fitblur];"+
+			"[fitfg]scale=%d:%d:force_original_aspect_ratio=decrease[fitimg];"+
+			"[fitblur][fitimg]overlay=(W-w)/2:(H-h)/2",
+			w, h, w, h, imageFitBlur, w, h)
+	default:
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:black",
+			w, h, w, h)
+	}
+}
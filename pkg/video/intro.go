@@ -0,0 +1,186 @@
+package video
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Intro card defaults
+const (
+	DefaultIntroDuration        = 4.0  // Seconds the title card is shown
+	MaxIntroDuration            = 30.0 // Longest intro a song may request
+	DefaultIntroBackgroundColor = "000000"
+	DefaultIntroTextColor       = "FFFFFF"
+	introFade                   = 0.5 // Fade in from and out to black, in seconds
+)
+
+// IntroCard is a title card shown before the song: the title, artist and
+// album centered over the album art (blurred and dimmed) or a solid color.
+// Zero values use the defaults above.
+type IntroCard struct {
+	Duration        float64 // Seconds
+	BackgroundColor string  // Hex RRGGBB (optionally with # or 0x), used without a background image
+	TextColor       string  // Hex RRGGBB (optionally with # or 0x)
+	BackgroundPath  string  // Optional image behind the text, e.g. album cover art
+
+	Title  string
+	Artist string
+	Album  string
+}
+
+// ValidateIntroCard checks a song's intro settings: the duration must be
+// between 0 and MaxIntroDuration and colors, when set, must be hex RRGGBB
+func ValidateIntroCard(duration float64, backgroundColor, textColor string) error {
+	if duration < 0 || duration > MaxIntroDuration || math.IsNaN(duration) {
+		return fmt.Errorf("intro_duration must be between 0 and %.0f seconds", MaxIntroDuration)
+	}
+	if backgroundColor != "" && !hexColorPattern.MatchString(trimHexColor(backgroundColor)) {
+		return fmt.Errorf("intro_background_color must be a hex color (RRGGBB)")
+	}
+	if textColor != "" && !hexColorPattern.MatchString(trimHexColor(textColor)) {
+		return fmt.Errorf("intro_text_color must be a hex color (RRGGBB)")
+	}
+	return nil
+}
+
+// trimHexColor strips a leading # or 0x from a hex color
+func trimHexColor(color string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(color), "#"), "0x")
+}
+
+// withDefaults returns the card with zero or invalid values replaced by defaults
+func (c IntroCard) withDefaults() IntroCard {
+	if c.Duration <= 0 || c.Duration > MaxIntroDuration {
+		c.Duration = DefaultIntroDuration
+	}
+	c.BackgroundColor = trimHexColor(c.BackgroundColor)
+	if !hexColorPattern.MatchString(c.BackgroundColor) {
+		c.BackgroundColor = DefaultIntroBackgroundColor
+	}
+	c.TextColor = trimHexColor(c.TextColor)
+	if !hexColorPattern.MatchString(c.TextColor) {
+		c.TextColor = DefaultIntroTextColor
+	}
+	if c.BackgroundPath != "" {
+		if _, err := os.Stat(c.BackgroundPath); err != nil {
+			log.Printf("Warning: intro background %s not found, using a solid color", c.BackgroundPath)
+			c.BackgroundPath = ""
+		}
+	}
+	return c
+}
+
+// introCardFilter draws the card's text over the background input, producing
+// the intro label
+func (vr *VideoRenderer) introCardFilter(card IntroCard, background string) string {
+	var filters []string
+	if card.BackgroundPath != "" {
+		// Fill the frame with the art, blurred and dimmed so the text stands out
+		filters = append(filters,
+			fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d", vr.Width, vr.Height, vr.Width, vr.Height),
+			"boxblur=20:5",
+			"eq=brightness=-0.25",
+		)
+	}
+
+	titleSize := vr.Height / 12
+	artistSize := vr.Height / 24
+	albumSize := vr.Height / 32
+	text := func(value string, size int, y string, bold bool) string {
+		return fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%s:fontsize=%d:fontcolor=0x%s:fontfile=%s:shadowcolor=black@0.7:shadowx=3:shadowy=3",
+			vr.escapeText(value), y, size, card.TextColor, vr.fontPath(vr.overlayFamily(bold)))
+	}
+	filters = append(filters, text(card.Title, titleSize, fmt.Sprintf("(h/2)-%d", titleSize), true))
+	if card.Artist != "" {
+		filters = append(filters, text(card.Artist, artistSize, fmt.Sprintf("(h/2)+%d", artistSize/2), false))
+	}
+	if card.Album != "" {
+		filters = append(filters, text(card.Album, albumSize, fmt.Sprintf("(h/2)+%d", artistSize*2), false))
+	}
+
+	if hasFilter("fade") {
+		filters = append(filters,
+			fmt.Sprintf("fade=t=in:st=0:d=%.2f", introFade),
+			fmt.Sprintf("fade=t=out:st=%.3f:d=%.2f", card.Duration-introFade, introFade),
+		)
+	}
+	filters = append(filters, fmt.Sprintf("fps=%d,format=yuv420p,setsar=1", vr.FPS))
+
+	return fmt.Sprintf("[%s]%s[intro]", background, strings.Join(filters, ","))
+}
+
+// PrependIntroCard adds a title card to the start of a rendered video in
+// place. The card is silent and cut (not crossfaded) into the song, so the
+// whole song, including its burned-in lyrics, starts exactly card.Duration
+// later; the soft subtitle track is offset by the same amount to stay in
+// sync. Tags are kept. It returns how many seconds the video grew by.
+func (vr *VideoRenderer) PrependIntroCard(videoPath string, card IntroCard) (float64, error) {
+	card = card.withDefaults()
+
+	main, err := ProbeVideo(videoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe video for intro: %w", err)
+	}
+
+	length := fmt.Sprintf("%.3f", card.Duration)
+	args := []string{"-i", videoPath}
+	if card.BackgroundPath != "" {
+		args = append(args, "-loop", "1", "-framerate", fmt.Sprintf("%d", vr.FPS), "-t", length, "-i", card.BackgroundPath)
+	} else {
+		args = append(args, "-f", "lavfi", "-t", length, "-i",
+			fmt.Sprintf("color=c=0x%s:s=%dx%d:r=%d", card.BackgroundColor, vr.Width, vr.Height, vr.FPS))
+	}
+	args = append(args, "-f", "lavfi", "-t", length, "-i", "anullsrc=channel_layout=stereo:sample_rate=48000")
+
+	// Subtitles come from a second read of the video shifted by the intro
+	hasSubtitles := main.HasStream("subtitle")
+	if hasSubtitles {
+		args = append(args, "-itsoffset", length, "-i", videoPath)
+	}
+
+	filters := []string{
+		vr.introCardFilter(card, "1:v"),
+		"[2:a]aformat=sample_rates=48000:channel_layouts=stereo[introaudio]",
+		fmt.Sprintf("[0:v]fps=%d,format=yuv420p,setsar=1[main]", vr.FPS),
+		"[0:a]aformat=sample_rates=48000:channel_layouts=stereo[mainaudio]",
+		"[intro][introaudio][main][mainaudio]concat=n=2:v=1:a=1[vout][aout]",
+	}
+	args = append(args,
+		"-filter_complex", strings.Join(filters, ";"),
+		"-map", "[vout]", "-map", "[aout]",
+	)
+
+	if hasSubtitles {
+		args = append(args, "-map", "3:s", "-c:s", "copy")
+	}
+
+	ext := filepath.Ext(videoPath)
+	tempPath := strings.TrimSuffix(videoPath, ext) + ".intro" + ext
+	args = append(args,
+		"-map_metadata", "0",
+		"-c:v", "libx264",
+		"-preset", vr.encodePreset().Preset,
+		"-crf", vr.encodePreset().CRF,
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-y", tempPath,
+	)
+
+	log.Printf("Prepending %.1fs intro card to %s", card.Duration, videoPath)
+	output, err := exec.Command("ffmpeg", args...).CombinedOutput()
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, fmt.Errorf("ffmpeg intro card failed: %w\nOutput: %s", err, string(output))
+	}
+	if err := os.Rename(tempPath, videoPath); err != nil {
+		os.Remove(tempPath)
+		return 0, fmt.Errorf("failed to replace video with intro version: %w", err)
+	}
+
+	return card.Duration, nil
+}
@@ -0,0 +1,90 @@
+package video
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// Ken Burns directions
+const (
+	KenBurnsZoomIn   = "in"        // Slowly zoom into the center (default)
+	KenBurnsZoomOut  = "out"       // Start zoomed in and slowly pull back
+	KenBurnsPanLeft  = "pan-left"  // Pan from right to left at a fixed zoom
+	KenBurnsPanRight = "pan-right" // Pan from left to right at a fixed zoom
+)
+
+// KenBurnsDirections lists the supported Ken Burns directions
+var KenBurnsDirections = []string{KenBurnsZoomIn, KenBurnsZoomOut, KenBurnsPanLeft, KenBurnsPanRight}
+
+// Ken Burns defaults
+const (
+	DefaultKenBurnsZoomRate = 0.01 // Zoom increase per second (1.0 = full frame)
+	KenBurnsMaxZoom         = 1.3  // Never zoom further than this, however long the image shows
+	kenBurnsMinPanZoom      = 1.1  // Pans need some zoom so there is room to move
+)
+
+// KenBurnsConfig controls the slow zoom/pan applied to static images.
+// It is off unless Enabled is set because zoompan adds noticeably to render time.
+type KenBurnsConfig struct {
+	Enabled   bool
+	ZoomRate  float64 // Zoom increase per second; 0 uses DefaultKenBurnsZoomRate
+	Direction string  // in (default), out, pan-left or pan-right
+}
+
+// withDefaults returns the config with zero or unknown values replaced by defaults
+func (c KenBurnsConfig) withDefaults() KenBurnsConfig {
+	if c.ZoomRate <= 0 {
+		c.ZoomRate = DefaultKenBurnsZoomRate
+	}
+	c.Direction = strings.ToLower(strings.TrimSpace(c.Direction))
+	switch c.Direction {
+	case KenBurnsZoomIn, KenBurnsZoomOut, KenBurnsPanLeft, KenBurnsPanRight:
+	case "":
+		c.Direction = KenBurnsZoomIn
+	default:
+		log.Printf("Warning: unknown Ken Burns direction %q, using %s", c.Direction, KenBurnsZoomIn)
+		c.Direction = KenBurnsZoomIn
+	}
+	return c
+}
+
+// kenBurnsFilter returns a zoompan filter that moves over a fitted frame for
+// the given duration. The frame is upscaled first so the motion doesn't
+// jitter from zoompan rounding crop positions to whole pixels.
+func (vr *VideoRenderer) kenBurnsFilter(config KenBurnsConfig, duration float64) string {
+	config = config.withDefaults()
+	frames := int(math.Ceil(duration * float64(vr.FPS)))
+	if frames < 1 {
+		frames = 1
+	}
+
+	// Zoom at the end of the image, capped so long intros don't zoom forever
+	endZoom := math.Min(1+config.ZoomRate*duration, KenBurnsMaxZoom)
+	progress := fmt.Sprintf("on/%d", frames)
+
+	var zoom, x, y string
+	centerX := "iw/2-(iw/zoom/2)"
+	centerY := "ih/2-(ih/zoom/2)"
+	switch config.Direction {
+	case KenBurnsZoomOut:
+		zoom = fmt.Sprintf("%.4f-%.4f*%s", endZoom, endZoom-1, progress)
+		x, y = centerX, centerY
+	case KenBurnsPanLeft, KenBurnsPanRight:
+		panZoom := math.Max(endZoom, kenBurnsMinPanZoom)
+		zoom = fmt.Sprintf("%.4f", panZoom)
+		if config.Direction == KenBurnsPanLeft {
+			x = fmt.Sprintf("(iw-iw/zoom)*(1-%s)", progress)
+		} else {
+			x = fmt.Sprintf("(iw-iw/zoom)*%s", progress)
+		}
+		y = centerY
+	default:
+		zoom = fmt.Sprintf("1+%.4f*%s", endZoom-1, progress)
+		x, y = centerX, centerY
+	}
+
+	return fmt.Sprintf("%s,scale=%d:%d,zoompan=z='%s':x='%s':y='%s':d=%d:s=%dx%d:fps=%d",
+		vr.fitFilter(), vr.Width*2, vr.Height*2, zoom, x, y, frames, vr.Width, vr.Height, vr.FPS)
+}
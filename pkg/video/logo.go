@@ -0,0 +1,96 @@
+package video
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Corners the artist logo can be placed in
+const (
+	LogoTopLeft     = "top-left"
+	LogoTopRight    = "top-right"
+	LogoBottomLeft  = "bottom-left"
+	LogoBottomRight = "bottom-right" // Default
+)
+
+// LogoCorners lists the supported logo corners
+var LogoCorners = []string{LogoTopLeft, LogoTopRight, LogoBottomLeft, LogoBottomRight}
+
+// Logo defaults, used for any LogoConfig field left at its zero value
+const (
+	DefaultLogoSize    = 256
+	DefaultLogoOpacity = 0.7
+	DefaultLogoMargin  = 20
+)
+
+// LogoConfig places the artist logo overlay. Zero fields use the defaults:
+// a 256px logo at 70% opacity, 20px from the bottom-right corner.
+type LogoConfig struct {
+	Size    int     // Width and height in pixels
+	Opacity float64 // 0-1
+	Corner  string  // top-left, top-right, bottom-left, bottom-right
+	Margin  int     // Pixels from the corner's edges
+}
+
+// Validate reports settings that can't be rendered; zero values are valid
+// since they select the defaults
+func (lc LogoConfig) Validate() error {
+	if lc.Size < 0 {
+		return fmt.Errorf("logo size must be greater than 0")
+	}
+	if lc.Opacity < 0 || lc.Opacity > 1 {
+		return fmt.Errorf("logo opacity must be between 0 and 1")
+	}
+	if lc.Margin < 0 {
+		return fmt.Errorf("logo margin must not be negative")
+	}
+	if lc.Corner != "" && !isLogoCorner(lc.Corner) {
+		return fmt.Errorf("logo corner must be one of: %s", strings.Join(LogoCorners, ", "))
+	}
+	return nil
+}
+
+// withDefaults fills zero or invalid fields with the defaults
+func (lc LogoConfig) withDefaults() LogoConfig {
+	if lc.Size <= 0 {
+		lc.Size = DefaultLogoSize
+	}
+	if lc.Opacity <= 0 || lc.Opacity > 1 {
+		lc.Opacity = DefaultLogoOpacity
+	}
+	if lc.Margin <= 0 {
+		lc.Margin = DefaultLogoMargin
+	}
+	if !isLogoCorner(lc.Corner) {
+		lc.Corner = LogoBottomRight
+	}
+	return lc
+}
+
+// isLogoCorner reports whether corner is a supported logo corner
+func isLogoCorner(corner string) bool {
+	for _, c := range LogoCorners {
+		if corner == c {
+			return true
+		}
+	}
+	return false
+}
+
+// logoOverlayFilter returns the filter_complex segment that scales the logo
+// input (e.g. "1:v"), applies its opacity and overlays it on the base label,
+// producing the out label
+func (lc LogoConfig) logoOverlayFilter(logoInput, base, out string) string {
+	lc = lc.withDefaults()
+
+	x, y := fmt.Sprintf("W-w-%d", lc.Margin), fmt.Sprintf("H-h-%d", lc.Margin)
+	if lc.Corner == LogoTopLeft || lc.Corner == LogoBottomLeft {
+		x = fmt.Sprintf("%d", lc.Margin)
+	}
+	if lc.Corner == LogoTopLeft || lc.Corner == LogoTopRight {
+		y = fmt.Sprintf("%d", lc.Margin)
+	}
+
+	return fmt.Sprintf("[%s]scale=%d:%d,format=rgba,colorchannelmixer=aa=%.2f[logo];[%s][logo]overlay=%s:%s[%s]",
+		logoInput, lc.Size, lc.Size, lc.Opacity, base, x, y, out)
+}
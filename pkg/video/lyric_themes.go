@@ -0,0 +1,297 @@
+package video
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lyric display themes selectable per song
+const (
+	LyricThemeScroll           = "scroll"               // 4 centered lines scrolling up, fading with distance (default)
+	LyricThemeSingleLineBottom = "single-line-bottom"   // Only the active line, subtitle-style near the bottom
+	LyricThemeKaraokeBox       = "two-line-karaoke-box" // Active and next line in a translucent box
+	LyricThemeFade             = "fade"                 // Active line centered, fading in and out
+)
+
+// lyricFont returns the font file used for all lyric themes
+func (vr *VideoRenderer) lyricFont() string {
+	return vr.fontPath(vr.LyricFont)
+}
+
+// lyricThemeFunc builds the drawtext filters for a theme from the display lines
+type lyricThemeFunc func(vr *VideoRenderer, lines []displayLine) []string
+
+var lyricThemes = map[string]lyricThemeFunc{
+	LyricThemeScroll:           scrollTheme,
+	LyricThemeSingleLineBottom: singleLineBottomTheme,
+	LyricThemeKaraokeBox:       karaokeBoxTheme,
+	LyricThemeFade:             fadeTheme,
+}
+
+// LyricThemes lists the supported lyric themes
+var LyricThemes = []string{LyricThemeScroll, LyricThemeSingleLineBottom, LyricThemeKaraokeBox, LyricThemeFade}
+
+// NormalizeLyricTheme returns theme if supported, otherwise the default scroll theme
+func NormalizeLyricTheme(theme string) string {
+	if _, ok := lyricThemes[theme]; ok {
+		return theme
+	}
+	return LyricThemeScroll
+}
+
+// displayLine is a lyric line broken to fit on screen, with onset-adjusted timing
+type displayLine struct {
+	Text      string
+	StartTime float64
+	EndTime   float64
+	LineIndex int // Which lyric line this came from
+}
+
+// breakDisplayLines splits long lyric lines at commas or spaces so each fits on
+// screen, dividing each line's time between its parts
+func breakDisplayLines(lyrics []LyricLine, vocalOnset float64) []displayLine {
+	var displayLines []displayLine
+	maxCharsPerLine := 38 // Max characters before breaking (reduced from 45 to prevent clipping)
+
+	for i, lyric := range lyrics {
+		text := lyric.Text
+		runes := []rune(text) // Break on characters, not bytes, so non-ASCII lyrics stay intact
+		startTime := lyric.StartTime + vocalOnset
+		endTime := lyric.EndTime + vocalOnset
+
+		// Check if line needs breaking
+		if len(runes) <= maxCharsPerLine {
+			displayLines = append(displayLines, displayLine{
+				Text:      text,
+				StartTime: startTime,
+				EndTime:   endTime,
+				LineIndex: i,
+			})
+		} else {
+			// Try to break at comma ANYWHERE in the text (not just middle 30-70%)
+			commaPos := -1
+			// Find the LAST comma before maxCharsPerLine
+			for idx := min(len(runes)-1, maxCharsPerLine); idx > 0; idx-- {
+				if runes[idx] == ',' {
+					commaPos = idx
+					break
+				}
+			}
+			// If no comma in first maxChars, try ANY comma
+			if commaPos < 0 {
+				for idx, ch := range runes {
+					if ch == ',' {
+						commaPos = idx
+						break
+					}
+				}
+			}
+
+			duration := endTime - startTime
+			if commaPos > 0 && commaPos < len(runes)-1 {
+				// Break at comma
+				line1 := strings.TrimSpace(string(runes[:commaPos+1]))
+				line2 := strings.TrimSpace(string(runes[commaPos+1:]))
+
+				// Check if line2 is still too long, recursively break it
+				if utf8.RuneCountInString(line2) > maxCharsPerLine {
+					// Split the time proportionally
+					line1Ratio := float64(utf8.RuneCountInString(line1)) / float64(len(runes))
+					line1Time := startTime + duration*line1Ratio
+
+					displayLines = append(displayLines, displayLine{
+						Text:      line1,
+						StartTime: startTime,
+						EndTime:   line1Time,
+						LineIndex: i,
+					})
+
+					// Recursively process line2 by adding it back to processing
+					// For now, just split at midpoint
+					line2Runes := []rune(line2)
+					midPoint := len(line2Runes) / 2
+					subLine1 := strings.TrimSpace(string(line2Runes[:midPoint]))
+					subLine2 := strings.TrimSpace(string(line2Runes[midPoint:]))
+					midTime := line1Time + (endTime-line1Time)*0.5
+
+					displayLines = append(displayLines, displayLine{
+						Text:      subLine1,
+						StartTime: line1Time,
+						EndTime:   midTime,
+						LineIndex: i,
+					})
+					displayLines = append(displayLines, displayLine{
+						Text:      subLine2,
+						StartTime: midTime,
+						EndTime:   endTime,
+						LineIndex: i,
+					})
+				} else {
+					// Simple two-line break
+					line1Ratio := float64(utf8.RuneCountInString(line1)) / float64(len(runes))
+					midTime := startTime + duration*line1Ratio
+
+					displayLines = append(displayLines, displayLine{
+						Text:      line1,
+						StartTime: startTime,
+						EndTime:   midTime,
+						LineIndex: i,
+					})
+					displayLines = append(displayLines, displayLine{
+						Text:      line2,
+						StartTime: midTime,
+						EndTime:   endTime,
+						LineIndex: i,
+					})
+				}
+			} else {
+				// Break at last space before max chars (fixed bounds check)
+				breakPos := -1
+				for idx := min(maxCharsPerLine-1, len(runes)-1); idx > 0; idx-- {
+					if runes[idx] == ' ' {
+						breakPos = idx
+						break
+					}
+				}
+				if breakPos <= 0 {
+					// Force break at maxCharsPerLine if no space found
+					breakPos = maxCharsPerLine
+				}
+				line1 := strings.TrimSpace(string(runes[:breakPos]))
+				line2 := strings.TrimSpace(string(runes[breakPos:]))
+				midTime := startTime + duration*0.5
+
+				displayLines = append(displayLines, displayLine{
+					Text:      line1,
+					StartTime: startTime,
+					EndTime:   midTime,
+					LineIndex: i,
+				})
+				displayLines = append(displayLines, displayLine{
+					Text:      line2,
+					StartTime: midTime,
+					EndTime:   endTime,
+					LineIndex: i,
+				})
+			}
+		}
+	}
+
+	return displayLines
+}
+
+// scrollTheme shows the active line and the next three, centered and fading with distance
+func scrollTheme(vr *VideoRenderer, displayLines []displayLine) []string {
+	font := vr.lyricFont()
+	// Build filter for multi-line display with scrolling
+	// Y positions for 4 lines (center screen, avoid top/bottom bars)
+	centerY := vr.Height / 2
+	lineSpacing := 80
+	line1Y := centerY - lineSpacing   // Active line (100% opacity)
+	line2Y := centerY                 // Next line (50% opacity)
+	line3Y := centerY + lineSpacing   // Future line (30% opacity)
+	line4Y := centerY + lineSpacing*2 // Future line (10% opacity)
+
+	var filterParts []string
+
+	// Render each display line at all 4 positions with appropriate timing and opacity
+	for i, line := range displayLines {
+		escapedText := vr.escapeText(line.Text)
+
+		// Position 1: Active line (100% opacity, blue with white border)
+		filter1 := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=64:fontcolor=0x4169E1:fontfile=%s:borderw=3:bordercolor=white:enable=between(t\\,%.2f\\,%.2f)",
+			escapedText, line1Y, font, line.StartTime, line.EndTime)
+		filterParts = append(filterParts, filter1)
+
+		// Position 2: Next line (50% opacity) - show NEXT line (i+1) while current is active
+		if i < len(displayLines)-1 {
+			nextLine := displayLines[i+1]
+			nextEscapedText := vr.escapeText(nextLine.Text)
+			filter2 := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=64:fontcolor=0x4169E1@0.5:fontfile=%s:borderw=3:bordercolor=white@0.5:enable=between(t\\,%.2f\\,%.2f)",
+				nextEscapedText, line2Y, font, line.StartTime, line.EndTime)
+			filterParts = append(filterParts, filter2)
+		}
+
+		// Position 3: Future line (30% opacity) - show line i+2 while current is active
+		if i < len(displayLines)-2 {
+			next2Line := displayLines[i+2]
+			next2EscapedText := vr.escapeText(next2Line.Text)
+			filter3 := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=64:fontcolor=0x4169E1@0.3:fontfile=%s:borderw=3:bordercolor=white@0.3:enable=between(t\\,%.2f\\,%.2f)",
+				next2EscapedText, line3Y, font, line.StartTime, line.EndTime)
+			filterParts = append(filterParts, filter3)
+		}
+
+		// Position 4: Future line (10% opacity) - show line i+3 while current is active
+		if i < len(displayLines)-3 {
+			next3Line := displayLines[i+3]
+			next3EscapedText := vr.escapeText(next3Line.Text)
+			filter4 := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=64:fontcolor=0x4169E1@0.1:fontfile=%s:borderw=3:bordercolor=white@0.1:enable=between(t\\,%.2f\\,%.2f)",
+				next3EscapedText, line4Y, font, line.StartTime, line.EndTime)
+			filterParts = append(filterParts, filter4)
+		}
+	}
+
+	return filterParts
+}
+
+// singleLineBottomTheme shows only the active line near the bottom, like subtitles
+func singleLineBottomTheme(vr *VideoRenderer, displayLines []displayLine) []string {
+	font := vr.lyricFont()
+	y := vr.Height - 220
+
+	var filterParts []string
+	for _, line := range displayLines {
+		filterParts = append(filterParts, fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=56:fontcolor=white:fontfile=%s:borderw=3:bordercolor=black:shadowcolor=black@0.6:shadowx=2:shadowy=2:enable=between(t\\,%.2f\\,%.2f)",
+			vr.escapeText(line.Text), y, font, line.StartTime, line.EndTime))
+	}
+	return filterParts
+}
+
+// karaokeBoxTheme shows the active line highlighted above the next line, inside
+// a translucent box in the lower third
+func karaokeBoxTheme(vr *VideoRenderer, displayLines []displayLine) []string {
+	font := vr.lyricFont()
+	if len(displayLines) == 0 {
+		return nil
+	}
+
+	boxHeight := 200
+	boxY := vr.Height - boxHeight - 120
+	activeY := boxY + 30
+	nextY := boxY + 115
+
+	// The box stays up for the whole vocal section
+	filterParts := []string{fmt.Sprintf("drawbox=x=160:y=%d:w=iw-320:h=%d:color=black@0.5:t=fill:enable=between(t\\,%.2f\\,%.2f)",
+		boxY, boxHeight, displayLines[0].StartTime, displayLines[len(displayLines)-1].EndTime)}
+
+	for i, line := range displayLines {
+		filterParts = append(filterParts, fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=60:fontcolor=0xFFD700:fontfile=%s:borderw=2:bordercolor=black:enable=between(t\\,%.2f\\,%.2f)",
+			vr.escapeText(line.Text), activeY, font, line.StartTime, line.EndTime))
+
+		if i < len(displayLines)-1 {
+			filterParts = append(filterParts, fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=48:fontcolor=white@0.7:fontfile=%s:enable=between(t\\,%.2f\\,%.2f)",
+				vr.escapeText(displayLines[i+1].Text), nextY, font, line.StartTime, line.EndTime))
+		}
+	}
+	return filterParts
+}
+
+// fadeTheme shows the active line centered, fading in at its start and out at its end
+func fadeTheme(vr *VideoRenderer, displayLines []displayLine) []string {
+	font := vr.lyricFont()
+	const maxFade = 0.4 // seconds
+
+	var filterParts []string
+	for _, line := range displayLines {
+		fade := min(maxFade, (line.EndTime-line.StartTime)/3)
+		if fade <= 0 {
+			fade = 0.01
+		}
+		alpha := fmt.Sprintf("if(lt(t\\,%.2f)\\,(t-%.2f)/%.2f\\,if(gt(t\\,%.2f)\\,(%.2f-t)/%.2f\\,1))",
+			line.StartTime+fade, line.StartTime, fade, line.EndTime-fade, line.EndTime, fade)
+		filterParts = append(filterParts, fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=(h-text_h)/2:fontsize=72:fontcolor=white:fontfile=%s:borderw=3:bordercolor=0x4169E1:alpha=%s:enable=between(t\\,%.2f\\,%.2f)",
+			vr.escapeText(line.Text), font, alpha, line.StartTime, line.EndTime))
+	}
+	return filterParts
+}
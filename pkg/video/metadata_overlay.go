@@ -10,6 +10,7 @@ type MetadataOverlay struct {
 	RightMargin int    `json:"right_margin"` // Pixels from right (for BPM)
 	FontSize    int    `json:"font_size"`    // Font size in points
 	FontFamily  string `json:"font_family"`  // Font family name
+	FontFile    string `json:"-"`            // Resolved font file; empty uses the system bold font
 	FontColor   string `json:"font_color"`   // Hex color code
 
 	// Shadow/outline for readability
@@ -90,8 +91,12 @@ func (m *MetadataOverlay) GetFFmpegDrawtextFilter(key, tempo string, bpm float64
 
 // drawText creates a single FFmpeg drawtext filter
 func (m *MetadataOverlay) drawText(text, position string) string {
-	filter := fmt.Sprintf("drawtext=text='%s':%s:fontsize=%d:fontcolor=%s:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSans-Bold.ttf",
-		text, position, m.FontSize, m.FontColor)
+	fontFile := m.FontFile
+	if fontFile == "" {
+		fontFile = DefaultSystemFonts().Bold
+	}
+	filter := fmt.Sprintf("drawtext=text='%s':%s:fontsize=%d:fontcolor=%s:fontfile=%s",
+		text, position, m.FontSize, m.FontColor, fontFile)
 
 	if m.TextShadow {
 		filter += fmt.Sprintf(":shadowcolor=%s:shadowx=%d:shadowy=%d",
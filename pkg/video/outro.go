@@ -0,0 +1,143 @@
+package video
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Outro card timing, in seconds
+const (
+	DefaultOutroDuration = 5.0  // How long an image card is shown when no duration is set
+	MaxOutroDuration     = 60.0 // Longest outro a song may request
+	outroCrossfade       = 1.0  // Fade from the song into the card
+)
+
+// outroImageExtensions are the card formats shown as a still image; anything
+// else is treated as a video clip
+var outroImageExtensions = map[string]bool{".png": true, ".jpg": true, ".jpeg": true, ".webp": true}
+
+// isOutroImage reports whether a card is a still image rather than a video
+func isOutroImage(cardPath string) bool {
+	return outroImageExtensions[strings.ToLower(filepath.Ext(cardPath))]
+}
+
+// ValidateOutroCard checks a song's outro settings: the card, when set, must
+// be an existing file, and the duration must be between 0 and MaxOutroDuration
+func ValidateOutroCard(cardPath string, duration float64) error {
+	if duration < 0 || duration > MaxOutroDuration || math.IsNaN(duration) {
+		return fmt.Errorf("outro_duration must be between 0 and %.0f seconds", MaxOutroDuration)
+	}
+	if cardPath == "" {
+		return nil
+	}
+	if info, err := os.Stat(cardPath); err != nil || info.IsDir() {
+		return fmt.Errorf("outro_card_path is not a readable file")
+	}
+	return nil
+}
+
+// AppendOutroCard adds an end screen to a rendered video in place, crossfading
+// from the song into the card. An image card is shown for duration seconds
+// (DefaultOutroDuration when zero); a video card plays for duration or its
+// whole length, with its own audio as outro music. Cards without audio are
+// silent. The card is scaled and padded to the video's frame size and frame
+// rate, and the video's subtitle track and tags are kept. It returns how many
+// seconds the video grew by.
+func (vr *VideoRenderer) AppendOutroCard(videoPath, cardPath string, duration float64) (float64, error) {
+	if err := ValidateOutroCard(cardPath, duration); err != nil {
+		return 0, err
+	}
+
+	main, err := ProbeVideo(videoPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to probe video for outro: %w", err)
+	}
+
+	cardIsImage := isOutroImage(cardPath)
+	cardHasAudio := false
+	cardLength := duration
+	if cardIsImage {
+		if cardLength <= 0 {
+			cardLength = DefaultOutroDuration
+		}
+	} else {
+		card, err := ProbeVideo(cardPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to probe outro card: %w", err)
+		}
+		if !card.HasStream("video") || card.Duration <= 0 {
+			return 0, fmt.Errorf("outro card %s has no playable video", cardPath)
+		}
+		cardHasAudio = card.HasStream("audio")
+		if cardLength <= 0 || cardLength > card.Duration {
+			cardLength = card.Duration
+		}
+	}
+
+	// Hard cut when the fade filters are unavailable
+	crossfade := math.Min(outroCrossfade, math.Min(cardLength, main.Duration)/2)
+	if !hasFilter("xfade") || !hasFilter("acrossfade") {
+		crossfade = 0
+	}
+
+	args := []string{"-i", videoPath}
+	if cardIsImage {
+		args = append(args, "-loop", "1", "-framerate", fmt.Sprintf("%d", vr.FPS))
+	}
+	args = append(args, "-t", fmt.Sprintf("%.3f", cardLength), "-i", cardPath)
+	cardAudio := "[1:a]"
+	if !cardHasAudio {
+		args = append(args, "-f", "lavfi", "-t", fmt.Sprintf("%.3f", cardLength), "-i", "anullsrc=channel_layout=stereo:sample_rate=48000")
+		cardAudio = "[2:a]"
+	}
+
+	const audioFormat = "aformat=sample_rates=48000:channel_layouts=stereo"
+	filters := []string{
+		fmt.Sprintf("[0:v]fps=%d,format=yuv420p,setsar=1[main]", vr.FPS),
+		fmt.Sprintf("[1:v]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,fps=%d,format=yuv420p,setsar=1[card]",
+			vr.Width, vr.Height, vr.Width, vr.Height, vr.FPS),
+		"[0:a]" + audioFormat + "[mainaudio]",
+		cardAudio + audioFormat + "[cardaudio]",
+	}
+	if crossfade > 0 {
+		filters = append(filters,
+			fmt.Sprintf("[main][card]xfade=transition=fade:duration=%.2f:offset=%.3f[vout]", crossfade, main.Duration-crossfade),
+			fmt.Sprintf("[mainaudio][cardaudio]acrossfade=d=%.2f[aout]", crossfade),
+		)
+	} else {
+		filters = append(filters, "[main][mainaudio][card][cardaudio]concat=n=2:v=1:a=1[vout][aout]")
+	}
+
+	ext := filepath.Ext(videoPath)
+	tempPath := strings.TrimSuffix(videoPath, ext) + ".outro" + ext
+	args = append(args,
+		"-filter_complex", strings.Join(filters, ";"),
+		"-map", "[vout]", "-map", "[aout]", "-map", "0:s?",
+		"-map_metadata", "0",
+		"-c:v", "libx264",
+		"-preset", vr.encodePreset().Preset,
+		"-crf", vr.encodePreset().CRF,
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-c:s", "copy",
+		"-y", tempPath,
+	)
+
+	log.Printf("Appending %.1fs outro card %s to %s (crossfade %.1fs)", cardLength, cardPath, videoPath, crossfade)
+	output, err := exec.Command("ffmpeg", args...).CombinedOutput()
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, fmt.Errorf("ffmpeg outro card failed: %w\nOutput: %s", err, string(output))
+	}
+	if err := os.Rename(tempPath, videoPath); err != nil {
+		os.Remove(tempPath)
+		return 0, fmt.Errorf("failed to replace video with outro version: %w", err)
+	}
+
+	return cardLength - crossfade, nil
+}
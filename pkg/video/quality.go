@@ -0,0 +1,46 @@
+package video
+
+// Encode quality levels, trading render time for file quality
+const (
+	QualityDraft    = "draft"    // Fast previews
+	QualityStandard = "standard" // Default
+	QualityHigh     = "high"
+	QualityArchive  = "archive" // Near-lossless masters
+)
+
+// Qualities lists the quality levels from fastest to best
+var Qualities = []string{QualityDraft, QualityStandard, QualityHigh, QualityArchive}
+
+// Draft render frame size (480p at the renderer's default aspect)
+const (
+	DraftWidth  = 854
+	DraftHeight = 480
+)
+
+// QualityPreset is the libx264 rate control and speed preset for a quality level
+type QualityPreset struct {
+	CRF    string
+	Preset string
+}
+
+// QualityPresets maps each quality level to its x264 settings
+var QualityPresets = map[string]QualityPreset{
+	QualityDraft:    {CRF: "30", Preset: "ultrafast"},
+	QualityStandard: {CRF: "23", Preset: "medium"},
+	QualityHigh:     {CRF: "20", Preset: "slow"},
+	QualityArchive:  {CRF: "18", Preset: "slow"},
+}
+
+// NormalizeQuality returns quality if known, otherwise standard
+func NormalizeQuality(quality string) string {
+	if _, ok := QualityPresets[quality]; ok {
+		return quality
+	}
+	return QualityStandard
+}
+
+// encodePreset returns the x264 settings for the renderer's quality level.
+// Intermediate and final encodes share it so draft renders are fast end to end.
+func (vr *VideoRenderer) encodePreset() QualityPreset {
+	return QualityPresets[NormalizeQuality(vr.Quality)]
+}
@@ -0,0 +1,198 @@
+package video
+
+import "sort"
+
+// Spectrum visualizer styles
+const (
+	SpectrumStereo       = "stereo" // Default
+	SpectrumShowfreqs    = "showfreqs"
+	SpectrumShowspectrum = "showspectrum"
+	SpectrumShowcqt      = "showcqt"
+	SpectrumShowwaves    = "showwaves"
+	SpectrumShowvolume   = "showvolume"
+	SpectrumAvectorscope = "avectorscope"
+)
+
+// SpectrumRainbow is the spectrum color that draws a multicolor gradient
+// instead of a single named color
+const SpectrumRainbow = "rainbow"
+
+// SpectrumStyle is a spectrum visualizer a song can choose, with the other
+// names it's accepted under
+type SpectrumStyle struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description"`
+}
+
+// SpectrumStyles lists the supported spectrum styles, default first
+var SpectrumStyles = []SpectrumStyle{
+	{SpectrumStereo, []string{"dual", "leftright"}, "Left/right channel bars growing inward from the edges"},
+	{SpectrumShowfreqs, []string{"bars", "equalizer", "freq"}, "Classic equalizer bars along the bottom"},
+	{SpectrumShowspectrum, []string{"spectrum", "spectro"}, "Stationary full-frame spectrum display"},
+	{SpectrumShowcqt, []string{"cqt", "professional"}, "High-quality constant-Q spectrum with bars"},
+	{SpectrumShowwaves, []string{"wave", "waveform"}, "Smooth full-frame waveform"},
+	{SpectrumShowvolume, []string{"volume", "meter"}, "Volume meter"},
+	{SpectrumAvectorscope, []string{"scope", "circle"}, "Circular vector scope of the stereo field"},
+}
+
+// IsSpectrumStyle reports whether name is a supported style or one of its aliases
+func IsSpectrumStyle(name string) bool {
+	for _, style := range SpectrumStyles {
+		if name == style.Name {
+			return true
+		}
+		for _, alias := range style.Aliases {
+			if name == alias {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// NormalizeSpectrumStyle returns the style a name or alias refers to,
+// otherwise the default stereo
+func NormalizeSpectrumStyle(name string) string {
+	for _, style := range SpectrumStyles {
+		if name == style.Name {
+			return style.Name
+		}
+		for _, alias := range style.Aliases {
+			if name == alias {
+				return style.Name
+			}
+		}
+	}
+	return SpectrumStereo
+}
+
+// SpectrumColors maps each named spectrum color to the hex value it's drawn
+// with. Values are brighter than their usual web colors so single-color
+// visualizers stay visible at low opacity.
+var SpectrumColors = map[string]string{
+	"charcoal": "0x808080", // Medium gray (brighter than 0x303030)
+	"cyan":     "0x00FFFF", // Bright cyan
+	"blue":     "0x0080FF", // Bright blue
+	"red":      "0xFF0000", // Bright red
+	"green":    "0x00FF00", // Bright green
+	"yellow":   "0xFFFF00", // Bright yellow
+	"magenta":  "0xFF00FF", // Bright magenta
+	"white":    "0xFFFFFF", // White
+	"orange":   "0xFF8000", // Bright orange
+	"purple":   "0x8000FF", // Bright purple
+	"pink":     "0xFF00FF", // Bright pink (magenta)
+	"gold":     "0xFFD700", // Gold
+}
+
+// SpectrumColorNames lists the colors a song can choose: rainbow, then the
+// named colors alphabetically
+func SpectrumColorNames() []string {
+	names := make([]string, 0, len(SpectrumColors))
+	for name := range SpectrumColors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{SpectrumRainbow}, names...)
+}
+
+// Transitions between background images
+const (
+	TransitionCrossfade = "crossfade" // Fade between images (default)
+	TransitionCut       = "cut"       // Straight cuts, used by draft renders
+)
+
+// Default render frame size
+const (
+	DefaultWidth  = 1920
+	DefaultHeight = 1024
+)
+
+// RenderResolution is a frame size the renderer produces
+type RenderResolution struct {
+	Name   string `json:"name"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// RenderResolutions lists the frame sizes the renderer produces
+var RenderResolutions = []RenderResolution{
+	{"standard", DefaultWidth, DefaultHeight},
+	{QualityDraft, DraftWidth, DraftHeight},
+}
+
+// TargetResolutions are the resolution labels a song's videos are recorded
+// with (the songs table defaults to 4k)
+var TargetResolutions = []string{"4k", "1080p", "720p", "480p"}
+
+// RenderOption is a named choice for a render setting and whether this
+// server's FFmpeg can produce it
+type RenderOption struct {
+	Name        string   `json:"name"`
+	Aliases     []string `json:"aliases,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Hex         string   `json:"hex,omitempty"`
+	Available   bool     `json:"available"`
+}
+
+// QualityOption is a quality level and its x264 settings
+type QualityOption struct {
+	Name   string `json:"name"`
+	CRF    string `json:"crf"`
+	Preset string `json:"preset"`
+}
+
+// RenderOptions is every choice a song's render settings accept
+type RenderOptions struct {
+	SpectrumStyles    []RenderOption     `json:"spectrum_styles"`
+	SpectrumColors    []RenderOption     `json:"spectrum_colors"`
+	SpectrumPositions []string           `json:"spectrum_positions"`
+	Transitions       []RenderOption     `json:"transitions"`
+	Resolutions       []RenderResolution `json:"resolutions"`
+	TargetResolutions []string           `json:"target_resolutions"`
+	Qualities         []QualityOption    `json:"qualities"`
+	DefaultQuality    string             `json:"default_quality"`
+	ImageFits         []string           `json:"image_fits"`
+}
+
+// AvailableRenderOptions lists the render settings a song can choose from.
+// Styles and transitions whose FFmpeg filter is missing are marked
+// unavailable; renders fall back from them (stereo spectrum, hard cuts).
+func AvailableRenderOptions() RenderOptions {
+	options := RenderOptions{
+		SpectrumPositions: SpectrumPositions,
+		Resolutions:       RenderResolutions,
+		TargetResolutions: TargetResolutions,
+		DefaultQuality:    QualityStandard,
+		ImageFits:         ImageFits,
+	}
+
+	for _, style := range SpectrumStyles {
+		options.SpectrumStyles = append(options.SpectrumStyles, RenderOption{
+			Name:        style.Name,
+			Aliases:     style.Aliases,
+			Description: style.Description,
+			Available:   hasFilter(spectrumStyleFilter(style.Name)),
+		})
+	}
+
+	for _, name := range SpectrumColorNames() {
+		option := RenderOption{Name: name, Hex: SpectrumColors[name], Available: true}
+		if name == SpectrumRainbow {
+			option.Description = "Multicolor gradient"
+		}
+		options.SpectrumColors = append(options.SpectrumColors, option)
+	}
+
+	options.Transitions = []RenderOption{
+		{Name: TransitionCrossfade, Description: "Fade between background images", Available: hasFilter("xfade")},
+		{Name: TransitionCut, Description: "Straight cuts between background images (draft renders)", Available: true},
+	}
+
+	for _, quality := range Qualities {
+		preset := QualityPresets[quality]
+		options.Qualities = append(options.Qualities, QualityOption{Name: quality, CRF: preset.CRF, Preset: preset.Preset})
+	}
+
+	return options
+}
@@ -3,11 +3,13 @@ package video
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // VideoRenderer handles video composition with FFmpeg
@@ -18,6 +20,26 @@ type VideoRenderer struct {
 	OutputDir    string
 	TempDir      string
 	BrandingPath string // Path to branding directory for logos
+	StrictText   bool   // Strip overlay text to Latin characters for fonts with limited glyphs
+	Quality      string // Encode quality: draft, standard (default), high, archive
+	ImageFit     string // Background image fit: letterbox (default), crop, blur-fill
+
+	// KeepTempFiles preserves each render's intermediate files in its own
+	// directory under TempDir (named KeptTempPrefix + timestamp) for debugging
+	KeepTempFiles bool
+
+	// DebugDir, when set, receives a named copy of each step's output
+	// (step1_slideshow.mp4, step2_spectrum.mp4, ...) for inspecting which
+	// pass changed what. It's cleared of the previous render's steps first.
+	DebugDir string
+
+	// Fonts resolves font names to uploaded files (nil uses system fonts only)
+	Fonts       *FontRegistry
+	LyricFont   string // Registered font name for lyric text
+	OverlayFont string // Registered font name for title, metadata and countdown text
+
+	// Logo sizes and places the artist logo overlay (zero values use defaults)
+	Logo LogoConfig
 
 	// Timing statistics
 	RenderTimings    []time.Duration
@@ -37,11 +59,22 @@ type VideoRenderOptions struct {
 	ImagePaths []ImageSegment
 
 	// Lyrics
-	LyricsData        []LyricLine
-	VocalOnset        float64 // Offset for lyrics timing (in seconds)
-	CrossfadeDuration float64 // Duration of crossfade between images (default 2.0s)
-	EnableKaraoke     bool    // Enable word-by-word karaoke highlighting (default false)
-	ASSSubtitlePath   string  // Path to ASS subtitle file for karaoke (optional)
+	LyricsData         []LyricLine
+	VocalOnset         float64 // Offset for lyrics timing (in seconds)
+	CrossfadeDuration  float64 // Duration of crossfade between images (default 2.0s)
+	HardCuts           bool    // Join images with straight cuts instead of crossfades (draft renders)
+	EnableKaraoke      bool    // Enable word-by-word karaoke highlighting (default false)
+	ASSSubtitlePath    string  // Path to ASS subtitle file for karaoke (optional)
+	LyricTheme         string  // Lyric layout: scroll (default), single-line-bottom, two-line-karaoke-box, fade
+	LyricRenderMode    string  // drawtext (default) or subtitles (burn an ASS generated from LyricsData)
+	EmbedSoftSubtitles bool    // Also embed the lyrics (or karaoke ASS) as a selectable subtitle track
+	SoftSubtitlesOnly  bool    // With EmbedSoftSubtitles, skip burning lyrics into the pixels
+
+	// Intro countdown shown before the vocals start
+	Countdown CountdownConfig
+
+	// Slow zoom/pan over each static image (off by default)
+	KenBurns KenBurnsConfig
 
 	// Metadata
 	Key    string
@@ -50,10 +83,23 @@ type VideoRenderOptions struct {
 	Title  string
 	Artist string
 
+	// Copyright line drawn at the bottom center; empty draws none
+	CopyrightText string
+
+	// Container tags (title and artist above are also tagged)
+	Album   string
+	Genre   string
+	Comment string
+
 	// Spectrum Analyzer
-	SpectrumStyle   string  // "showwaves", "showfreqs", "showspectrum", etc.
-	SpectrumColor   string  // Color for spectrum (hex or color name)
-	SpectrumOpacity float64 // Opacity for spectrum overlay (0.0-1.0)
+	SpectrumStyle    string  // "showwaves", "showfreqs", "showspectrum", etc.
+	SpectrumColor    string  // Color for spectrum (hex or color name)
+	SpectrumOpacity  float64 // Opacity for spectrum overlay (0.0-1.0)
+	SpectrumPosition string  // top, bottom, center, fullscreen or edges; empty uses the style's default
+	SpectrumHeight   float64 // Fraction of the frame height; 0 uses the style's default
+
+	// Fades are applied in the final encode: audio from/to silence, picture from/to black
+	Fades Fades
 
 	// Output
 	OutputPath string
@@ -75,8 +121,8 @@ type LyricLine struct {
 
 func NewVideoRenderer(outputDir string, brandingPath string) *VideoRenderer {
 	return &VideoRenderer{
-		Width:            1920,
-		Height:           1024,
+		Width:            DefaultWidth,
+		Height:           DefaultHeight,
 		FPS:              30,
 		OutputDir:        outputDir,
 		BrandingPath:     brandingPath,
@@ -86,6 +132,47 @@ func NewVideoRenderer(outputDir string, brandingPath string) *VideoRenderer {
 	}
 }
 
+// KeptTempPrefix names the per-job directories that hold preserved intermediates
+const KeptTempPrefix = "job_"
+
+// jobTempDir returns a fresh per-job directory for preserved intermediates
+func (vr *VideoRenderer) jobTempDir(opts *VideoRenderOptions) string {
+	base := strings.TrimSuffix(filepath.Base(opts.OutputPath), filepath.Ext(opts.OutputPath))
+	return filepath.Join(vr.TempDir, fmt.Sprintf("%s%s_%s", KeptTempPrefix, time.Now().Format("20060102-150405"), base))
+}
+
+// discardTemp removes an intermediate file unless temp files are being kept
+func (vr *VideoRenderer) discardTemp(path string) {
+	if !vr.KeepTempFiles {
+		os.Remove(path)
+	}
+}
+
+// runFFmpegStep runs an ffmpeg command that writes outputPath, discarding the
+// partial output if it fails so a failed render leaves nothing behind.
+// Successful outputs are the caller's to clean up.
+func (vr *VideoRenderer) runFFmpegStep(cmd *exec.Cmd, outputPath string) ([]byte, error) {
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		vr.discardTemp(outputPath)
+	}
+	return output, err
+}
+
+// ArtistLogoFilename is the logo overlaid on videos, inside the branding directory
+const ArtistLogoFilename = "artist-logo.png"
+
+// artistLogo returns the artist logo path and whether it exists, warning when
+// it's missing so a misconfigured branding path doesn't go unnoticed
+func (vr *VideoRenderer) artistLogo() (string, bool) {
+	logoPath := filepath.Join(vr.BrandingPath, ArtistLogoFilename)
+	if _, err := os.Stat(logoPath); err != nil {
+		log.Printf("Warning: artist logo not found at %s, rendering without logo", logoPath)
+		return logoPath, false
+	}
+	return logoPath, true
+}
+
 // RenderVideo creates the final video composition
 func (vr *VideoRenderer) RenderVideo(opts *VideoRenderOptions) (string, error) {
 	startTime := time.Now()
@@ -98,6 +185,27 @@ func (vr *VideoRenderer) RenderVideo(opts *VideoRenderOptions) (string, error) {
 		log.Printf("Video rendering took: %.1fs", duration.Seconds())
 	}()
 
+	// -t and segment timing need a real length; zero would encode an empty video
+	if math.IsNaN(opts.Duration) || math.IsInf(opts.Duration, 0) || opts.Duration <= 0 {
+		return "", fmt.Errorf("invalid render duration %.2fs", opts.Duration)
+	}
+
+	// Fail before rendering if the container can't hold the subtitle track
+	subtitleCodec := ""
+	if opts.EmbedSoftSubtitles {
+		codec, err := subtitleCodecFor(opts.OutputPath)
+		if err != nil {
+			return "", err
+		}
+		subtitleCodec = codec
+	}
+
+	// Intermediates go to a per-job directory when kept, so renders don't overwrite each other's
+	if vr.KeepTempFiles {
+		vr.TempDir = vr.jobTempDir(opts)
+		log.Printf("Keeping intermediate files in %s", vr.TempDir)
+	}
+
 	// Ensure temp and output directories exist
 	if err := os.MkdirAll(vr.TempDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
@@ -105,42 +213,83 @@ func (vr *VideoRenderer) RenderVideo(opts *VideoRenderOptions) (string, error) {
 	if err := os.MkdirAll(vr.OutputDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create output directory: %w", err)
 	}
+	vr.resetDebugDir()
 
 	log.Println("Step 1/5: Creating image slideshow...")
 	slideshowPath := filepath.Join(vr.TempDir, "slideshow.mp4")
 	if err := vr.createImageSlideshow(opts, slideshowPath); err != nil {
 		return "", fmt.Errorf("failed to create slideshow: %w", err)
 	}
-	defer os.Remove(slideshowPath)
+	defer vr.discardTemp(slideshowPath)
+	vr.SaveDebugStep(1, "slideshow", slideshowPath)
 
 	log.Println("Step 2/5: Adding spectrum analyzer overlay...")
 	spectrumPath, err := vr.addSpectrumAnalyzer(slideshowPath, opts)
 	if err != nil {
 		return "", fmt.Errorf("failed to add spectrum analyzer: %w", err)
 	}
-	defer os.Remove(spectrumPath)
+	defer vr.discardTemp(spectrumPath)
+	vr.SaveDebugStep(2, "spectrum", spectrumPath)
 
 	log.Println("Step 3/5: Adding metadata and branding overlays...")
 	metadataPath, err := vr.addMetadataOverlays(spectrumPath, opts)
 	if err != nil {
 		return "", fmt.Errorf("failed to add metadata: %w", err)
 	}
-	defer os.Remove(metadataPath)
+	defer vr.discardTemp(metadataPath)
+	vr.SaveDebugStep(3, "metadata", metadataPath)
+
+	// Timed lyrics as an ASS file, for subtitle rendering and/or a soft subtitle track
+	lyricsASSPath := ""
+	if len(opts.LyricsData) > 0 && (opts.LyricRenderMode == LyricRenderSubtitles || opts.EmbedSoftSubtitles) {
+		lyricsASSPath = filepath.Join(vr.TempDir, "lyrics.ass")
+		if err := WriteLyricsASS(opts.LyricsData, opts.VocalOnset, vr.Width, vr.Height, lyricsASSPath); err != nil {
+			return "", fmt.Errorf("failed to write lyrics subtitles: %w", err)
+		}
+		defer vr.discardTemp(lyricsASSPath)
+		vr.SaveDebugStep(4, "lyrics", lyricsASSPath)
+	}
 
-	log.Println("Step 4/5: Adding lyrics overlay...")
-	lyricsPath, err := vr.addLyricsOverlay(metadataPath, opts)
-	if err != nil {
-		return "", fmt.Errorf("failed to add lyrics: %w", err)
+	lyricsPath := metadataPath
+	if opts.EmbedSoftSubtitles && opts.SoftSubtitlesOnly {
+		log.Println("Step 4/5: Skipping burned-in lyrics (soft subtitles only)")
+	} else {
+		log.Println("Step 4/5: Adding lyrics overlay...")
+		lyricsPath, err = vr.addLyricsOverlay(metadataPath, opts, lyricsASSPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to add lyrics: %w", err)
+		}
+		defer vr.discardTemp(lyricsPath)
+		vr.SaveDebugStep(4, "lyrics", lyricsPath)
 	}
-	defer os.Remove(lyricsPath)
 
 	log.Println("Step 5/5: Adding audio and encoding final video...")
-	finalPath, err := vr.addAudioAndEncode(lyricsPath, opts.AudioPath, opts.Duration, opts.OutputPath)
+	// Prefer the karaoke ASS for the soft track since it carries word timing
+	softSubtitlePath := ""
+	if opts.EmbedSoftSubtitles {
+		softSubtitlePath = lyricsASSPath
+		if opts.ASSSubtitlePath != "" && fileExists(opts.ASSSubtitlePath) {
+			softSubtitlePath = opts.ASSSubtitlePath
+		}
+		if softSubtitlePath == "" {
+			log.Println("No lyrics available for soft subtitle track, skipping")
+		}
+	}
+	finalPath, err := vr.addAudioAndEncode(lyricsPath, opts.AudioPath, softSubtitlePath, subtitleCodec, opts.Duration, opts.Fades, opts.OutputPath, metadataArgs(opts))
 	if err != nil {
 		return "", fmt.Errorf("failed to encode final video: %w", err)
 	}
 
+	vr.SaveDebugStep(5, "final", finalPath)
+
 	log.Printf("✓ Video rendered successfully: %s", finalPath)
+	if vr.KeepTempFiles {
+		log.Printf("Intermediate files kept: slideshow=%s spectrum=%s metadata=%s lyrics=%s",
+			slideshowPath, spectrumPath, metadataPath, lyricsPath)
+		if lyricsASSPath != "" {
+			log.Printf("Intermediate lyrics subtitles kept: %s", lyricsASSPath)
+		}
+	}
 	return finalPath, nil
 }
 
@@ -148,88 +297,118 @@ func (vr *VideoRenderer) RenderVideo(opts *VideoRenderOptions) (string, error) {
 func (vr *VideoRenderer) addMetadataOverlays(inputPath string, opts *VideoRenderOptions) (string, error) {
 	tempPath := filepath.Join(vr.TempDir, "with_metadata.mp4")
 
+	cmd := vr.metadataOverlayCmd([]string{"-i", inputPath}, "", opts, []string{
+		"-c:v", "libx264",
+		"-preset", vr.encodePreset().Preset,
+		"-crf", vr.encodePreset().CRF,
+	}, tempPath)
+
+	output, err := vr.runFFmpegStep(cmd, tempPath)
+	if err != nil {
+		return "", fmt.Errorf("ffmpeg metadata overlay failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return tempPath, nil
+}
+
+// RenderOverlayPreview renders a single PNG frame of imagePath (or a plain
+// placeholder background when imagePath is empty) with the metadata and
+// branding overlays applied at the renderer's resolution
+func (vr *VideoRenderer) RenderOverlayPreview(imagePath string, opts *VideoRenderOptions, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create preview directory: %w", err)
+	}
+
+	inputArgs := []string{"-f", "lavfi", "-i", fmt.Sprintf("color=c=0x303030:s=%dx%d", vr.Width, vr.Height)}
+	inputFilter := ""
+	if imagePath != "" {
+		inputArgs = []string{"-i", imagePath}
+		inputFilter = vr.fitFilter()
+	}
+
+	cmd := vr.metadataOverlayCmd(inputArgs, inputFilter, opts, []string{"-frames:v", "1"}, outputPath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ffmpeg overlay preview failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// metadataOverlayCmd builds the ffmpeg command for the metadata and branding
+// pass. inputArgs supply the first input; inputFilter, if set, runs on it
+// before the overlays; outputArgs precede outputPath.
+func (vr *VideoRenderer) metadataOverlayCmd(inputArgs []string, inputFilter string, opts *VideoRenderOptions, outputArgs []string, outputPath string) *exec.Cmd {
+	filterStr := vr.metadataOverlayFilters(opts)
+	if inputFilter != "" {
+		filterStr = inputFilter + "," + filterStr
+	}
+
+	args := append([]string{}, inputArgs...)
+
+	// Check if artist logo exists for overlay
+	logoPath, logoExists := vr.artistLogo()
+	if logoExists {
+		// Use filter_complex to add text overlays + logo overlay (sized and placed per vr.Logo)
+		args = append(args,
+			"-i", logoPath,
+			"-filter_complex",
+			"[0:v]"+filterStr+"[v1];"+vr.Logo.logoOverlayFilter("1:v", "v1", "vout"),
+			"-map", "[vout]",
+		)
+	} else {
+		// No logo, just text overlays
+		args = append(args, "-vf", filterStr)
+	}
+
+	args = append(args, outputArgs...)
+	args = append(args, "-y", outputPath)
+	return exec.Command("ffmpeg", args...)
+}
+
+// metadataOverlayFilters returns the drawtext chain for the key, tempo, BPM,
+// title and copyright overlays
+func (vr *VideoRenderer) metadataOverlayFilters(opts *VideoRenderOptions) string {
 	// Build comprehensive filter for metadata + branding
 	var filterParts []string
 
 	// Top bar - Yellow/Gold text (Saira Condensed 48pt)
 	// KEY (Top-Left, aligned left, 20px from edges)
 	if opts.Key != "" {
-		keyFilter := fmt.Sprintf("drawtext=text='KEY\\\\: %s':x=20:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2",
-			escapeText(opts.Key))
+		keyFilter := fmt.Sprintf("drawtext=text='KEY\\\\: %s':x=20:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2",
+			vr.escapeText(opts.Key), vr.fontPath(vr.overlayFamily(true)))
 		filterParts = append(filterParts, keyFilter)
 	}
 
 	// TEMPO (Top-Center, aligned center)
 	if opts.Tempo != "" {
-		tempoFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2",
-			escapeText(opts.Tempo))
+		tempoFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2",
+			vr.escapeText(opts.Tempo), vr.fontPath(vr.overlayFamily(true)))
 		filterParts = append(filterParts, tempoFilter)
 	}
 
 	// BPM (Top-Right, aligned right, 20px from edge)
 	if opts.BPM > 0 {
-		bpmFilter := fmt.Sprintf("drawtext=text='BPM\\\\: %.0f':x=w-text_w-20:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2",
-			opts.BPM)
+		bpmFilter := fmt.Sprintf("drawtext=text='BPM\\\\: %.0f':x=w-text_w-20:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2",
+			opts.BPM, vr.fontPath(vr.overlayFamily(true)))
 		filterParts = append(filterParts, bpmFilter)
 	}
 
 	// Bottom bar - Title (yellow/gold), Copyright (white), Logo (image overlay)
 	// Song title - bottom left (Saira Condensed 64, yellow/gold)
 	// Position: 20px from left, 96px from bottom (raised 16px)
-	titleFilter := fmt.Sprintf("drawtext=text='%s':x=20:y=h-96:fontsize=64:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2",
-		escapeText(opts.Title))
+	titleFilter := fmt.Sprintf("drawtext=text='%s':x=20:y=h-96:fontsize=64:fontcolor=0xFFD700:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2",
+		vr.escapeText(opts.Title), vr.fontPath(vr.overlayFamily(true)))
 	filterParts = append(filterParts, titleFilter)
 
 	// Copyright - bottom center (Roboto 20, white)
 	// Position: centered horizontally, 25px from bottom
-	copyright := "All content Copyright 2017-2026 Nlaak Studios"
-	copyrightFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=h-25:fontsize=20:fontcolor=white:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf:shadowcolor=black@0.7:shadowx=1:shadowy=1",
-		escapeText(copyright))
-	filterParts = append(filterParts, copyrightFilter)
-
-	filterStr := strings.Join(filterParts, ",")
-
-	// Check if artist logo exists for overlay
-	logoPath := filepath.Join(vr.BrandingPath, "artist-logo.png")
-	logoExists := false
-	if _, err := os.Stat(logoPath); err == nil {
-		logoExists = true
-	}
-
-	var cmd *exec.Cmd
-	if logoExists {
-		// Use filter_complex to add text overlays + logo overlay (256x256 with 70% opacity, bottom-right, 20px margins)
-		cmd = exec.Command("ffmpeg",
-			"-i", inputPath,
-			"-i", logoPath,
-			"-filter_complex",
-			fmt.Sprintf("[0:v]%s[v1];[1:v]scale=256:256,format=rgba,colorchannelmixer=aa=0.7[logo];[v1][logo]overlay=W-w-20:H-h-20[vout]", filterStr),
-			"-map", "[vout]",
-			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
-			"-y",
-			tempPath,
-		)
-	} else {
-		// No logo, just text overlays
-		cmd = exec.Command("ffmpeg",
-			"-i", inputPath,
-			"-vf", filterStr,
-			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
-			"-y",
-			tempPath,
-		)
-	}
-
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("ffmpeg metadata overlay failed: %w\nOutput: %s", err, string(output))
+	if opts.CopyrightText != "" {
+		copyrightFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=h-25:fontsize=20:fontcolor=white:fontfile=%s:shadowcolor=black@0.7:shadowx=1:shadowy=1",
+			vr.escapeText(opts.CopyrightText), vr.fontPath(vr.overlayFamily(false)))
+		filterParts = append(filterParts, copyrightFilter)
 	}
 
-	return tempPath, nil
+	return strings.Join(filterParts, ",")
 }
 
 // createBasicVideo creates slideshow with all static overlays (metadata, title, copyright, logo)
@@ -251,60 +430,57 @@ func (vr *VideoRenderer) createBasicVideo(opts *VideoRenderOptions) (string, err
 	// Top bar - Yellow/Gold text (Saira Condensed 48pt)
 	// KEY (Top-Left, aligned left, 20px from edges)
 	if opts.Key != "" {
-		keyFilter := fmt.Sprintf("drawtext=text='KEY\\\\: %s':x=20:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2",
-			escapeText(opts.Key))
+		keyFilter := fmt.Sprintf("drawtext=text='KEY\\\\: %s':x=20:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2",
+			vr.escapeText(opts.Key), vr.fontPath(vr.overlayFamily(true)))
 		filterParts = append(filterParts, keyFilter)
 	}
 
 	// TEMPO (Top-Center, aligned center)
 	if opts.Tempo != "" {
-		tempoFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2",
-			escapeText(opts.Tempo))
+		tempoFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2",
+			vr.escapeText(opts.Tempo), vr.fontPath(vr.overlayFamily(true)))
 		filterParts = append(filterParts, tempoFilter)
 	}
 
 	// BPM (Top-Right, aligned right, 20px from edge)
 	if opts.BPM > 0 {
-		bpmFilter := fmt.Sprintf("drawtext=text='BPM\\\\: %.0f':x=w-text_w-20:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2",
-			opts.BPM)
+		bpmFilter := fmt.Sprintf("drawtext=text='BPM\\\\: %.0f':x=w-text_w-20:y=20:fontsize=48:fontcolor=0xFFD700:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2",
+			opts.BPM, vr.fontPath(vr.overlayFamily(true)))
 		filterParts = append(filterParts, bpmFilter)
 	}
 
 	// Bottom bar - Title (yellow/gold), Copyright (white), Logo (image overlay)
 	// Song title - bottom left (Saira Condensed 64, yellow/gold)
 	// Position: 20px from left, 96px from bottom (raised 16px)
-	titleFilter := fmt.Sprintf("drawtext=text='%s':x=20:y=h-96:fontsize=64:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2",
-		escapeText(opts.Title))
+	titleFilter := fmt.Sprintf("drawtext=text='%s':x=20:y=h-96:fontsize=64:fontcolor=0xFFD700:fontfile=%s:shadowcolor=black@0.7:shadowx=2:shadowy=2",
+		vr.escapeText(opts.Title), vr.fontPath(vr.overlayFamily(true)))
 	filterParts = append(filterParts, titleFilter)
 
 	// Copyright - bottom center (Roboto 20, white)
 	// Position: centered horizontally, 25px from bottom
-	copyright := "All content Copyright 2017-2026 Nlaak Studios"
-	copyrightFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=h-25:fontsize=20:fontcolor=white:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf:shadowcolor=black@0.7:shadowx=1:shadowy=1",
-		escapeText(copyright))
-	filterParts = append(filterParts, copyrightFilter)
+	if opts.CopyrightText != "" {
+		copyrightFilter := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=h-25:fontsize=20:fontcolor=white:fontfile=%s:shadowcolor=black@0.7:shadowx=1:shadowy=1",
+			vr.escapeText(opts.CopyrightText), vr.fontPath(vr.overlayFamily(false)))
+		filterParts = append(filterParts, copyrightFilter)
+	}
 
 	filterStr := strings.Join(filterParts, ",")
 
 	// Check if artist logo exists for overlay
-	logoPath := filepath.Join(vr.BrandingPath, "artist-logo.png")
-	logoExists := false
-	if _, err := os.Stat(logoPath); err == nil {
-		logoExists = true
-	}
+	logoPath, logoExists := vr.artistLogo()
 
 	var cmd *exec.Cmd
 	if logoExists {
-		// Use filter_complex to add text overlays + logo overlay (256x256 with 70% opacity, bottom-right, 20px margins)
+		// Use filter_complex to add text overlays + logo overlay (sized and placed per vr.Logo)
 		cmd = exec.Command("ffmpeg",
 			"-i", slideshowPath,
 			"-i", logoPath,
 			"-filter_complex",
-			fmt.Sprintf("[0:v]%s[v1];[1:v]scale=256:256,format=rgba,colorchannelmixer=aa=0.7[logo];[v1][logo]overlay=W-w-20:H-h-20[vout]", filterStr),
+			"[0:v]"+filterStr+"[v1];"+vr.Logo.logoOverlayFilter("1:v", "v1", "vout"),
 			"-map", "[vout]",
 			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-preset", vr.encodePreset().Preset,
+			"-crf", vr.encodePreset().CRF,
 			"-y",
 			tempPath,
 		)
@@ -314,14 +490,14 @@ func (vr *VideoRenderer) createBasicVideo(opts *VideoRenderOptions) (string, err
 			"-i", slideshowPath,
 			"-vf", filterStr,
 			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-preset", vr.encodePreset().Preset,
+			"-crf", vr.encodePreset().CRF,
 			"-y",
 			tempPath,
 		)
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := vr.runFFmpegStep(cmd, tempPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg basic video creation failed: %w\nOutput: %s", err, string(output))
 	}
@@ -338,6 +514,10 @@ func (vr *VideoRenderer) addSpectrumAnalyzer(inputPath string, opts *VideoRender
 	if spectrumStyle == "" {
 		spectrumStyle = "stereo" // Default to stereo spectrum visualizer
 	}
+	if filter := spectrumStyleFilter(spectrumStyle); !hasFilter(filter) {
+		log.Printf("Warning: FFmpeg has no %s filter, using stereo spectrum", filter)
+		spectrumStyle = "stereo"
+	}
 
 	spectrumColor := opts.SpectrumColor
 	if spectrumColor == "" {
@@ -350,31 +530,19 @@ func (vr *VideoRenderer) addSpectrumAnalyzer(inputPath string, opts *VideoRender
 	}
 
 	// Determine if using rainbow or mono color
-	useRainbow := (spectrumColor == "rainbow")
+	useRainbow := (spectrumColor == SpectrumRainbow)
 	monoColorHex := "0x00FFFF" // Default bright cyan
 
 	// Map color names to bright hex values for spectrum visualization
 	if !useRainbow {
-		colorMap := map[string]string{
-			"charcoal": "0x808080", // Medium gray (brighter than 0x303030)
-			"cyan":     "0x00FFFF", // Bright cyan
-			"blue":     "0x0080FF", // Bright blue
-			"red":      "0xFF0000", // Bright red
-			"green":    "0x00FF00", // Bright green
-			"yellow":   "0xFFFF00", // Bright yellow
-			"magenta":  "0xFF00FF", // Bright magenta
-			"white":    "0xFFFFFF", // White
-			"orange":   "0xFF8000", // Bright orange
-			"purple":   "0x8000FF", // Bright purple
-			"pink":     "0xFF00FF", // Bright pink (magenta)
-			"gold":     "0xFFD700", // Gold
-		}
-
-		if hex, ok := colorMap[spectrumColor]; ok {
+		if hex, ok := SpectrumColors[spectrumColor]; ok {
 			monoColorHex = hex
 		}
 	}
 
+	// Size and placement; the style's own placement unless the song sets one
+	layout, positioned := vr.spectrumLayout(spectrumStyle, opts)
+
 	// Build spectrum visualization filter based on style
 	var spectrumFilter string
 	var filterComplex string
@@ -385,11 +553,11 @@ func (vr *VideoRenderer) addSpectrumAnalyzer(inputPath string, opts *VideoRender
 		if useRainbow {
 			// Rainbow gradient waveform
 			spectrumFilter = fmt.Sprintf("[1:a]showwaves=s=%dx%d:mode=cline:colors=red|orange|yellow|green|cyan|blue|violet:scale=sqrt,format=rgba,colorchannelmixer=aa=%.2f[spectrum]",
-				vr.Width, vr.Height, spectrumOpacity)
+				layout.Width, layout.Height, spectrumOpacity)
 		} else {
 			// Mono color waveform with explicit hex color
 			spectrumFilter = fmt.Sprintf("[1:a]showwaves=s=%dx%d:mode=cline:colors=%s:scale=sqrt,format=rgba,colorchannelmixer=aa=%.2f[spectrum]",
-				vr.Width, vr.Height, monoColorHex, spectrumOpacity)
+				layout.Width, layout.Height, monoColorHex, spectrumOpacity)
 		}
 
 	case "showfreqs", "bars", "equalizer":
@@ -397,11 +565,11 @@ func (vr *VideoRenderer) addSpectrumAnalyzer(inputPath string, opts *VideoRender
 		if useRainbow {
 			// Rainbow gradient bars
 			spectrumFilter = fmt.Sprintf("[1:a]showfreqs=s=%dx%d:mode=bar:fscale=log:ascale=sqrt:win_size=4096:colors=red|orange|yellow|green|cyan|blue|violet,format=rgba,colorchannelmixer=aa=%.2f[spectrum]",
-				vr.Width, vr.Height, spectrumOpacity)
+				layout.Width, layout.Height, spectrumOpacity)
 		} else {
 			// Mono color bars with explicit hex color for brightness
 			spectrumFilter = fmt.Sprintf("[1:a]showfreqs=s=%dx%d:mode=bar:fscale=log:ascale=sqrt:win_size=4096:colors=%s,format=rgba,colorchannelmixer=aa=%.2f[spectrum]",
-				vr.Width, vr.Height, monoColorHex, spectrumOpacity)
+				layout.Width, layout.Height, monoColorHex, spectrumOpacity)
 		}
 
 	case "showspectrum", "spectrum":
@@ -409,19 +577,23 @@ func (vr *VideoRenderer) addSpectrumAnalyzer(inputPath string, opts *VideoRender
 		if useRainbow {
 			// Rainbow gradient spectrum
 			spectrumFilter = fmt.Sprintf("[1:a]showspectrum=s=%dx%d:slide=replace:color=rainbow:scale=sqrt:saturation=3,format=rgba,colorchannelmixer=aa=%.2f[spectrum]",
-				vr.Width, vr.Height, spectrumOpacity)
+				layout.Width, layout.Height, spectrumOpacity)
 		} else {
 			// Mono color spectrum
 			spectrumFilter = fmt.Sprintf("[1:a]showspectrum=s=%dx%d:slide=replace:color=intensity:scale=sqrt,format=rgba,colorchannelmixer=aa=%.2f[spectrum]",
-				vr.Width, vr.Height, spectrumOpacity)
+				layout.Width, layout.Height, spectrumOpacity)
 		}
 
 	case "showcqt", "cqt":
 		// High-quality Constant Q Transform spectrum with bars
 		// Frequency range: 50Hz to 20kHz
 		// CQT has built-in colorization, opacity applied after
+		barHeight := layout.Height / 3
+		if positioned {
+			barHeight = layout.Height
+		}
 		spectrumFilter = fmt.Sprintf("[1:a]showcqt=s=%dx%d:fps=30:bar_h=%d:sono_h=0:bar_t=%.2f:basefreq=50:endfreq=20000,format=rgba[spectrum]",
-			vr.Width, vr.Height, vr.Height/3, spectrumOpacity)
+			layout.Width, layout.Height, barHeight, spectrumOpacity)
 
 	case "showvolume":
 		// Volume meter
@@ -431,7 +603,7 @@ func (vr *VideoRenderer) addSpectrumAnalyzer(inputPath string, opts *VideoRender
 	case "avectorscope":
 		// Circular vector scope (stereo field visualization)
 		spectrumFilter = fmt.Sprintf("[1:a]avectorscope=s=%dx%d:zoom=1.5:draw=line,format=rgba,colorchannelmixer=aa=%.2f[spectrum]",
-			vr.Width, vr.Height, spectrumOpacity)
+			layout.Width, layout.Height, spectrumOpacity)
 
 	case "stereo", "":
 		// Stereo spectrum visualizer - left/right channel bars on edges growing inward
@@ -462,10 +634,10 @@ func (vr *VideoRenderer) addSpectrumAnalyzer(inputPath string, opts *VideoRender
 		}
 
 		spectrumFilter = fmt.Sprintf(
-			"[1:a]channelsplit=channel_layout=stereo[L][R];"+
+			"[1:a]%schannelsplit=channel_layout=stereo[L][R];"+
 				"[L]showfreqs=%s[left_vis];"+
 				"[R]showfreqs=%s[right_vis]",
-			leftChain, rightChain)
+			stereoInputFilter(opts.AudioPath), leftChain, rightChain)
 
 		// Now overlay bars at edges: left at x=0, right at x=W-w
 		filterComplex = fmt.Sprintf(
@@ -484,7 +656,14 @@ func (vr *VideoRenderer) addSpectrumAnalyzer(inputPath string, opts *VideoRender
 
 	// Determine overlay position (stereo mode jumps here directly)
 	if filterComplex == "" {
-		if spectrumStyle == "showfreqs" || spectrumStyle == "bars" || spectrumStyle == "equalizer" {
+		if positioned {
+			y := layout.Y
+			if spectrumStyle == "showvolume" {
+				// The meter keeps its size and only moves
+				y = spectrumOffset(layout.Position, vr.Height, vr.Height/10)
+			}
+			filterComplex = fmt.Sprintf("%s;[0:v][spectrum]overlay=0:%d[outv]", spectrumFilter, y)
+		} else if spectrumStyle == "showfreqs" || spectrumStyle == "bars" || spectrumStyle == "equalizer" {
 			// Position at bottom of screen
 			waveHeight := vr.Height / 4
 			yPosition := vr.Height - waveHeight
@@ -505,8 +684,8 @@ applyFilter:
 		"-c:v", "libx264",
 		"-c:a", "aac",
 		"-b:a", "192k",
-		"-preset", "medium",
-		"-crf", "23",
+		"-preset", vr.encodePreset().Preset,
+		"-crf", vr.encodePreset().CRF,
 		"-t", fmt.Sprintf("%.2f", opts.Duration),
 		"-y",
 		tempPath,
@@ -514,10 +693,10 @@ applyFilter:
 
 	// DEBUG: Log the exact FFmpeg command
 	log.Printf("[SPECTRUM DEBUG] Filter: %s", filterComplex)
-	log.Printf("[SPECTRUM DEBUG] Full command: ffmpeg -i %s -i %s -filter_complex '%s' -map '[outv]' -map '1:a' -c:v libx264 -c:a aac -b:a 192k -preset medium -crf 23 -t %.2f -y %s",
-		inputPath, opts.AudioPath, filterComplex, opts.Duration, tempPath)
+	log.Printf("[SPECTRUM DEBUG] Full command: ffmpeg -i %s -i %s -filter_complex '%s' -map '[outv]' -map '1:a' -c:v libx264 -c:a aac -b:a 192k -preset %s -crf %s -t %.2f -y %s",
+		inputPath, opts.AudioPath, filterComplex, vr.encodePreset().Preset, vr.encodePreset().CRF, opts.Duration, tempPath)
 
-	output, err := cmd.CombinedOutput()
+	output, err := vr.runFFmpegStep(cmd, tempPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg spectrum analyzer failed: %w\nOutput: %s", err, string(output))
 	}
@@ -527,9 +706,56 @@ applyFilter:
 		colorMode = spectrumColor
 	}
 	log.Printf("Added %s spectrum analyzer (%s, %.0f%% opacity)", spectrumStyle, colorMode, spectrumOpacity*100)
+	if positioned {
+		log.Printf("Spectrum placed %s at %dpx tall", layout.Position, layout.Height)
+	}
 	return tempPath, nil
 }
 
+// stereoInputFilter returns the filter that gives the stereo spectrum the
+// two channels channelsplit needs. Stereo audio is used as-is; mono is
+// upmixed (both sides then show the same bars) and other layouts are
+// downmixed. If the channels can't be probed the conversion is applied anyway,
+// since it's harmless on stereo input.
+func stereoInputFilter(audioPath string) string {
+	const toStereo = "aformat=channel_layouts=stereo,"
+
+	probe, err := ProbeVideo(audioPath)
+	if err != nil {
+		log.Printf("Warning: could not probe audio channels for stereo spectrum, converting to stereo: %v", err)
+		return toStereo
+	}
+
+	switch channels := probe.AudioChannels(); channels {
+	case 2:
+		return ""
+	case 0:
+		log.Printf("Warning: no audio stream found in %s for stereo spectrum, converting to stereo", audioPath)
+	case 1:
+		log.Printf("Audio is mono; upmixing to stereo for the stereo spectrum")
+	default:
+		log.Printf("Audio has %d channels; downmixing to stereo for the stereo spectrum", channels)
+	}
+	return toStereo
+}
+
+// spectrumStyleFilter returns the FFmpeg filter a spectrum style is drawn with
+func spectrumStyleFilter(style string) string {
+	switch style {
+	case "showwaves":
+		return "showwaves"
+	case "showspectrum", "spectrum":
+		return "showspectrum"
+	case "showcqt", "cqt":
+		return "showcqt"
+	case "showvolume":
+		return "showvolume"
+	case "avectorscope":
+		return "avectorscope"
+	}
+	return "showfreqs"
+}
+
 // getColorHex converts color name to hex value for FFmpeg
 func getColorHex(colorName string) string {
 	colors := map[string]string{
@@ -559,10 +785,14 @@ func (vr *VideoRenderer) createImageSlideshow(opts *VideoRenderOptions, outputPa
 
 	// If only one image, create a simple static video
 	if len(opts.ImagePaths) == 1 {
-		_, err := vr.createStaticImageVideo(opts.ImagePaths[0].ImagePath, opts.Duration, tempPath)
+		_, err := vr.createStaticImageVideo(opts.ImagePaths[0].ImagePath, opts.Duration, opts.KenBurns, tempPath)
 		return err
 	}
 
+	if opts.HardCuts || !hasFilter("xfade") {
+		return vr.concatImageSegments(opts, tempPath)
+	}
+
 	// Set default crossfade duration
 	crossfadeDuration := opts.CrossfadeDuration
 	if crossfadeDuration <= 0 {
@@ -586,7 +816,7 @@ func (vr *VideoRenderer) createImageSlideshow(opts *VideoRenderOptions, outputPa
 		segmentPath := filepath.Join(vr.TempDir, fmt.Sprintf("segment_%d.mp4", i))
 
 		// Create video segment for this image
-		_, err := vr.createStaticImageVideo(seg.ImagePath, duration, segmentPath)
+		_, err := vr.createStaticImageVideo(seg.ImagePath, duration, opts.KenBurns, segmentPath)
 		if err != nil {
 			return fmt.Errorf("failed to create segment %d: %w", i, err)
 		}
@@ -599,7 +829,7 @@ func (vr *VideoRenderer) createImageSlideshow(opts *VideoRenderOptions, outputPa
 	if len(segmentPaths) == 1 {
 		// Single image (shouldn't reach here, but handle anyway)
 		cmd := exec.Command("ffmpeg", "-i", segmentPaths[0], "-c", "copy", "-y", tempPath)
-		output, err := cmd.CombinedOutput()
+		output, err := vr.runFFmpegStep(cmd, tempPath)
 		if err != nil {
 			return fmt.Errorf("ffmpeg copy failed: %w\nOutput: %s", err, string(output))
 		}
@@ -634,11 +864,11 @@ func (vr *VideoRenderer) createImageSlideshow(opts *VideoRenderOptions, outputPa
 		filterComplex := strings.Join(filterParts, ";")
 
 		args := append(inputs, "-filter_complex", filterComplex, "-map", "[outv]",
-			"-c:v", "libx264", "-preset", "medium", "-crf", "23", "-pix_fmt", "yuv420p",
+			"-c:v", "libx264", "-preset", vr.encodePreset().Preset, "-crf", vr.encodePreset().CRF, "-pix_fmt", "yuv420p",
 			"-r", fmt.Sprintf("%d", vr.FPS), "-y", tempPath)
 
 		cmd := exec.Command("ffmpeg", args...)
-		output, err := cmd.CombinedOutput()
+		output, err := vr.runFFmpegStep(cmd, tempPath)
 		if err != nil {
 			return fmt.Errorf("ffmpeg xfade failed: %w\nOutput: %s", err, string(output))
 		}
@@ -647,22 +877,72 @@ func (vr *VideoRenderer) createImageSlideshow(opts *VideoRenderOptions, outputPa
 	return nil
 }
 
-// createStaticImageVideo creates a video from a single image with specified duration
-func (vr *VideoRenderer) createStaticImageVideo(imagePath string, duration float64, outputPath string) (string, error) {
-	cmd := exec.Command("ffmpeg",
-		"-loop", "1",
-		"-i", imagePath,
+// concatImageSegments joins one static segment per image with straight cuts,
+// skipping the xfade pass entirely
+func (vr *VideoRenderer) concatImageSegments(opts *VideoRenderOptions, outputPath string) error {
+	var inputs []string
+	var labels strings.Builder
+	count := 0
+	for i, seg := range opts.ImagePaths {
+		duration := seg.EndTime - seg.StartTime
+		if duration <= 0 {
+			log.Printf("Warning: invalid segment duration for image %d: %.2f", i, duration)
+			continue
+		}
+
+		segmentPath := filepath.Join(vr.TempDir, fmt.Sprintf("segment_%d.mp4", i))
+		if _, err := vr.createStaticImageVideo(seg.ImagePath, duration, opts.KenBurns, segmentPath); err != nil {
+			return fmt.Errorf("failed to create segment %d: %w", i, err)
+		}
+		defer os.Remove(segmentPath)
+
+		inputs = append(inputs, "-i", segmentPath)
+		fmt.Fprintf(&labels, "[%d:v]", count)
+		count++
+	}
+
+	if count == 0 {
+		return fmt.Errorf("no valid image segments")
+	}
+
+	filterComplex := fmt.Sprintf("%sconcat=n=%d:v=1:a=0[outv]", labels.String(), count)
+	args := append(inputs, "-filter_complex", filterComplex, "-map", "[outv]",
+		"-c:v", "libx264", "-preset", vr.encodePreset().Preset, "-crf", vr.encodePreset().CRF, "-pix_fmt", "yuv420p",
+		"-r", fmt.Sprintf("%d", vr.FPS), "-y", outputPath)
+
+	cmd := exec.Command("ffmpeg", args...)
+	output, err := vr.runFFmpegStep(cmd, outputPath)
+	if err != nil {
+		return fmt.Errorf("ffmpeg concat failed: %w\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// createStaticImageVideo creates a video from a single image with specified duration,
+// optionally with a slow Ken Burns zoom/pan
+func (vr *VideoRenderer) createStaticImageVideo(imagePath string, duration float64, kenBurns KenBurnsConfig, outputPath string) (string, error) {
+	// zoompan emits every frame from the single decoded image, so only the
+	// plain still needs the input looped
+	inputArgs := []string{"-loop", "1", "-i", imagePath}
+	filter := vr.fitFilter()
+	if kenBurns.Enabled && hasFilter("zoompan") {
+		inputArgs = []string{"-i", imagePath}
+		filter = vr.kenBurnsFilter(kenBurns, duration)
+	}
+
+	args := append(inputArgs,
 		"-t", fmt.Sprintf("%.2f", duration),
-		"-vf", fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:black",
-			vr.Width, vr.Height, vr.Width, vr.Height),
+		"-vf", filter,
 		"-c:v", "libx264",
 		"-pix_fmt", "yuv420p",
 		"-r", fmt.Sprintf("%d", vr.FPS),
 		"-y",
 		outputPath,
 	)
+	cmd := exec.Command("ffmpeg", args...)
 
-	output, err := cmd.CombinedOutput()
+	output, err := vr.runFFmpegStep(cmd, outputPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg static image failed: %w\nOutput: %s", err, string(output))
 	}
@@ -675,6 +955,7 @@ func (vr *VideoRenderer) addMetadataOverlay(inputPath string, opts *VideoRenderO
 	tempPath := filepath.Join(vr.TempDir, "with_metadata.mp4")
 
 	overlay := DefaultMetadataOverlay()
+	overlay.FontFile = vr.fontPath(overlay.FontFamily)
 	filterStr := overlay.GetFFmpegDrawtextFilter(opts.Key, opts.Tempo, opts.BPM, vr.Width)
 
 	if filterStr == "" {
@@ -686,14 +967,14 @@ func (vr *VideoRenderer) addMetadataOverlay(inputPath string, opts *VideoRenderO
 		"-i", inputPath,
 		"-vf", filterStr,
 		"-c:v", "libx264",
-		"-preset", "medium",
-		"-crf", "23",
+		"-preset", vr.encodePreset().Preset,
+		"-crf", vr.encodePreset().CRF,
 		"-c:a", "copy",
 		"-y",
 		tempPath,
 	)
 
-	output, err := cmd.CombinedOutput()
+	output, err := vr.runFFmpegStep(cmd, tempPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg metadata overlay failed: %w\nOutput: %s", err, string(output))
 	}
@@ -705,45 +986,41 @@ func (vr *VideoRenderer) addMetadataOverlay(inputPath string, opts *VideoRenderO
 func (vr *VideoRenderer) addBrandingOverlays(inputPath string, opts *VideoRenderOptions) (string, error) {
 	tempPath := filepath.Join(vr.TempDir, "with_branding.mp4")
 
-	// Check if artist logo exists
-	logoPath := filepath.Join(vr.BrandingPath, "artist-logo.png")
-	logoExists := false
-	if _, err := os.Stat(logoPath); err == nil {
-		logoExists = true
-	}
+	// Check if artist logo exists for overlay
+	logoPath, logoExists := vr.artistLogo()
 
 	// Build filter for title (bottom left), copyright (bottom center), and logo (bottom right)
 	var filterParts []string
 
 	// Song title - bottom left (Saira Condensed 64, white with shadow)
 	// Position: 40px from left, 52px from bottom (raised 12px)
-	titleFilter := fmt.Sprintf("drawtext=text='%s':x=40:y=h-92:fontsize=64:fontcolor=white:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black:shadowx=2:shadowy=2",
-		escapeText(opts.Title))
+	titleFilter := fmt.Sprintf("drawtext=text='%s':x=40:y=h-92:fontsize=64:fontcolor=white:fontfile=%s:shadowcolor=black:shadowx=2:shadowy=2",
+		vr.escapeText(opts.Title), vr.fontPath(vr.overlayFamily(true)))
 	filterParts = append(filterParts, titleFilter)
 
 	// Copyright - bottom center (Roboto 20, white with shadow)
 	// Position: centered horizontally, 20px from bottom
-	copyright := "All content Copyright 2017-2026 Nlaak Studios"
-	copyrightFilter := fmt.Sprintf(",drawtext=text='%s':x=(w-text_w)/2:y=h-30:fontsize=20:fontcolor=white:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf:shadowcolor=black:shadowx=1:shadowy=1",
-		escapeText(copyright))
-	filterParts = append(filterParts, copyrightFilter)
+	if opts.CopyrightText != "" {
+		copyrightFilter := fmt.Sprintf(",drawtext=text='%s':x=(w-text_w)/2:y=h-30:fontsize=20:fontcolor=white:fontfile=%s:shadowcolor=black:shadowx=1:shadowy=1",
+			vr.escapeText(opts.CopyrightText), vr.fontPath(vr.overlayFamily(false)))
+		filterParts = append(filterParts, copyrightFilter)
+	}
 
 	filterStr := strings.Join(filterParts, "")
 
 	// Build FFmpeg command with logo overlay if it exists
 	var cmd *exec.Cmd
 	if logoExists {
-		// Use overlay filter to add logo (150x150, bottom-right corner, 20px margins)
-		// Note: Logo is positioned in BOTTOM-RIGHT, not bottom-left
+		// Overlay the logo sized and placed per vr.Logo
 		cmd = exec.Command("ffmpeg",
 			"-i", inputPath,
 			"-i", logoPath,
 			"-filter_complex",
-			fmt.Sprintf("[0:v]%s[v1];[1:v]scale=150:150[logo];[v1][logo]overlay=W-w-20:H-h-20[vout]", filterStr),
+			"[0:v]"+filterStr+"[v1];"+vr.Logo.logoOverlayFilter("1:v", "v1", "vout"),
 			"-map", "[vout]",
 			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-preset", vr.encodePreset().Preset,
+			"-crf", vr.encodePreset().CRF,
 			"-y",
 			tempPath,
 		)
@@ -753,14 +1030,14 @@ func (vr *VideoRenderer) addBrandingOverlays(inputPath string, opts *VideoRender
 			"-i", inputPath,
 			"-vf", filterStr,
 			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-preset", vr.encodePreset().Preset,
+			"-crf", vr.encodePreset().CRF,
 			"-y",
 			tempPath,
 		)
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := vr.runFFmpegStep(cmd, tempPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg branding overlay failed: %w\nOutput: %s", err, string(output))
 	}
@@ -768,12 +1045,13 @@ func (vr *VideoRenderer) addBrandingOverlays(inputPath string, opts *VideoRender
 	return tempPath, nil
 }
 
-// addLyricsOverlay adds word-by-word karaoke lyrics with preview line
-func (vr *VideoRenderer) addLyricsOverlay(inputPath string, opts *VideoRenderOptions) (string, error) {
+// addLyricsOverlay adds word-by-word karaoke lyrics with preview line.
+// lyricsASSPath is the ASS generated from LyricsData, burned in subtitles mode.
+func (vr *VideoRenderer) addLyricsOverlay(inputPath string, opts *VideoRenderOptions, lyricsASSPath string) (string, error) {
 	tempPath := filepath.Join(vr.TempDir, "with_lyrics.mp4")
 
-	// If ASS subtitle file is provided, use it for karaoke
-	if opts.ASSSubtitlePath != "" && fileExists(opts.ASSSubtitlePath) {
+	// If ASS subtitle file is provided, use it for karaoke (needs libass)
+	if opts.ASSSubtitlePath != "" && fileExists(opts.ASSSubtitlePath) && hasFilter("subtitles") {
 		log.Printf("Using ASS karaoke subtitles: %s", opts.ASSSubtitlePath)
 		return vr.addASSSubtitles(inputPath, opts.ASSSubtitlePath, tempPath)
 	}
@@ -789,202 +1067,22 @@ func (vr *VideoRenderer) addLyricsOverlay(inputPath string, opts *VideoRenderOpt
 		vocalOnset = 0
 	}
 
-	log.Printf("Building multi-line lyrics display for %d lyric lines", len(opts.LyricsData))
-
-	// Break long lyrics into display lines
-	type DisplayLine struct {
-		Text      string
-		StartTime float64
-		EndTime   float64
-		LineIndex int // Which lyric line this came from
-	}
-
-	var displayLines []DisplayLine
-	maxCharsPerLine := 38 // Max characters before breaking (reduced from 45 to prevent clipping)
-
-	for i, lyric := range opts.LyricsData {
-		text := lyric.Text
-		startTime := lyric.StartTime + vocalOnset
-		endTime := lyric.EndTime + vocalOnset
-
-		// Check if line needs breaking
-		if len(text) <= maxCharsPerLine {
-			displayLines = append(displayLines, DisplayLine{
-				Text:      text,
-				StartTime: startTime,
-				EndTime:   endTime,
-				LineIndex: i,
-			})
-		} else {
-			// Try to break at comma ANYWHERE in the text (not just middle 30-70%)
-			commaPos := -1
-			// Find the LAST comma before maxCharsPerLine
-			for idx := min(len(text)-1, maxCharsPerLine); idx > 0; idx-- {
-				if text[idx] == ',' {
-					commaPos = idx
-					break
-				}
-			}
-			// If no comma in first maxChars, try ANY comma
-			if commaPos < 0 {
-				for idx, ch := range text {
-					if ch == ',' {
-						commaPos = idx
-						break
-					}
-				}
-			}
-
-			duration := endTime - startTime
-			if commaPos > 0 && commaPos < len(text)-1 {
-				// Break at comma
-				line1 := strings.TrimSpace(text[:commaPos+1])
-				line2 := strings.TrimSpace(text[commaPos+1:])
-
-				// Check if line2 is still too long, recursively break it
-				if len(line2) > maxCharsPerLine {
-					// Split the time proportionally
-					line1Ratio := float64(len(line1)) / float64(len(text))
-					line1Time := startTime + duration*line1Ratio
-
-					displayLines = append(displayLines, DisplayLine{
-						Text:      line1,
-						StartTime: startTime,
-						EndTime:   line1Time,
-						LineIndex: i,
-					})
-
-					// Recursively process line2 by adding it back to processing
-					// For now, just split at midpoint
-					midPoint := len(line2) / 2
-					subLine1 := strings.TrimSpace(line2[:midPoint])
-					subLine2 := strings.TrimSpace(line2[midPoint:])
-					midTime := line1Time + (endTime-line1Time)*0.5
-
-					displayLines = append(displayLines, DisplayLine{
-						Text:      subLine1,
-						StartTime: line1Time,
-						EndTime:   midTime,
-						LineIndex: i,
-					})
-					displayLines = append(displayLines, DisplayLine{
-						Text:      subLine2,
-						StartTime: midTime,
-						EndTime:   endTime,
-						LineIndex: i,
-					})
-				} else {
-					// Simple two-line break
-					line1Ratio := float64(len(line1)) / float64(len(text))
-					midTime := startTime + duration*line1Ratio
-
-					displayLines = append(displayLines, DisplayLine{
-						Text:      line1,
-						StartTime: startTime,
-						EndTime:   midTime,
-						LineIndex: i,
-					})
-					displayLines = append(displayLines, DisplayLine{
-						Text:      line2,
-						StartTime: midTime,
-						EndTime:   endTime,
-						LineIndex: i,
-					})
-				}
-			} else {
-				// Break at last space before max chars (fixed bounds check)
-				breakPos := -1
-				for idx := min(maxCharsPerLine-1, len(text)-1); idx > 0; idx-- {
-					if text[idx] == ' ' {
-						breakPos = idx
-						break
-					}
-				}
-				if breakPos <= 0 {
-					// Force break at maxCharsPerLine if no space found
-					breakPos = maxCharsPerLine
-				}
-				line1 := strings.TrimSpace(text[:breakPos])
-				line2 := strings.TrimSpace(text[breakPos:])
-				midTime := startTime + duration*0.5
-
-				displayLines = append(displayLines, DisplayLine{
-					Text:      line1,
-					StartTime: startTime,
-					EndTime:   midTime,
-					LineIndex: i,
-				})
-				displayLines = append(displayLines, DisplayLine{
-					Text:      line2,
-					StartTime: midTime,
-					EndTime:   endTime,
-					LineIndex: i,
-				})
-			}
-		}
-	}
-
-	// Build filter for multi-line display with scrolling
-	// Y positions for 4 lines (center screen, avoid top/bottom bars)
-	centerY := vr.Height / 2
-	lineSpacing := 80
-	line1Y := centerY - lineSpacing   // Active line (100% opacity)
-	line2Y := centerY                 // Next line (50% opacity)
-	line3Y := centerY + lineSpacing   // Future line (30% opacity)
-	line4Y := centerY + lineSpacing*2 // Future line (10% opacity)
-
 	var filterParts []string
-
-	// Render each display line at all 4 positions with appropriate timing and opacity
-	for i, line := range displayLines {
-		escapedText := escapeText(line.Text)
-
-		// Position 1: Active line (100% opacity, blue with white border)
-		filter1 := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=64:fontcolor=0x4169E1:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:borderw=3:bordercolor=white:enable=between(t\\,%.2f\\,%.2f)",
-			escapedText, line1Y, line.StartTime, line.EndTime)
-		filterParts = append(filterParts, filter1)
-
-		// Position 2: Next line (50% opacity) - show NEXT line (i+1) while current is active
-		if i < len(displayLines)-1 {
-			nextLine := displayLines[i+1]
-			nextEscapedText := escapeText(nextLine.Text)
-			filter2 := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=64:fontcolor=0x4169E1@0.5:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:borderw=3:bordercolor=white@0.5:enable=between(t\\,%.2f\\,%.2f)",
-				nextEscapedText, line2Y, line.StartTime, line.EndTime)
-			filterParts = append(filterParts, filter2)
-		}
-
-		// Position 3: Future line (30% opacity) - show line i+2 while current is active
-		if i < len(displayLines)-2 {
-			next2Line := displayLines[i+2]
-			next2EscapedText := escapeText(next2Line.Text)
-			filter3 := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=64:fontcolor=0x4169E1@0.3:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:borderw=3:bordercolor=white@0.3:enable=between(t\\,%.2f\\,%.2f)",
-				next2EscapedText, line3Y, line.StartTime, line.EndTime)
-			filterParts = append(filterParts, filter3)
-		}
-
-		// Position 4: Future line (10% opacity) - show line i+3 while current is active
-		if i < len(displayLines)-3 {
-			next3Line := displayLines[i+3]
-			next3EscapedText := escapeText(next3Line.Text)
-			filter4 := fmt.Sprintf("drawtext=text='%s':x=(w-text_w)/2:y=%d:fontsize=64:fontcolor=0x4169E1@0.1:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:borderw=3:bordercolor=white@0.1:enable=between(t\\,%.2f\\,%.2f)",
-				next3EscapedText, line4Y, line.StartTime, line.EndTime)
-			filterParts = append(filterParts, filter4)
+	if opts.LyricRenderMode == LyricRenderSubtitles && lyricsASSPath != "" && hasFilter("subtitles") {
+		// libass handles wrapping and timing, keeping the filter graph small
+		log.Printf("Burning lyrics subtitles for %d lyric lines", len(opts.LyricsData))
+		filterParts = append(filterParts, vr.subtitlesFilter(lyricsASSPath))
+	} else {
+		themeName := NormalizeLyricTheme(opts.LyricTheme)
+		if opts.LyricTheme != "" && themeName != opts.LyricTheme {
+			log.Printf("Warning: unknown lyric theme %q, using %s", opts.LyricTheme, themeName)
 		}
+		log.Printf("Building %s lyrics display for %d lyric lines", themeName, len(opts.LyricsData))
+		displayLines := breakDisplayLines(opts.LyricsData, vocalOnset)
+		filterParts = lyricThemes[themeName](vr, displayLines)
 	}
 
-	// Add progress indicator for intro (non-vocal sections)
-	if vocalOnset > 2.0 {
-		// Position at 25% from bottom (centered)
-		progressBarY := int(float64(vr.Height) * 0.75)
-		progressWidth := 600
-		progressFilter := fmt.Sprintf("drawbox=x=(w-%d)/2:y=%d:w=%d*min(1\\,t/%.2f):h=6:color=0xFFD700:enable=lt(t\\,%.2f)",
-			progressWidth, progressBarY, progressWidth, vocalOnset, vocalOnset)
-		filterParts = append(filterParts, progressFilter)
-
-		countdownFilter := fmt.Sprintf("drawtext=text='Starting in %%{eif\\:max(0\\,%.2f-t)\\:d}s':x=(w-text_w)/2:y=%d:fontsize=36:fontcolor=0xFFD700:fontfile=/usr/share/fonts/truetype/dejavu/DejaVuSansCondensed-Bold.ttf:shadowcolor=black@0.7:shadowx=2:shadowy=2:enable=lt(t\\,%.2f)",
-			vocalOnset, progressBarY-40, vocalOnset)
-		filterParts = append(filterParts, countdownFilter)
-	}
+	filterParts = append(filterParts, vr.introCountdownFilters(vocalOnset, opts.Countdown)...)
 
 	filterStr := strings.Join(filterParts, ",")
 
@@ -1009,8 +1107,8 @@ func (vr *VideoRenderer) addLyricsOverlay(inputPath string, opts *VideoRenderOpt
 			"-i", inputPath,
 			"-filter_complex_script", filterFile.Name(),
 			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-preset", vr.encodePreset().Preset,
+			"-crf", vr.encodePreset().CRF,
 			"-y",
 			tempPath,
 		)
@@ -1019,14 +1117,14 @@ func (vr *VideoRenderer) addLyricsOverlay(inputPath string, opts *VideoRenderOpt
 			"-i", inputPath,
 			"-vf", filterStr,
 			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-preset", vr.encodePreset().Preset,
+			"-crf", vr.encodePreset().CRF,
 			"-y",
 			tempPath,
 		)
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := vr.runFFmpegStep(cmd, tempPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg lyrics overlay failed: %w\nOutput: %s", err, string(output))
 	}
@@ -1035,22 +1133,44 @@ func (vr *VideoRenderer) addLyricsOverlay(inputPath string, opts *VideoRenderOpt
 }
 
 // addAudio adds audio to the video
-// addAudioAndEncode adds audio and encodes final video in one step
-func (vr *VideoRenderer) addAudioAndEncode(videoPath, audioPath string, duration float64, outputPath string) (string, error) {
-	cmd := exec.Command("ffmpeg",
+// addAudioAndEncode adds audio and encodes final video in one step, embedding MP4 tags.
+// If subtitlePath is set it is muxed in as a soft (selectable) subtitle track
+// encoded with subtitleCodec. Fades are timed from the inputs' actual length.
+func (vr *VideoRenderer) addAudioAndEncode(videoPath, audioPath, subtitlePath, subtitleCodec string, duration float64, fades Fades, outputPath string, metadata []string) (string, error) {
+	args := []string{
 		"-i", videoPath,
 		"-i", audioPath,
+	}
+	if subtitlePath != "" {
+		args = append(args,
+			"-i", subtitlePath,
+			"-map", "0:v", "-map", "1:a", "-map", "2:s",
+			"-c:s", subtitleCodec,
+			"-metadata:s:s:0", "language=und",
+		)
+	}
+	if fades.any() {
+		args = append(args, fades.args(fadeLength(videoPath, audioPath, duration))...)
+	}
+	args = append(args,
 		"-c:v", "libx264",
-		"-preset", "medium",
-		"-crf", "23",
+		"-preset", vr.encodePreset().Preset,
+		"-crf", vr.encodePreset().CRF,
 		"-c:a", "aac",
 		"-b:a", "192k",
-		"-shortest",
-		"-y",
-		outputPath,
 	)
+	// -shortest would stop at the last subtitle, so bound by the song duration instead
+	if subtitlePath != "" && duration > 0 {
+		args = append(args, "-t", fmt.Sprintf("%.3f", duration))
+	} else {
+		args = append(args, "-shortest")
+	}
+	args = append(args, metadata...)
+	args = append(args, "-y", outputPath)
 
-	output, err := cmd.CombinedOutput()
+	cmd := exec.Command("ffmpeg", args...)
+
+	output, err := vr.runFFmpegStep(cmd, outputPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg add audio and encode failed: %w\nOutput: %s", err, string(output))
 	}
@@ -1058,6 +1178,80 @@ func (vr *VideoRenderer) addAudioAndEncode(videoPath, audioPath string, duration
 	return outputPath, nil
 }
 
+// ReplaceAudio writes a copy of a rendered video with its audio replaced by
+// audioPath. Video and subtitle streams and the container tags are copied
+// without re-encoding, so an extra audio version of a render only costs an
+// audio encode and a mux. The new audio starts offset seconds in, so it lines
+// up with a song that follows an intro card, and gets the same fades as the
+// original.
+func ReplaceAudio(videoPath, audioPath, outputPath string, duration, offset float64, fade Fade) error {
+	args := []string{
+		"-i", videoPath,
+		"-i", audioPath,
+		"-map", "0:v", "-map", "1:a", "-map", "0:s?",
+		"-map_metadata", "0",
+		"-c:v", "copy",
+		"-c:s", "copy",
+		"-c:a", "aac",
+		"-b:a", "192k",
+	}
+	var audioFilters []string
+	if offset > 0 {
+		audioFilters = append(audioFilters, fmt.Sprintf("adelay=%d:all=1", int(math.Round(offset*1000))))
+	}
+	if filter := fade.fitted(duration-offset).filter("afade", offset); filter != "" {
+		audioFilters = append(audioFilters, filter)
+	}
+	if len(audioFilters) > 0 {
+		args = append(args, "-af", strings.Join(audioFilters, ","))
+	}
+	// Stems can run past the video, so bound by the song when its length is known
+	if duration > 0 {
+		args = append(args, "-t", fmt.Sprintf("%.3f", duration))
+	} else {
+		args = append(args, "-shortest")
+	}
+	args = append(args, "-y", outputPath)
+
+	output, err := exec.Command("ffmpeg", args...).CombinedOutput()
+	if err != nil {
+		os.Remove(outputPath)
+		return fmt.Errorf("ffmpeg replace audio failed: %w\nOutput: %s", err, string(output))
+	}
+	return nil
+}
+
+// metadataArgs builds -metadata arguments for the container tags in opts.
+// Arguments are passed to FFmpeg directly (no shell), so values need no quoting;
+// control characters are stripped so tags stay on one line.
+func metadataArgs(opts *VideoRenderOptions) []string {
+	tags := []struct {
+		key   string
+		value string
+	}{
+		{"title", opts.Title},
+		{"artist", opts.Artist},
+		{"album", opts.Album},
+		{"genre", opts.Genre},
+		{"comment", opts.Comment},
+	}
+
+	var args []string
+	for _, tag := range tags {
+		value := strings.TrimSpace(strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return ' '
+			}
+			return r
+		}, tag.value))
+		if value == "" {
+			continue
+		}
+		args = append(args, "-metadata", tag.key+"="+value)
+	}
+	return args
+}
+
 // copyVideo copies a video file
 func (vr *VideoRenderer) copyVideo(inputPath, outputPath string) (string, error) {
 	cmd := exec.Command("ffmpeg",
@@ -1067,7 +1261,7 @@ func (vr *VideoRenderer) copyVideo(inputPath, outputPath string) (string, error)
 		outputPath,
 	)
 
-	output, err := cmd.CombinedOutput()
+	output, err := vr.runFFmpegStep(cmd, outputPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg copy failed: %w\nOutput: %s", err, string(output))
 	}
@@ -1075,17 +1269,6 @@ func (vr *VideoRenderer) copyVideo(inputPath, outputPath string) (string, error)
 	return outputPath, nil
 }
 
-// escapeText escapes special characters for FFmpeg drawtext
-func (vr *VideoRenderer) escapeText(text string) string {
-	replacer := strings.NewReplacer(
-		"\\", "\\\\",
-		"'", "\\'",
-		":", "\\:",
-		"%", "\\%",
-	)
-	return replacer.Replace(text)
-}
-
 // GetAverageRenderTime returns the average video render time
 func (vr *VideoRenderer) GetAverageRenderTime() time.Duration {
 	if len(vr.RenderTimings) == 0 {
@@ -1098,58 +1281,73 @@ func (vr *VideoRenderer) GetAverageRenderTime() time.Duration {
 	return total / time.Duration(len(vr.RenderTimings))
 }
 
-// sanitizeText removes problematic characters that cause FFmpeg issues
-// Keeps: letters, numbers, spaces, comma, period, question mark, exclamation, dash, parentheses
+// escapeText escapes text for a single-quoted drawtext text='...' value,
+// sanitizing it first when StrictText is enabled
+func (vr *VideoRenderer) escapeText(text string) string {
+	if vr.StrictText {
+		text = sanitizeText(text)
+	}
+	return escapeDrawtext(text)
+}
+
+// escapeDrawtext escapes text for use inside a single-quoted drawtext text='...' value.
+// Unicode is preserved; only the characters that the filtergraph, option parser and
+// drawtext text expansion treat specially are escaped:
+//   - \ and % are escaped for drawtext expansion and again for the option parser
+//   - : is escaped for the option parser
+//   - ' closes the quote, adds an escaped quote, and reopens it
+//   - newlines become spaces since overlays are single-line
+func escapeDrawtext(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", " ")
+	replacer := strings.NewReplacer(
+		"\\", "\\\\\\\\",
+		"%", "\\\\%",
+		":", "\\:",
+		"'", "'\\\\\\''",
+		"\n", " ",
+		"\r", " ",
+	)
+	return replacer.Replace(text)
+}
+
+// sanitizeText removes characters that may be missing from limited fonts (strict mode)
+// Keeps: Latin letters (including accents), numbers, spaces, comma, period,
+// question mark, exclamation, dash, parentheses, apostrophe
 func sanitizeText(text string) string {
 	var result strings.Builder
 	for _, r := range text {
 		switch {
-		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z': // letters
+		case unicode.Is(unicode.Latin, r): // letters
 			result.WriteRune(r)
 		case r >= '0' && r <= '9': // numbers
 			result.WriteRune(r)
-		case r == ' ', r == ',', r == '.', r == '?', r == '!', r == '-', r == '(', r == ')':
+		case r == ' ', r == ',', r == '.', r == '?', r == '!', r == '-', r == '(', r == ')', r == '\'':
 			result.WriteRune(r)
-			// Skip all other characters including quotes, apostrophes, colons, semicolons, etc.
+			// Skip all other characters including symbols, emoji and non-Latin scripts
 		}
 	}
 	return result.String()
 }
 
-// escapeText escapes special characters for FFmpeg drawtext filter
-func escapeText(text string) string {
-	// First sanitize to remove problematic characters
-	text = sanitizeText(text)
-	// Then escape remaining special chars for FFmpeg
-	text = strings.ReplaceAll(text, "\\", "\\\\")
-	text = strings.ReplaceAll(text, "'", "\\'")
-	text = strings.ReplaceAll(text, ":", "\\:")
-	return text
-}
-
 // addASSSubtitles adds ASS karaoke subtitles to the video with logo overlay
 func (vr *VideoRenderer) addASSSubtitles(inputPath, assPath, outputPath string) (string, error) {
 	log.Printf("Adding ASS subtitles from: %s", assPath)
 
 	// Check if artist logo exists for overlay
-	logoPath := filepath.Join(vr.BrandingPath, "artist-logo.png")
-	logoExists := false
-	if _, err := os.Stat(logoPath); err == nil {
-		logoExists = true
-	}
+	logoPath, logoExists := vr.artistLogo()
 
 	var cmd *exec.Cmd
 	if logoExists {
-		// Use filter_complex to add ASS subtitles + logo overlay (256x256 with 70% opacity, bottom-right, 20px margins)
+		// Use filter_complex to add ASS subtitles + logo overlay (sized and placed per vr.Logo)
 		cmd = exec.Command("ffmpeg",
 			"-i", inputPath,
 			"-i", logoPath,
 			"-filter_complex",
-			fmt.Sprintf("[0:v]subtitles=%s[v1];[1:v]scale=256:256,format=rgba,colorchannelmixer=aa=0.7[logo];[v1][logo]overlay=W-w-20:H-h-20[vout]", assPath),
+			"[0:v]"+vr.subtitlesFilter(assPath)+"[v1];"+vr.Logo.logoOverlayFilter("1:v", "v1", "vout"),
 			"-map", "[vout]",
 			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-preset", vr.encodePreset().Preset,
+			"-crf", vr.encodePreset().CRF,
 			"-y",
 			outputPath,
 		)
@@ -1157,16 +1355,16 @@ func (vr *VideoRenderer) addASSSubtitles(inputPath, assPath, outputPath string)
 		// No logo, just ASS subtitles
 		cmd = exec.Command("ffmpeg",
 			"-i", inputPath,
-			"-vf", fmt.Sprintf("subtitles=%s", assPath),
+			"-vf", vr.subtitlesFilter(assPath),
 			"-c:v", "libx264",
-			"-preset", "medium",
-			"-crf", "23",
+			"-preset", vr.encodePreset().Preset,
+			"-crf", vr.encodePreset().CRF,
 			"-y",
 			outputPath,
 		)
 	}
 
-	output, err := cmd.CombinedOutput()
+	output, err := vr.runFFmpegStep(cmd, outputPath)
 	if err != nil {
 		return "", fmt.Errorf("ffmpeg ASS subtitle overlay failed: %w\nOutput: %s", err, string(output))
 	}
@@ -0,0 +1,75 @@
+package video
+
+import "testing"
+
+func TestEscapeDrawtext(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Hello World", "Hello World"},
+		{"apostrophe", "Don't Stop", `Don'\\\''t Stop`},
+		{"colon", "KEY: C", `KEY\: C`},
+		{"percent", "100% Pure", `100\\% Pure`},
+		{"backslash", `AC\DC`, `AC\\\\DC`},
+		{"newlines", "Line one\r\nLine two\nthree", "Line one Line two three"},
+		{"accents", "Café Olé Niño Über", "Café Olé Niño Über"},
+		{"cjk", "東京の夜 서울 밤", "東京の夜 서울 밤"},
+		{"emoji", "Love 💖 Song 🎶", "Love 💖 Song 🎶"},
+		{"mixed", "Rock'n'Roll: 200% 🎸", `Rock'\\\''n'\\\''Roll\: 200\\% 🎸`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeDrawtext(tt.in); got != tt.want {
+				t.Errorf("escapeDrawtext(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "Hello, World!", "Hello, World!"},
+		{"apostrophe", "Don't Stop (Live)", "Don't Stop (Live)"},
+		{"accents", "Café Olé Niño Über", "Café Olé Niño Über"},
+		{"cjk", "東京 Tokyo", " Tokyo"},
+		{"emoji", "Love 💖 Song", "Love  Song"},
+		{"symbols", `KEY: 100% AC\DC`, "KEY 100 ACDC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeText(tt.in); got != tt.want {
+				t.Errorf("sanitizeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		in     string
+		want   string
+	}{
+		{"unicode kept", false, "東京 Café 💖: 50%", `東京 Café 💖\: 50\\%`},
+		{"strict", true, "東京 Café 💖: 50%", " Café  50"},
+		{"strict apostrophe", true, "Don't", `Don'\\\''t`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vr := &VideoRenderer{StrictText: tt.strict}
+			if got := vr.escapeText(tt.in); got != tt.want {
+				t.Errorf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package video
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+)
+
+// Where the spectrum visualizer is drawn in the frame
+const (
+	SpectrumTop        = "top"
+	SpectrumBottom     = "bottom"
+	SpectrumCenter     = "center"
+	SpectrumFullscreen = "fullscreen"
+	SpectrumEdges      = "edges" // Stereo only: bars growing inward from the left and right edges
+)
+
+// SpectrumPositions lists the supported spectrum positions
+var SpectrumPositions = []string{SpectrumTop, SpectrumBottom, SpectrumCenter, SpectrumFullscreen, SpectrumEdges}
+
+// Spectrum heights, as a fraction of the frame height
+const (
+	DefaultSpectrumHeight = 0.25 // Used when a position is set without a height
+	MinSpectrumHeight     = 0.05
+)
+
+// ValidateSpectrumLayout checks a song's spectrum position and height. Both
+// are optional: empty and zero keep the style's default placement.
+func ValidateSpectrumLayout(position string, height float64) error {
+	if position != "" && !isSpectrumPosition(position) {
+		return fmt.Errorf("spectrum_position must be one of: %s", strings.Join(SpectrumPositions, ", "))
+	}
+	if height != 0 && (height < MinSpectrumHeight || height > 1 || math.IsNaN(height)) {
+		return fmt.Errorf("spectrum_height must be between %.2f and 1", MinSpectrumHeight)
+	}
+	return nil
+}
+
+// isSpectrumPosition reports whether position is supported
+func isSpectrumPosition(position string) bool {
+	for _, p := range SpectrumPositions {
+		if position == p {
+			return true
+		}
+	}
+	return false
+}
+
+// spectrumLayout is the size the spectrum is drawn at and where it's placed
+type spectrumLayout struct {
+	Position string
+	Width    int
+	Height   int
+	Y        int // Top edge of the overlay
+}
+
+// spectrumLayout works out where a style's spectrum goes. Without a position
+// or height it returns a full-frame layout and false, so callers keep the
+// style's built-in placement. A height without a position goes at the
+// bottom. The stereo style only draws at the edges, and only stereo can.
+func (vr *VideoRenderer) spectrumLayout(style string, opts *VideoRenderOptions) (spectrumLayout, bool) {
+	full := spectrumLayout{Position: SpectrumFullscreen, Width: vr.Width, Height: vr.Height}
+	position, height := opts.SpectrumPosition, opts.SpectrumHeight
+
+	if style == SpectrumStereo {
+		if (position != "" && position != SpectrumEdges) || height > 0 {
+			log.Printf("Warning: the stereo spectrum is always drawn at the edges, ignoring position %q and height %.2f", position, height)
+		}
+		return full, false
+	}
+	if position == SpectrumEdges || (position != "" && !isSpectrumPosition(position)) {
+		log.Printf("Warning: spectrum position %q isn't supported by the %s style, using its default", position, style)
+		position = ""
+	}
+	if position == "" && height <= 0 {
+		return full, false
+	}
+
+	if position == "" {
+		position = SpectrumBottom
+	}
+	if position == SpectrumFullscreen {
+		return full, true
+	}
+	if height < MinSpectrumHeight || height > 1 {
+		height = DefaultSpectrumHeight
+	}
+
+	layout := spectrumLayout{Position: position, Width: vr.Width, Height: int(float64(vr.Height)*height) &^ 1}
+	layout.Y = spectrumOffset(position, vr.Height, layout.Height)
+	return layout, true
+}
+
+// spectrumOffset returns the top edge of an overlay height pixels tall at
+// position in a frame frameHeight pixels tall
+func spectrumOffset(position string, frameHeight, height int) int {
+	switch position {
+	case SpectrumBottom:
+		return frameHeight - height
+	case SpectrumCenter:
+		return (frameHeight - height) / 2
+	}
+	return 0
+}
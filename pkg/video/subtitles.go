@@ -0,0 +1,106 @@
+package video
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Lyric render modes for the non-karaoke lyric path
+const (
+	LyricRenderDrawtext  = "drawtext"  // One drawtext filter per display line (default)
+	LyricRenderSubtitles = "subtitles" // Burn an ASS generated from the timed lines
+)
+
+// LyricRenderModes lists the supported lyric render modes
+var LyricRenderModes = []string{LyricRenderDrawtext, LyricRenderSubtitles}
+
+// NormalizeLyricRenderMode returns mode if supported, otherwise drawtext
+func NormalizeLyricRenderMode(mode string) string {
+	if mode == LyricRenderSubtitles {
+		return mode
+	}
+	return LyricRenderDrawtext
+}
+
+// subtitleCodecFor returns the soft subtitle codec the output container supports.
+// MP4/MOV only carry mov_text (styling is dropped); MKV keeps the ASS as-is.
+func subtitleCodecFor(outputPath string) (string, error) {
+	switch strings.ToLower(filepath.Ext(outputPath)) {
+	case ".mp4", ".m4v", ".mov":
+		return "mov_text", nil
+	case ".mkv":
+		return "ass", nil
+	case ".webm":
+		return "webvtt", nil
+	default:
+		return "", fmt.Errorf("container %q does not support soft subtitles", filepath.Ext(outputPath))
+	}
+}
+
+// lyricsASSHeader styles lyrics like the scroll theme's active line: royal blue,
+// white outline, centered. Colors are &HAABBGGRR. Font size and margins are
+// filled in scaled to the frame height.
+const lyricsASSHeader = `[Script Info]
+ScriptType: v4.00+
+PlayResX: %d
+PlayResY: %d
+WrapStyle: 0
+ScaledBorderAndShadow: yes
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Lyrics,DejaVu Sans Condensed,%d,&H00E16941,&H00FFFFFF,&H00FFFFFF,&H80000000,-1,0,0,0,100,100,0,0,1,3,2,5,%d,%d,0,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+`
+
+// WriteLyricsASS writes timed lyric lines as an ASS subtitle file sized for a
+// width x height video. Line timings are shifted by vocalOnset; libass wraps
+// long lines itself. Styling is designed at 1024 lines tall and scaled to height.
+func WriteLyricsASS(lines []LyricLine, vocalOnset float64, width, height int, path string) error {
+	if vocalOnset < 0 {
+		vocalOnset = 0
+	}
+
+	scale := float64(height) / 1024
+	fontSize := int(64*scale + 0.5)
+	margin := int(160*scale + 0.5)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, lyricsASSHeader, width, height, fontSize, margin, margin)
+	for _, line := range lines {
+		text := assEscape(line.Text)
+		if text == "" || line.EndTime <= line.StartTime {
+			continue
+		}
+		fmt.Fprintf(&b, "Dialogue: 0,%s,%s,Lyrics,,0,0,0,,%s\n",
+			assTimestamp(line.StartTime+vocalOnset), assTimestamp(line.EndTime+vocalOnset), text)
+	}
+
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// assTimestamp formats seconds as ASS h:mm:ss.cc
+func assTimestamp(seconds float64) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	cs := int(seconds*100 + 0.5)
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, (cs/6000)%60, (cs/100)%60, cs%100)
+}
+
+// assEscape keeps lyric text from being read as ASS override tags
+func assEscape(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.NewReplacer(
+		"\\", "/",
+		"{", "(",
+		"}", ")",
+		"\r\n", "\\N",
+		"\n", "\\N",
+	).Replace(text)
+	return text
+}
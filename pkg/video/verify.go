@@ -0,0 +1,154 @@
+package video
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DurationTolerance is how far (in seconds) a rendered video's length may
+// drift from the song's before verification fails; encoders pad or trim a
+// few frames at the ends
+const DurationTolerance = 2.0
+
+// ProbeStream summarizes one stream of a rendered file
+type ProbeStream struct {
+	Index     int    `json:"index"`
+	CodecType string `json:"codec_type"` // video, audio, subtitle
+	CodecName string `json:"codec_name"`
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	Channels  int    `json:"channels,omitempty"` // Audio streams only
+}
+
+// ProbeResult is what ffprobe reports about a rendered file
+type ProbeResult struct {
+	Duration float64       `json:"duration"`
+	Width    int           `json:"width"`
+	Height   int           `json:"height"`
+	Streams  []ProbeStream `json:"streams"`
+}
+
+// HasStream reports whether the file has a stream of the given codec type
+func (pr *ProbeResult) HasStream(codecType string) bool {
+	for _, s := range pr.Streams {
+		if s.CodecType == codecType {
+			return true
+		}
+	}
+	return false
+}
+
+// AudioChannels returns the channel count of the first audio stream, or 0
+// if there is none
+func (pr *ProbeResult) AudioChannels() int {
+	for _, s := range pr.Streams {
+		if s.CodecType == "audio" {
+			return s.Channels
+		}
+	}
+	return 0
+}
+
+// Resolution formats the video stream's frame size, e.g. "1920x1080"
+func (pr *ProbeResult) Resolution() string {
+	return fmt.Sprintf("%dx%d", pr.Width, pr.Height)
+}
+
+// ProbeVideo runs ffprobe on a file and returns its duration and streams
+func ProbeVideo(path string) (*ProbeResult, error) {
+	cmd := exec.Command("ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration:stream=index,codec_type,codec_name,width,height,channels",
+		"-of", "json",
+		path,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return nil, fmt.Errorf("ffprobe failed: %w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var raw struct {
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+		Streams []ProbeStream `json:"streams"`
+	}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	result := &ProbeResult{Streams: raw.Streams}
+	if raw.Format.Duration != "" {
+		result.Duration, err = strconv.ParseFloat(raw.Format.Duration, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration %q from ffprobe", raw.Format.Duration)
+		}
+	}
+	for _, s := range raw.Streams {
+		if s.CodecType == "video" {
+			result.Width, result.Height = s.Width, s.Height
+			break
+		}
+	}
+	return result, nil
+}
+
+// VerifyVideo confirms a rendered file is a playable MP4 with video and audio
+// streams lasting expectedDuration (within DurationTolerance; zero skips the
+// check). It returns the probe so callers can record what was produced.
+func VerifyVideo(path string, expectedDuration float64) (*ProbeResult, error) {
+	probe, err := ProbeVideo(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !probe.HasStream("video") {
+		return probe, fmt.Errorf("%s has no video stream", path)
+	}
+	if !probe.HasStream("audio") {
+		return probe, fmt.Errorf("%s has no audio stream", path)
+	}
+	if probe.Width <= 0 || probe.Height <= 0 {
+		return probe, fmt.Errorf("%s has an invalid frame size %s", path, probe.Resolution())
+	}
+	if probe.Duration <= 0 {
+		return probe, fmt.Errorf("%s has no duration", path)
+	}
+	if expectedDuration > 0 && math.Abs(probe.Duration-expectedDuration) > DurationTolerance {
+		return probe, fmt.Errorf("%s is %.2fs long, expected %.2fs (±%.0fs)", path, probe.Duration, expectedDuration, DurationTolerance)
+	}
+
+	if err := checkDecodable(path, probe.Duration); err != nil {
+		return probe, err
+	}
+	return probe, nil
+}
+
+// checkDecodable decodes a second from the start and from the end of the
+// file, which is where truncated or corrupt renders break
+func checkDecodable(path string, duration float64) error {
+	for _, seek := range []float64{0, math.Max(duration-1, 0)} {
+		cmd := exec.Command("ffmpeg",
+			"-v", "error",
+			"-ss", fmt.Sprintf("%.2f", seek),
+			"-i", path,
+			"-t", "1",
+			"-f", "null", "-",
+		)
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s is not playable at %.2fs: %w: %s", path, seek, err, strings.TrimSpace(string(output)))
+		}
+		if decodeErrors := strings.TrimSpace(string(output)); decodeErrors != "" {
+			return fmt.Errorf("%s has decode errors at %.2fs: %s", path, seek, decodeErrors)
+		}
+	}
+	return nil
+}
@@ -65,6 +65,43 @@ def split_text_intelligently(words_data, max_chars):
     
     return lines
 
+def is_cjk(char):
+    """
+    True for scripts written without spaces between words (CJK, kana, hangul syllables)
+    """
+    code = ord(char)
+    return (
+        0x3040 <= code <= 0x30FF or   # Hiragana / Katakana
+        0x3400 <= code <= 0x4DBF or   # CJK Extension A
+        0x4E00 <= code <= 0x9FFF or   # CJK Unified Ideographs
+        0xAC00 <= code <= 0xD7AF or   # Hangul syllables
+        0xF900 <= code <= 0xFAFF      # CJK Compatibility Ideographs
+    )
+
+def tokenize_line(line):
+    """
+    Split a lyric line into words. Whitespace-separated scripts split on spaces;
+    CJK runs are split per character to match Whisper's word timings.
+    Non-ASCII text is preserved as-is.
+    """
+    tokens = []
+    for chunk in line.split():
+        if not any(is_cjk(c) for c in chunk):
+            tokens.append(chunk)
+            continue
+        buffer = ""
+        for c in chunk:
+            if is_cjk(c):
+                if buffer:
+                    tokens.append(buffer)
+                    buffer = ""
+                tokens.append(c)
+            else:
+                buffer += c
+        if buffer:
+            tokens.append(buffer)
+    return tokens
+
 def align_lyrics_with_timings(whisper_segments, actual_lyrics_lines):
     """
     Align actual lyrics with Whisper timings
@@ -77,7 +114,7 @@ def align_lyrics_with_timings(whisper_segments, actual_lyrics_lines):
     # Extract all words from actual lyrics
     actual_words = []
     for line in actual_lyrics_lines:
-        line_words = line.split()
+        line_words = tokenize_line(line)
         actual_words.extend(line_words)
     
     # Align word-by-word, preserving Whisper segment boundaries
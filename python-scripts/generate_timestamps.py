@@ -8,7 +8,15 @@ import json
 import argparse
 import sys
 
-def try_whisperx(vocals_path, output_json, model_name="large-v3"):
+def whisper_language(language):
+    """
+    Map the requested language to Whisper's argument ("auto" -> None lets Whisper detect it)
+    """
+    if not language or language == "auto":
+        return None
+    return language
+
+def try_whisperx(vocals_path, output_json, model_name="large-v3", language="en"):
     """
     Try WhisperX on GPU first
     """
@@ -41,18 +49,20 @@ def try_whisperx(vocals_path, output_json, model_name="large-v3"):
             model_name, 
             device, 
             compute_type=compute_type,
-            language="en"
+            language=whisper_language(language)
         )
         
         # 2. Transcribe with timestamps
         print(f"Transcribing {vocals_path}...")
         audio = whisperx.load_audio(vocals_path)
         result = model.transcribe(audio, batch_size=batch_size)
+        detected_language = result.get("language") or language
+        print(f"Language: {detected_language}")
         
         # 3. Align whisper output
         print("Aligning timestamps...")
         model_a, metadata = whisperx.load_align_model(
-            language_code=result.get("language", "en"),
+            language_code=detected_language,
             device=device
         )
         
@@ -72,7 +82,7 @@ def try_whisperx(vocals_path, output_json, model_name="large-v3"):
         del model_a
         
         # 4. Save result
-        output_data = {"segments": result["segments"], "language": "en", "method": "whisperx"}
+        output_data = {"segments": result["segments"], "language": detected_language, "method": "whisperx"}
         
         with open(output_json, 'w', encoding='utf-8') as f:
             json.dump(output_data, f, indent=2, ensure_ascii=False)
@@ -88,7 +98,7 @@ def try_whisperx(vocals_path, output_json, model_name="large-v3"):
         print("Falling back to Faster-Whisper on CPU...")
         return False
 
-def use_faster_whisper(vocals_path, output_json, model_size="base"):
+def use_faster_whisper(vocals_path, output_json, model_size="base", language="en"):
     """
     Fallback to Faster-Whisper on CPU
     """
@@ -101,7 +111,7 @@ def use_faster_whisper(vocals_path, output_json, model_size="base"):
     segments, info = model.transcribe(
         vocals_path,
         word_timestamps=True,
-        language="en",
+        language=whisper_language(language),
         vad_filter=True,
         vad_parameters=dict(min_silence_duration_ms=500)
     )
@@ -136,7 +146,7 @@ def use_faster_whisper(vocals_path, output_json, model_size="base"):
     print(f"✓ Faster-Whisper: Saved to {output_json}")
     print(f"✓ Transcribed {len(result['segments'])} segments with {total_words} words")
 
-def generate_word_timestamps(vocals_path, output_json, model_name="large-v3", force_cpu=False):
+def generate_word_timestamps(vocals_path, output_json, model_name="large-v3", force_cpu=False, language="en"):
     """
     Generate word-level timestamps with automatic fallback
     
@@ -145,10 +155,11 @@ def generate_word_timestamps(vocals_path, output_json, model_name="large-v3", fo
         output_json: Where to save timestamps  
         model_name: Model size (for both WhisperX and Faster-Whisper)
         force_cpu: Skip WhisperX and use Faster-Whisper directly
+        language: ISO language code, or "auto" to detect
     """
     # Try WhisperX first unless forcing CPU
     if not force_cpu:
-        if try_whisperx(vocals_path, output_json, model_name):
+        if try_whisperx(vocals_path, output_json, model_name, language):
             return
     
     # Fallback to Faster-Whisper
@@ -163,19 +174,20 @@ def generate_word_timestamps(vocals_path, output_json, model_name="large-v3", fo
         "tiny": "tiny"
     }
     faster_model = model_map.get(model_name, "base")
-    use_faster_whisper(vocals_path, output_json, faster_model)
+    use_faster_whisper(vocals_path, output_json, faster_model, language)
 
 def main():
     parser = argparse.ArgumentParser(description='Generate word-level timestamps with GPU/CPU fallback')
     parser.add_argument('--vocals', required=True, help='Path to vocals.wav')
     parser.add_argument('--output', required=True, help='Output JSON file')
     parser.add_argument('--model', default='large-v3', help='Model size (default: large-v3)')
+    parser.add_argument('--language', default='en', help='ISO language code, or "auto" to detect (default: en)')
     parser.add_argument('--force-cpu', action='store_true', help='Force CPU mode (skip WhisperX)')
     
     args = parser.parse_args()
     
     try:
-        generate_word_timestamps(args.vocals, args.output, args.model, args.force_cpu)
+        generate_word_timestamps(args.vocals, args.output, args.model, args.force_cpu, args.language)
         sys.exit(0)
     except Exception as e:
         print(f"ERROR: {e}", file=sys.stderr)
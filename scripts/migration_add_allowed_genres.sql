@@ -0,0 +1,5 @@
+-- Migration: Add allowed genre list to settings
+-- Purpose: Let operators extend the genres the enrichment LLM may choose from
+--          without recompiling (stored as a JSON array; empty uses the built-in 15)
+
+ALTER TABLE settings ADD COLUMN allowed_genres TEXT DEFAULT '';
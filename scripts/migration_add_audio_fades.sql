@@ -0,0 +1,7 @@
+-- Migration: Add audio fade in/out to songs
+-- Purpose: Fade the song in from silence and out to silence (with the picture
+--          fading from and to black) over the given number of seconds.
+--          Zero keeps the abrupt start and end.
+
+ALTER TABLE songs ADD COLUMN audio_fade_in REAL DEFAULT 0;
+ALTER TABLE songs ADD COLUMN audio_fade_out REAL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Migration: Add beat_times to songs table
+-- Purpose: Persist beat times from audio analysis so image transitions can snap to the beat
+
+ALTER TABLE songs ADD COLUMN beat_times TEXT DEFAULT '';
@@ -0,0 +1,10 @@
+-- Migration: Add a default copyright line to settings
+-- Purpose: Videos and YouTube descriptions use each song's copyright_text
+--          instead of a hardcoded line. NULL uses this default at render time;
+--          an empty string draws none. Videos ignored copyright_text until
+--          now, so existing songs without one follow a default set to the
+--          line they were rendered with; change it here or in settings.
+
+ALTER TABLE settings ADD COLUMN default_copyright_text TEXT DEFAULT '';
+UPDATE settings SET default_copyright_text = 'All content Copyright 2017-2026 Nlaak Studios';
+UPDATE songs SET copyright_text = NULL WHERE copyright_text = '';
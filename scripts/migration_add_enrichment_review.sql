@@ -0,0 +1,6 @@
+-- Migration: Add enrichment review workflow
+-- Purpose: Hold AI metadata proposals on the song (as JSON) until a user approves or
+--          edits them. auto_apply_enrichment restores the fully-automated behavior.
+
+ALTER TABLE songs ADD COLUMN pending_enrichment TEXT DEFAULT '';
+ALTER TABLE settings ADD COLUMN auto_apply_enrichment BOOLEAN DEFAULT 0;
@@ -0,0 +1,6 @@
+-- Migration: Record where a song's genre came from
+-- Purpose: Distinguish genres entered by the user from ones detected by audio
+--          analysis or inferred by the LLM ('user', 'analysis' or 'ai').
+--          Existing songs are left empty since their source isn't known.
+
+ALTER TABLE songs ADD COLUMN genre_source TEXT;
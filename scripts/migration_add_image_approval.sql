@@ -0,0 +1,8 @@
+-- Migration: Add image approval workflow
+-- Purpose: Allow songs to pause after image generation until the backgrounds are approved
+
+-- Per-image approval flag
+ALTER TABLE generated_images ADD COLUMN approved BOOLEAN DEFAULT 0;
+
+-- Per-song setting to require approval before rendering
+ALTER TABLE songs ADD COLUMN require_image_approval BOOLEAN DEFAULT 0;
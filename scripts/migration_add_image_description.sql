@@ -0,0 +1,4 @@
+-- Migration: Add description to generated_images table
+-- Purpose: Vision-model description of the generated image, used as alt-text and to verify it matches the prompt
+
+ALTER TABLE generated_images ADD COLUMN description TEXT;
@@ -0,0 +1,5 @@
+-- Migration: Add image_fit to songs table
+-- Purpose: Choose how background images that don't match the frame's aspect ratio are
+--          fitted: letterbox (black bars), crop (fill and trim) or blur-fill (blurred backdrop)
+
+ALTER TABLE songs ADD COLUMN image_fit TEXT DEFAULT 'letterbox';
@@ -0,0 +1,4 @@
+-- Migration: Add perceptual hash to generated images
+-- Purpose: Detect near-identical backgrounds across sections (64-bit pHash stored as hex)
+
+ALTER TABLE generated_images ADD COLUMN phash TEXT;
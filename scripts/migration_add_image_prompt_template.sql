@@ -0,0 +1,6 @@
+-- Migration: Add image prompt templates
+-- Purpose: Let users control how the LLM is steered when writing image prompts,
+--          with optional per-section-type overrides (stored as a JSON object)
+
+ALTER TABLE settings ADD COLUMN image_prompt_template TEXT DEFAULT '';
+ALTER TABLE settings ADD COLUMN image_prompt_section_templates TEXT DEFAULT '';
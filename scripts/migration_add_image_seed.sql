@@ -0,0 +1,4 @@
+-- Migration: Add seed to generated_images table
+-- Purpose: Diffusion seed the image was generated with, so regeneration can keep the composition while the prompt is tweaked
+
+ALTER TABLE generated_images ADD COLUMN seed INTEGER;
@@ -0,0 +1,5 @@
+-- Migration: Add variant tracking to generated_images table
+-- Purpose: Alternative candidates generated from an image's prompt with different seeds; only non-variant (active) images are rendered
+
+ALTER TABLE generated_images ADD COLUMN parent_image_id INTEGER REFERENCES generated_images(id) ON DELETE CASCADE;
+ALTER TABLE generated_images ADD COLUMN is_variant BOOLEAN DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Migration: Add instrumental flag to songs table
+-- Purpose: Instrumental tracks skip lyrics processing and karaoke, and spread images evenly
+
+ALTER TABLE songs ADD COLUMN instrumental BOOLEAN DEFAULT 0;
@@ -0,0 +1,8 @@
+-- Migration: Add an intro title card to songs
+-- Purpose: Optionally show the title, artist and album over the album art (or
+--          a solid color) for a few seconds before the song starts
+
+ALTER TABLE songs ADD COLUMN intro_card_enabled BOOLEAN DEFAULT 0;
+ALTER TABLE songs ADD COLUMN intro_duration REAL DEFAULT 0;
+ALTER TABLE songs ADD COLUMN intro_background_color TEXT;
+ALTER TABLE songs ADD COLUMN intro_text_color TEXT;
@@ -0,0 +1,9 @@
+-- Migration: Add intro countdown settings to songs table
+-- Purpose: Let songs hide or restyle the "Starting in Ns" countdown shown before the vocals.
+--          Zero/empty values use the built-in defaults.
+
+ALTER TABLE songs ADD COLUMN hide_countdown BOOLEAN DEFAULT 0;
+ALTER TABLE songs ADD COLUMN countdown_threshold REAL DEFAULT 0;
+ALTER TABLE songs ADD COLUMN countdown_bar_width INTEGER DEFAULT 0;
+ALTER TABLE songs ADD COLUMN countdown_color TEXT DEFAULT '';
+ALTER TABLE songs ADD COLUMN countdown_text TEXT DEFAULT '';
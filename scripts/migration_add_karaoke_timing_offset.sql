@@ -0,0 +1,4 @@
+-- Migration: Add karaoke timing offset to songs table
+-- Purpose: Shift karaoke subtitles uniformly when lyrics lead or lag the audio
+
+ALTER TABLE songs ADD COLUMN karaoke_timing_offset REAL DEFAULT 0;
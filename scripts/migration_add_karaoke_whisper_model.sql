@@ -0,0 +1,4 @@
+-- Migration: Add karaoke whisper model selection to songs table
+-- Purpose: Choose Whisper model quality (tiny/base/small/medium/large-v3) for karaoke timing
+
+ALTER TABLE songs ADD COLUMN karaoke_whisper_model TEXT DEFAULT 'base';
@@ -0,0 +1,7 @@
+-- Migration: Add Ken Burns settings to songs table
+-- Purpose: Optional slow zoom/pan over static images so long instrumental sections
+--          aren't visually dead. Off by default; zero/empty values use the defaults.
+
+ALTER TABLE songs ADD COLUMN enable_ken_burns BOOLEAN DEFAULT 0;
+ALTER TABLE songs ADD COLUMN ken_burns_zoom_rate REAL DEFAULT 0;
+ALTER TABLE songs ADD COLUMN ken_burns_direction TEXT DEFAULT '';
@@ -0,0 +1,9 @@
+-- Migration: Add artist logo placement to settings
+-- Purpose: Make the logo overlay's size, opacity, corner and margin
+--          configurable. Zero or empty keeps the built-in 256px logo at 70%
+--          opacity, 20px from the bottom-right corner.
+
+ALTER TABLE settings ADD COLUMN logo_size INTEGER DEFAULT 0;
+ALTER TABLE settings ADD COLUMN logo_opacity REAL DEFAULT 0;
+ALTER TABLE settings ADD COLUMN logo_corner TEXT DEFAULT '';
+ALTER TABLE settings ADD COLUMN logo_margin INTEGER DEFAULT 0;
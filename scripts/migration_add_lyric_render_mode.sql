@@ -0,0 +1,5 @@
+-- Migration: Add lyric_render_mode and soft_subtitles to songs table
+-- Purpose: Render non-karaoke lyrics with drawtext or a burned-in ASS, and optionally embed a soft subtitle track
+
+ALTER TABLE songs ADD COLUMN lyric_render_mode TEXT DEFAULT 'drawtext';
+ALTER TABLE songs ADD COLUMN soft_subtitles BOOLEAN DEFAULT 0;
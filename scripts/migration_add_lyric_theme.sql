@@ -0,0 +1,4 @@
+-- Migration: Add lyric_theme to songs table
+-- Purpose: Select the lyric overlay layout (scroll, single-line-bottom, two-line-karaoke-box, fade)
+
+ALTER TABLE songs ADD COLUMN lyric_theme TEXT DEFAULT 'scroll';
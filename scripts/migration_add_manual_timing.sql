@@ -0,0 +1,4 @@
+-- Migration: Add manual lyric timing flag to songs table
+-- Purpose: Mark lyrics_display as hand-corrected so renders don't re-align it
+
+ALTER TABLE songs ADD COLUMN manual_timing BOOLEAN DEFAULT 0;
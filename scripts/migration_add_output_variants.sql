@@ -0,0 +1,6 @@
+-- Migration: Add output variants to songs and a variant label to videos
+-- Purpose: Render extra audio versions of a song (instrumental, vocals) that share the
+--          main render's visuals, each stored as its own video record
+
+ALTER TABLE songs ADD COLUMN output_variants TEXT;
+ALTER TABLE videos ADD COLUMN variant TEXT DEFAULT 'mixed';
@@ -0,0 +1,6 @@
+-- Migration: Add an outro card to songs
+-- Purpose: Append a branded end screen (an image or short video) after the
+--          main video, crossfading into it
+
+ALTER TABLE songs ADD COLUMN outro_card_path TEXT;
+ALTER TABLE songs ADD COLUMN outro_duration REAL DEFAULT 0;
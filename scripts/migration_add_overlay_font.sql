@@ -0,0 +1,4 @@
+-- Migration: Add overlay font setting
+-- Purpose: Name of an uploaded font (see /api/v1/fonts) used for title, metadata and countdown overlays
+
+ALTER TABLE settings ADD COLUMN overlay_font TEXT DEFAULT '';
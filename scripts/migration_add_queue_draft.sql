@@ -0,0 +1,4 @@
+-- Migration: Add draft renders to the queue
+-- Purpose: Flag queue items that render a low-resolution preview instead of the final video
+
+ALTER TABLE queue ADD COLUMN draft BOOLEAN DEFAULT 0;
@@ -0,0 +1,16 @@
+-- Migration: Add queue_events table
+-- Purpose: Record every queue item status change and pipeline phase start with its
+--          timestamp, so a render's full timeline (waits, retries) can be reviewed
+
+CREATE TABLE IF NOT EXISTS queue_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    queue_id INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    step TEXT,
+    message TEXT,
+    retry_count INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (queue_id) REFERENCES queue(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_events_queue ON queue_events(queue_id);
@@ -0,0 +1,4 @@
+-- Migration: Add last_heartbeat to queue table
+-- Purpose: Persist a periodic heartbeat while processing so items orphaned by a crash can be reclaimed
+
+ALTER TABLE queue ADD COLUMN last_heartbeat TIMESTAMP;
@@ -0,0 +1,4 @@
+-- Migration: Add last_phase to queue table
+-- Purpose: Record the last completed pipeline phase so failed jobs can resume instead of restarting
+
+ALTER TABLE queue ADD COLUMN last_phase TEXT DEFAULT '';
@@ -0,0 +1,4 @@
+-- Migration: Add request IDs to queue items
+-- Purpose: Correlate renders with the API request (X-Request-ID) that queued them
+
+ALTER TABLE queue ADD COLUMN request_id TEXT DEFAULT '';
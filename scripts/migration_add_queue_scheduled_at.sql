@@ -0,0 +1,5 @@
+-- Migration: Add a scheduled start time to queue items
+-- Purpose: Let renders be queued now but held until a later time (e.g. overnight,
+--          when the GPU is free); the worker skips items scheduled in the future
+
+ALTER TABLE queue ADD COLUMN scheduled_at TIMESTAMP;
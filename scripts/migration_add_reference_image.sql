@@ -0,0 +1,6 @@
+-- Migration: Add a reference image to songs
+-- Purpose: Generate every background of a song from one reference image (img2img)
+--          so an album shares a consistent look
+
+ALTER TABLE songs ADD COLUMN reference_image_path TEXT;
+ALTER TABLE songs ADD COLUMN reference_strength REAL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Migration: Add encode quality presets
+-- Purpose: Per-song quality (draft, standard, high, archive) with a global default in settings
+
+ALTER TABLE songs ADD COLUMN quality TEXT DEFAULT '';
+ALTER TABLE settings ADD COLUMN default_quality TEXT DEFAULT 'standard';
@@ -0,0 +1,4 @@
+-- Migration: Add soft_subtitles_only to songs table
+-- Purpose: Render a clean video with lyrics only in the embedded (toggleable) subtitle track
+
+ALTER TABLE songs ADD COLUMN soft_subtitles_only BOOLEAN DEFAULT 0;
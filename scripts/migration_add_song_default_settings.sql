@@ -0,0 +1,9 @@
+-- Migration: Add default render preferences to settings
+-- Purpose: Let an operator set a house style once; new songs that leave these
+--          unset take them from settings. Empty or zero keeps the built-in defaults.
+
+ALTER TABLE settings ADD COLUMN default_background_style TEXT DEFAULT '';
+ALTER TABLE settings ADD COLUMN default_spectrum_color TEXT DEFAULT '';
+ALTER TABLE settings ADD COLUMN default_spectrum_opacity REAL DEFAULT 0;
+ALTER TABLE settings ADD COLUMN default_target_resolution TEXT DEFAULT '';
+ALTER TABLE settings ADD COLUMN default_lyric_theme TEXT DEFAULT '';
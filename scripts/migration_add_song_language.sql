@@ -0,0 +1,4 @@
+-- Migration: Add language to songs table
+-- Purpose: ISO language code passed to Whisper for karaoke timing ("auto" detects and stores the result)
+
+ALTER TABLE songs ADD COLUMN language TEXT DEFAULT 'en';
@@ -0,0 +1,6 @@
+-- Migration: Add per-song master prompt overrides
+-- Purpose: Give a song (e.g. one track of a concept album) its own visual language,
+--          taking precedence over the global master prompt and negative prompt
+
+ALTER TABLE songs ADD COLUMN master_prompt_override TEXT DEFAULT '';
+ALTER TABLE songs ADD COLUMN master_negative_override TEXT DEFAULT '';
@@ -0,0 +1,7 @@
+-- Migration: Add spectrum overlay position and height to songs
+-- Purpose: Let a song place the spectrum visualizer at the top, bottom,
+--          center or full screen, at a chosen fraction of the frame height.
+--          Empty/zero keep each style's built-in placement.
+
+ALTER TABLE songs ADD COLUMN spectrum_position TEXT;
+ALTER TABLE songs ADD COLUMN spectrum_height REAL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Migration: Add video fade in/out to songs
+-- Purpose: Fade the picture in from black and out to black over the given
+--          number of seconds in the final encode, over every overlay.
+--          Zero (the default) doesn't fade the picture.
+
+ALTER TABLE songs ADD COLUMN video_fade_in REAL DEFAULT 0;
+ALTER TABLE songs ADD COLUMN video_fade_out REAL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Migration: Add video filename template to settings
+-- Purpose: Configurable output naming, e.g. "{artist}-{title}-{id}" (default keeps "{title}")
+
+ALTER TABLE settings ADD COLUMN video_filename_template TEXT DEFAULT '{title}';
@@ -0,0 +1,6 @@
+-- Migration: Add probed stream details to videos table
+-- Purpose: Record the frame size and streams ffprobe found when verifying a render
+
+ALTER TABLE videos ADD COLUMN width INTEGER DEFAULT 0;
+ALTER TABLE videos ADD COLUMN height INTEGER DEFAULT 0;
+ALTER TABLE videos ADD COLUMN streams TEXT;
@@ -0,0 +1,4 @@
+-- Migration: Add vocal onset override to songs table
+-- Purpose: Let songs correct a misdetected vocal onset without re-analysis
+
+ALTER TABLE songs ADD COLUMN vocal_onset_override REAL;
@@ -0,0 +1,4 @@
+-- Migration: Add YouTube description template
+-- Purpose: Let users customize the format of generated YouTube descriptions
+
+ALTER TABLE settings ADD COLUMN youtube_description_template TEXT DEFAULT '';
@@ -55,7 +55,7 @@ CREATE TABLE IF NOT EXISTS songs (
     
     -- Branding
     brand_logo_path TEXT,
-    copyright_text TEXT DEFAULT 'All content Copyright 2017-2026 Nlaak Studios',
+    copyright_text TEXT,  -- NULL uses the settings default; '' draws none
     
     -- Video settings
     background_style TEXT DEFAULT 'cinematic',
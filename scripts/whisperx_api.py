@@ -78,7 +78,8 @@ def run_whisperx_script(audio_path: str, language: str = "en", model: str = "lar
         
         # Transcribe
         logger.info("Starting transcription...")
-        result = model.transcribe(audio_path, language=language)
+        # "auto" lets Whisper detect the language; the result reports what it found
+        result = model.transcribe(audio_path, language=None if language == "auto" else language)
         logger.info("Transcription completed")
         
         # Convert to desired format